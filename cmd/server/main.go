@@ -4,18 +4,25 @@ import (
 	"context"
 	"log"
 	"net/http"
+	"net/url"
 	"os"
 	"os/signal"
 	"syscall"
 	"time"
+	_ "time/tzdata" // time zone ของผู้ใช้ (My Work) ใช้ได้แม้ image ไม่มี /usr/share/zoneinfo
 
 	"task-manager/internal/api"
 	"task-manager/internal/auth"
 	"task-manager/internal/config"
 	"task-manager/internal/db"
+	"task-manager/internal/domain"
+	"task-manager/internal/mail"
 	"task-manager/internal/middleware"
+	"task-manager/internal/ratelimit"
 	"task-manager/internal/repo"
 	"task-manager/internal/service"
+	"task-manager/internal/validate"
+	"task-manager/pkg/response"
 
 	"github.com/gin-gonic/gin"
 	"github.com/joho/godotenv"
@@ -43,40 +50,157 @@ func main() {
 
 	// DI
 	j := auth.NewJWT(cfg)
-	pw := auth.NewPasswordHasher()
+	var pw auth.PasswordHasher
+	switch cfg.PasswordHasher {
+	case "argon2id":
+		pw = auth.NewArgon2idHasher(auth.Argon2Params{
+			MemoryKiB:   uint32(cfg.Argon2MemoryKiB),
+			Iterations:  uint32(cfg.Argon2Iterations),
+			Parallelism: uint8(cfg.Argon2Parallelism),
+			SaltLength:  auth.DefaultArgon2Params.SaltLength,
+			KeyLength:   auth.DefaultArgon2Params.KeyLength,
+		})
+	case "bcrypt":
+		pw = auth.NewBcryptHasher(cfg.BcryptCost)
+	default:
+		log.Fatalf("invalid PASSWORD_HASHER %q (want argon2id or bcrypt)", cfg.PasswordHasher)
+	}
+	pwPolicy := auth.DefaultPasswordPolicy.ForHasher(cfg.PasswordHasher)
+	pwPolicy.MinLength = cfg.PasswordMinLength
+	validate.SetPasswordPolicy(pwPolicy)
 
 	userRepo := repo.NewUserRepo(database)
 	taskRepo := repo.NewTaskRepo(database)
+	statusRepo := repo.NewStatusRepo(database)
+	refreshRepo := repo.NewRefreshTokenRepo(database)
+	sessionRepo := repo.NewSessionRepo(database)
+	userTokenRepo := repo.NewUserTokenRepo(database)
+	mfaRepo := repo.NewMFARepo(database)
+	passkeyRepo := repo.NewPasskeyRepo(database)
+	webAuthnSessionRepo := repo.NewWebAuthnSessionRepo(database)
+	identityRepo := repo.NewIdentityRepo(database)
+	accessTokenRepo := repo.NewAccessTokenRepo(database)
+	preferencesRepo := repo.NewPreferencesRepo(database)
+	workspaceRepo := repo.NewWorkspaceRepo(database)
+	invitationRepo := repo.NewInvitationRepo(database)
+	projectRepo := repo.NewProjectRepo(database)
+	taskPeopleRepo := repo.NewTaskPeopleRepo(database)
+	checklistRepo := repo.NewChecklistRepo(database)
 
-	authSvc := service.NewAuthService(userRepo, pw, j)
-	userSvc := service.NewUserService(userRepo, pw)
-	taskSvc := service.NewTaskService(taskRepo)
+	authSvc := service.NewAuthService(userRepo, refreshRepo, sessionRepo, identityRepo, pw, pwPolicy, j)
+	userSvc := service.NewUserService(userRepo, preferencesRepo, pw, pwPolicy)
+	workspaceSvc := service.NewWorkspaceService(workspaceRepo)
+	projectSvc := service.NewProjectService(projectRepo, workspaceRepo, statusRepo)
+	sessionSvc := service.NewSessionService(sessionRepo, refreshRepo)
+	mfaSvc := service.NewMFAService(mfaRepo, userRepo, j)
+	identitySvc := service.NewIdentityService(identityRepo, userRepo, sessionRepo, pw)
+	accessTokenSvc := service.NewAccessTokenService(accessTokenRepo, userRepo)
 
-	// Google OAuth
-	googleCfg := auth.NewGoogleOAuthFromEnv()
+	// Passkeys: RP ID ต้องเป็นโดเมนของหน้าเว็บที่เรียก navigator.credentials
+	rpID, rpOrigins := cfg.WebAuthnRPID, cfg.WebAuthnOrigins
+	if rpID == "" {
+		if u, err := url.Parse(cfg.FrontendURL); err == nil {
+			rpID = u.Hostname()
+		}
+	}
+	if len(rpOrigins) == 0 {
+		rpOrigins = []string{cfg.FrontendURL, cfg.PublicURL}
+	}
+	passkeySvc, err := service.NewPasskeyService(service.PasskeyConfig{
+		RPID:        rpID,
+		DisplayName: "Task Manager",
+		Origins:     rpOrigins,
+	}, userRepo, passkeyRepo, webAuthnSessionRepo)
+	if err != nil {
+		log.Fatalf("Failed to configure passkeys: %v", err)
+	}
+
+	// Mail: ไม่ได้ตั้ง SMTP_HOST ก็ยังรันได้ (อีเมลจะถูกพิมพ์ลง log)
+	var mailer mail.Mailer = mail.NewLogMailer()
+	if cfg.SMTPHost != "" {
+		mailer = mail.NewSMTPMailer(mail.SMTPConfig{
+			Host:     cfg.SMTPHost,
+			Port:     cfg.SMTPPort,
+			Username: cfg.SMTPUsername,
+			Password: cfg.SMTPPassword,
+			From:     cfg.MailFrom,
+		})
+	}
+	resetSvc := service.NewPasswordResetService(
+		userRepo, userTokenRepo, sessionSvc, pw, pwPolicy, mailer,
+		cfg.FrontendURL+"/auth/reset-password.html",
+		time.Duration(cfg.PasswordResetTTLMin)*time.Minute,
+	)
+	verifySvc := service.NewEmailVerificationService(
+		userRepo, userTokenRepo, mailer,
+		cfg.PublicURL+"/api/auth/verify-email",
+		time.Duration(cfg.EmailVerifyTTLHours)*time.Hour,
+		time.Duration(cfg.EmailVerifyResendMin)*time.Minute,
+	)
+	inviteSvc := service.NewInvitationService(
+		invitationRepo, workspaceRepo, userRepo, j, mailer,
+		cfg.FrontendURL+"/invite.html",
+		time.Duration(cfg.InviteTTLHours)*time.Hour,
+	)
+	taskSvc := service.NewTaskService(
+		taskRepo, statusRepo, workspaceRepo, projectRepo, taskPeopleRepo, checklistRepo, userSvc, mailer,
+		cfg.FrontendURL+"/my-work.html",
+	)
+	deleteChildren := domain.ChildDeleteMode(cfg.TaskDeleteChildren)
+	if !deleteChildren.Valid() {
+		log.Fatalf("invalid TASK_DELETE_CHILDREN %q (want orphan or cascade)", cfg.TaskDeleteChildren)
+	}
+
+	// กัน brute-force login / ไล่เช็คอีเมล
+	var throttleStore ratelimit.Store
+	switch cfg.RateLimitStore {
+	case "memory":
+		throttleStore = ratelimit.NewMemoryStore()
+	case "db":
+		throttleStore = ratelimit.NewSQLStore(database)
+	default:
+		log.Fatalf("invalid RATE_LIMIT_STORE %q (want db or memory)", cfg.RateLimitStore)
+	}
+	limiter := ratelimit.New(throttleStore)
+	go func() {
+		for range time.Tick(time.Hour) {
+			if err := limiter.Prune(context.Background(), 24*time.Hour); err != nil {
+				log.Printf("ratelimit prune failed: %v", err)
+			}
+		}
+	}()
+
+	// OIDC login providers (ไม่ได้ตั้งค่า = ไม่มีปุ่มนั้น ไม่ทำให้ start ไม่ขึ้น)
+	providers := auth.NewOIDCRegistry(cfg.OIDCProviders)
+	nextOrigins := cfg.OAuthNextOrigins
+	if len(nextOrigins) == 0 {
+		nextOrigins = []string{cfg.FrontendURL}
+	}
 
 	// Router
 	r := gin.New()
-	_ = r.SetTrustedProxies(nil)
+	if err := r.SetTrustedProxies(cfg.TrustedProxies); err != nil {
+		log.Fatalf("invalid TRUSTED_PROXIES: %v", err)
+	}
 	r.RedirectTrailingSlash = false
 	r.RedirectFixedPath = false
 	r.Use(
 		gin.Recovery(),
+		middleware.RequestID(),
+		middleware.Locale(),
 		middleware.CORSMiddleware(),
 		middleware.SecureHeaders(),
 		func(c *gin.Context) {
 			start := time.Now()
 			c.Next()
-			log.Printf("%s %s %d (%s)",
-				c.Request.Method, c.Request.URL.Path, c.Writer.Status(), time.Since(start),
+			log.Printf("%s %s %d (%s) rid=%s",
+				c.Request.Method, c.Request.URL.Path, c.Writer.Status(), time.Since(start), response.RequestID(c),
 			)
 		},
 	)
 
 	r.GET("/healthz", func(c *gin.Context) { c.JSON(http.StatusOK, gin.H{"ok": true}) })
 
-
-
 	// Static file serving สำหรับ frontend
 	r.Static("/assets", "./frontend/vanilla/assets")
 	r.Static("/auth", "./frontend/vanilla/auth")
@@ -87,18 +211,63 @@ func main() {
 	r.StaticFile("/create_account.html", "./frontend/vanilla/create_account.html")
 	r.StaticFile("/account-type.html", "./frontend/vanilla/account-type.html")
 	r.StaticFile("/team-invitation.html", "./frontend/vanilla/team-invitation.html")
+	r.StaticFile("/invite.html", "./frontend/vanilla/invite.html")
 	r.StaticFile("/home.html", "./frontend/vanilla/home.html")
 	r.StaticFile("/my-work.html", "./frontend/vanilla/my-work.html")
 	r.StaticFile("/dashboard-reporting.html", "./frontend/vanilla/dashboard-reporting.html")
 	r.StaticFile("/index.html", "./frontend/vanilla/index.html")
 
+	authMw := middleware.JWTMiddleware(&j, sessionSvc, accessTokenSvc)
+
 	// ส่ง arg ให้ครบ (เพิ่ม frontendURL เข้าไปเป็นตัวสุดท้าย)
-	api.RegisterAuthRoutes(r, authSvc, userRepo, googleCfg, cfg.FrontendURL)
-	api.RegisterUserRoutes(r, userSvc, middleware.JWTMiddleware(&j))
-	
+	api.RegisterAuthRoutes(r, &api.AuthHandler{
+		Svc:         authSvc,
+		ResetSvc:    resetSvc,
+		VerifySvc:   verifySvc,
+		MFASvc:      mfaSvc,
+		PasskeySvc:  passkeySvc,
+		IdentitySvc: identitySvc,
+		InviteSvc:   inviteSvc,
+		UserRepo:    userRepo,
+		Limiter:     limiter,
+		Providers:   providers,
+		JWT:         j,
+		FrontendURL: cfg.FrontendURL,
+
+		NextAllowList: nextOrigins,
+	}, authMw)
+	api.RegisterUserRoutes(r, &api.UserHandler{
+		UserSvc:    userSvc,
+		SessionSvc: sessionSvc,
+		VerifySvc:  verifySvc,
+		MFASvc:     mfaSvc,
+		PasskeySvc: passkeySvc,
+
+		IdentitySvc: identitySvc,
+		Providers:   providers,
+		JWT:         j,
+
+		TokenSvc: accessTokenSvc,
+		Limiter:  limiter,
+	}, authMw)
+	api.RegisterAdminRoutes(r, &api.AdminHandler{
+		MFASvc:     mfaSvc,
+		UserSvc:    userSvc,
+		SessionSvc: sessionSvc,
+	}, authMw)
+
+	// task/team ต้องยืนยันอีเมลก่อน ถ้าเปิด REQUIRE_VERIFIED_EMAIL
+	workMws := []gin.HandlerFunc{authMw}
+	if cfg.RequireVerifiedEmail {
+		workMws = append(workMws, middleware.RequireVerifiedEmail(verifySvc))
+	}
+
 	// Root route - ต้องอยู่ท้ายสุดเพื่อไม่ให้ override routes อื่น
 	r.StaticFile("/", "./frontend/vanilla/index.html")
-	api.RegisterTaskRoutes(r, taskSvc, j)
+	api.RegisterTaskRoutes(r, &api.TaskHandler{Svc: taskSvc, DeleteChildren: deleteChildren}, workMws...)
+	api.RegisterWorkspaceRoutes(r, &api.WorkspaceHandler{Svc: workspaceSvc, InviteSvc: inviteSvc}, workMws...)
+	api.RegisterInvitationRoutes(r, &api.InvitationHandler{Svc: inviteSvc}, authMw)
+	api.RegisterProjectRoutes(r, &api.ProjectHandler{Svc: projectSvc, TaskSvc: taskSvc}, workMws...)
 
 	srv := &http.Server{
 		Addr:              ":" + cfg.Port,
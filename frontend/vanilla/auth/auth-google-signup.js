@@ -75,8 +75,8 @@ function handleGoogleSignupComplete(event) {
     return;
   }
   
-  if (password.length < 6) {
-    showError('รหัสผ่านต้องมีอย่างน้อย 6 ตัวอักษร');
+  if (password.length < 8) {
+    showError('รหัสผ่านต้องมีอย่างน้อย 8 ตัวอักษร');
     return;
   }
   
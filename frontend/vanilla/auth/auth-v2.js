@@ -17,6 +17,18 @@ function showError(message) {
   }
 }
 
+// 429 จาก /api/auth/login: ลองผิดหลายครั้งเกินไป
+function loginThrottledMessage(seconds) {
+  const wait = seconds >= 60 ? `${Math.ceil(seconds / 60)} นาที` : `${seconds} วินาที`;
+  return `ลองเข้าสู่ระบบผิดหลายครั้งเกินไป กรุณารอ ${wait} แล้วลองใหม่`;
+}
+
+// มาจากลิงก์เชิญเข้าทีม (invite.html เก็บ token ไว้): login เสร็จให้กลับไปรับคำเชิญ
+function afterLoginPath() {
+  const invite = sessionStorage.getItem('tm_invite_token');
+  return invite ? `/invite.html?token=${encodeURIComponent(invite)}` : '/home.html';
+}
+
 // Global function for Google login
 window.handleGoogleLogin = function() {
   console.log('handleGoogleLogin called');
@@ -27,7 +39,9 @@ window.handleGoogleLogin = function() {
     const nextUrl = ''; // ไม่กำหนด next URL เพื่อให้ใช้ onboardIfNew logic
     const onboardIfNew = '1'; // ส่งผู้ใช้ใหม่ไปหน้า profile
     const timestamp = Date.now(); // เพิ่ม timestamp เพื่อป้องกัน cache
-    const fullUrl = `${API_BASE}/api/auth/google/login?onboardIfNew=${onboardIfNew}&t=${timestamp}&cache=${Math.random()}`;
+    const invite = sessionStorage.getItem('tm_invite_token');
+    const fullUrl = `${API_BASE}/api/auth/google/login?onboardIfNew=${onboardIfNew}&t=${timestamp}&cache=${Math.random()}`
+      + (invite ? `&invite=${encodeURIComponent(invite)}` : '');
     
     console.log('Redirecting to:', fullUrl);
     
@@ -65,6 +79,55 @@ window.handleGoogleLogin = function() {
   }
 }
 
+// base64url <-> ArrayBuffer สำหรับ WebAuthn
+const b64urlToBuf = (s) => Uint8Array.from(atob(s.replace(/-/g, '+').replace(/_/g, '/')), c => c.charCodeAt(0)).buffer;
+const bufToB64url = (b) => btoa(String.fromCharCode(...new Uint8Array(b))).replace(/\+/g, '-').replace(/\//g, '_').replace(/=+$/, '');
+
+// Global function for passkey login (ไม่ต้องกรอกอีเมล เบราว์เซอร์จะให้เลือก passkey เอง)
+window.handlePasskeyLogin = async function() {
+  if (!window.PublicKeyCredential) {
+    showError('เบราว์เซอร์นี้ไม่รองรับ passkey');
+    return;
+  }
+  try {
+    const begin = await fetch(`${API_BASE}/api/auth/passkey/login/begin`, { method: 'POST' }).then(r => r.json());
+    const opts = begin.options.publicKey;
+    opts.challenge = b64urlToBuf(opts.challenge);
+    (opts.allowCredentials || []).forEach(c => { c.id = b64urlToBuf(c.id); });
+
+    const cred = await navigator.credentials.get({ publicKey: opts });
+    const res = await fetch(`${API_BASE}/api/auth/passkey/login/finish`, {
+      method: 'POST',
+      headers: { 'Content-Type': 'application/json' },
+      credentials: 'include',
+      body: JSON.stringify({
+        ceremony_id: begin.ceremony_id,
+        credential: {
+          id: cred.id,
+          rawId: bufToB64url(cred.rawId),
+          type: cred.type,
+          response: {
+            clientDataJSON: bufToB64url(cred.response.clientDataJSON),
+            authenticatorData: bufToB64url(cred.response.authenticatorData),
+            signature: bufToB64url(cred.response.signature),
+            userHandle: cred.response.userHandle ? bufToB64url(cred.response.userHandle) : ''
+          }
+        }
+      })
+    });
+    const data = await res.json();
+    if (!res.ok || !data.token) {
+      showError(data.error || 'เข้าสู่ระบบด้วย passkey ไม่สำเร็จ');
+      return;
+    }
+    localStorage.setItem('access_token', data.token);
+    window.location.href = afterLoginPath();
+  } catch (error) {
+    console.error('Passkey login error:', error);
+    showError('เข้าสู่ระบบด้วย passkey ไม่สำเร็จ');
+  }
+}
+
 // DOMContentLoaded event listener
 document.addEventListener('DOMContentLoaded', function() {
   // Check if user is already authenticated (for create account page)
@@ -82,6 +145,14 @@ if (window.location.pathname.includes('create_account.html')) {
     }, 100);
   }
   
+  // OAuth callback ส่งกลับมาพร้อม ?error=
+  const oauthError = new URLSearchParams(window.location.search).get('error');
+  if (oauthError === 'account_exists') {
+    showError('มีบัญชีที่ใช้อีเมลนี้อยู่แล้ว กรุณาเข้าสู่ระบบด้วยวิธีเดิม แล้วเชื่อมบัญชีได้ที่หน้า Settings');
+  } else if (oauthError === 'oauth') {
+    showError('เข้าสู่ระบบไม่สำเร็จ กรุณาลองใหม่อีกครั้ง');
+  }
+
   // Handle Continue button in signup page
   const btnContinue = document.getElementById('btn-continue');
   if (btnContinue) {
@@ -135,11 +206,19 @@ if (window.location.pathname.includes('create_account.html')) {
        })
        .then(response => response.json())
        .then(data => {
+         if (data.mfa_required) {
+           // เปิด 2FA ไว้ ไปหน้ากรอกรหัสก่อน
+           sessionStorage.setItem('tm_mfa_token', data.mfa_token);
+           window.location.href = './mfa.html';
+           return;
+         }
          if (data.success && data.token) {
            // Store token in localStorage
            localStorage.setItem('access_token', data.token);
            // Redirect to dashboard
-           window.location.href = '/home.html';
+           window.location.href = afterLoginPath();
+         } else if (data.retry_after) {
+           showError(loginThrottledMessage(data.retry_after));
          } else {
            showError('ชื่อผู้ใช้หรือรหัสผ่านไม่ถูกต้อง');
          }
@@ -196,13 +275,21 @@ if (window.location.pathname.includes('create_account.html')) {
         })
         .then(response => response.json())
         .then(data => {
+          if (data.mfa_required) {
+            sessionStorage.removeItem('tm_login_email');
+            sessionStorage.setItem('tm_mfa_token', data.mfa_token);
+            window.location.href = './mfa.html';
+            return;
+          }
           if (data.success && data.token) {
             // Store token in localStorage
             localStorage.setItem('access_token', data.token);
             // Clear session storage
             sessionStorage.removeItem('tm_login_email');
             // Redirect to dashboard
-            window.location.href = '/home.html';
+            window.location.href = afterLoginPath();
+          } else if (data.retry_after) {
+            showError(loginThrottledMessage(data.retry_after));
           } else {
             showError(data.error || 'ชื่อผู้ใช้หรือรหัสผ่านไม่ถูกต้อง');
           }
@@ -243,7 +330,8 @@ if (window.location.pathname.includes('create_account.html')) {
           email: email,
           username: username,
           name: fullName,
-          password: password
+          password: password,
+          invite_token: sessionStorage.getItem('tm_invite_token') || undefined
         })
       })
       .then(response => response.json())
@@ -253,6 +341,11 @@ if (window.location.pathname.includes('create_account.html')) {
           localStorage.setItem('access_token', data.token);
           // Clear stored email
           sessionStorage.removeItem('tm_signup_email');
+          sessionStorage.removeItem('tm_invite_token');
+          // สมัครจากคำเชิญ = เข้าทีมแล้ว ไปที่ workspace นั้นเลย
+          if (data.workspace) {
+            localStorage.setItem('workspace_id', String(data.workspace.id));
+          }
           // Redirect to dashboard
           window.location.href = '/home.html';
         } else {
@@ -2,29 +2,27 @@
 document.addEventListener('DOMContentLoaded', function() {
     loadUserSettings();
     initializeEventListeners();
+    loadPasskeys();
+    loadIdentities();
+    loadAccessTokens();
+    showLinkResult();
 });
 
-function loadUserSettings() {
-    // Load saved settings from localStorage or API
-    const savedSettings = JSON.parse(localStorage.getItem('userSettings')) || {};
-    
-    // Apply saved language
-    if (savedSettings.language) {
-        document.getElementById('language-select').value = savedSettings.language;
-    }
-    
-    // Apply saved theme
-    if (savedSettings.theme === 'dark') {
-        document.getElementById('theme-toggle').checked = true;
-        document.body.classList.add('dark-theme');
-    }
-    
-    // Apply notification settings
-    if (savedSettings.notifications) {
-        document.getElementById('email-notifications').checked = savedSettings.notifications.email || false;
-        document.getElementById('push-notifications').checked = savedSettings.notifications.push || false;
-        document.getElementById('task-reminders').checked = savedSettings.notifications.reminders || false;
-    }
+// ค่าจาก GET/PATCH/DELETE /api/users/me/preferences
+function applyPreferences(prefs) {
+    document.getElementById('language-select').value = prefs.language || localStorage.getItem('tm_lang') || 'en';
+    document.getElementById('theme-toggle').checked = prefs.theme === 'dark';
+    document.body.classList.toggle('dark-theme', prefs.theme === 'dark');
+    document.getElementById('email-notifications').checked = prefs.notifications.email;
+    document.getElementById('push-notifications').checked = prefs.notifications.push;
+    document.getElementById('task-reminders').checked = prefs.notifications.task_reminders;
+    if (prefs.language) localStorage.setItem('tm_lang', prefs.language);
+}
+
+async function loadUserSettings() {
+    const res = await fetch('/api/users/me/preferences', { headers: authHeaders() });
+    if (!res.ok) return;
+    applyPreferences(await res.json());
 }
 
 function initializeEventListeners() {
@@ -197,21 +195,28 @@ function finalDeleteAccount() {
     }, 2000);
 }
 
-function saveSettings() {
-    const settings = {
+async function saveSettings() {
+    const prefs = {
         language: document.getElementById('language-select').value,
         theme: document.getElementById('theme-toggle').checked ? 'dark' : 'light',
         notifications: {
             email: document.getElementById('email-notifications').checked,
             push: document.getElementById('push-notifications').checked,
-            reminders: document.getElementById('task-reminders').checked
-        },
-        username: document.getElementById('username').value
+            task_reminders: document.getElementById('task-reminders').checked
+        }
     };
-    
-    // Save to localStorage (in real app, this would be an API call)
-    localStorage.setItem('userSettings', JSON.stringify(settings));
-    
+
+    const res = await fetch('/api/users/me/preferences', {
+        method: 'PATCH',
+        headers: { ...authHeaders(), 'Content-Type': 'application/json' },
+        body: JSON.stringify(prefs)
+    });
+    const data = await res.json().catch(() => ({}));
+    if (!res.ok) {
+        showNotification(data.error || 'Failed to save settings', 'error');
+        return;
+    }
+    applyPreferences(data);
     showNotification('Settings saved successfully!');
 }
 
@@ -219,21 +224,13 @@ function resetSettings() {
     const confirmed = confirm('Are you sure you want to reset all settings to default?');
     
     if (confirmed) {
-        // Reset form values
-        document.getElementById('language-select').value = 'en';
-        document.getElementById('theme-toggle').checked = false;
-        document.getElementById('email-notifications').checked = true;
-        document.getElementById('push-notifications').checked = true;
-        document.getElementById('task-reminders').checked = false;
-        document.getElementById('username').value = 'Admin User';
-        
-        // Remove dark theme
-        document.body.classList.remove('dark-theme');
-        
-        // Clear localStorage
-        localStorage.removeItem('userSettings');
-        
-        showNotification('Settings reset to default values!');
+        fetch('/api/users/me/preferences', { method: 'DELETE', headers: authHeaders() })
+            .then(res => res.ok ? res.json() : Promise.reject(res))
+            .then(prefs => {
+                applyPreferences(prefs);
+                showNotification('Settings reset to default values!');
+            })
+            .catch(() => showNotification('Failed to reset settings', 'error'));
     }
 }
 
@@ -332,3 +329,282 @@ document.addEventListener('keydown', function(event) {
         closePasswordModal();
     }
 });
+
+/* ------- Passkeys ------- */
+function authHeaders() {
+    return {
+        'Content-Type': 'application/json',
+        'Authorization': `Bearer ${localStorage.getItem('access_token') || ''}`
+    };
+}
+
+const b64urlToBuf = (s) => Uint8Array.from(atob(s.replace(/-/g, '+').replace(/_/g, '/')), c => c.charCodeAt(0)).buffer;
+const bufToB64url = (b) => btoa(String.fromCharCode(...new Uint8Array(b))).replace(/\+/g, '-').replace(/\//g, '_').replace(/=+$/, '');
+
+async function loadPasskeys() {
+    const list = document.getElementById('passkey-list');
+    if (!list) return;
+
+    const res = await fetch('/api/users/me/passkeys', { headers: authHeaders() });
+    if (!res.ok) return;
+    const data = await res.json();
+
+    list.innerHTML = '';
+    if (data.passkeys.length === 0) {
+        list.innerHTML = '<li class="muted">No passkeys yet.</li>';
+        return;
+    }
+    data.passkeys.forEach(p => {
+        const li = document.createElement('li');
+        const lastUsed = p.last_used_at ? new Date(p.last_used_at).toLocaleDateString() : 'never';
+        li.textContent = `${p.name} — added ${new Date(p.created_at).toLocaleDateString()}, last used ${lastUsed} `;
+        const btn = document.createElement('button');
+        btn.className = 'danger-btn';
+        btn.textContent = 'Remove';
+        btn.onclick = () => removePasskey(p.id, p.name);
+        li.appendChild(btn);
+        list.appendChild(li);
+    });
+}
+
+async function addPasskey() {
+    if (!window.PublicKeyCredential) {
+        showNotification('This browser does not support passkeys.', 'error');
+        return;
+    }
+    try {
+        const begin = await fetch('/api/auth/passkey/register/begin', { method: 'POST', headers: authHeaders() }).then(r => r.json());
+        const opts = begin.options.publicKey;
+        opts.challenge = b64urlToBuf(opts.challenge);
+        opts.user.id = b64urlToBuf(opts.user.id);
+        (opts.excludeCredentials || []).forEach(c => { c.id = b64urlToBuf(c.id); });
+
+        const cred = await navigator.credentials.create({ publicKey: opts });
+        const name = prompt('Name this passkey (e.g. "MacBook")', '') || '';
+        const res = await fetch('/api/auth/passkey/register/finish', {
+            method: 'POST',
+            headers: authHeaders(),
+            body: JSON.stringify({
+                ceremony_id: begin.ceremony_id,
+                name,
+                credential: {
+                    id: cred.id,
+                    rawId: bufToB64url(cred.rawId),
+                    type: cred.type,
+                    response: {
+                        clientDataJSON: bufToB64url(cred.response.clientDataJSON),
+                        attestationObject: bufToB64url(cred.response.attestationObject),
+                        transports: cred.response.getTransports ? cred.response.getTransports() : []
+                    }
+                }
+            })
+        });
+        if (!res.ok) throw new Error('register failed');
+        showNotification('Passkey added!');
+        loadPasskeys();
+    } catch (error) {
+        console.error('Passkey registration error:', error);
+        showNotification('Could not add passkey.', 'error');
+    }
+}
+
+async function removePasskey(id, name) {
+    if (!confirm(`Remove passkey "${name}"?`)) return;
+    const res = await fetch(`/api/users/me/passkeys/${id}`, { method: 'DELETE', headers: authHeaders() });
+    if (!res.ok) {
+        const data = await res.json().catch(() => ({}));
+        showNotification(res.status === 409 ? 'This is your last way to sign in.' : (data.error || 'Could not remove passkey.'), 'error');
+        return;
+    }
+    showNotification('Passkey removed.');
+    loadPasskeys();
+}
+
+/* ------- Connected accounts ------- */
+let identityHasPassword = false;
+
+async function loadIdentities() {
+    const list = document.getElementById('identity-list');
+    const providersEl = document.getElementById('identity-providers');
+    if (!list || !providersEl) return;
+
+    const [res, providers] = await Promise.all([
+        fetch('/api/users/me/identities', { headers: authHeaders() }),
+        fetch('/api/auth/providers').then(r => r.ok ? r.json() : { providers: [] })
+    ]);
+    if (!res.ok) return;
+    const data = await res.json();
+    identityHasPassword = data.has_password;
+
+    list.innerHTML = '';
+    if (data.identities.length === 0) {
+        list.innerHTML = '<li class="muted">No connected accounts.</li>';
+    }
+    data.identities.forEach(i => {
+        const li = document.createElement('li');
+        li.textContent = `${i.provider} — ${i.email || 'no email'}, connected ${new Date(i.created_at).toLocaleDateString()} `;
+        const btn = document.createElement('button');
+        btn.className = 'danger-btn';
+        btn.textContent = 'Disconnect';
+        btn.onclick = () => unlinkIdentity(i.id, i.provider);
+        li.appendChild(btn);
+        list.appendChild(li);
+    });
+
+    providersEl.innerHTML = '';
+    (providers.providers || []).forEach(p => {
+        const btn = document.createElement('button');
+        btn.className = 'secondary-btn';
+        btn.textContent = `Connect ${p.display_name || p.name}`;
+        btn.onclick = () => linkIdentity(p.name);
+        providersEl.appendChild(btn);
+    });
+}
+
+// reauthBody ถามรหัสผ่านก่อนผูก/ยกเลิก (บัญชีที่ไม่มีรหัสผ่านต้องเพิ่งล็อกอินมา)
+function reauthBody() {
+    if (!identityHasPassword) return JSON.stringify({});
+    const password = prompt('Confirm your password to continue');
+    if (password === null) return null;
+    return JSON.stringify({ password });
+}
+
+async function reauthError(res, fallback) {
+    const data = await res.json().catch(() => ({}));
+    if (data.reauth_required && !identityHasPassword) {
+        showNotification('Please sign out and sign in again, then retry.', 'error');
+    } else if (res.status === 409) {
+        showNotification('This is your last way to sign in.', 'error');
+    } else {
+        showNotification(data.error || fallback, 'error');
+    }
+}
+
+async function linkIdentity(provider) {
+    const body = reauthBody();
+    if (body === null) return;
+    const res = await fetch(`/api/users/me/identities/${encodeURIComponent(provider)}/link`, {
+        method: 'POST', headers: authHeaders(), body
+    });
+    if (!res.ok) {
+        await reauthError(res, 'Could not connect account.');
+        return;
+    }
+    const data = await res.json();
+
+    // submit form (ไม่ใช้ fetch) เพื่อให้ browser ตาม redirect ไปหน้า provider และ ticket ไม่อยู่ใน URL
+    const form = document.createElement('form');
+    form.method = 'POST';
+    form.action = data.action;
+    const input = document.createElement('input');
+    input.type = 'hidden';
+    input.name = 'ticket';
+    input.value = data.ticket;
+    form.appendChild(input);
+    document.body.appendChild(form);
+    form.submit();
+}
+
+async function unlinkIdentity(id, provider) {
+    if (!confirm(`Disconnect ${provider}?`)) return;
+    const body = reauthBody();
+    if (body === null) return;
+    const res = await fetch(`/api/users/me/identities/${id}`, { method: 'DELETE', headers: authHeaders(), body });
+    if (!res.ok) {
+        await reauthError(res, 'Could not disconnect account.');
+        return;
+    }
+    showNotification('Account disconnected.');
+    loadIdentities();
+}
+
+// ผลลัพธ์จาก OAuth callback (?linked= / ?link_error=)
+function showLinkResult() {
+    const params = new URLSearchParams(window.location.search);
+    const messages = {
+        in_use: 'That account is already connected to another user.',
+        expired: 'The request expired, please try again.',
+        failed: 'Could not connect account.'
+    };
+    if (params.get('linked')) {
+        showNotification(`${params.get('linked')} account connected!`);
+    } else if (params.get('link_error')) {
+        showNotification(messages[params.get('link_error')] || messages.failed, 'error');
+    }
+    if (params.has('linked') || params.has('link_error')) {
+        history.replaceState(null, '', window.location.pathname);
+    }
+}
+
+/* ------- Personal access tokens ------- */
+async function loadAccessTokens() {
+    const list = document.getElementById('token-list');
+    if (!list) return;
+
+    const res = await fetch('/api/users/me/tokens', { headers: authHeaders() });
+    if (!res.ok) return;
+    const data = await res.json();
+
+    const scopes = document.getElementById('token-scopes');
+    scopes.innerHTML = '';
+    data.available_scopes.forEach(sc => {
+        const label = document.createElement('label');
+        label.className = 'checkbox-label';
+        const box = document.createElement('input');
+        box.type = 'checkbox';
+        box.value = sc;
+        box.checked = sc === 'tasks:read';
+        label.appendChild(box);
+        label.appendChild(document.createTextNode(` ${sc} `));
+        scopes.appendChild(label);
+    });
+
+    list.innerHTML = '';
+    if (data.tokens.length === 0) {
+        list.innerHTML = '<li class="muted">No tokens yet.</li>';
+        return;
+    }
+    data.tokens.forEach(t => {
+        const li = document.createElement('li');
+        const lastUsed = t.last_used_at ? new Date(t.last_used_at).toLocaleDateString() : 'never';
+        li.textContent = `${t.name} (${t.prefix}…) [${t.scopes.join(', ')}] — expires ${new Date(t.expires_at).toLocaleDateString()}, last used ${lastUsed} `;
+        const btn = document.createElement('button');
+        btn.className = 'danger-btn';
+        btn.textContent = 'Revoke';
+        btn.onclick = () => revokeAccessToken(t.id, t.name);
+        li.appendChild(btn);
+        list.appendChild(li);
+    });
+}
+
+async function createAccessToken() {
+    const name = prompt('Token name (e.g. "CI")', '');
+    if (!name) return;
+    const days = parseInt(prompt('Expires in how many days? (1-365)', '30'), 10);
+    const scopes = [...document.querySelectorAll('#token-scopes input:checked')].map(b => b.value);
+
+    const res = await fetch('/api/users/me/tokens', {
+        method: 'POST',
+        headers: authHeaders(),
+        body: JSON.stringify({ name, scopes, expires_in_days: days || 30 })
+    });
+    const data = await res.json().catch(() => ({}));
+    if (!res.ok) {
+        showNotification(data.error || 'Could not create token.', 'error');
+        return;
+    }
+    // แสดงครั้งเดียว ปิดแล้วดูอีกไม่ได้
+    prompt('Copy your token now. You will not be able to see it again.', data.token);
+    loadAccessTokens();
+}
+
+async function revokeAccessToken(id, name) {
+    if (!confirm(`Revoke token "${name}"? Scripts using it will stop working.`)) return;
+    const res = await fetch(`/api/users/me/tokens/${id}`, { method: 'DELETE', headers: authHeaders() });
+    if (!res.ok) {
+        showNotification('Could not revoke token.', 'error');
+        return;
+    }
+    showNotification('Token revoked.');
+    loadAccessTokens();
+}
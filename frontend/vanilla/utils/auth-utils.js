@@ -55,7 +55,10 @@ function logout(redirectPath = '../index.html') {
   localStorage.removeItem('access_token');
   // Clear cookie by setting it to expire
   document.cookie = 'access_token=; expires=Thu, 01 Jan 1970 00:00:00 UTC; path=/;';
-  location.replace(redirectPath);
+  // Revoke the refresh token server-side (cookies are HttpOnly, only the server can clear them)
+  fetch('/api/auth/logout', { method: 'POST', credentials: 'include' })
+    .catch(() => {})
+    .finally(() => location.replace(redirectPath));
 }
 
 // Export functions for use in other scripts
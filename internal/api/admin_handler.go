@@ -0,0 +1,92 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"task-manager/internal/authctx"
+	"task-manager/internal/domain"
+	"task-manager/internal/middleware"
+	"task-manager/internal/service"
+	"task-manager/internal/validate"
+	"task-manager/pkg/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AdminHandler struct {
+	MFASvc     service.MFAService
+	UserSvc    service.UserService
+	SessionSvc service.SessionService
+}
+
+// RegisterAdminRoutes: middleware ตรวจ permission จาก role ใน token ก่อน
+// แล้วชั้น service ตรวจซ้ำจาก role ใน DB (token อาจยังถือ role เก่าอยู่จนหมดอายุ)
+func RegisterAdminRoutes(r *gin.Engine, h *AdminHandler, authMw gin.HandlerFunc) {
+
+	g := r.Group("/api/admin")
+	g.Use(authMw, middleware.RequireScope(domain.ScopeAdmin), middleware.RequirePermission(domain.PermUserManage))
+	{
+		g.POST("/users/:id/mfa/reset", h.resetUserMFA)
+		g.PUT("/users/:id/role", h.setUserRole)
+	}
+}
+
+// resetUserMFA ปิด 2FA ให้ผู้ใช้ที่เข้าแอปไม่ได้ (เช่น ทำโทรศัพท์หาย)
+func (h *AdminHandler) resetUserMFA(c *gin.Context) {
+	actorID, ok := authctx.UserID(c)
+	if !ok {
+		response.Fail(c, http.StatusUnauthorized, response.CodeUnauthorized, "unauthorized")
+		return
+	}
+	targetID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.Fail(c, http.StatusBadRequest, "invalid_user_id", "invalid user id")
+		return
+	}
+
+	if err := h.MFASvc.AdminReset(c.Request.Context(), actorID, targetID); err != nil {
+		switch {
+		case errors.Is(err, domain.ErrForbidden):
+			response.Fail(c, http.StatusForbidden, response.CodeForbidden, "forbidden")
+		case errors.Is(err, domain.ErrUserNotFound):
+			response.Fail(c, http.StatusNotFound, "user_not_found", "user not found")
+		default:
+			response.Error(c, err, "failed to reset 2fa")
+		}
+		return
+	}
+	response.OK(c, gin.H{"success": true})
+}
+
+// setUserRole เปลี่ยน role แล้วเพิกถอน session ของผู้ใช้นั้น ให้ token ใหม่ได้ role ใหม่ทันที
+func (h *AdminHandler) setUserRole(c *gin.Context) {
+	actorID, ok := authctx.UserID(c)
+	if !ok {
+		response.Fail(c, http.StatusUnauthorized, response.CodeUnauthorized, "unauthorized")
+		return
+	}
+	targetID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.Fail(c, http.StatusBadRequest, "invalid_user_id", "invalid user id")
+		return
+	}
+	var req struct {
+		Role string `json:"role" binding:"required,oneof=admin manager user"`
+	}
+	if !validate.Bind(c, &req) {
+		return
+	}
+
+	ctx := c.Request.Context()
+	if err := h.UserSvc.SetRole(ctx, actorID, targetID, req.Role); err != nil {
+		response.Error(c, err, "failed to update role")
+		return
+	}
+	if err := h.SessionSvc.RevokeAll(ctx, targetID, ""); err != nil {
+		response.Error(c, err, "role updated but failed to revoke sessions")
+		return
+	}
+	response.OK(c, gin.H{"id": targetID, "role": req.Role, "permissions": domain.PermissionsOf(req.Role)})
+}
@@ -1,292 +1,986 @@
 package api
 
 import (
-	"encoding/base64"
+	"context"
+	"crypto/subtle"
 	"encoding/json"
+	"errors"
 	"log"
+	"math"
 	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
 	"time"
 
 	"task-manager/internal/auth"
+	"task-manager/internal/authctx"
+	"task-manager/internal/domain"
+	"task-manager/internal/i18n"
+	"task-manager/internal/middleware"
+	"task-manager/internal/ratelimit"
 	"task-manager/internal/repo"
 	"task-manager/internal/service"
+	"task-manager/internal/validate"
+	"task-manager/pkg/response"
 
 	"github.com/gin-gonic/gin"
 )
 
 type AuthHandler struct {
 	Svc         service.AuthService
+	ResetSvc    service.PasswordResetService
+	VerifySvc   service.EmailVerificationService
+	MFASvc      service.MFAService
+	PasskeySvc  service.PasskeyService
+	IdentitySvc service.IdentityService
+	InviteSvc   service.InvitationService
 	UserRepo    repo.UserRepo
-	GoogleCfg   *auth.GoogleOAuth
+	Limiter     *ratelimit.Limiter
+	Providers   *auth.OIDCRegistry
+	JWT         auth.JWT
 	FrontendURL string
+
+	// origin ที่ ?next= หลัง OAuth login ชี้ไปได้ (path ภายในอนุญาตเสมอ)
+	NextAllowList []string
 }
 
-func RegisterAuthRoutes(r *gin.Engine, svc service.AuthService, userRepo repo.UserRepo, gcfg *auth.GoogleOAuth, frontendURL string) {
-	h := &AuthHandler{Svc: svc, UserRepo: userRepo, GoogleCfg: gcfg, FrontendURL: frontendURL}
+func RegisterAuthRoutes(r *gin.Engine, h *AuthHandler, authMw gin.HandlerFunc) {
 
 	api := r.Group("/api/auth")
 	{
 		api.POST("/check-email", h.checkEmail)
 		api.POST("/login", h.login)
+		api.POST("/mfa/verify", h.mfaVerify)
 		api.POST("/register", h.register)
 		api.POST("/complete-google-registration", h.completeGoogleRegistration)
-		api.GET("/google/login", h.googleLogin)
-		api.GET("/google/callback", h.googleCallback)
+		api.GET("/providers", h.listProviders)
+		api.GET("/:provider/login", h.oauthLogin)
+		api.GET("/:provider/callback", h.oauthCallback)
+		api.POST("/:provider/link", h.oauthLink)
+		api.POST("/refresh", h.refresh)
+		api.POST("/logout", h.logout)
+		api.POST("/forgot-password", h.forgotPassword)
+		api.POST("/reset-password", h.resetPassword)
+		api.GET("/verify-email", h.verifyEmailLink)
+		api.POST("/verify-email", h.verifyEmail)
+
+		// passkey (WebAuthn): login ไม่ต้องล็อกอินก่อน ส่วนการลงทะเบียนต้องล็อกอินแล้ว
+		api.POST("/passkey/login/begin", h.passkeyLoginBegin)
+		api.POST("/passkey/login/finish", h.passkeyLoginFinish)
+		api.POST("/passkey/register/begin", authMw, middleware.RequireSession(), h.passkeyRegisterBegin)
+		api.POST("/passkey/register/finish", authMw, middleware.RequireSession(), h.passkeyRegisterFinish)
+	}
+}
+
+const (
+	accessCookie  = "access_token"
+	refreshCookie = "refresh_token"
+
+	// refresh token ถูกส่งเฉพาะ request ไปที่ /api/auth เท่านั้น
+	refreshCookiePath = "/api/auth"
+
+	// state/nonce/PKCE ระหว่าง /{provider}/login กับ /callback
+	oauthStateCookie = "oauth_state"
+)
+
+// setAuthCookies ตั้ง cookie ให้อายุตรงกับ TTL ของ token แต่ละตัว
+func setAuthCookies(c *gin.Context, t *service.TokenPair) {
+	c.SetCookie(accessCookie, t.AccessToken, int(t.AccessTTL.Seconds()), "/", "", false, true)
+	c.SetCookie(refreshCookie, t.RefreshToken, int(t.RefreshTTL.Seconds()), refreshCookiePath, "", false, true)
+}
+
+func clearAuthCookies(c *gin.Context) {
+	c.SetCookie(accessCookie, "", -1, "/", "", false, true)
+	c.SetCookie(refreshCookie, "", -1, refreshCookiePath, "", false, true)
+}
+
+// safeNext อนุญาตเฉพาะ path ภายในเว็บเรา (/...) หรือ URL ที่ origin อยู่ใน NextAllowList
+// กันการใช้ ?next= เป็น open redirect
+func (h *AuthHandler) safeNext(next string) (string, bool) {
+	if next == "" || strings.Contains(next, "\\") {
+		return "", false
+	}
+	u, err := url.Parse(next)
+	if err != nil {
+		return "", false
+	}
+	if u.Scheme == "" && u.Host == "" {
+		if !strings.HasPrefix(next, "/") || strings.HasPrefix(next, "//") {
+			return "", false
+		}
+		return strings.TrimRight(h.FrontendURL, "/") + next, true
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return "", false
+	}
+	origin := u.Scheme + "://" + u.Host
+	for _, allowed := range h.NextAllowList {
+		if strings.EqualFold(strings.TrimRight(allowed, "/"), origin) {
+			return next, true
+		}
+	}
+	return "", false
+}
+
+func clientInfo(c *gin.Context) domain.ClientInfo {
+	return domain.ClientInfo{UserAgent: c.Request.UserAgent(), IP: c.ClientIP()}
+}
+
+// sendVerificationAsync ส่งอีเมลยืนยันเบื้องหลัง ไม่ให้ SMTP ช้าทำให้การสมัครช้า
+// (context ใหม่ไม่มีภาษาของ request ติดมา จึงส่ง locale ต่อให้เอง)
+func (h *AuthHandler) sendVerificationAsync(c *gin.Context, userID int64) {
+	locale := i18n.FromContext(c.Request.Context())
+	go func() {
+		ctx, cancel := context.WithTimeout(i18n.WithLocale(context.Background(), locale), 30*time.Second)
+		defer cancel()
+		if err := h.VerifySvc.SendVerification(ctx, userID); err != nil && !errors.Is(err, domain.ErrTooManyRequests) {
+			log.Printf("send verification email failed: %v", err)
+		}
+	}()
+}
+
+func tokenResponse(t *service.TokenPair) gin.H {
+	return gin.H{
+		"token":         t.AccessToken,
+		"expires_in":    int(t.AccessTTL.Seconds()),
+		"refresh_token": t.RefreshToken,
 	}
 }
 
 func (h *AuthHandler) checkEmail(c *gin.Context) {
 	var in struct {
-		Email string `json:"email"`
+		Email string `json:"email" binding:"required,email"`
+	}
+	if !validate.Bind(c, &in) {
+		return
 	}
-	if err := c.BindJSON(&in); err != nil || in.Email == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid email"})
+	// นับทุกครั้งที่เรียก ไม่ใช่แค่ครั้งที่ผิด (กันไล่เช็คว่าอีเมลไหนมีบัญชี)
+	ip := c.ClientIP()
+	if throttled(c, h.Limiter, ratelimit.CheckEmailByIP, ip) {
 		return
 	}
+	if _, err := h.Limiter.Fail(c.Request.Context(), ratelimit.CheckEmailByIP, ip, ip); err != nil {
+		log.Printf("ratelimit: record check-email failed: %v", err)
+	}
 	exists, err := h.UserRepo.EmailExists(c.Request.Context(), in.Email)
 	if err != nil {
 		// Log the actual error for debugging
 		println("EmailExists error:", err.Error())
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		response.Error(c, err, "server error")
 		return
 	}
-	c.JSON(http.StatusOK, gin.H{"exists": exists})
+	response.OK(c, gin.H{"exists": exists})
 }
 
 func (h *AuthHandler) login(c *gin.Context) {
+	// login ไม่ตรวจ policy ของรหัสผ่าน (บัญชีเก่าอาจตั้งไว้ก่อนมี policy)
 	var in struct {
-		Email    string `json:"email"` // Actually usernameOrEmail
-		Password string `json:"password"`
+		Email    string `json:"email" binding:"required,notblank"` // Actually usernameOrEmail
+		Password string `json:"password" binding:"required"`
 	}
-	if err := c.BindJSON(&in); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid input"})
+	if !validate.Bind(c, &in) {
 		return
 	}
-	if in.Email == "" || in.Password == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "username/email and password are required"})
+
+	// ตรวจทั้งต่อ IP และต่อบัญชี (นับแม้บัญชีไม่มีอยู่จริง จะได้แยกไม่ออกว่ามีหรือไม่)
+	ip, account := c.ClientIP(), strings.ToLower(strings.TrimSpace(in.Email))
+	if throttled(c, h.Limiter, ratelimit.LoginByIP, ip) || throttled(c, h.Limiter, ratelimit.LoginByAccount, account) {
 		return
 	}
 
 	// Authenticate user
 	user, err := h.Svc.Login(c.Request.Context(), in.Email, in.Password)
 	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid credentials"})
+		if errors.Is(err, domain.ErrInvalidCredentials) {
+			h.loginFailed(c, ip, account)
+			response.Fail(c, http.StatusUnauthorized, "invalid_credentials", "invalid credentials")
+			return
+		}
+		response.Error(c, err, "login failed")
 		return
 	}
 
-	// Generate token
-	token, err := h.Svc.GenerateToken(user.ID)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "token generation failed"})
+	// ตัวนับของบัญชียังไม่ล้าง จนกว่าจะผ่าน 2FA
+	if h.mfaChallenge(c, user.ID) {
 		return
 	}
 
-	// Set cookie
-	c.SetCookie("access_token", token, int((24 * time.Hour).Seconds()), "/", "", false, true)
+	h.resetLoginFailures(c, user)
+	h.completeLogin(c, user, "login successful")
+}
 
-	c.JSON(http.StatusOK, gin.H{
-		"success": true,
-		"message": "login successful",
-		"token": token,
+// mfaChallenge: ผู้ใช้เปิด 2FA ไว้ = ยังไม่ออก token จริง ตอบ mfa_token ให้ไปยืนยันรหัสที่ /mfa/verify ก่อน
+// คืน true ถ้าตอบไปแล้ว (ต้องผ่าน 2FA หรือ error)
+func (h *AuthHandler) mfaChallenge(c *gin.Context, userID int64) bool {
+	mfaRequired, err := h.MFASvc.Required(c.Request.Context(), userID)
+	if err != nil {
+		response.Error(c, err, "server error")
+		return true
+	}
+	if !mfaRequired {
+		return false
+	}
+	mfaToken, ttl, err := h.MFASvc.StartLogin(userID)
+	if err != nil {
+		response.Error(c, err, "token generation failed")
+		return true
+	}
+	response.OK(c, gin.H{
+		"success":      false,
+		"mfa_required": true,
+		"mfa_token":    mfaToken,
+		"expires_in":   int(ttl.Seconds()),
+	})
+	return true
+}
+
+// resetLoginFailures ล้างตัวนับของบัญชีหลัง login สำเร็จครบทุกขั้น (ตัวนับ IP ไม่ล้าง
+// ไม่งั้นล็อกอินบัญชีตัวเองสลับเพื่อรีเซ็ตได้) ผู้ใช้ login ด้วยอีเมลหรือ username ก็ได้ จึงล้างทั้งสอง
+func (h *AuthHandler) resetLoginFailures(c *gin.Context, user *domain.User) {
+	accounts := []string{strings.ToLower(user.Email)}
+	if user.Username.Valid {
+		accounts = append(accounts, strings.ToLower(user.Username.String))
+	}
+	for _, account := range accounts {
+		if err := h.Limiter.Reset(c.Request.Context(), ratelimit.LoginByAccount, account); err != nil {
+			log.Printf("ratelimit: reset failed: %v", err)
+		}
+	}
+}
+
+// throttled ตอบ 429 (พร้อม Retry-After) ถ้า key นี้ยังถูกบล็อกอยู่
+func throttled(c *gin.Context, limiter *ratelimit.Limiter, rule ratelimit.Rule, subject string) bool {
+	wait, err := limiter.Check(c.Request.Context(), rule, subject)
+	if err != nil {
+		log.Printf("ratelimit: check %s failed: %v", rule.Name, err)
+		response.Error(c, err, "server error")
+		return true
+	}
+	if wait <= 0 {
+		return false
+	}
+	secs := int(math.Ceil(wait.Seconds()))
+	c.Header("Retry-After", strconv.Itoa(secs))
+	response.FailWith(c, http.StatusTooManyRequests, response.CodeTooManyRequests,
+		"too many attempts, please try again later", gin.H{"retry_after": secs})
+	return true
+}
+
+// loginFailed นับ login ที่ผิดทั้งต่อ IP และต่อบัญชี
+func (h *AuthHandler) loginFailed(c *gin.Context, ip, account string) {
+	ctx := c.Request.Context()
+	if _, err := h.Limiter.Fail(ctx, ratelimit.LoginByIP, ip, ip); err != nil {
+		log.Printf("ratelimit: record login failure failed: %v", err)
+	}
+	if _, err := h.Limiter.Fail(ctx, ratelimit.LoginByAccount, account, ip); err != nil {
+		log.Printf("ratelimit: record login failure failed: %v", err)
+	}
+}
+
+// completeLogin ออก token + cookie และตอบกลับแบบเดียวกันทั้ง login ปกติและหลังผ่าน 2FA
+func (h *AuthHandler) completeLogin(c *gin.Context, user *domain.User, message string) {
+	tokens, err := h.Svc.IssueTokens(c.Request.Context(), user.ID, clientInfo(c))
+	if err != nil {
+		response.Error(c, err, "token generation failed")
+		return
+	}
+	setAuthCookies(c, tokens)
+
+	response.OK(c, gin.H{
+		"success":       true,
+		"message":       message,
+		"token":         tokens.AccessToken,
+		"expires_in":    int(tokens.AccessTTL.Seconds()),
+		"refresh_token": tokens.RefreshToken,
 		"user": gin.H{
-			"id":    user.ID,
-			"email": user.Email,
-			"name":  user.Name,
+			"id":             user.ID,
+			"email":          user.Email,
+			"name":           user.Name,
+			"email_verified": user.EmailVerified(),
 		},
 	})
 }
 
-func (h *AuthHandler) register(c *gin.Context) {
+func (h *AuthHandler) mfaVerify(c *gin.Context) {
 	var in struct {
-		Email    string `json:"email"`
-		Username string `json:"username"`
-		Password string `json:"password"`
-		Name     string `json:"name"`
+		MFAToken string `json:"mfa_token" binding:"required"`
+		Code     string `json:"code" binding:"required,max=32"`
+	}
+	if !validate.Bind(c, &in) {
+		return
+	}
+
+	// ตรวจตัวนับต่อผู้ใช้ก่อนตรวจรหัส (mfa_token ผิดไม่นับ เพราะไม่รู้ว่าเป็นของใคร)
+	pending, err := h.JWT.ParseMFAToken(in.MFAToken)
+	if err != nil {
+		response.Fail(c, http.StatusUnauthorized, "invalid_mfa_token", "invalid or expired mfa token")
+		return
+	}
+	if mfaThrottled(c, h.Limiter, pending) {
+		return
+	}
+
+	userID, err := h.MFASvc.CompleteLogin(c.Request.Context(), in.MFAToken, in.Code)
+	mfaAttempt(c, h.Limiter, pending, err)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrInvalidToken):
+			response.Fail(c, http.StatusUnauthorized, "invalid_mfa_token", "invalid or expired mfa token")
+		case errors.Is(err, domain.ErrInvalidMFACode), errors.Is(err, domain.ErrMFANotEnabled):
+			response.Fail(c, http.StatusUnauthorized, "invalid_mfa_code", "invalid code")
+		default:
+			response.Error(c, err, "mfa verification failed")
+		}
+		return
 	}
-	if err := c.BindJSON(&in); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid input"})
+
+	user, err := h.UserRepo.GetByID(c.Request.Context(), userID)
+	if err != nil {
+		response.Error(c, err, "server error")
 		return
 	}
-	if in.Email == "" || in.Username == "" || in.Password == "" || in.Name == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "email, username, password, and name are required"})
+	h.resetLoginFailures(c, user)
+	h.completeLogin(c, user, "login successful")
+}
+
+// mfaThrottled: ตัวนับรหัส 2FA ผิดต่อผู้ใช้ ใช้ร่วมกันทั้งตอน login และหน้า settings
+func mfaThrottled(c *gin.Context, limiter *ratelimit.Limiter, userID int64) bool {
+	return throttled(c, limiter, ratelimit.MFAByUser, strconv.FormatInt(userID, 10))
+}
+
+// mfaAttempt นับรหัสที่ผิด หรือล้างตัวนับเมื่อรหัสถูก (err อื่นไม่นับ)
+func mfaAttempt(c *gin.Context, limiter *ratelimit.Limiter, userID int64, err error) {
+	ctx, subject := c.Request.Context(), strconv.FormatInt(userID, 10)
+	switch {
+	case err == nil:
+		err = limiter.Reset(ctx, ratelimit.MFAByUser, subject)
+	case errors.Is(err, domain.ErrInvalidMFACode):
+		_, err = limiter.Fail(ctx, ratelimit.MFAByUser, subject, c.ClientIP())
+	default:
+		return
+	}
+	if err != nil {
+		log.Printf("ratelimit: record mfa attempt failed: %v", err)
+	}
+}
+
+// registerInput ใช้ร่วมกันทั้งสมัครปกติและสมัครต่อจาก Google
+type registerInput struct {
+	Email    string `json:"email" binding:"required,email,max=254"`
+	Username string `json:"username" binding:"required,username"`
+	Password string `json:"password" binding:"required,password"`
+	Name     string `json:"name" binding:"required,notblank,max=100"`
+
+	// สมัครจากลิงก์เชิญเข้าทีม (อีเมลต้องตรงกับที่ถูกเชิญ)
+	InviteToken string `json:"invite_token" binding:"omitempty,max=2048"`
+}
+
+// checkInvite ตรวจ token คำเชิญก่อนสร้างบัญชี (ผิดแล้วไม่สร้างบัญชีค้างไว้)
+func (h *AuthHandler) checkInvite(c *gin.Context, in *registerInput) bool {
+	if in.InviteToken == "" {
+		return true
+	}
+	if err := h.InviteSvc.ValidateFor(c.Request.Context(), in.InviteToken, in.Email); err != nil {
+		response.Error(c, err, "registration failed")
+		return false
+	}
+	return true
+}
+
+// acceptInvite รับคำเชิญให้ผู้ใช้ที่เพิ่งสมัคร/ล็อกอิน; ไม่สำเร็จไม่ถือว่าสมัครไม่สำเร็จ (คืน nil)
+func (h *AuthHandler) acceptInvite(c *gin.Context, userID int64, token string) *domain.Workspace {
+	if token == "" {
+		return nil
+	}
+	w, err := h.InviteSvc.Accept(c.Request.Context(), userID, token)
+	if err != nil {
+		log.Printf("accept invitation failed for user %d: %v", userID, err)
+		return nil
+	}
+	return w
+}
+
+func (h *AuthHandler) register(c *gin.Context) {
+	var in registerInput
+	if !validate.Bind(c, &in) || !h.checkInvite(c, &in) {
 		return
 	}
 
 	// Check if email already exists
 	exists, err := h.UserRepo.EmailExists(c.Request.Context(), in.Email)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		response.Error(c, err, "server error")
 		return
 	}
 	if exists {
-		c.JSON(http.StatusConflict, gin.H{"error": "email already registered"})
+		response.Fail(c, http.StatusConflict, "email_exists", "email already registered")
 		return
 	}
 
 	// Check if username already exists
 	usernameExists, err := h.UserRepo.UsernameExists(c.Request.Context(), in.Username)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		response.Error(c, err, "server error")
 		return
 	}
 	if usernameExists {
-		c.JSON(http.StatusConflict, gin.H{"error": "username already taken"})
+		response.Fail(c, http.StatusConflict, "username_exists", "username already taken")
 		return
 	}
 
 	// Register user
 	user, err := h.Svc.Register(c.Request.Context(), in.Email, in.Username, in.Password, in.Name)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "registration failed"})
+		// email/username ซ้ำ (race กับการเช็คด้านบน) = 409, รหัสผ่านอ่อน = 400
+		response.Error(c, err, "registration failed")
+		return
+	}
+
+	// Issue tokens for the new user
+	tokens, err := h.Svc.IssueTokens(c.Request.Context(), user.ID, clientInfo(c))
+	if err != nil {
+		response.Error(c, err, "token generation failed")
 		return
 	}
+	setAuthCookies(c, tokens)
+
+	// รับคำเชิญได้ = ยืนยันอีเมลแล้ว ไม่ต้องส่งลิงก์ยืนยันอีก
+	body := gin.H{
+		"success":       true,
+		"message":       "registration successful",
+		"token":         tokens.AccessToken,
+		"expires_in":    int(tokens.AccessTTL.Seconds()),
+		"refresh_token": tokens.RefreshToken,
+		"user": gin.H{
+			"id":             user.ID,
+			"email":          user.Email,
+			"name":           user.Name,
+			"email_verified": user.EmailVerified(),
+		},
+	}
+	if w := h.acceptInvite(c, user.ID, in.InviteToken); w != nil {
+		body["workspace"] = workspaceResponse(w)
+		body["user"].(gin.H)["email_verified"] = true
+	} else {
+		h.sendVerificationAsync(c, user.ID)
+	}
+	response.Created(c, body)
+}
+
+// onboardingInput: หน้า create account หลังสมัครด้วย provider (อีเมลมาจากบัญชีใน ticket ไม่ใช่จาก body)
+type onboardingInput struct {
+	Ticket   string `json:"onboarding_ticket" binding:"required,max=2048"`
+	Username string `json:"username" binding:"required,username"`
+	Password string `json:"password" binding:"required,password"`
+	Name     string `json:"name" binding:"required,notblank,max=100"`
 
-	// Generate token for the new user
-	token, err := h.Svc.GenerateToken(user.ID)
+	InviteToken string `json:"invite_token" binding:"omitempty,max=2048"`
+}
+
+// completeGoogleRegistration ตั้ง username / รหัสผ่านให้บัญชีที่ OAuth callback เพิ่งสร้าง
+// ต้องมี onboarding ticket ที่ callback ออกให้ และใช้ได้กับบัญชีที่ยังไม่มีรหัสผ่านเท่านั้น
+func (h *AuthHandler) completeGoogleRegistration(c *gin.Context) {
+	var in onboardingInput
+	if !validate.Bind(c, &in) {
+		return
+	}
+	userID, err := h.JWT.ParseOnboardingTicket(in.Ticket)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "token generation failed"})
+		response.Error(c, domain.ErrInvalidToken, "registration failed")
 		return
 	}
 
-	// Set cookie
-	c.SetCookie("access_token", token, int((24 * time.Hour).Seconds()), "/", "", false, true)
+	if in.InviteToken != "" {
+		pending, err := h.UserRepo.GetByID(c.Request.Context(), userID)
+		if err != nil {
+			response.Error(c, err, "registration failed")
+			return
+		}
+		if err := h.InviteSvc.ValidateFor(c.Request.Context(), in.InviteToken, pending.Email); err != nil {
+			response.Error(c, err, "registration failed")
+			return
+		}
+	}
 
-	c.JSON(http.StatusCreated, gin.H{
-		"success": true,
-		"message": "registration successful",
-		"token": token,
+	user, err := h.Svc.CompleteOAuthRegistration(c.Request.Context(), userID, in.Username, in.Password, in.Name)
+	if err != nil {
+		// ticket ใช้แล้ว = 400 invalid_token, username ซ้ำ = 409, รหัสผ่านอ่อน = 400
+		response.Error(c, err, "registration failed")
+		return
+	}
+	w := h.acceptInvite(c, user.ID, in.InviteToken)
+	if w == nil && !user.EmailVerified() {
+		h.sendVerificationAsync(c, user.ID)
+	}
+
+	// ออก session แบบเดียวกับ login (รวมขั้น 2FA ถ้าเปิดไว้ระหว่างนั้น)
+	if h.mfaChallenge(c, user.ID) {
+		return
+	}
+	tokens, err := h.Svc.IssueTokens(c.Request.Context(), user.ID, clientInfo(c))
+	if err != nil {
+		response.Error(c, err, "token generation failed")
+		return
+	}
+	setAuthCookies(c, tokens)
+
+	body := gin.H{
+		"success":       true,
+		"message":       "registration completed successfully",
+		"token":         tokens.AccessToken,
+		"expires_in":    int(tokens.AccessTTL.Seconds()),
+		"refresh_token": tokens.RefreshToken,
 		"user": gin.H{
-			"id":    user.ID,
-			"email": user.Email,
-			"name":  user.Name,
+			"id":             user.ID,
+			"email":          user.Email,
+			"name":           user.Name.String,
+			"email_verified": user.EmailVerified() || w != nil,
 		},
-	})
+	}
+	if w != nil {
+		body["workspace"] = workspaceResponse(w)
+	}
+	response.OK(c, body)
 }
 
-func (h *AuthHandler) completeGoogleRegistration(c *gin.Context) {
-	var in struct {
-		Email    string `json:"email" binding:"required,email"`
-		Username string `json:"username" binding:"required"`
-		Password string `json:"password" binding:"required"`
-		Name     string `json:"name" binding:"required"`
+// listProviders คืน OIDC provider ที่เปิดใช้ ให้หน้า login วาดปุ่ม
+func (h *AuthHandler) listProviders(c *gin.Context) {
+	response.OK(c, gin.H{"providers": h.Providers.List()})
+}
+
+// provider หา OIDC provider จาก path (/api/auth/{provider}/...) ตอบ 404 ถ้าไม่ได้เปิดใช้
+func (h *AuthHandler) provider(c *gin.Context) (*auth.OIDCProvider, bool) {
+	p, ok := h.Providers.Get(c.Param("provider"))
+	if !ok {
+		response.Fail(c, http.StatusNotFound, "unknown_login_provider", "unknown login provider")
+		return nil, false
 	}
+	return p, true
+}
 
-	if err := c.ShouldBindJSON(&in); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid input"})
+func (h *AuthHandler) oauthLogin(c *gin.Context) {
+	p, ok := h.provider(c)
+	if !ok {
 		return
 	}
+	next := c.Query("next")
+	onboardIfNew := c.Query("onboardIfNew")
+	if next != "" {
+		if _, ok := h.safeNext(next); !ok {
+			log.Printf("OAuth login (%s) - next %q is not allowed, ignored", p.Name(), next)
+			next = ""
+		}
+	}
 
-	// Check if username already exists
-	usernameExists, err := h.UserRepo.UsernameExists(c.Request.Context(), in.Username)
+	st, err := auth.NewOAuthState(p.Name(), next, onboardIfNew)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		h.oauthFailed(c, "OAuth login ("+p.Name()+") - create state", err)
 		return
 	}
-	if usernameExists {
-		c.JSON(http.StatusConflict, gin.H{"error": "username already taken"})
+	// มาจากหน้ารับคำเชิญ: เก็บ token ไว้ใน state แล้วรับให้ตอน callback
+	st.Invite = c.Query("invite")
+	h.startOAuth(c, p, st, http.StatusFound)
+}
+
+// oauthLink เริ่ม OAuth เพื่อผูก provider กับบัญชีที่ล็อกอินอยู่
+// หน้า settings ขอ ticket จาก /api/users/me/identities/{provider}/link แล้ว submit form มาที่นี่
+// (ticket อยู่ใน body ไม่ติดไปกับ URL/history)
+func (h *AuthHandler) oauthLink(c *gin.Context) {
+	p, ok := h.provider(c)
+	if !ok {
+		return
+	}
+	userID, err := h.JWT.ParseLinkTicket(c.PostForm("ticket"), p.Name())
+	if err != nil {
+		c.Redirect(http.StatusSeeOther, h.FrontendURL+"/settings/index.html?link_error=expired")
+		return
+	}
+
+	st, err := auth.NewOAuthState(p.Name(), "", "")
+	if err != nil {
+		h.oauthFailed(c, "OAuth link ("+p.Name()+") - create state", err)
+		return
+	}
+	st.LinkUserID = userID
+	h.startOAuth(c, p, st, http.StatusSeeOther)
+}
+
+// startOAuth เก็บ state ลง cookie แล้วส่ง browser ไปหน้า login ของ provider
+func (h *AuthHandler) startOAuth(c *gin.Context, p *auth.OIDCProvider, st *auth.OAuthState, status int) {
+	loginURL, err := p.LoginURL(st.State, st.Nonce, st.CodeVerifier)
+	if err != nil {
+		log.Printf("OAuth login (%s) unavailable: %v", p.Name(), err)
+		c.String(http.StatusBadGateway, "login provider is unavailable, please try again later")
+		return
+	}
+	cookie, ttl, err := h.JWT.GenerateOAuthState(st)
+	if err != nil {
+		h.oauthFailed(c, "OAuth login ("+p.Name()+") - sign state", err)
+		return
+	}
+
+	// Lax: cookie ต้องติดมากับ redirect (GET) จาก provider กลับมาที่ callback
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(oauthStateCookie, cookie, int(ttl.Seconds()), refreshCookiePath, "", false, true)
+	c.Redirect(status, loginURL)
+}
+
+func (h *AuthHandler) oauthCallback(c *gin.Context) {
+	p, ok := h.provider(c)
+	if !ok {
+		return
+	}
+
+	// state ต้องมาจาก browser เดียวกับที่เริ่ม login (cookie ที่เราเซ็นไว้) และใช้ได้ครั้งเดียว
+	raw, _ := c.Cookie(oauthStateCookie)
+	c.SetCookie(oauthStateCookie, "", -1, refreshCookiePath, "", false, true)
+	st, err := h.JWT.ParseOAuthState(raw)
+	if err != nil || st.Provider != p.Name() ||
+		subtle.ConstantTimeCompare([]byte(st.State), []byte(c.Query("state"))) != 1 {
+		c.String(http.StatusBadRequest, "oauth error: invalid or expired login attempt, please try again")
+		return
+	}
+
+	// ผู้ใช้กดยกเลิกที่หน้า provider
+	if e := c.Query("error"); e != "" {
+		log.Printf("OAuth callback (%s) - provider returned error: %s", p.Name(), e)
+		c.Redirect(http.StatusFound, h.FrontendURL+"/auth/login.html?error=oauth")
+		return
+	}
+
+	ext, err := p.Exchange(c.Request.Context(), c.Query("code"), st.Nonce, st.CodeVerifier)
+	if err != nil {
+		h.oauthFailed(c, "OAuth callback ("+p.Name()+") - exchange code", err)
 		return
 	}
 
-	// Complete Google registration
-	user, err := h.Svc.CompleteGoogleRegistration(c.Request.Context(), in.Email, in.Username, in.Password, in.Name)
+	if st.LinkUserID != 0 {
+		h.finishLink(c, p, st.LinkUserID, ext)
+		return
+	}
+
+	u, created, err := h.Svc.LoginOrSignupOAuth(c.Request.Context(), ext)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "registration failed"})
+		switch {
+		case errors.Is(err, domain.ErrEmailNotVerified):
+			c.String(http.StatusForbidden, "auth error: %s account email is not verified", p.Name())
+		case errors.Is(err, domain.ErrIdentityNotLinked):
+			// ไม่รวมบัญชีให้อัตโนมัติ: ให้ล็อกอินด้วยวิธีเดิมแล้วผูกจากหน้า settings
+			c.Redirect(http.StatusFound, h.FrontendURL+"/auth/login.html?error=account_exists&provider="+url.QueryEscape(p.Name()))
+		default:
+			h.oauthFailed(c, "OAuth callback ("+p.Name()+") - login or signup", err)
+		}
 		return
 	}
 
-	// Generate token for the updated user
-	token, err := h.Svc.GenerateToken(user.ID)
+	// รับคำเชิญก่อนขั้น 2FA: ตัวตนยืนยันกับ provider แล้ว และอีเมลต้องตรงกับที่ถูกเชิญ
+	// ไม่สำเร็จ = กลับไปหน้ารับคำเชิญพร้อม error code (ยังไม่ออก session ให้)
+	var invited *domain.Workspace
+	if st.Invite != "" {
+		invited, err = h.InviteSvc.Accept(c.Request.Context(), u.ID, st.Invite)
+		if err != nil {
+			code := "invalid_token"
+			if _, known, ok := response.Lookup(err); ok {
+				code = known
+			} else {
+				log.Printf("OAuth callback (%s) - accept invitation failed for user %d: %v", p.Name(), u.ID, err)
+			}
+			c.Redirect(http.StatusFound, h.FrontendURL+"/invite.html?error="+url.QueryEscape(code)+"&token="+url.QueryEscape(st.Invite))
+			return
+		}
+	}
+
+	// ผู้ใช้ใหม่: ยังไม่ออก session ส่งไปตั้ง username/รหัสผ่านที่หน้า create_account พร้อม onboarding ticket
+	// (ticket อยู่ใน fragment จึงไม่ถูกส่งไป server/log) รับคำเชิญแล้วแนบ workspace ไปด้วย
+	if created {
+		ticket, _, err := h.JWT.GenerateOnboardingTicket(u.ID)
+		if err != nil {
+			h.oauthFailed(c, "OAuth callback ("+p.Name()+") - onboarding ticket", err)
+			return
+		}
+		target := h.FrontendURL + "/create_account.html?email=" + url.QueryEscape(ext.Email)
+		if invited != nil {
+			target += "&workspace=" + strconv.FormatInt(invited.ID, 10)
+		}
+		c.Redirect(http.StatusFound, target+"#onboarding_ticket="+url.QueryEscape(ticket))
+		return
+	}
+
+	// เปิด 2FA ไว้: ส่งไปหน้ากรอกรหัส (token อยู่ใน fragment จึงไม่ถูกส่งไป server/log)
+	mfaRequired, err := h.MFASvc.Required(c.Request.Context(), u.ID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "token generation failed"})
+		h.oauthFailed(c, "OAuth callback ("+p.Name()+") - check 2FA", err)
+		return
+	}
+	if mfaRequired {
+		mfaToken, _, err := h.MFASvc.StartLogin(u.ID)
+		if err != nil {
+			h.oauthFailed(c, "OAuth callback ("+p.Name()+") - start 2FA", err)
+			return
+		}
+		c.Redirect(http.StatusFound, h.FrontendURL+"/auth/mfa.html#mfa_token="+mfaToken)
 		return
 	}
 
-	// Set cookie
-	c.SetCookie("access_token", token, int((24 * time.Hour).Seconds()), "/", "", false, true)
+	tokens, err := h.Svc.IssueTokens(c.Request.Context(), u.ID, clientInfo(c))
+	if err != nil {
+		h.oauthFailed(c, "OAuth callback ("+p.Name()+") - issue tokens", err)
+		return
+	}
+	setAuthCookies(c, tokens)
 
-	c.JSON(http.StatusOK, gin.H{
+	if invited != nil {
+		c.Redirect(http.StatusFound, h.FrontendURL+"/dashboard/index.html?workspace="+strconv.FormatInt(invited.ID, 10))
+		return
+	}
+	if target, ok := h.safeNext(st.Next); ok {
+		c.Redirect(http.StatusFound, target)
+		return
+	}
+	c.Redirect(http.StatusFound, h.FrontendURL+"/dashboard/index.html")
+}
+
+// oauthFailed log error ไว้ที่ server แล้วส่งกลับหน้า login (ไม่ส่งรายละเอียด error ภายในไปให้ browser)
+func (h *AuthHandler) oauthFailed(c *gin.Context, what string, err error) {
+	log.Printf("%s failed: %v", what, err)
+	c.Redirect(http.StatusFound, h.FrontendURL+"/auth/login.html?error=oauth")
+}
+
+// finishLink ผูก identity ที่เพิ่ง login ที่ provider กับผู้ใช้ใน link ticket แล้วกลับไปหน้า settings
+func (h *AuthHandler) finishLink(c *gin.Context, p *auth.OIDCProvider, userID int64, ext *auth.ExternalUser) {
+	settings := h.FrontendURL + "/settings/index.html"
+	if _, err := h.IdentitySvc.Link(c.Request.Context(), userID, ext); err != nil {
+		if errors.Is(err, domain.ErrIdentityInUse) {
+			c.Redirect(http.StatusFound, settings+"?link_error=in_use")
+			return
+		}
+		log.Printf("OAuth link (%s) failed for user %d: %v", p.Name(), userID, err)
+		c.Redirect(http.StatusFound, settings+"?link_error=failed")
+		return
+	}
+	c.Redirect(http.StatusFound, settings+"?linked="+url.QueryEscape(p.Name()))
+}
+
+// refreshTokenFrom อ่าน refresh token จาก body ({"refresh_token": ...}) หรือ cookie
+func refreshTokenFrom(c *gin.Context) string {
+	var in struct {
+		RefreshToken string `json:"refresh_token"`
+	}
+	_ = c.ShouldBindJSON(&in)
+	if in.RefreshToken != "" {
+		return in.RefreshToken
+	}
+	if cookie, err := c.Cookie(refreshCookie); err == nil {
+		return cookie
+	}
+	return ""
+}
+
+func (h *AuthHandler) refresh(c *gin.Context) {
+	rt := refreshTokenFrom(c)
+	if rt == "" {
+		response.Fail(c, http.StatusUnauthorized, "refresh_token_required", "refresh token required")
+		return
+	}
+
+	tokens, err := h.Svc.Refresh(c.Request.Context(), rt)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrRefreshTokenReused):
+			log.Printf("Refresh token reuse detected, token family revoked")
+			clearAuthCookies(c)
+			response.Fail(c, http.StatusUnauthorized, "refresh_token_reused", "refresh token reused")
+		case errors.Is(err, domain.ErrInvalidRefreshToken):
+			clearAuthCookies(c)
+			response.Fail(c, http.StatusUnauthorized, "invalid_refresh_token", "invalid refresh token")
+		default:
+			response.Error(c, err, "token refresh failed")
+		}
+		return
+	}
+
+	setAuthCookies(c, tokens)
+	response.OK(c, tokenResponse(tokens))
+}
+
+func (h *AuthHandler) logout(c *gin.Context) {
+	if rt := refreshTokenFrom(c); rt != "" {
+		if err := h.Svc.Logout(c.Request.Context(), rt); err != nil {
+			response.Error(c, err, "logout failed")
+			return
+		}
+	}
+	clearAuthCookies(c)
+	response.OK(c, gin.H{"success": true, "message": "logged out"})
+}
+
+func (h *AuthHandler) forgotPassword(c *gin.Context) {
+	var in struct {
+		Email string `json:"email" binding:"required,email"`
+	}
+	if !validate.Bind(c, &in) {
+		return
+	}
+
+	// ส่งอีเมลเบื้องหลัง เพื่อให้เวลาตอบกลับเท่ากันไม่ว่าจะมีอีเมลนี้หรือไม่
+	locale := i18n.FromContext(c.Request.Context())
+	go func(email string) {
+		ctx, cancel := context.WithTimeout(i18n.WithLocale(context.Background(), locale), 30*time.Second)
+		defer cancel()
+		if err := h.ResetSvc.RequestReset(ctx, email); err != nil {
+			log.Printf("password reset request failed: %v", err)
+		}
+	}(in.Email)
+
+	response.OK(c, gin.H{
 		"success": true,
-		"message": "registration completed successfully",
-		"token": token,
-		"user": gin.H{
-			"id":    user.ID,
-			"email": user.Email,
-			"name":  user.Name.String,
-		},
+		"message": "if an account exists for this email, a reset link has been sent",
 	})
 }
 
-func (h *AuthHandler) googleLogin(c *gin.Context) {
-	next := c.Query("next")
-	onboardIfNew := c.Query("onboardIfNew")
-	
-	// Debug logging
-	log.Printf("Google login - Next: %s, OnboardIfNew: %s", next, onboardIfNew)
-
-	st := struct {
-		Next         string `json:"next"`
-		OnboardIfNew string `json:"onboardIfNew"`
-	}{Next: next, OnboardIfNew: onboardIfNew}
+func (h *AuthHandler) resetPassword(c *gin.Context) {
+	var in struct {
+		Token    string `json:"token" binding:"required"`
+		Password string `json:"password" binding:"required,password"`
+	}
+	if !validate.Bind(c, &in) {
+		return
+	}
 
-	b, _ := json.Marshal(st)
-	state := base64.URLEncoding.EncodeToString(b)
-	
-	// Debug state
-	log.Printf("State encoded: %s", state)
+	if err := h.ResetSvc.Reset(c.Request.Context(), in.Token, in.Password); err != nil {
+		switch {
+		case errors.Is(err, domain.ErrInvalidToken):
+			response.Fail(c, http.StatusBadRequest, "invalid_token", "invalid or expired token")
+		default:
+			response.Error(c, err, "password reset failed")
+		}
+		return
+	}
+	response.OK(c, gin.H{"success": true, "message": "password has been reset"})
+}
 
-	url := h.GoogleCfg.LoginURL(state)
-	c.Redirect(http.StatusFound, url)
+// verifyEmailLink คือปลายทางของลิงก์ในอีเมล ยืนยันแล้ว redirect ไปหน้า login
+func (h *AuthHandler) verifyEmailLink(c *gin.Context) {
+	result := "1"
+	if err := h.VerifySvc.Verify(c.Request.Context(), c.Query("token")); err != nil {
+		if !errors.Is(err, domain.ErrInvalidToken) {
+			log.Printf("email verification failed: %v", err)
+		}
+		result = "0"
+	}
+	c.Redirect(http.StatusFound, h.FrontendURL+"/auth/login.html?verified="+result)
 }
 
-func (h *AuthHandler) googleCallback(c *gin.Context) {
-	code := c.Query("code")
-	stateB64 := c.Query("state")
+func (h *AuthHandler) verifyEmail(c *gin.Context) {
+	var in struct {
+		Token string `json:"token" binding:"required"`
+	}
+	if !validate.Bind(c, &in) {
+		return
+	}
+
+	if err := h.VerifySvc.Verify(c.Request.Context(), in.Token); err != nil {
+		if errors.Is(err, domain.ErrInvalidToken) {
+			response.Fail(c, http.StatusBadRequest, "invalid_token", "invalid or expired token")
+			return
+		}
+		response.Error(c, err, "email verification failed")
+		return
+	}
+	response.OK(c, gin.H{"success": true, "message": "email verified"})
+}
 
-	var st struct {
-		Next         string `json:"next"`
-		OnboardIfNew string `json:"onboardIfNew"`
+func (h *AuthHandler) passkeyLoginBegin(c *gin.Context) {
+	ceremonyID, options, err := h.PasskeySvc.BeginLogin(c.Request.Context())
+	if err != nil {
+		response.Error(c, err, "failed to start passkey login")
+		return
 	}
-	if sb, err := base64.URLEncoding.DecodeString(stateB64); err == nil {
-		_ = json.Unmarshal(sb, &st)
+	response.OK(c, gin.H{"ceremony_id": ceremonyID, "options": options})
+}
+
+// passkeyCredentialInput คือ body ของขั้น finish: credential เป็น JSON จาก navigator.credentials ตรงๆ
+type passkeyCredentialInput struct {
+	CeremonyID string          `json:"ceremony_id" binding:"required"`
+	Name       string          `json:"name" binding:"max=100"`
+	Credential json.RawMessage `json:"credential" binding:"required"`
+}
+
+func (h *AuthHandler) passkeyLoginFinish(c *gin.Context) {
+	var in passkeyCredentialInput
+	if !validate.Bind(c, &in) {
+		return
 	}
-	
-	// Debug logging
-	log.Printf("OAuth callback - Next: %s, OnboardIfNew: %s", st.Next, st.OnboardIfNew)
 
-	gu, err := h.GoogleCfg.FetchUser(c.Request.Context(), code)
+	userID, err := h.PasskeySvc.FinishLogin(c.Request.Context(), in.CeremonyID, in.Credential)
 	if err != nil {
-		c.String(http.StatusBadRequest, "oauth error: %v", err)
+		if errors.Is(err, domain.ErrInvalidPasskey) || errors.Is(err, domain.ErrUserNotFound) {
+			response.Fail(c, http.StatusUnauthorized, "passkey_verification_failed", "passkey verification failed")
+			return
+		}
+		response.Error(c, err, "passkey login failed")
 		return
 	}
 
-	u, token, created, err := h.Svc.LoginOrSignupGoogle( // ← รับ 4 ค่า
-		c.Request.Context(), gu.Email, gu.Name, gu.Sub, gu.Picture,
-	)
+	user, err := h.UserRepo.GetByID(c.Request.Context(), userID)
 	if err != nil {
-		c.String(http.StatusInternalServerError, "auth error: %v", err)
+		response.Error(c, err, "server error")
+		return
+	}
+	// passkey บังคับ user verification (PIN/biometric) อยู่แล้ว จึงไม่ต้องถาม TOTP ซ้ำ
+	h.completeLogin(c, user, "login successful")
+}
+
+func (h *AuthHandler) passkeyRegisterBegin(c *gin.Context) {
+	userID, ok := authctx.UserID(c)
+	if !ok {
+		response.Fail(c, http.StatusUnauthorized, response.CodeUnauthorized, "unauthorized")
 		return
 	}
 
-	c.SetCookie("access_token", token, int((24 * time.Hour).Seconds()), "/", "", false, true)
+	ceremonyID, options, err := h.PasskeySvc.BeginRegistration(c.Request.Context(), userID)
+	if err != nil {
+		response.Error(c, err, "failed to start passkey registration")
+		return
+	}
+	response.OK(c, gin.H{"ceremony_id": ceremonyID, "options": options})
+}
 
-	// flow ไปหน้าต่อ - ตรวจสอบผู้ใช้ใหม่ก่อน
-  if created {
-    // ผู้ใช้ใหม่ไปหน้า create_account.html พร้อมกับอีเมล
-    c.Redirect(http.StatusFound, h.FrontendURL+"/create_account.html?email="+gu.Email)
-    return
-  }
+func (h *AuthHandler) passkeyRegisterFinish(c *gin.Context) {
+	userID, ok := authctx.UserID(c)
+	if !ok {
+		response.Fail(c, http.StatusUnauthorized, response.CodeUnauthorized, "unauthorized")
+		return
+	}
 
-  // ตรวจสอบว่าผู้ใช้เก่ามี username และ password หรือยัง
-  if !u.Username.Valid || u.Username.String == "" || !u.PasswordHash.Valid || u.PasswordHash.String == "" {
-    // ผู้ใช้เก่าที่ยังไม่มี username หรือ password ให้ไปตั้งค่า
-    c.Redirect(http.StatusFound, h.FrontendURL+"/create_account.html?email="+gu.Email)
-    return
-  }
+	var in passkeyCredentialInput
+	if !validate.Bind(c, &in) {
+		return
+	}
 
-	if st.Next != "" {
-		c.Redirect(http.StatusFound, st.Next)
+	p, err := h.PasskeySvc.FinishRegistration(c.Request.Context(), userID, in.CeremonyID, in.Name, in.Credential)
+	if err != nil {
+		if errors.Is(err, domain.ErrInvalidPasskey) {
+			response.Fail(c, http.StatusBadRequest, "passkey_verification_failed", "passkey verification failed")
+			return
+		}
+		response.Error(c, err, "passkey registration failed")
 		return
 	}
-	// ผู้ใช้เก่าที่มีข้อมูลครบแล้ว ไปหน้า dashboard
-	c.Redirect(http.StatusFound, h.FrontendURL+"/dashboard/index.html")
+	response.Created(c, passkeyResponse(p))
+}
+
+func passkeyResponse(p *domain.Passkey) gin.H {
+	var lastUsed interface{}
+	if p.LastUsedAt.Valid {
+		lastUsed = p.LastUsedAt.Time
+	}
+	return gin.H{
+		"id":           p.ID,
+		"name":         p.Name,
+		"transports":   p.Transports,
+		"created_at":   p.CreatedAt,
+		"last_used_at": lastUsed,
+	}
 }
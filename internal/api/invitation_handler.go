@@ -0,0 +1,99 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"task-manager/internal/authctx"
+	"task-manager/internal/domain"
+	"task-manager/internal/middleware"
+	"task-manager/internal/service"
+	"task-manager/internal/validate"
+	"task-manager/pkg/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+// InvitationHandler: ฝั่งผู้ถูกเชิญ (เปิดลิงก์จากอีเมล) ส่วนการเชิญ/ยกเลิกอยู่ที่ WorkspaceHandler
+type InvitationHandler struct {
+	Svc service.InvitationService
+}
+
+// ไม่ใส่ RequireVerifiedEmail: การรับคำเชิญจากอีเมลถือเป็นการยืนยันอีเมลไปในตัว
+func RegisterInvitationRoutes(r *gin.Engine, h *InvitationHandler, authMw gin.HandlerFunc) {
+	g := r.Group("/api/invitations")
+	{
+		// หน้ารับคำเชิญเรียกก่อนล็อกอิน เพื่อแสดงชื่อทีมและอีเมลที่ถูกเชิญ
+		g.GET("/lookup", h.lookup)
+		g.POST("/accept", authMw, middleware.RequireSession(), h.accept)
+	}
+}
+
+func invitationResponse(inv *domain.Invitation) gin.H {
+	var invitedBy, inviter any
+	if inv.InvitedBy.Valid {
+		invitedBy = inv.InvitedBy.Int64
+	}
+	if inv.InviterName.Valid {
+		inviter = inv.InviterName.String
+	}
+	return gin.H{
+		"id":           inv.ID,
+		"workspace_id": inv.WorkspaceID,
+		"email":        inv.Email,
+		"role":         inv.Role,
+		"status":       inv.Status,
+		"invited_by":   invitedBy,
+		"inviter_name": inviter,
+		"expires_at":   inv.ExpiresAt,
+		"expired":      inv.Expired(time.Now()),
+		"sent_at":      inv.SentAt,
+		"created_at":   inv.CreatedAt,
+	}
+}
+
+func (h *InvitationHandler) lookup(c *gin.Context) {
+	token := c.Query("token")
+	if token == "" {
+		response.Invalid(c, "token is required", response.Required("token"))
+		return
+	}
+
+	inv, err := h.Svc.Lookup(c.Request.Context(), token)
+	if err != nil {
+		response.Error(c, err, "failed to look up invitation")
+		return
+	}
+	var inviter any
+	if inv.InviterName.Valid {
+		inviter = inv.InviterName.String
+	}
+	response.OK(c, gin.H{
+		"workspace":    gin.H{"id": inv.WorkspaceID, "name": inv.WorkspaceName},
+		"email":        inv.Email,
+		"role":         inv.Role,
+		"inviter_name": inviter,
+		"expires_at":   inv.ExpiresAt,
+	})
+}
+
+func (h *InvitationHandler) accept(c *gin.Context) {
+	userID, ok := authctx.UserID(c)
+	if !ok {
+		response.Fail(c, http.StatusUnauthorized, response.CodeUnauthorized, "unauthorized")
+		return
+	}
+	var in struct {
+		Token string `json:"token" binding:"required,max=2048"`
+	}
+	if !validate.Bind(c, &in) {
+		return
+	}
+
+	w, err := h.Svc.Accept(c.Request.Context(), userID, in.Token)
+	if err != nil {
+		response.Error(c, err, "failed to accept invitation")
+		return
+	}
+	response.OK(c, gin.H{"workspace": workspaceResponse(w)})
+}
@@ -0,0 +1,28 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"task-manager/internal/i18n"
+)
+
+// JSON เสียต้องตอบเป็นภาษาของ request เหมือน error อื่นของ validate
+func TestInvalidJSONUsesRequestLanguage(t *testing.T) {
+	ts := newTestServer(t)
+	for _, lang := range []string{i18n.EN, i18n.TH} {
+		t.Run(lang, func(t *testing.T) {
+			req, err := http.NewRequest(http.MethodPost, ts.URL+"/api/auth/login", strings.NewReader("{not json"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("Accept-Language", lang)
+			body := ts.client().send(t, req).expect(t, http.StatusBadRequest).json()
+			if want := i18n.T(lang, "validation.invalid_json"); body["code"] != "invalid_input" || body["error"] != want {
+				t.Fatalf("body = %v, want invalid_input %q", body, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,55 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// 401 เฉพาะเมื่อรหัสผ่าน/บัญชีไม่ถูก error อื่น (เช่น ฐานข้อมูลล่ม) ต้องเป็น 500
+func TestLoginErrors(t *testing.T) {
+	ts := newTestServer(t)
+	ts.signup(t, "alice")
+
+	tests := []struct {
+		name, login, password string
+	}{
+		{"wrong password", "alice", "wrong"},
+		{"unknown username", "nobody", testPassword},
+		{"unknown email", "nobody@example.com", testPassword},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := ts.client().do(t, http.MethodPost, "/api/auth/login", map[string]string{"email": tt.login, "password": tt.password})
+			if r.StatusCode != http.StatusUnauthorized || r.code() != "invalid_credentials" {
+				t.Fatalf("got %d %s, want 401 invalid_credentials", r.StatusCode, r.raw)
+			}
+		})
+	}
+
+	t.Run("database error", func(t *testing.T) {
+		ts.exec(t, `ALTER TABLE users RENAME TO users_gone`)
+		r := ts.client().do(t, http.MethodPost, "/api/auth/login", map[string]string{"email": "alice", "password": testPassword})
+		if r.StatusCode != http.StatusInternalServerError || r.code() == "invalid_credentials" {
+			t.Fatalf("got %d %s, want 500", r.StatusCode, r.raw)
+		}
+	})
+}
+
+// tag password ของ validate ต้องใช้ PASSWORD_MIN_LENGTH ที่ตั้งไว้ ไม่ใช่ค่า default
+func TestRegisterUsesConfiguredPasswordPolicy(t *testing.T) {
+	ts := newTestServer(t, testServerOptions{passwordMinLength: len(testPassword) + 1})
+	r := ts.client().do(t, http.MethodPost, "/api/auth/register", map[string]string{
+		"email": "alice@example.com", "username": "alice", "name": "alice", "password": testPassword,
+	}).expect(t, http.StatusBadRequest)
+
+	details, _ := r.json()["details"].([]any)
+	if len(details) != 1 {
+		t.Fatalf("details = %s, want one password error", r.raw)
+	}
+	d := details[0].(map[string]any)
+	if d["field"] != "password" || d["code"] != "password_too_short" || !strings.Contains(d["message"].(string), strconv.Itoa(len(testPassword)+1)) {
+		t.Fatalf("detail = %v, want password_too_short with the configured length", d)
+	}
+}
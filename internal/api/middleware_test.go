@@ -0,0 +1,230 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"testing"
+
+	"task-manager/internal/auth"
+	"task-manager/internal/config"
+	"task-manager/internal/domain"
+)
+
+// withAuthorization ส่ง request พร้อม Authorization header ตามที่ให้มา ("" = ไม่ส่ง header)
+func (c *testClient) withAuthorization(t testing.TB, header, method, path string) *testResponse {
+	t.Helper()
+	req, err := http.NewRequest(method, c.ts.URL+path, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if header != "" {
+		req.Header.Set("Authorization", header)
+	}
+	return c.send(t, req)
+}
+
+// createToken สร้าง personal access token ด้วย login session ของ c แล้วคืน token จริงกับ id
+func (c *testClient) createToken(t testing.TB, scopes ...string) (string, int64) {
+	t.Helper()
+	body := c.do(t, http.MethodPost, "/api/users/me/tokens", map[string]any{"name": "ci", "scopes": scopes}).
+		expect(t, http.StatusCreated).json()
+	return body["token"].(string), int64(body["id"].(float64))
+}
+
+// as คืน client ที่ใช้ token นี้แทน (cookie jar แยก)
+func (ts *testServer) as(token string) *testClient {
+	c := ts.client()
+	c.token = token
+	return c
+}
+
+func TestAuthRejectsMissingOrInvalidBearer(t *testing.T) {
+	ts := newTestServer(t)
+	alice := ts.signup(t, "alice")
+
+	// token ที่ลงลายเซ็นด้วย secret อื่น
+	other := auth.NewJWT(config.Config{JWTAccessSecret: "other-access", JWTRefreshSecret: "other-refresh", AccessTTLMin: 15, RefreshTTLHours: 24})
+	foreign, _, err := other.GenerateAccessToken(alice.id, domain.RoleAdmin, "", "sid")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// ลายเซ็นถูก แต่ session ไม่มีอยู่จริง
+	orphan, _, err := ts.jwt.GenerateAccessToken(alice.id, domain.RoleAdmin, "", "no-such-session")
+	if err != nil {
+		t.Fatal(err)
+	}
+	mfaToken, _, err := ts.jwt.GenerateMFAToken(alice.id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	refresh, _, err := ts.jwt.GenerateRefreshToken(alice.id, "jti", "family")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name, header, code string
+	}{
+		{"no header", "", "unauthorized"},
+		{"not bearer", "Basic " + alice.token, "unauthorized"},
+		{"bearer without token", "Bearer", "unauthorized"},
+		{"garbage", "Bearer not-a-jwt", "invalid_token"},
+		{"signed with another secret", "Bearer " + foreign, "invalid_token"},
+		{"mfa token", "Bearer " + mfaToken, "invalid_token"},
+		{"refresh token", "Bearer " + refresh, "invalid_token"},
+		{"unknown personal access token", "Bearer " + auth.PersonalTokenPrefix + "bogus", "invalid_token"},
+		{"session that does not exist", "Bearer " + orphan, "session_revoked"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := alice.withAuthorization(t, tt.header, http.MethodGet, "/api/users/me")
+			if r.StatusCode != http.StatusUnauthorized || r.code() != tt.code {
+				t.Fatalf("got %d %s, want 401 %s", r.StatusCode, r.raw, tt.code)
+			}
+		})
+	}
+
+	alice.do(t, http.MethodGet, "/api/users/me", nil).expect(t, http.StatusOK)
+}
+
+func TestAuthRejectsRevokedSession(t *testing.T) {
+	ts := newTestServer(t)
+	laptop := ts.signup(t, "alice")
+	phone := &testClient{ts: ts, http: ts.client().http, id: laptop.id}
+	phone.login(t)
+
+	// ออกจากระบบเครื่องอื่น: access token ของ phone ยังไม่หมดอายุแต่ใช้ไม่ได้แล้ว
+	laptop.do(t, http.MethodDelete, "/api/users/me/sessions", nil).expect(t, http.StatusOK)
+	if r := phone.do(t, http.MethodGet, "/api/tasks", nil); r.StatusCode != http.StatusUnauthorized || r.code() != "session_revoked" {
+		t.Fatalf("revoked session = %d %s, want 401 session_revoked", r.StatusCode, r.raw)
+	}
+	laptop.do(t, http.MethodGet, "/api/tasks", nil).expect(t, http.StatusOK)
+
+	laptop.do(t, http.MethodDelete, "/api/users/me/sessions?include_current=true", nil).expect(t, http.StatusOK)
+	laptop.do(t, http.MethodGet, "/api/tasks", nil).expect(t, http.StatusUnauthorized)
+}
+
+// handler เห็น principal ที่ middleware ตั้งไว้: user id ของเจ้าของ token และ session ที่ใช้อยู่
+func TestAuthSetsPrincipal(t *testing.T) {
+	ts := newTestServer(t)
+	alice := ts.signup(t, "alice")
+	ts.signup(t, "bob")
+
+	me := alice.do(t, http.MethodGet, "/api/users/me", nil).expect(t, http.StatusOK).json()
+	if int64(me["id"].(float64)) != alice.id {
+		t.Fatalf("me = %v, want alice", me)
+	}
+
+	second := &testClient{ts: ts, http: ts.client().http, id: alice.id}
+	second.login(t)
+	list := alice.do(t, http.MethodGet, "/api/users/me/sessions", nil).expect(t, http.StatusOK).json()
+	sessions, _ := list["sessions"].([]any)
+	current := 0
+	for _, s := range sessions {
+		if s.(map[string]any)["current"] == true {
+			current++
+		}
+	}
+	if len(sessions) != 2 || current != 1 {
+		t.Fatalf("sessions = %v, want 2 with exactly one current", list)
+	}
+
+	pat, _ := alice.createToken(t, domain.ScopeProfileRead)
+	me = ts.as(pat).do(t, http.MethodGet, "/api/users/me", nil).expect(t, http.StatusOK).json()
+	if int64(me["id"].(float64)) != alice.id {
+		t.Fatalf("me via token = %v, want alice", me)
+	}
+}
+
+func TestPersonalAccessTokenScopes(t *testing.T) {
+	ts := newTestServer(t)
+	alice := ts.signup(t, "alice")
+	readOnly, readOnlyID := alice.createToken(t, domain.ScopeTasksRead)
+	writer, _ := alice.createToken(t, domain.ScopeTasksRead, domain.ScopeTasksWrite)
+	bot := ts.as(readOnly)
+
+	tests := []struct {
+		name         string
+		c            *testClient
+		method, path string
+		body         any
+		status       int
+		code         string
+	}{
+		{"read with tasks:read", bot, http.MethodGet, "/api/tasks", nil, http.StatusOK, ""},
+		{"write without tasks:write", bot, http.MethodPost, "/api/tasks", map[string]string{"title": "x"}, http.StatusForbidden, "insufficient_scope"},
+		{"write with tasks:write", ts.as(writer), http.MethodPost, "/api/tasks", map[string]string{"title": "x"}, http.StatusCreated, ""},
+		{"profile without profile:read", bot, http.MethodGet, "/api/users/me", nil, http.StatusForbidden, "insufficient_scope"},
+		// จัดการบัญชีต้องเป็น login session เสมอ ไม่ว่า token จะมี scope อะไร
+		{"list sessions", bot, http.MethodGet, "/api/users/me/sessions", nil, http.StatusForbidden, "session_required"},
+		{"create another token", ts.as(writer), http.MethodPost, "/api/users/me/tokens", map[string]any{"name": "x", "scopes": []string{domain.ScopeAdmin}}, http.StatusForbidden, "session_required"},
+		{"create workspace", ts.as(writer), http.MethodPost, "/api/workspaces", map[string]string{"name": "x"}, http.StatusForbidden, "session_required"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := tt.c.do(t, tt.method, tt.path, tt.body).expect(t, tt.status)
+			if r.code() != tt.code {
+				t.Fatalf("code = %q, want %q: %s", r.code(), tt.code, r.raw)
+			}
+		})
+	}
+
+	alice.do(t, http.MethodDelete, fmt.Sprintf("/api/users/me/tokens/%d", readOnlyID), nil).expect(t, http.StatusOK)
+	if r := bot.do(t, http.MethodGet, "/api/tasks", nil); r.StatusCode != http.StatusUnauthorized || r.code() != "invalid_token" {
+		t.Fatalf("revoked token = %d %s, want 401 invalid_token", r.StatusCode, r.raw)
+	}
+}
+
+// scope admin อย่างเดียวไม่พอ ต้องมี permission ของ role ปัจจุบันด้วย
+// (role อ่านใหม่ทุก request ไม่ได้ฝังใน token: ถูกลด role แล้ว token เดิมหมดสิทธิ์ทันที)
+func TestPersonalAccessTokenAdminScopeFollowsRole(t *testing.T) {
+	ts := newTestServer(t)
+	alice := ts.signup(t, "alice")
+	bob := ts.signup(t, "bob")
+	path := fmt.Sprintf("/api/admin/users/%d/mfa/reset", bob.id)
+
+	// user ธรรมดาขอ scope admin ไม่ได้
+	alice.do(t, http.MethodPost, "/api/users/me/tokens", map[string]any{"name": "x", "scopes": []string{domain.ScopeAdmin}}).
+		expect(t, http.StatusBadRequest)
+
+	ts.exec(t, `UPDATE users SET role = $1 WHERE id = $2`, domain.RoleAdmin, alice.id)
+	alice.login(t)
+	pat, _ := alice.createToken(t, domain.ScopeAdmin)
+	ts.as(pat).do(t, http.MethodPost, path, nil).expect(t, http.StatusOK)
+
+	// admin ที่ใช้ token ไม่มี scope admin ก็ยังเข้าไม่ได้
+	readOnly, _ := alice.createToken(t, domain.ScopeTasksRead)
+	if r := ts.as(readOnly).do(t, http.MethodPost, path, nil); r.StatusCode != http.StatusForbidden || r.code() != "insufficient_scope" {
+		t.Fatalf("admin without admin scope = %d %s, want 403 insufficient_scope", r.StatusCode, r.raw)
+	}
+
+	ts.exec(t, `UPDATE users SET role = $1 WHERE id = $2`, domain.RoleUser, alice.id)
+	if r := ts.as(pat).do(t, http.MethodPost, path, nil); r.StatusCode != http.StatusForbidden || r.code() != "forbidden" {
+		t.Fatalf("demoted user with admin scope = %d %s, want 403 forbidden", r.StatusCode, r.raw)
+	}
+}
+
+var verifyLink = regexp.MustCompile(`https?://\S+/api/auth/verify-email\?token=\S+`)
+
+func TestRequireVerifiedEmail(t *testing.T) {
+	ts := newTestServer(t, testServerOptions{requireVerifiedEmail: true})
+	alice := ts.signup(t, "alice")
+
+	for _, path := range []string{"/api/tasks", "/api/workspaces", "/api/projects"} {
+		if r := alice.do(t, http.MethodGet, path, nil); r.StatusCode != http.StatusForbidden || r.code() != "email_not_verified" {
+			t.Fatalf("GET %s before verifying = %d %s, want 403 email_not_verified", path, r.StatusCode, r.raw)
+		}
+	}
+	// บัญชีของตัวเองยังเข้าได้ (ต้องกดส่งอีเมลยืนยันซ้ำได้)
+	alice.do(t, http.MethodGet, "/api/users/me", nil).expect(t, http.StatusOK)
+
+	link := verifyLink.FindString(ts.mail.waitFor(t, "alice@example.com").Text)
+	if link == "" {
+		t.Fatal("verification email has no link")
+	}
+	if got := location(t, ts, ts.client().do(t, http.MethodGet, link, nil)); got != "/auth/login.html?verified=1" {
+		t.Fatalf("verify redirect = %q", got)
+	}
+	alice.do(t, http.MethodGet, "/api/tasks", nil).expect(t, http.StatusOK)
+}
@@ -0,0 +1,214 @@
+package api
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"task-manager/internal/auth/oidctest"
+)
+
+// oauthVia ทำต่อจาก response ที่ส่ง browser ไปหน้า provider: ผู้ใช้กดยอมรับแล้ว browser ตาม redirect กลับมาที่ callback
+func (c *testClient) oauthVia(t *testing.T, start *testResponse, u oidctest.User) *testResponse {
+	t.Helper()
+	loginURL := start.Header.Get("Location")
+	if !strings.HasPrefix(loginURL, c.ts.issuer.URL+"/authorize?") {
+		t.Fatalf("%s redirected to %q, want the provider", start.Request.URL.Path, loginURL)
+	}
+	return c.do(t, http.MethodGet, c.ts.issuer.Approve(t, loginURL, u).String(), nil)
+}
+
+// location คืน path + query ของ redirect (ตัด origin ของ frontend ออก)
+func location(t *testing.T, ts *testServer, r *testResponse) string {
+	t.Helper()
+	if r.StatusCode != http.StatusFound && r.StatusCode != http.StatusSeeOther {
+		t.Fatalf("%s = %d, want a redirect: %s", r.Request.URL.Path, r.StatusCode, r.raw)
+	}
+	return strings.TrimPrefix(r.Header.Get("Location"), ts.URL)
+}
+
+var carol = oidctest.User{Subject: "sub-carol", Email: "carol+tm@example.com", EmailVerified: true, Name: "Carol"}
+
+func TestOAuthLoginUsesPKCEAndNonce(t *testing.T) {
+	ts := newTestServer(t)
+	r := ts.client().do(t, http.MethodGet, "/api/auth/mock/login", nil).expect(t, http.StatusFound)
+
+	u, err := url.Parse(r.Header.Get("Location"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	q := u.Query()
+	if q.Get("state") == "" || q.Get("nonce") == "" || q.Get("code_challenge") == "" || q.Get("code_challenge_method") != "S256" {
+		t.Fatalf("login URL lacks state / nonce / PKCE: %s", u)
+	}
+	if strings.Contains(u.RawQuery, "next") {
+		t.Errorf("state must not carry next in the clear: %s", u)
+	}
+}
+
+func TestOAuthSignupNewUser(t *testing.T) {
+	ts := newTestServer(t)
+	c := ts.client()
+
+	back := c.oauthVia(t, c.do(t, http.MethodGet, "/api/auth/mock/login", nil), carol)
+	// อีเมลมี + ต้องถูก escape ไม่งั้นหน้า create_account อ่านได้เป็นช่องว่าง
+	if got, want := location(t, ts, back), "/create_account.html?email="+url.QueryEscape(carol.Email); !strings.HasPrefix(got, want) {
+		t.Fatalf("redirect = %q, want prefix %q", got, want)
+	}
+}
+
+// login CSRF: ผู้โจมตีเริ่ม login ใน browser ตัวเอง แล้วหลอกให้เหยื่อเปิด callback URL นั้น
+func TestOAuthCallbackRejectsStateFromAnotherBrowser(t *testing.T) {
+	ts := newTestServer(t)
+	attacker, victim := ts.client(), ts.client()
+
+	start := attacker.do(t, http.MethodGet, "/api/auth/mock/login", nil).expect(t, http.StatusFound)
+	callback := ts.issuer.Approve(t, start.Header.Get("Location"), carol)
+
+	victim.do(t, http.MethodGet, callback.String(), nil).expect(t, http.StatusBadRequest)
+}
+
+func TestOAuthCallbackRejectsTamperedOrReplayedState(t *testing.T) {
+	ts := newTestServer(t)
+
+	t.Run("tampered state", func(t *testing.T) {
+		c := ts.client()
+		start := c.do(t, http.MethodGet, "/api/auth/mock/login", nil).expect(t, http.StatusFound)
+		callback := ts.issuer.Approve(t, start.Header.Get("Location"), carol)
+		q := callback.Query()
+		q.Set("state", q.Get("state")+"x")
+		callback.RawQuery = q.Encode()
+		c.do(t, http.MethodGet, callback.String(), nil).expect(t, http.StatusBadRequest)
+	})
+
+	t.Run("replayed callback", func(t *testing.T) {
+		c := ts.client()
+		start := c.do(t, http.MethodGet, "/api/auth/mock/login", nil).expect(t, http.StatusFound)
+		callback := ts.issuer.Approve(t, start.Header.Get("Location"), carol)
+		c.do(t, http.MethodGet, callback.String(), nil).expect(t, http.StatusFound)
+		c.do(t, http.MethodGet, callback.String(), nil).expect(t, http.StatusBadRequest)
+	})
+}
+
+// แลก code ไม่สำเร็จ: กลับหน้า login พร้อม error=oauth ไม่ส่งรายละเอียด error ภายในให้ browser
+func TestOAuthCallbackExchangeFailureRedirects(t *testing.T) {
+	ts := newTestServer(t)
+	c := ts.client()
+	start := c.do(t, http.MethodGet, "/api/auth/mock/login", nil).expect(t, http.StatusFound)
+	callback := ts.issuer.Approve(t, start.Header.Get("Location"), carol)
+	q := callback.Query()
+	q.Set("code", "bogus")
+	callback.RawQuery = q.Encode()
+
+	back := c.do(t, http.MethodGet, callback.String(), nil)
+	if got := location(t, ts, back); got != "/auth/login.html?error=oauth" {
+		t.Fatalf("redirect = %q, want /auth/login.html?error=oauth", got)
+	}
+	if strings.Contains(string(back.raw), "error:") {
+		t.Errorf("body leaks the internal error: %s", back.raw)
+	}
+}
+
+// บัญชีที่มีอีเมลเดียวกันแต่ยังไม่ได้ผูก provider: ไม่รวมบัญชีให้อัตโนมัติ
+func TestOAuthLoginDoesNotMergeByEmail(t *testing.T) {
+	ts := newTestServer(t)
+	ts.signup(t, "dave")
+	c := ts.client()
+
+	back := c.oauthVia(t, c.do(t, http.MethodGet, "/api/auth/mock/login", nil),
+		oidctest.User{Subject: "sub-dave", Email: "dave@example.com", EmailVerified: true})
+	if got := location(t, ts, back); !strings.Contains(got, "error=account_exists") {
+		t.Fatalf("redirect = %q, want account_exists", got)
+	}
+}
+
+func TestOAuthLink(t *testing.T) {
+	ts := newTestServer(t)
+	alice := ts.signup(t, "alice")
+	aliceAtMock := oidctest.User{Subject: "sub-alice", Email: "alice.other@example.com", EmailVerified: true}
+
+	// link ticket ต้องยืนยันรหัสผ่านก่อน
+	alice.do(t, http.MethodPost, "/api/users/me/identities/mock/link", map[string]string{"password": "wrong"}).
+		expect(t, http.StatusUnauthorized)
+
+	linkVia := func(t *testing.T, c *testClient, u oidctest.User) string {
+		t.Helper()
+		body := c.do(t, http.MethodPost, "/api/users/me/identities/mock/link", map[string]string{"password": testPassword}).
+			expect(t, http.StatusOK).json()
+		ticket, _ := body["ticket"].(string)
+		start := c.do(t, http.MethodPost, body["action"].(string), url.Values{"ticket": {ticket}}).expect(t, http.StatusSeeOther)
+		return location(t, ts, c.oauthVia(t, start, u))
+	}
+
+	if got := linkVia(t, alice, aliceAtMock); got != "/settings/index.html?linked=mock" {
+		t.Fatalf("link redirect = %q", got)
+	}
+	list := alice.do(t, http.MethodGet, "/api/users/me/identities", nil).expect(t, http.StatusOK).json()
+	ids, _ := list["identities"].([]any)
+	if len(ids) != 1 || ids[0].(map[string]any)["provider"] != "mock" {
+		t.Fatalf("identities = %v", list)
+	}
+
+	// identity เดียวกันผูกกับบัญชีอื่นไม่ได้
+	bob := ts.signup(t, "bob")
+	if got := linkVia(t, bob, aliceAtMock); got != "/settings/index.html?link_error=in_use" {
+		t.Fatalf("second link redirect = %q, want in_use", got)
+	}
+
+	// ผูกแล้ว login ด้วย provider ได้เป็น alice
+	c := ts.client()
+	if got := location(t, ts, c.oauthVia(t, c.do(t, http.MethodGet, "/api/auth/mock/login", nil), aliceAtMock)); got != "/dashboard/index.html" {
+		t.Fatalf("login after link redirect = %q", got)
+	}
+}
+
+func TestOAuthLinkRejectsBadTicket(t *testing.T) {
+	ts := newTestServer(t)
+	alice := ts.signup(t, "alice")
+	body := alice.do(t, http.MethodPost, "/api/users/me/identities/mock/link", map[string]string{"password": testPassword}).
+		expect(t, http.StatusOK).json()
+	ticket, _ := body["ticket"].(string)
+
+	for name, form := range map[string]url.Values{
+		"missing":  {},
+		"tampered": {"ticket": {ticket + "x"}},
+		// access token ใช้แทน link ticket ไม่ได้ (key แยกกัน)
+		"access token": {"ticket": {alice.token}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			r := ts.client().do(t, http.MethodPost, "/api/auth/mock/link", form)
+			if got := location(t, ts, r); got != "/settings/index.html?link_error=expired" {
+				t.Fatalf("redirect = %q", got)
+			}
+		})
+	}
+}
+
+func TestOAuthNextAllowList(t *testing.T) {
+	ts := newTestServer(t)
+	alice := ts.signup(t, "alice")
+	aliceAtMock := oidctest.User{Subject: "sub-alice", Email: "alice@example.com", EmailVerified: true}
+	body := alice.do(t, http.MethodPost, "/api/users/me/identities/mock/link", map[string]string{"password": testPassword}).
+		expect(t, http.StatusOK).json()
+	start := alice.do(t, http.MethodPost, "/api/auth/mock/link", url.Values{"ticket": {body["ticket"].(string)}})
+	alice.oauthVia(t, start, aliceAtMock)
+
+	tests := []struct{ next, want string }{
+		{"/my-work.html", "/my-work.html"},
+		{ts.URL + "/home.html", "/home.html"},
+		{"https://evil.example/phish", "/dashboard/index.html"},
+		{"//evil.example/phish", "/dashboard/index.html"},
+		{"/\\evil.example", "/dashboard/index.html"},
+		{"javascript:alert(1)", "/dashboard/index.html"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.next, func(t *testing.T) {
+			c := ts.client()
+			start := c.do(t, http.MethodGet, "/api/auth/mock/login?next="+url.QueryEscape(tt.next), nil)
+			if got := location(t, ts, c.oauthVia(t, start, aliceAtMock)); got != tt.want {
+				t.Fatalf("redirect = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
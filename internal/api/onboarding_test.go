@@ -0,0 +1,136 @@
+package api
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"task-manager/internal/auth/oidctest"
+)
+
+// oauthSignup สมัครด้วย provider แล้วคืน onboarding ticket จาก fragment ของ redirect
+func oauthSignup(t *testing.T, ts *testServer, u oidctest.User) (*testClient, string) {
+	t.Helper()
+	c := ts.client()
+	back := c.oauthVia(t, c.do(t, http.MethodGet, "/api/auth/mock/login", nil), u)
+	target, err := url.Parse(location(t, ts, back))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if target.Path != "/create_account.html" {
+		t.Fatalf("signup redirected to %s", target)
+	}
+	frag, err := url.ParseQuery(target.Fragment)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// ยังไม่ได้ตั้งรหัสผ่าน = ยังไม่มี session
+	for _, ck := range back.Cookies() {
+		if ck.Name == accessCookie && ck.Value != "" {
+			t.Fatalf("signup set %s before onboarding finished", accessCookie)
+		}
+	}
+	return c, frag.Get("onboarding_ticket")
+}
+
+func onboard(ticket, username string) map[string]string {
+	return map[string]string{
+		"onboarding_ticket": ticket, "username": username, "name": username, "password": testPassword,
+	}
+}
+
+func TestOnboardingCompletesNewOAuthAccount(t *testing.T) {
+	ts := newTestServer(t)
+	c, ticket := oauthSignup(t, ts, carol)
+	if ticket == "" {
+		t.Fatal("no onboarding ticket in redirect")
+	}
+
+	body := c.do(t, http.MethodPost, "/api/auth/complete-google-registration", onboard(ticket, "carol")).
+		expect(t, http.StatusOK).json()
+	if tok, _ := body["token"].(string); tok == "" {
+		t.Fatalf("no token after onboarding: %v", body)
+	}
+	if got := body["user"].(map[string]any)["email"]; got != carol.Email {
+		t.Errorf("email = %v, want the provider email", got)
+	}
+
+	// ใช้ ticket ซ้ำเปลี่ยนรหัสผ่านไม่ได้
+	again := onboard(ticket, "carol2")
+	again["password"] = "An0ther-Passw0rd-Here"
+	if r := ts.client().do(t, http.MethodPost, "/api/auth/complete-google-registration", again); r.StatusCode != http.StatusBadRequest || r.code() != "invalid_token" {
+		t.Fatalf("replayed ticket = %d %s, want 400 invalid_token", r.StatusCode, r.raw)
+	}
+	ts.client().do(t, http.MethodPost, "/api/auth/login", map[string]string{"email": "carol", "password": testPassword}).
+		expect(t, http.StatusOK)
+}
+
+// เดิมส่งแค่อีเมลของคนอื่นก็ตั้งรหัสผ่านใหม่ทับได้ (account takeover)
+func TestOnboardingCannotTakeOverExistingAccount(t *testing.T) {
+	ts := newTestServer(t)
+	victim := ts.signup(t, "victim")
+	attacker := ts.client()
+
+	t.Run("email without ticket", func(t *testing.T) {
+		r := attacker.do(t, http.MethodPost, "/api/auth/complete-google-registration", map[string]string{
+			"email": "victim@example.com", "username": "pwned", "name": "pwned", "password": "Attack3r-Chosen-Pass",
+		})
+		if r.StatusCode != http.StatusBadRequest {
+			t.Fatalf("status = %d, want 400: %s", r.StatusCode, r.raw)
+		}
+	})
+
+	tickets := map[string]string{
+		"garbage":      "not-a-ticket",
+		"access token": victim.token,
+	}
+	// ticket ที่ลงลายเซ็นถูกต้องแต่เป็นของบัญชีที่ตั้งรหัสผ่านไว้แล้ว
+	forged, _, err := ts.jwt.GenerateOnboardingTicket(victim.id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tickets["ticket for an account with a password"] = forged
+	link, _, err := ts.jwt.GenerateLinkTicket(victim.id, "mock")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tickets["link ticket"] = link
+
+	for name, ticket := range tickets {
+		t.Run(name, func(t *testing.T) {
+			body := onboard(ticket, "pwned")
+			body["password"] = "Attack3r-Chosen-Pass"
+			r := attacker.do(t, http.MethodPost, "/api/auth/complete-google-registration", body)
+			if r.StatusCode != http.StatusBadRequest || r.code() != "invalid_token" {
+				t.Fatalf("got %d %s, want 400 invalid_token", r.StatusCode, r.raw)
+			}
+		})
+	}
+
+	// รหัสผ่านเดิมยังใช้ได้ username ยังเป็นของเดิม
+	victim.login(t)
+	ts.client().do(t, http.MethodPost, "/api/auth/login", map[string]string{"email": "pwned", "password": "Attack3r-Chosen-Pass"}).
+		expect(t, http.StatusUnauthorized)
+}
+
+// เปิด 2FA ไว้แล้ว onboarding ต้องผ่านขั้น 2FA ก่อนได้ token เหมือน login
+func TestOnboardingGoesThroughMFA(t *testing.T) {
+	ts := newTestServer(t)
+	c, ticket := oauthSignup(t, ts, carol)
+
+	var userID int64
+	if err := ts.db.QueryRow(`SELECT id FROM users WHERE email = $1`, strings.ToLower(carol.Email)).Scan(&userID); err != nil {
+		t.Fatal(err)
+	}
+	ts.exec(t, `INSERT INTO user_mfa (user_id, secret, enabled_at) VALUES ($1, 'JBSWY3DPEHPK3PXP', CURRENT_TIMESTAMP)`, userID)
+
+	body := c.do(t, http.MethodPost, "/api/auth/complete-google-registration", onboard(ticket, "carol")).
+		expect(t, http.StatusOK).json()
+	if body["mfa_required"] != true || body["mfa_token"] == "" {
+		t.Fatalf("response = %v, want mfa_required", body)
+	}
+	if _, ok := body["token"]; ok {
+		t.Fatalf("access token issued before 2FA: %v", body)
+	}
+}
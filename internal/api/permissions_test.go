@@ -0,0 +1,136 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"task-manager/internal/domain"
+)
+
+// createTask สร้างงานใหม่ใน workspace แล้วคืน id
+func (c *testClient) createTask(t testing.TB, workspaceID int64, title string) int64 {
+	t.Helper()
+	body := c.do(t, http.MethodPost, "/api/tasks", map[string]any{"title": title, "workspace_id": workspaceID}).
+		expect(t, http.StatusCreated).json()
+	return int64(body["id"].(float64))
+}
+
+// createWorkspace สร้าง workspace (ผู้สร้างเป็น admin ของ workspace) แล้วคืน id
+func (c *testClient) createWorkspace(t testing.TB, name string) int64 {
+	t.Helper()
+	body := c.do(t, http.MethodPost, "/api/workspaces", map[string]string{"name": name}).
+		expect(t, http.StatusCreated).json()
+	return int64(body["id"].(float64))
+}
+
+// สิทธิ์ตาม role ระดับระบบ: ทุก role เป็นสมาชิกธรรมดา (member) ของ workspace เดียวกับเจ้าของงาน
+// งานของคนอื่น = ต้องพึ่ง *:any ของ role, งานของตัวเอง = ทุก role ทำได้
+func TestRoutePermissionMatrix(t *testing.T) {
+	ts := newTestServer(t)
+	owner := ts.signup(t, "owner")
+	wsID := owner.createWorkspace(t, "Team")
+	target := ts.signup(t, "target")
+
+	actors := map[string]*testClient{}
+	for _, role := range []string{domain.RoleUser, domain.RoleManager, domain.RoleAdmin} {
+		c := ts.signup(t, role+"_actor")
+		ts.exec(t, `UPDATE users SET role = $1 WHERE id = $2`, role, c.id)
+		ts.exec(t, `INSERT INTO workspace_members (workspace_id, user_id, role) VALUES ($1, $2, 'member')`, wsID, c.id)
+		c.login(t)
+		actors[role] = c
+	}
+
+	type want struct{ user, manager, admin int }
+	tests := []struct {
+		name string
+		// request คืน method, path, body (เรียกใหม่ทุก role เพราะบางเคสลบงานทิ้ง)
+		request func(t *testing.T, c *testClient) (string, string, any)
+		want    want
+	}{
+		{
+			name: "list tasks",
+			request: func(t *testing.T, c *testClient) (string, string, any) {
+				return http.MethodGet, fmt.Sprintf("/api/tasks?workspace_id=%d", wsID), nil
+			},
+			want: want{http.StatusOK, http.StatusOK, http.StatusOK},
+		},
+		{
+			name: "create task",
+			request: func(t *testing.T, c *testClient) (string, string, any) {
+				return http.MethodPost, "/api/tasks", map[string]any{"title": "mine", "workspace_id": wsID}
+			},
+			want: want{http.StatusCreated, http.StatusCreated, http.StatusCreated},
+		},
+		{
+			name: "update own task",
+			request: func(t *testing.T, c *testClient) (string, string, any) {
+				return http.MethodPut, fmt.Sprintf("/api/tasks/%d", c.createTask(t, wsID, "mine")), map[string]string{"title": "edited"}
+			},
+			want: want{http.StatusOK, http.StatusOK, http.StatusOK},
+		},
+		{
+			name: "delete own task",
+			request: func(t *testing.T, c *testClient) (string, string, any) {
+				return http.MethodDelete, fmt.Sprintf("/api/tasks/%d", c.createTask(t, wsID, "mine")), nil
+			},
+			want: want{http.StatusNoContent, http.StatusNoContent, http.StatusNoContent},
+		},
+		{
+			name: "read another member's task",
+			request: func(t *testing.T, c *testClient) (string, string, any) {
+				return http.MethodGet, fmt.Sprintf("/api/tasks/%d", owner.createTask(t, wsID, "theirs")), nil
+			},
+			want: want{http.StatusOK, http.StatusOK, http.StatusOK},
+		},
+		{
+			name: "update another member's task",
+			request: func(t *testing.T, c *testClient) (string, string, any) {
+				return http.MethodPut, fmt.Sprintf("/api/tasks/%d", owner.createTask(t, wsID, "theirs")), map[string]string{"title": "edited"}
+			},
+			want: want{http.StatusForbidden, http.StatusOK, http.StatusOK},
+		},
+		{
+			name: "add checklist item to another member's task",
+			request: func(t *testing.T, c *testClient) (string, string, any) {
+				return http.MethodPost, fmt.Sprintf("/api/tasks/%d/checklist", owner.createTask(t, wsID, "theirs")), map[string]string{"title": "step"}
+			},
+			want: want{http.StatusForbidden, http.StatusCreated, http.StatusCreated},
+		},
+		{
+			name: "delete another member's task",
+			request: func(t *testing.T, c *testClient) (string, string, any) {
+				return http.MethodDelete, fmt.Sprintf("/api/tasks/%d", owner.createTask(t, wsID, "theirs")), nil
+			},
+			want: want{http.StatusForbidden, http.StatusForbidden, http.StatusNoContent},
+		},
+		{
+			name: "admin: set user role",
+			request: func(t *testing.T, c *testClient) (string, string, any) {
+				return http.MethodPut, fmt.Sprintf("/api/admin/users/%d/role", target.id), map[string]string{"role": domain.RoleUser}
+			},
+			want: want{http.StatusForbidden, http.StatusForbidden, http.StatusOK},
+		},
+		{
+			name: "admin: reset user 2FA",
+			request: func(t *testing.T, c *testClient) (string, string, any) {
+				return http.MethodPost, fmt.Sprintf("/api/admin/users/%d/mfa/reset", target.id), nil
+			},
+			want: want{http.StatusForbidden, http.StatusForbidden, http.StatusOK},
+		},
+	}
+
+	for _, tt := range tests {
+		for role, status := range map[string]int{
+			domain.RoleUser:    tt.want.user,
+			domain.RoleManager: tt.want.manager,
+			domain.RoleAdmin:   tt.want.admin,
+		} {
+			t.Run(tt.name+"/"+role, func(t *testing.T) {
+				c := actors[role]
+				method, path, body := tt.request(t, c)
+				c.do(t, method, path, body).expect(t, status)
+			})
+		}
+	}
+}
@@ -0,0 +1,353 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	"task-manager/internal/authctx"
+	"task-manager/internal/domain"
+	"task-manager/internal/middleware"
+	"task-manager/internal/service"
+	"task-manager/internal/validate"
+	"task-manager/pkg/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ProjectHandler struct {
+	Svc     service.ProjectService
+	TaskSvc service.TaskService
+}
+
+// mws: เหมือนของ task (JWTMiddleware + RequireVerifiedEmail ถ้าเปิดไว้)
+func RegisterProjectRoutes(r *gin.Engine, h *ProjectHandler, mws ...gin.HandlerFunc) {
+	readScope := middleware.RequireScope(domain.ScopeTasksRead)
+
+	g := r.Group("/api/projects")
+	g.Use(mws...)
+	{
+		g.GET("", readScope, h.list)
+		g.GET("/:id", readScope, h.get)
+		g.GET("/:id/members", readScope, h.members)
+		g.GET("/:id/tasks", readScope, middleware.RequirePermission(domain.PermTaskRead), h.tasks)
+	}
+
+	// สร้าง/แก้ไขโปรเจกต์ต้องล็อกอินจริง personal access token ใช้ไม่ได้
+	g = g.Group("", middleware.RequireSession())
+	{
+		g.POST("", h.create)
+		g.PATCH("/:id", h.update)
+		g.DELETE("/:id", h.delete)
+		g.PUT("/:id/statuses", h.setStatuses)
+		g.PUT("/:id/members/:userId", h.setMember)
+		g.DELETE("/:id/members/:userId", h.removeMember)
+	}
+}
+
+func projectResponse(p *domain.Project) gin.H {
+	var createdBy any
+	if p.CreatedBy.Valid {
+		createdBy = p.CreatedBy.Int64
+	}
+	return gin.H{
+		"id":           p.ID,
+		"workspace_id": p.WorkspaceID,
+		"name":         p.Name,
+		"color":        p.Color,
+		"archived":     p.Archived,
+		"role":         p.Role,
+		"created_by":   createdBy,
+		"created_at":   p.CreatedAt,
+		"updated_at":   p.UpdatedAt,
+	}
+}
+
+func projectMemberResponse(m domain.ProjectMember) gin.H {
+	var name any
+	if m.Name.Valid {
+		name = m.Name.String
+	}
+	return gin.H{
+		"user_id":   m.UserID,
+		"email":     m.Email,
+		"name":      name,
+		"role":      m.Role,
+		"joined_at": m.JoinedAt,
+	}
+}
+
+func projectIDParam(c *gin.Context) (int64, bool) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || id <= 0 {
+		response.Fail(c, http.StatusBadRequest, "invalid_project_id", "invalid project id")
+		return 0, false
+	}
+	return id, true
+}
+
+// list: ?workspace_id= (ไม่ส่ง = workspace ส่วนตัว) &archived=true รวมโปรเจกต์ที่เก็บถาวร
+func (h *ProjectHandler) list(c *gin.Context) {
+	userID, ok := authctx.UserID(c)
+	if !ok {
+		response.Fail(c, http.StatusUnauthorized, response.CodeUnauthorized, "unauthorized")
+		return
+	}
+	var workspaceID int64
+	if w := c.Query("workspace_id"); w != "" {
+		parsed, err := strconv.ParseInt(w, 10, 64)
+		if err != nil || parsed <= 0 {
+			response.Fail(c, http.StatusBadRequest, "invalid_workspace_id", "invalid workspace id")
+			return
+		}
+		workspaceID = parsed
+	}
+	includeArchived := false
+	if a := c.Query("archived"); a != "" {
+		parsed, err := strconv.ParseBool(a)
+		if err != nil {
+			response.Invalid(c, "invalid archived", response.FieldError{Field: "archived", Code: "invalid", Message: "archived must be true or false"})
+			return
+		}
+		includeArchived = parsed
+	}
+
+	projects, err := h.Svc.List(c.Request.Context(), userID, workspaceID, includeArchived)
+	if err != nil {
+		response.Error(c, err, "failed to list projects")
+		return
+	}
+	out := make([]gin.H, 0, len(projects))
+	for _, p := range projects {
+		out = append(out, projectResponse(p))
+	}
+	response.OK(c, gin.H{"projects": out})
+}
+
+func (h *ProjectHandler) get(c *gin.Context) {
+	userID, ok := authctx.UserID(c)
+	if !ok {
+		response.Fail(c, http.StatusUnauthorized, response.CodeUnauthorized, "unauthorized")
+		return
+	}
+	id, ok := projectIDParam(c)
+	if !ok {
+		return
+	}
+
+	p, err := h.Svc.Get(c.Request.Context(), userID, id)
+	if err != nil {
+		response.Error(c, err, "failed to get project")
+		return
+	}
+	response.OK(c, projectResponse(p))
+}
+
+// tasks คืนงานในโปรเจกต์ทีละหน้า (รูปแบบเดียวกับ GET /api/tasks)
+func (h *ProjectHandler) tasks(c *gin.Context) {
+	userID, ok := authctx.UserID(c)
+	if !ok {
+		response.Fail(c, http.StatusUnauthorized, response.CodeUnauthorized, "unauthorized")
+		return
+	}
+	id, ok := projectIDParam(c)
+	if !ok {
+		return
+	}
+	limit, offset, ok := pageQuery(c)
+	if !ok {
+		return
+	}
+	subtree, ok := subtreeQuery(c)
+	if !ok {
+		return
+	}
+
+	tasks, total, err := h.TaskSvc.ListProjectTasks(c.Request.Context(), userID, id, subtree, limit, offset)
+	if err != nil {
+		response.Error(c, err, "failed to get tasks")
+		return
+	}
+	out := make([]gin.H, 0, len(tasks))
+	for _, t := range tasks {
+		out = append(out, taskResponse(t))
+	}
+	response.Paginated(c, "tasks", out, response.Page{Limit: limit, Offset: offset, Total: total})
+}
+
+func (h *ProjectHandler) create(c *gin.Context) {
+	userID, ok := authctx.UserID(c)
+	if !ok {
+		response.Fail(c, http.StatusUnauthorized, response.CodeUnauthorized, "unauthorized")
+		return
+	}
+	var in struct {
+		WorkspaceID int64  `json:"workspace_id" binding:"omitempty,min=1"` // ไม่ส่ง = workspace ส่วนตัว
+		Name        string `json:"name" binding:"required,notblank,max=100"`
+		Color       string `json:"color" binding:"omitempty,color"`
+	}
+	if !validate.Bind(c, &in) {
+		return
+	}
+
+	p, err := h.Svc.Create(c.Request.Context(), userID, in.WorkspaceID, in.Name, in.Color)
+	if err != nil {
+		response.Error(c, err, "failed to create project")
+		return
+	}
+	response.Created(c, projectResponse(p))
+}
+
+// update: ส่งเฉพาะ field ที่เปลี่ยน ({"archived": true} = เก็บถาวร)
+func (h *ProjectHandler) update(c *gin.Context) {
+	userID, ok := authctx.UserID(c)
+	if !ok {
+		response.Fail(c, http.StatusUnauthorized, response.CodeUnauthorized, "unauthorized")
+		return
+	}
+	id, ok := projectIDParam(c)
+	if !ok {
+		return
+	}
+	var in struct {
+		Name     *string `json:"name" binding:"omitempty,notblank,max=100"`
+		Color    *string `json:"color" binding:"omitempty,notblank,color"`
+		Archived *bool   `json:"archived"`
+	}
+	if !validate.Bind(c, &in) {
+		return
+	}
+
+	p, err := h.Svc.Update(c.Request.Context(), userID, id, service.ProjectUpdate{
+		Name: in.Name, Color: in.Color, Archived: in.Archived,
+	})
+	if err != nil {
+		response.Error(c, err, "failed to update project")
+		return
+	}
+	response.OK(c, projectResponse(p))
+}
+
+// setStatuses แทนที่ชุดสถานะของโปรเจกต์ (ตรวจ key ซ้ำ / category / ต้องมีสถานะเริ่มและจบที่ service)
+func (h *ProjectHandler) setStatuses(c *gin.Context) {
+	userID, ok := authctx.UserID(c)
+	if !ok {
+		response.Fail(c, http.StatusUnauthorized, response.CodeUnauthorized, "unauthorized")
+		return
+	}
+	id, ok := projectIDParam(c)
+	if !ok {
+		return
+	}
+	var in struct {
+		Statuses domain.StatusSet `json:"statuses" binding:"required,min=1,max=20"`
+	}
+	if !validate.Bind(c, &in) {
+		return
+	}
+
+	ctx := c.Request.Context()
+	if err := h.TaskSvc.SetProjectStatuses(ctx, userID, id, in.Statuses); err != nil {
+		response.Error(c, err, "failed to update statuses")
+		return
+	}
+	set, err := h.TaskSvc.Statuses(ctx, userID, id)
+	if err != nil {
+		response.Error(c, err, "failed to get statuses")
+		return
+	}
+	response.OK(c, gin.H{"statuses": set})
+}
+
+// delete ลบโปรเจกต์ งานในโปรเจกต์ยังอยู่ใน workspace (ไม่มีโปรเจกต์)
+func (h *ProjectHandler) delete(c *gin.Context) {
+	userID, ok := authctx.UserID(c)
+	if !ok {
+		response.Fail(c, http.StatusUnauthorized, response.CodeUnauthorized, "unauthorized")
+		return
+	}
+	id, ok := projectIDParam(c)
+	if !ok {
+		return
+	}
+
+	if err := h.Svc.Delete(c.Request.Context(), userID, id); err != nil {
+		response.Error(c, err, "failed to delete project")
+		return
+	}
+	response.NoContent(c)
+}
+
+func (h *ProjectHandler) members(c *gin.Context) {
+	userID, ok := authctx.UserID(c)
+	if !ok {
+		response.Fail(c, http.StatusUnauthorized, response.CodeUnauthorized, "unauthorized")
+		return
+	}
+	id, ok := projectIDParam(c)
+	if !ok {
+		return
+	}
+
+	members, err := h.Svc.Members(c.Request.Context(), userID, id)
+	if err != nil {
+		response.Error(c, err, "failed to list members")
+		return
+	}
+	out := make([]gin.H, 0, len(members))
+	for _, m := range members {
+		out = append(out, projectMemberResponse(m))
+	}
+	response.OK(c, gin.H{"members": out})
+}
+
+// setMember เพิ่มสมาชิกของ workspace เข้าโปรเจกต์ หรือเปลี่ยน role
+func (h *ProjectHandler) setMember(c *gin.Context) {
+	userID, ok := authctx.UserID(c)
+	if !ok {
+		response.Fail(c, http.StatusUnauthorized, response.CodeUnauthorized, "unauthorized")
+		return
+	}
+	id, ok := projectIDParam(c)
+	if !ok {
+		return
+	}
+	targetID, ok := memberIDParam(c)
+	if !ok {
+		return
+	}
+	var in struct {
+		Role string `json:"role" binding:"required,oneof=admin member"`
+	}
+	if !validate.Bind(c, &in) {
+		return
+	}
+
+	if err := h.Svc.SetMember(c.Request.Context(), userID, id, targetID, in.Role); err != nil {
+		response.Error(c, err, "failed to update member")
+		return
+	}
+	response.OK(c, gin.H{"success": true})
+}
+
+// removeMember: admin เอาสมาชิกออก หรือสมาชิกออกจากโปรเจกต์เอง (userId = ตัวเอง)
+func (h *ProjectHandler) removeMember(c *gin.Context) {
+	userID, ok := authctx.UserID(c)
+	if !ok {
+		response.Fail(c, http.StatusUnauthorized, response.CodeUnauthorized, "unauthorized")
+		return
+	}
+	id, ok := projectIDParam(c)
+	if !ok {
+		return
+	}
+	targetID, ok := memberIDParam(c)
+	if !ok {
+		return
+	}
+
+	if err := h.Svc.RemoveMember(c.Request.Context(), userID, id, targetID); err != nil {
+		response.Error(c, err, "failed to remove member")
+		return
+	}
+	response.NoContent(c)
+}
@@ -0,0 +1,318 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/cookiejar"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"task-manager/internal/auth"
+	"task-manager/internal/auth/oidctest"
+	"task-manager/internal/config"
+	"task-manager/internal/db"
+	"task-manager/internal/domain"
+	"task-manager/internal/mail"
+	"task-manager/internal/middleware"
+	"task-manager/internal/ratelimit"
+	"task-manager/internal/repo"
+	"task-manager/internal/service"
+	"task-manager/internal/validate"
+
+	"github.com/gin-gonic/gin"
+)
+
+// testServer ต่อ router แบบเดียวกับ cmd/server/main.go (middleware จริง, SQLite จริง)
+// ต่างกันแค่: bcrypt cost ต่ำ, ตัวนับ rate limit ในหน่วยความจำ, อีเมลเก็บไว้ใน fakeMailer
+// และมี OIDC provider "mock" ชี้ไปที่ issuer ในเทสต์
+type testServer struct {
+	*httptest.Server
+	db     *sql.DB
+	jwt    auth.JWT
+	mail   *fakeMailer
+	issuer *oidctest.Issuer
+}
+
+type testServerOptions struct {
+	requireVerifiedEmail bool
+	passwordMinLength    int // 0 = ตาม auth.DefaultPasswordPolicy
+}
+
+func newTestServer(t *testing.T, opts ...testServerOptions) *testServer {
+	t.Helper()
+	var opt testServerOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	gin.SetMode(gin.TestMode)
+
+	// ไม่เปิด foreign_keys ให้เหมือน production (MustOpen) ลบข้อมูลลูกต้องทำเองใน repo
+	// busy_timeout: อีเมลยืนยันเขียน DB เบื้องหลัง ชนกับ exec ในเทสต์ได้
+	database := repo.MustOpen("file:" + filepath.Join(t.TempDir(), "test.db") + "?_pragma=busy_timeout(5000)")
+	t.Cleanup(func() { database.Close() })
+	if err := db.RunMigrations(database); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+
+	// URL ต้องรู้ก่อนประกอบ router (redirect URL ของ OIDC, ลิงก์ในอีเมล)
+	srv := httptest.NewUnstartedServer(nil)
+	base := "http://" + srv.Listener.Addr().String()
+	issuer := oidctest.New(t)
+
+	cfg := config.Config{
+		JWTAccessSecret:  "test-access-secret",
+		JWTRefreshSecret: "test-refresh-secret",
+		AccessTTLMin:     15,
+		RefreshTTLHours:  24,
+		FrontendURL:      base,
+		PublicURL:        base,
+		OIDCProviders: []config.OIDCProviderConfig{{
+			Name:         "mock",
+			Issuer:       issuer.URL,
+			ClientID:     issuer.ClientID,
+			ClientSecret: issuer.ClientSecret,
+			RedirectURL:  base + "/api/auth/mock/callback",
+		}},
+	}
+
+	j := auth.NewJWT(cfg)
+	pw := auth.NewBcryptHasher(4)
+	pwPolicy := auth.DefaultPasswordPolicy.ForHasher("bcrypt")
+	if opt.passwordMinLength > 0 {
+		pwPolicy.MinLength = opt.passwordMinLength
+	}
+	validate.SetPasswordPolicy(pwPolicy)
+	mailer := &fakeMailer{}
+
+	userRepo := repo.NewUserRepo(database)
+	statusRepo := repo.NewStatusRepo(database)
+	refreshRepo := repo.NewRefreshTokenRepo(database)
+	sessionRepo := repo.NewSessionRepo(database)
+	identityRepo := repo.NewIdentityRepo(database)
+	workspaceRepo := repo.NewWorkspaceRepo(database)
+	projectRepo := repo.NewProjectRepo(database)
+
+	authSvc := service.NewAuthService(userRepo, refreshRepo, sessionRepo, identityRepo, pw, pwPolicy, j)
+	userSvc := service.NewUserService(userRepo, repo.NewPreferencesRepo(database), pw, pwPolicy)
+	sessionSvc := service.NewSessionService(sessionRepo, refreshRepo)
+	mfaSvc := service.NewMFAService(repo.NewMFARepo(database), userRepo, j)
+	identitySvc := service.NewIdentityService(identityRepo, userRepo, sessionRepo, pw)
+	accessTokenSvc := service.NewAccessTokenService(repo.NewAccessTokenRepo(database), userRepo)
+	passkeySvc, err := service.NewPasskeyService(service.PasskeyConfig{
+		RPID: "127.0.0.1", DisplayName: "Task Manager", Origins: []string{base},
+	}, userRepo, repo.NewPasskeyRepo(database), repo.NewWebAuthnSessionRepo(database))
+	if err != nil {
+		t.Fatal(err)
+	}
+	userTokenRepo := repo.NewUserTokenRepo(database)
+	resetSvc := service.NewPasswordResetService(userRepo, userTokenRepo, sessionSvc, pw, pwPolicy, mailer,
+		base+"/auth/reset-password.html", 30*time.Minute)
+	verifySvc := service.NewEmailVerificationService(userRepo, userTokenRepo, mailer,
+		base+"/api/auth/verify-email", 24*time.Hour, time.Minute)
+	inviteSvc := service.NewInvitationService(repo.NewInvitationRepo(database), workspaceRepo, userRepo, j, mailer,
+		base+"/invite.html", 24*time.Hour)
+	taskSvc := service.NewTaskService(
+		repo.NewTaskRepo(database), statusRepo, workspaceRepo, projectRepo, repo.NewTaskPeopleRepo(database),
+		repo.NewChecklistRepo(database), userSvc, mailer, base+"/my-work.html",
+	)
+	limiter := ratelimit.New(ratelimit.NewMemoryStore())
+	providers := auth.NewOIDCRegistry(cfg.OIDCProviders)
+
+	r := gin.New()
+	r.Use(middleware.RequestID(), middleware.Locale(), middleware.CORSMiddleware(), middleware.SecureHeaders())
+	authMw := middleware.JWTMiddleware(&j, sessionSvc, accessTokenSvc)
+
+	RegisterAuthRoutes(r, &AuthHandler{
+		Svc: authSvc, ResetSvc: resetSvc, VerifySvc: verifySvc, MFASvc: mfaSvc, PasskeySvc: passkeySvc,
+		IdentitySvc: identitySvc, InviteSvc: inviteSvc, UserRepo: userRepo, Limiter: limiter,
+		Providers: providers, JWT: j, FrontendURL: base, NextAllowList: []string{base},
+	}, authMw)
+	RegisterUserRoutes(r, &UserHandler{
+		UserSvc: userSvc, SessionSvc: sessionSvc, VerifySvc: verifySvc, MFASvc: mfaSvc, PasskeySvc: passkeySvc,
+		IdentitySvc: identitySvc, Providers: providers, JWT: j, TokenSvc: accessTokenSvc, Limiter: limiter,
+	}, authMw)
+	RegisterAdminRoutes(r, &AdminHandler{MFASvc: mfaSvc, UserSvc: userSvc, SessionSvc: sessionSvc}, authMw)
+
+	workMws := []gin.HandlerFunc{authMw}
+	if opt.requireVerifiedEmail {
+		workMws = append(workMws, middleware.RequireVerifiedEmail(verifySvc))
+	}
+	RegisterTaskRoutes(r, &TaskHandler{Svc: taskSvc, DeleteChildren: domain.ChildrenOrphan}, workMws...)
+	RegisterWorkspaceRoutes(r, &WorkspaceHandler{Svc: service.NewWorkspaceService(workspaceRepo), InviteSvc: inviteSvc}, workMws...)
+	RegisterInvitationRoutes(r, &InvitationHandler{Svc: inviteSvc}, authMw)
+	RegisterProjectRoutes(r, &ProjectHandler{Svc: service.NewProjectService(projectRepo, workspaceRepo, statusRepo), TaskSvc: taskSvc}, workMws...)
+
+	srv.Config.Handler = r
+	srv.Start()
+	t.Cleanup(srv.Close)
+	return &testServer{Server: srv, db: database, jwt: j, mail: mailer, issuer: issuer}
+}
+
+// fakeMailer เก็บอีเมลที่ส่งไว้ให้เทสต์อ่านลิงก์ / token
+type fakeMailer struct {
+	mu   sync.Mutex
+	sent []mail.Message
+}
+
+func (m *fakeMailer) Send(_ context.Context, msg mail.Message) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.sent = append(m.sent, msg)
+	return nil
+}
+
+// waitFor คืนอีเมลล่าสุดที่ส่งถึง to (บางอีเมลส่งเบื้องหลัง จึงรอได้ถึงหนึ่งวินาที)
+func (m *fakeMailer) waitFor(t testing.TB, to string) mail.Message {
+	t.Helper()
+	for deadline := time.Now().Add(time.Second); time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+		m.mu.Lock()
+		for i := len(m.sent) - 1; i >= 0; i-- {
+			if m.sent[i].To == to {
+				msg := m.sent[i]
+				m.mu.Unlock()
+				return msg
+			}
+		}
+		m.mu.Unlock()
+	}
+	t.Fatalf("no email sent to %s", to)
+	return mail.Message{}
+}
+
+// testClient is one browser: cookie jar ของตัวเอง ไม่ตาม redirect อัตโนมัติ
+// token ถ้าตั้งไว้จะส่งเป็น Authorization: Bearer
+type testClient struct {
+	ts    *testServer
+	http  *http.Client
+	token string
+	id    int64
+}
+
+func (ts *testServer) client() *testClient {
+	jar, _ := cookiejar.New(nil)
+	return &testClient{ts: ts, http: &http.Client{
+		Jar:           jar,
+		CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse },
+	}}
+}
+
+// testResponse is a finished request; body ถูกอ่านไว้แล้ว
+type testResponse struct {
+	*http.Response
+	raw []byte
+}
+
+// json decodes the body as an object (ตอบไม่ใช่ JSON = map ว่าง)
+func (r *testResponse) json() map[string]any {
+	var m map[string]any
+	_ = json.Unmarshal(r.raw, &m)
+	if m == nil {
+		m = map[string]any{}
+	}
+	return m
+}
+
+func (r *testResponse) code() string {
+	s, _ := r.json()["code"].(string)
+	return s
+}
+
+func (c *testClient) do(t testing.TB, method, path string, body any) *testResponse {
+	t.Helper()
+	var rd io.Reader
+	contentType := ""
+	switch b := body.(type) {
+	case nil:
+	case url.Values:
+		rd, contentType = strings.NewReader(b.Encode()), "application/x-www-form-urlencoded"
+	default:
+		data, err := json.Marshal(b)
+		if err != nil {
+			t.Fatal(err)
+		}
+		rd, contentType = bytes.NewReader(data), "application/json"
+	}
+
+	target := path
+	if !strings.HasPrefix(path, "http") {
+		target = c.ts.URL + path
+	}
+	req, err := http.NewRequest(method, target, rd)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+	return c.send(t, req)
+}
+
+// send ส่ง request ที่ประกอบเองแล้ว (เช่นตั้ง header เอง) ผ่าน cookie jar ของ client
+func (c *testClient) send(t testing.TB, req *http.Request) *testResponse {
+	t.Helper()
+	resp, err := c.http.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	raw, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &testResponse{Response: resp, raw: raw}
+}
+
+// expect fails the test unless the response has the given status
+func (r *testResponse) expect(t testing.TB, status int) *testResponse {
+	t.Helper()
+	if r.StatusCode != status {
+		t.Fatalf("%s %s = %d, want %d: %s", r.Request.Method, r.Request.URL.Path, r.StatusCode, status, r.raw)
+	}
+	return r
+}
+
+const testPassword = "Corr3ct-Horse-Battery"
+
+// signup สมัครผ่าน /api/auth/register แล้วคืน client ที่ล็อกอินอยู่
+func (ts *testServer) signup(t testing.TB, name string) *testClient {
+	t.Helper()
+	c := ts.client()
+	body := c.do(t, http.MethodPost, "/api/auth/register", map[string]string{
+		"email": name + "@example.com", "username": name, "name": name, "password": testPassword,
+	}).expect(t, http.StatusCreated).json()
+	c.token, _ = body["token"].(string)
+	c.id = int64(body["user"].(map[string]any)["id"].(float64))
+	return c
+}
+
+// login ล็อกอินใหม่ (เช่นหลังเปลี่ยน role ใน DB ให้ได้ access token ที่มี role ใหม่)
+func (c *testClient) login(t testing.TB) {
+	t.Helper()
+	var u domain.User
+	if err := c.ts.db.QueryRow(`SELECT email FROM users WHERE id = $1`, c.id).Scan(&u.Email); err != nil {
+		t.Fatal(err)
+	}
+	body := c.do(t, http.MethodPost, "/api/auth/login", map[string]string{"email": u.Email, "password": testPassword}).
+		expect(t, http.StatusOK).json()
+	c.token, _ = body["token"].(string)
+}
+
+// exec รัน SQL ตรงๆ สำหรับจัดข้อมูลที่ไม่มี API (เช่นตั้ง role ระดับระบบ)
+func (ts *testServer) exec(t testing.TB, query string, args ...any) {
+	t.Helper()
+	if _, err := ts.db.Exec(query, args...); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,94 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func statusKeys(t testing.TB, body map[string]any) []string {
+	t.Helper()
+	var keys []string
+	for _, st := range body["statuses"].([]any) {
+		keys = append(keys, st.(map[string]any)["status"].(string))
+	}
+	return keys
+}
+
+// ชุดสถานะที่ตั้งเองถูกเก็บไว้ แล้วใช้ตรวจงานที่สร้าง/แก้ในโปรเจกต์นั้น
+func TestProjectCustomStatuses(t *testing.T) {
+	ts := newTestServer(t)
+	alice := ts.signup(t, "alice")
+	wsID := alice.createWorkspace(t, "Team")
+	projID := alice.createProject(t, wsID, "Release")
+	statuses := fmt.Sprintf("/api/projects/%d/statuses", projID)
+
+	// งานที่มีอยู่ก่อนตั้งชุดสถานะ (ใช้ชุด default)
+	body := alice.do(t, http.MethodPost, "/api/tasks", map[string]any{"title": "old", "project_id": projID, "status": "doing"}).
+		expect(t, http.StatusCreated).json()
+	oldTask := fmt.Sprintf("/api/tasks/%d", int64(body["id"].(float64)))
+
+	set := []map[string]string{
+		{"status": "backlog", "label": "Backlog", "category": "not_started"},
+		{"status": "review", "label": "In review", "category": "active"},
+		{"status": "shipped", "label": "Shipped", "category": "done"},
+	}
+	got := alice.do(t, http.MethodPut, statuses, map[string]any{"statuses": set}).expect(t, http.StatusOK).json()
+	if keys := fmt.Sprint(statusKeys(t, got)); keys != "[backlog review shipped]" {
+		t.Fatalf("stored statuses = %s", keys)
+	}
+	got = alice.do(t, http.MethodGet, fmt.Sprintf("/api/tasks/statuses?project_id=%d", projID), nil).expect(t, http.StatusOK).json()
+	if keys := fmt.Sprint(statusKeys(t, got)); keys != "[backlog review shipped]" {
+		t.Fatalf("GET statuses = %s", keys)
+	}
+	// งานเดิมที่ใช้สถานะที่ไม่มีแล้ว ย้ายไปสถานะแรกที่ category เดียวกัน
+	if got := alice.do(t, http.MethodGet, oldTask, nil).expect(t, http.StatusOK).json(); got["status"] != "review" {
+		t.Errorf("existing task status = %v, want review", got["status"])
+	}
+
+	// สร้างงาน: ไม่ส่งสถานะ = สถานะเริ่มต้นของชุด, สถานะ default ใช้ไม่ได้แล้ว
+	body = alice.do(t, http.MethodPost, "/api/tasks", map[string]any{"title": "new", "project_id": projID}).
+		expect(t, http.StatusCreated).json()
+	if body["status"] != "backlog" {
+		t.Errorf("new task status = %v, want backlog", body["status"])
+	}
+	task := fmt.Sprintf("/api/tasks/%d", int64(body["id"].(float64)))
+	if r := alice.do(t, http.MethodPost, "/api/tasks", map[string]any{"title": "x", "project_id": projID, "status": "todo"}); r.StatusCode != http.StatusBadRequest || r.code() != "invalid_status" {
+		t.Fatalf("create with default status = %d %s, want 400 invalid_status", r.StatusCode, r.raw)
+	}
+
+	// แก้งาน: ตรวจกับชุดของโปรเจกต์เหมือนกัน
+	if r := alice.do(t, http.MethodPut, task, map[string]string{"status": "done"}); r.StatusCode != http.StatusBadRequest || r.code() != "invalid_status" {
+		t.Fatalf("update to default status = %d %s, want 400 invalid_status", r.StatusCode, r.raw)
+	}
+	if got := alice.do(t, http.MethodPut, task, map[string]string{"status": "shipped"}).expect(t, http.StatusOK).json(); got["status"] != "shipped" {
+		t.Errorf("updated status = %v", got["status"])
+	}
+
+	t.Run("invalid set", func(t *testing.T) {
+		for name, bad := range map[string][]map[string]string{
+			"no done status": {{"status": "a", "label": "A", "category": "not_started"}},
+			"duplicate key": {
+				{"status": "a", "label": "A", "category": "not_started"},
+				{"status": "a", "label": "A again", "category": "done"},
+			},
+			"unknown category": {
+				{"status": "a", "label": "A", "category": "not_started"},
+				{"status": "b", "label": "B", "category": "finished"},
+			},
+		} {
+			if r := alice.do(t, http.MethodPut, statuses, map[string]any{"statuses": bad}); r.StatusCode != http.StatusBadRequest {
+				t.Errorf("%s = %d %s, want 400", name, r.StatusCode, r.raw)
+			}
+		}
+	})
+
+	t.Run("member who is not project admin", func(t *testing.T) {
+		bob := ts.signup(t, "bob")
+		ts.exec(t, `INSERT INTO workspace_members (workspace_id, user_id, role) VALUES ($1, $2, 'member')`, wsID, bob.id)
+		alice.do(t, http.MethodPut, fmt.Sprintf("/api/projects/%d/members/%d", projID, bob.id), map[string]string{"role": "member"}).
+			expect(t, http.StatusOK)
+		bob.do(t, http.MethodGet, fmt.Sprintf("/api/tasks/statuses?project_id=%d", projID), nil).expect(t, http.StatusOK)
+		bob.do(t, http.MethodPut, statuses, map[string]any{"statuses": set}).expect(t, http.StatusForbidden)
+	})
+}
@@ -1,67 +1,671 @@
 package api
 
 import (
+	"context"
+	"database/sql"
+	"log"
 	"net/http"
 	"strconv"
+	"time"
 
-	"task-manager/internal/auth"
+	"task-manager/internal/authctx"
+	"task-manager/internal/domain"
+	"task-manager/internal/i18n"
 	"task-manager/internal/middleware"
 	"task-manager/internal/service"
+	"task-manager/internal/validate"
+	"task-manager/pkg/response"
 
 	"github.com/gin-gonic/gin"
 )
 
+const dateLayout = "2006-01-02"
+
 type TaskHandler struct {
 	Svc service.TaskService
+
+	// ลบงานที่มีงานย่อยโดยไม่ส่ง ?children= (TASK_DELETE_CHILDREN)
+	DeleteChildren domain.ChildDeleteMode
 }
 
-func RegisterTaskRoutes(r *gin.Engine, svc service.TaskService, jwt auth.JWT) {
-	h := &TaskHandler{Svc: svc}
+// mws: JWTMiddleware ตามด้วย guard อื่นๆ (เช่น RequireVerifiedEmail)
+func RegisterTaskRoutes(r *gin.Engine, h *TaskHandler, mws ...gin.HandlerFunc) {
+	// scope ของ personal access token แล้วตาม permission ของ role
+	readScope := middleware.RequireScope(domain.ScopeTasksRead)
+	writeScope := middleware.RequireScope(domain.ScopeTasksWrite)
+	read := middleware.RequirePermission(domain.PermTaskRead)
+	create := middleware.RequirePermission(domain.PermTaskCreate)
+	update := middleware.RequirePermission(domain.PermTaskUpdate)
+	del := middleware.RequirePermission(domain.PermTaskDelete)
 
 	g := r.Group("/api/tasks")
-	g.Use(middleware.JWTMiddleware(&jwt))
+	g.Use(mws...)
+	{
+		g.GET("", readScope, read, h.getTasks)
+		g.POST("", writeScope, create, h.createTask)
+		g.GET("/statuses", readScope, read, h.getStatuses)
+		g.GET("/:id", readScope, read, h.getTask)
+		g.PUT("/:id", writeScope, update, h.updateTask)
+		g.DELETE("/:id", writeScope, del, h.deleteTask)
+
+		// ผู้รับผิดชอบ (มอบหมาย = แก้งาน) และผู้ติดตาม (ติดตามเอง = แค่เห็นงาน)
+		g.GET("/:id/assignees", readScope, read, h.getAssignees)
+		g.PUT("/:id/assignees/:userId", writeScope, update, h.assign)
+		g.DELETE("/:id/assignees/:userId", writeScope, update, h.unassign)
+		g.GET("/:id/watchers", readScope, read, h.getWatchers)
+		g.PUT("/:id/watchers/:userId", writeScope, read, h.watch)
+		g.DELETE("/:id/watchers/:userId", writeScope, read, h.unwatch)
+
+		// checklist ในงาน (แก้ = แก้งาน)
+		g.GET("/:id/checklist", readScope, read, h.getChecklist)
+		g.POST("/:id/checklist", writeScope, update, h.addChecklistItem)
+		g.PATCH("/:id/checklist/:itemId", writeScope, update, h.updateChecklistItem)
+		g.DELETE("/:id/checklist/:itemId", writeScope, update, h.deleteChecklistItem)
+	}
+
+	// My Work: งานที่มอบหมายให้ฉันจากทุก workspace
+	me := r.Group("/api/me")
+	me.Use(mws...)
+	me.GET("/work", readScope, read, h.getMyWork)
+
+	// Also register /tasks for backward compatibility (dashboard.js ใช้ path นี้)
+	legacy := r.Group("/tasks")
+	legacy.Use(mws...)
 	{
-		g.GET("", h.getTasks)
-		g.POST("", h.createTask)
-		g.PUT("/:id", h.updateTask)
-		g.DELETE("/:id", h.deleteTask)
+		legacy.GET("", readScope, read, h.getTasks)
+		legacy.POST("", writeScope, create, h.createTask)
+		legacy.PUT("/:id", writeScope, update, h.updateTask)
+		legacy.DELETE("/:id", writeScope, del, h.deleteTask)
 	}
+}
 
-	// Also register /tasks for backward compatibility
-	r.GET("/tasks", middleware.JWTMiddleware(&jwt), h.getTasks)
+// taskInput ใช้ pointer เพื่อแยก "ไม่ได้ส่งมา" ออกจาก "ส่งค่าว่าง" (PUT แบบ partial)
+type taskInput struct {
+	Title       *string `json:"title" binding:"omitempty,notblank,max=200"`
+	Description *string `json:"description" binding:"omitempty,max=5000"`
+	Status      *string `json:"status" binding:"omitempty,notblank,max=50"` // ตรวจกับชุดสถานะของ project ที่ service
+	Priority    *string `json:"priority" binding:"omitempty,oneof=low medium high"`
+	DueDate     *string `json:"due_date" binding:"omitempty,date"`    // YYYY-MM-DD, "" = ล้างวันที่
+	ProjectID   *int64  `json:"project_id" binding:"omitempty,min=0"` // ย้ายเข้าโปรเจกต์, 0 = เอาออกจากโปรเจกต์
+	ParentID    *int64  `json:"parent_id" binding:"omitempty,min=0"`  // ย้ายไปใต้งานแม่, 0 = เป็นงานระดับบน
 }
 
-func (h *TaskHandler) getTasks(c *gin.Context) {
-	userID, exists := c.Get("user_id")
-	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
-		return
+// createTaskInput: ตอนสร้างต้องมี title (ตอนแก้ไขส่งเฉพาะ field ที่เปลี่ยน)
+// workspace_id ไม่ส่ง = workspace ส่วนตัว (dashboard เดิม) หรือ workspace ของ project_id
+type createTaskInput struct {
+	taskInput
+	WorkspaceID int64 `json:"workspace_id" binding:"omitempty,min=1"`
+}
+
+func (in *createTaskInput) Check() []response.FieldError {
+	if in.Title == nil {
+		return []response.FieldError{response.Required("title")}
+	}
+	return nil
+}
+
+func (in *taskInput) applyTo(t *domain.Task) error {
+	if in.Title != nil {
+		t.Title = *in.Title
+	}
+	if in.Description != nil {
+		t.Description = sql.NullString{String: *in.Description, Valid: *in.Description != ""}
+	}
+	if in.Status != nil {
+		t.Status = *in.Status
+	}
+	if in.Priority != nil {
+		t.Priority = *in.Priority
+	}
+	if in.DueDate != nil {
+		if *in.DueDate == "" {
+			t.DueDate = sql.NullTime{}
+		} else {
+			d, ok := validate.ParseDate(*in.DueDate)
+			if !ok {
+				return domain.ErrInvalidInput
+			}
+			t.DueDate = sql.NullTime{Time: d, Valid: true}
+		}
+	}
+	if in.ProjectID != nil {
+		t.ProjectID = sql.NullInt64{Int64: *in.ProjectID, Valid: *in.ProjectID != 0}
+	}
+	if in.ParentID != nil {
+		t.ParentID = sql.NullInt64{Int64: *in.ParentID, Valid: *in.ParentID != 0}
+	}
+	return nil
+}
+
+func taskResponse(t *domain.Task) gin.H {
+	var desc, due, project, parent any
+	if t.Description.Valid {
+		desc = t.Description.String
+	}
+	if t.ProjectID.Valid {
+		project = t.ProjectID.Int64
+	}
+	if t.ParentID.Valid {
+		parent = t.ParentID.Int64
 	}
+	if t.DueDate.Valid {
+		due = t.DueDate.Time.Format(dateLayout)
+	}
+	out := gin.H{
+		"id":              t.ID,
+		"workspace_id":    t.WorkspaceID,
+		"project_id":      project,
+		"parent_id":       parent,
+		"owner_id":        t.OwnerID,
+		"assignee_ids":    t.AssigneeIDs,
+		"title":           t.Title,
+		"description":     desc,
+		"status":          t.Status,
+		"status_category": t.StatusCategory,
+		"priority":        t.Priority,
+		"due_date":        due,
+		"subtasks":        t.Subtasks,
+		"checklist":       t.Checklist,
+		"created_at":      t.CreatedAt,
+		"updated_at":      t.UpdatedAt,
+	}
+	// include=subtree เท่านั้น
+	if t.Children != nil {
+		children := make([]gin.H, 0, len(t.Children))
+		for _, c := range t.Children {
+			children = append(children, taskResponse(c))
+		}
+		out["children"] = children
+	}
+	return out
+}
+
+// actor: ผู้ที่มีสิทธิ์ :any จัดการงานของคนอื่นใน workspace เดียวกันได้
+func actor(p *authctx.Principal, anyPerm domain.Permission) service.Actor {
+	return service.Actor{UserID: p.UserID, AnyOwner: p.Can(anyPerm)}
+}
 
-	limit := 200 // default limit
+func taskIDParam(c *gin.Context) (int64, bool) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	return id, err == nil && id > 0
+}
+
+// pageQuery อ่าน ?limit=&offset= (limit ปรับด้วย ClampTaskLimit) offset ผิด = เขียน 400 แล้วคืน false
+func pageQuery(c *gin.Context) (limit, offset int, ok bool) {
 	if l := c.Query("limit"); l != "" {
 		if parsed, err := strconv.Atoi(l); err == nil {
 			limit = parsed
 		}
 	}
+	if o := c.Query("offset"); o != "" {
+		parsed, err := strconv.Atoi(o)
+		if err != nil || parsed < 0 {
+			response.Invalid(c, "invalid offset", response.FieldError{Field: "offset", Code: "invalid", Message: "offset must be a non-negative integer"})
+			return 0, 0, false
+		}
+		offset = parsed
+	}
+	return service.ClampTaskLimit(limit), offset, true
+}
+
+// subtreeQuery อ่าน ?include=subtree (หน้าละงานระดับบน พร้อมงานย่อยทั้งต้นไม้) ค่าอื่น = เขียน 400 แล้วคืน ok = false
+func subtreeQuery(c *gin.Context) (subtree, ok bool) {
+	switch c.Query("include") {
+	case "":
+		return false, true
+	case "subtree":
+		return true, true
+	}
+	response.Invalid(c, "invalid include", response.FieldError{Field: "include", Code: "oneof", Message: "include must be subtree"})
+	return false, false
+}
+
+func (h *TaskHandler) getTasks(c *gin.Context) {
+	p, ok := authctx.From(c)
+	if !ok {
+		response.Fail(c, http.StatusUnauthorized, response.CodeUnauthorized, "unauthorized")
+		return
+	}
+
+	limit, offset, ok := pageQuery(c)
+	if !ok {
+		return
+	}
+	subtree, ok := subtreeQuery(c)
+	if !ok {
+		return
+	}
+	var workspaceID int64 // 0 = workspace ส่วนตัว
+	if w := c.Query("workspace_id"); w != "" {
+		parsed, err := strconv.ParseInt(w, 10, 64)
+		if err != nil || parsed <= 0 {
+			response.Fail(c, http.StatusBadRequest, "invalid_workspace_id", "invalid workspace id")
+			return
+		}
+		workspaceID = parsed
+	}
 
-	tasks, err := h.Svc.GetUserTasks(c.Request.Context(), userID.(int), limit)
+	tasks, total, err := h.Svc.ListTasks(c.Request.Context(), p.UserID, workspaceID, subtree, limit, offset)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get tasks"})
+		response.Error(c, err, "failed to get tasks")
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"tasks": tasks})
+	out := make([]gin.H, 0, len(tasks))
+	for _, t := range tasks {
+		out = append(out, taskResponse(t))
+	}
+	response.Paginated(c, "tasks", out, response.Page{Limit: limit, Offset: offset, Total: total})
+}
+
+// getStatuses คืนชุดสถานะ (และ category) ให้ board/report ใช้ตรงกัน
+// (project_id ที่มองไม่เห็น = 404 เหมือนโปรเจกต์ที่ไม่มีอยู่)
+func (h *TaskHandler) getStatuses(c *gin.Context) {
+	userID, ok := authctx.UserID(c)
+	if !ok {
+		response.Fail(c, http.StatusUnauthorized, response.CodeUnauthorized, "unauthorized")
+		return
+	}
+	var projectID int64
+	if p := c.Query("project_id"); p != "" {
+		parsed, err := strconv.ParseInt(p, 10, 64)
+		if err != nil || parsed <= 0 {
+			response.Fail(c, http.StatusBadRequest, "invalid_project_id", "invalid project id")
+			return
+		}
+		projectID = parsed
+	}
+
+	set, err := h.Svc.Statuses(c.Request.Context(), userID, projectID)
+	if err != nil {
+		response.Error(c, err, "failed to get statuses")
+		return
+	}
+	response.OK(c, gin.H{"statuses": set})
+}
+
+func (h *TaskHandler) getTask(c *gin.Context) {
+	p, ok := authctx.From(c)
+	if !ok {
+		response.Fail(c, http.StatusUnauthorized, response.CodeUnauthorized, "unauthorized")
+		return
+	}
+	id, ok := taskIDParam(c)
+	if !ok {
+		response.Fail(c, http.StatusBadRequest, "invalid_task_id", "invalid task id")
+		return
+	}
+
+	t, err := h.Svc.GetTask(c.Request.Context(), id, p.UserID)
+	if err != nil {
+		response.Error(c, err, "failed to get task")
+		return
+	}
+	response.OK(c, taskResponse(t))
 }
 
 func (h *TaskHandler) createTask(c *gin.Context) {
-	c.JSON(http.StatusNotImplemented, gin.H{"error": "not implemented"})
+	p, ok := authctx.From(c)
+	if !ok {
+		response.Fail(c, http.StatusUnauthorized, response.CodeUnauthorized, "unauthorized")
+		return
+	}
+
+	var in createTaskInput
+	if !validate.Bind(c, &in) {
+		return
+	}
+
+	t := &domain.Task{OwnerID: p.UserID, WorkspaceID: in.WorkspaceID}
+	if err := in.applyTo(t); err != nil {
+		response.Error(c, err, "failed to create task")
+		return
+	}
+
+	created, err := h.Svc.CreateTask(c.Request.Context(), t)
+	if err != nil {
+		response.Error(c, err, "failed to create task")
+		return
+	}
+	response.Created(c, taskResponse(created))
 }
 
 func (h *TaskHandler) updateTask(c *gin.Context) {
-	c.JSON(http.StatusNotImplemented, gin.H{"error": "not implemented"})
+	p, ok := authctx.From(c)
+	if !ok {
+		response.Fail(c, http.StatusUnauthorized, response.CodeUnauthorized, "unauthorized")
+		return
+	}
+	id, ok := taskIDParam(c)
+	if !ok {
+		response.Fail(c, http.StatusBadRequest, "invalid_task_id", "invalid task id")
+		return
+	}
+
+	var in taskInput
+	if !validate.Bind(c, &in) {
+		return
+	}
+
+	ctx := c.Request.Context()
+	t, err := h.Svc.GetTask(ctx, id, p.UserID)
+	if err != nil {
+		response.Error(c, err, "failed to update task")
+		return
+	}
+	before := *t
+	if err := in.applyTo(t); err != nil {
+		response.Error(c, err, "failed to update task")
+		return
+	}
+	if err := h.Svc.UpdateTask(ctx, actor(p, domain.PermTaskUpdateAny), t); err != nil {
+		response.Error(c, err, "failed to update task")
+		return
+	}
+
+	updated, err := h.Svc.GetTask(ctx, id, p.UserID)
+	if err != nil {
+		response.Error(c, err, "failed to update task")
+		return
+	}
+	notifyAsync(c, func(ctx context.Context) error {
+		return h.Svc.NotifyWatchers(ctx, p.UserID, &before, updated)
+	})
+	response.OK(c, taskResponse(updated))
 }
 
 func (h *TaskHandler) deleteTask(c *gin.Context) {
-	c.JSON(http.StatusNotImplemented, gin.H{"error": "not implemented"})
+	p, ok := authctx.From(c)
+	if !ok {
+		response.Fail(c, http.StatusUnauthorized, response.CodeUnauthorized, "unauthorized")
+		return
+	}
+	id, ok := taskIDParam(c)
+	if !ok {
+		response.Fail(c, http.StatusBadRequest, "invalid_task_id", "invalid task id")
+		return
+	}
+
+	// ?children=orphan|cascade (ไม่ส่ง = ค่าจาก config)
+	mode := h.DeleteChildren
+	if m := c.Query("children"); m != "" {
+		mode = domain.ChildDeleteMode(m)
+		if !mode.Valid() {
+			response.Invalid(c, "invalid children", response.FieldError{Field: "children", Code: "oneof", Message: "children must be orphan or cascade"})
+			return
+		}
+	}
+
+	if err := h.Svc.DeleteTask(c.Request.Context(), actor(p, domain.PermTaskDeleteAny), id, mode); err != nil {
+		response.Error(c, err, "failed to delete task")
+		return
+	}
+	response.NoContent(c)
+}
+
+// notifyAsync ส่งอีเมลแจ้งเตือนเบื้องหลัง ไม่ให้ SMTP ช้าทำให้ request ช้า (ส่งภาษาของ request ต่อให้เอง)
+func notifyAsync(c *gin.Context, send func(ctx context.Context) error) {
+	locale := i18n.FromContext(c.Request.Context())
+	go func() {
+		ctx, cancel := context.WithTimeout(i18n.WithLocale(context.Background(), locale), 30*time.Second)
+		defer cancel()
+		if err := send(ctx); err != nil {
+			log.Printf("task notification failed: %v", err)
+		}
+	}()
+}
+
+func taskPersonResponse(m domain.TaskPerson) gin.H {
+	var name any
+	if m.Name.Valid {
+		name = m.Name.String
+	}
+	return gin.H{
+		"user_id":  m.UserID,
+		"email":    m.Email,
+		"name":     name,
+		"added_at": m.AddedAt,
+	}
+}
+
+// taskPersonParams อ่าน :id กับ :userId ของ /api/tasks/:id/{assignees,watchers}/:userId
+func taskPersonParams(c *gin.Context) (taskID, userID int64, ok bool) {
+	taskID, ok = taskIDParam(c)
+	if !ok {
+		response.Fail(c, http.StatusBadRequest, "invalid_task_id", "invalid task id")
+		return 0, 0, false
+	}
+	userID, ok = memberIDParam(c)
+	return taskID, userID, ok
+}
+
+func (h *TaskHandler) listPeople(c *gin.Context, list func(ctx context.Context, userID, taskID int64) ([]domain.TaskPerson, error), key string) {
+	p, ok := authctx.From(c)
+	if !ok {
+		response.Fail(c, http.StatusUnauthorized, response.CodeUnauthorized, "unauthorized")
+		return
+	}
+	id, ok := taskIDParam(c)
+	if !ok {
+		response.Fail(c, http.StatusBadRequest, "invalid_task_id", "invalid task id")
+		return
+	}
+
+	people, err := list(c.Request.Context(), p.UserID, id)
+	if err != nil {
+		response.Error(c, err, "failed to list "+key)
+		return
+	}
+	out := make([]gin.H, 0, len(people))
+	for _, m := range people {
+		out = append(out, taskPersonResponse(m))
+	}
+	response.OK(c, gin.H{key: out})
+}
+
+func (h *TaskHandler) getAssignees(c *gin.Context) { h.listPeople(c, h.Svc.Assignees, "assignees") }
+func (h *TaskHandler) getWatchers(c *gin.Context)  { h.listPeople(c, h.Svc.Watchers, "watchers") }
+
+// changePeople: PUT/DELETE ของ assignees / watchers (สำเร็จ = 204)
+func (h *TaskHandler) changePeople(c *gin.Context, change func(ctx context.Context, actor service.Actor, taskID, targetID int64) error, msg string) (taskID, targetID int64, ok bool) {
+	p, ok := authctx.From(c)
+	if !ok {
+		response.Fail(c, http.StatusUnauthorized, response.CodeUnauthorized, "unauthorized")
+		return 0, 0, false
+	}
+	taskID, targetID, ok = taskPersonParams(c)
+	if !ok {
+		return 0, 0, false
+	}
+
+	if err := change(c.Request.Context(), actor(p, domain.PermTaskUpdateAny), taskID, targetID); err != nil {
+		response.Error(c, err, msg)
+		return 0, 0, false
+	}
+	response.NoContent(c)
+	return taskID, targetID, true
+}
+
+func (h *TaskHandler) assign(c *gin.Context) {
+	taskID, targetID, ok := h.changePeople(c, h.Svc.Assign, "failed to assign task")
+	if !ok {
+		return
+	}
+	actorID, _ := authctx.UserID(c)
+	notifyAsync(c, func(ctx context.Context) error {
+		return h.Svc.NotifyAssigned(ctx, actorID, taskID, targetID)
+	})
+}
+
+func (h *TaskHandler) unassign(c *gin.Context) {
+	h.changePeople(c, h.Svc.Unassign, "failed to unassign task")
+}
+
+func (h *TaskHandler) watch(c *gin.Context) {
+	h.changePeople(c, h.Svc.Watch, "failed to watch task")
+}
+
+func (h *TaskHandler) unwatch(c *gin.Context) {
+	h.changePeople(c, h.Svc.Unwatch, "failed to unwatch task")
+}
+
+type checklistInput struct {
+	Title string `json:"title" binding:"required,notblank,max=200"`
+}
+
+// checklistPatch: field ที่ไม่ได้ส่ง = ไม่เปลี่ยน
+type checklistPatch struct {
+	Title *string `json:"title" binding:"omitempty,notblank,max=200"`
+	Done  *bool   `json:"done"`
+}
+
+func checklistItemResponse(it *domain.ChecklistItem) gin.H {
+	return gin.H{
+		"id":         it.ID,
+		"task_id":    it.TaskID,
+		"title":      it.Title,
+		"done":       it.Done,
+		"position":   it.Position,
+		"created_at": it.CreatedAt,
+		"updated_at": it.UpdatedAt,
+	}
+}
+
+// checklistParams อ่าน :id กับ :itemId ของ /api/tasks/:id/checklist/:itemId
+func checklistParams(c *gin.Context) (taskID, itemID int64, ok bool) {
+	taskID, ok = taskIDParam(c)
+	if !ok {
+		response.Fail(c, http.StatusBadRequest, "invalid_task_id", "invalid task id")
+		return 0, 0, false
+	}
+	itemID, err := strconv.ParseInt(c.Param("itemId"), 10, 64)
+	if err != nil || itemID <= 0 {
+		response.Fail(c, http.StatusBadRequest, "invalid_item_id", "invalid checklist item id")
+		return 0, 0, false
+	}
+	return taskID, itemID, true
+}
+
+func (h *TaskHandler) getChecklist(c *gin.Context) {
+	p, ok := authctx.From(c)
+	if !ok {
+		response.Fail(c, http.StatusUnauthorized, response.CodeUnauthorized, "unauthorized")
+		return
+	}
+	id, ok := taskIDParam(c)
+	if !ok {
+		response.Fail(c, http.StatusBadRequest, "invalid_task_id", "invalid task id")
+		return
+	}
+
+	items, err := h.Svc.Checklist(c.Request.Context(), p.UserID, id)
+	if err != nil {
+		response.Error(c, err, "failed to get checklist")
+		return
+	}
+	out := make([]gin.H, 0, len(items))
+	for i := range items {
+		out = append(out, checklistItemResponse(&items[i]))
+	}
+	response.OK(c, gin.H{"items": out})
+}
+
+func (h *TaskHandler) addChecklistItem(c *gin.Context) {
+	p, ok := authctx.From(c)
+	if !ok {
+		response.Fail(c, http.StatusUnauthorized, response.CodeUnauthorized, "unauthorized")
+		return
+	}
+	id, ok := taskIDParam(c)
+	if !ok {
+		response.Fail(c, http.StatusBadRequest, "invalid_task_id", "invalid task id")
+		return
+	}
+	var in checklistInput
+	if !validate.Bind(c, &in) {
+		return
+	}
+
+	it, err := h.Svc.AddChecklistItem(c.Request.Context(), actor(p, domain.PermTaskUpdateAny), id, in.Title)
+	if err != nil {
+		response.Error(c, err, "failed to add checklist item")
+		return
+	}
+	response.Created(c, checklistItemResponse(it))
+}
+
+func (h *TaskHandler) updateChecklistItem(c *gin.Context) {
+	p, ok := authctx.From(c)
+	if !ok {
+		response.Fail(c, http.StatusUnauthorized, response.CodeUnauthorized, "unauthorized")
+		return
+	}
+	taskID, itemID, ok := checklistParams(c)
+	if !ok {
+		return
+	}
+	var in checklistPatch
+	if !validate.Bind(c, &in) {
+		return
+	}
+
+	it, err := h.Svc.UpdateChecklistItem(c.Request.Context(), actor(p, domain.PermTaskUpdateAny), taskID, itemID,
+		service.ChecklistUpdate{Title: in.Title, Done: in.Done})
+	if err != nil {
+		response.Error(c, err, "failed to update checklist item")
+		return
+	}
+	response.OK(c, checklistItemResponse(it))
+}
+
+func (h *TaskHandler) deleteChecklistItem(c *gin.Context) {
+	p, ok := authctx.From(c)
+	if !ok {
+		response.Fail(c, http.StatusUnauthorized, response.CodeUnauthorized, "unauthorized")
+		return
+	}
+	taskID, itemID, ok := checklistParams(c)
+	if !ok {
+		return
+	}
+
+	if err := h.Svc.DeleteChecklistItem(c.Request.Context(), actor(p, domain.PermTaskUpdateAny), taskID, itemID); err != nil {
+		response.Error(c, err, "failed to delete checklist item")
+		return
+	}
+	response.NoContent(c)
+}
+
+// getMyWork: ?tz= time zone ของ browser ใช้เมื่อผู้ใช้ยังไม่ได้ตั้งใน preferences
+func (h *TaskHandler) getMyWork(c *gin.Context) {
+	p, ok := authctx.From(c)
+	if !ok {
+		response.Fail(c, http.StatusUnauthorized, response.CodeUnauthorized, "unauthorized")
+		return
+	}
+	tz := c.Query("tz")
+	if !domain.ValidTimezone(tz) {
+		response.Invalid(c, "invalid tz", response.FieldError{Field: "tz", Code: "timezone",
+			Message: i18n.T(i18n.FromContext(c.Request.Context()), "validation.timezone")})
+		return
+	}
+
+	w, err := h.Svc.MyWork(c.Request.Context(), p.UserID, tz)
+	if err != nil {
+		response.Error(c, err, "failed to load my work")
+		return
+	}
+	groups := make([]gin.H, 0, len(domain.WorkGroups))
+	for _, g := range domain.WorkGroups {
+		tasks := make([]gin.H, 0, len(w.Groups[g]))
+		for _, t := range w.Groups[g] {
+			tasks = append(tasks, taskResponse(t))
+		}
+		groups = append(groups, gin.H{"key": g, "tasks": tasks})
+	}
+	response.OK(c, gin.H{
+		"timezone": w.Timezone,
+		"today":    w.Today.Format(dateLayout),
+		"groups":   groups,
+	})
 }
@@ -0,0 +1,190 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"task-manager/internal/domain"
+)
+
+// createProject สร้างโปรเจกต์ใน workspace แล้วคืน id
+func (c *testClient) createProject(t testing.TB, workspaceID int64, name string) int64 {
+	t.Helper()
+	body := c.do(t, http.MethodPost, "/api/projects", map[string]any{"workspace_id": workspaceID, "name": name}).
+		expect(t, http.StatusCreated).json()
+	return int64(body["id"].(float64))
+}
+
+// สมาชิกของ workspace หนึ่งอ่าน/แก้ข้อมูลของอีก workspace ไม่ได้ ไม่ว่าจะรู้ id หรือมี role ระดับระบบสูงแค่ไหน
+// (bob เป็น admin ของระบบ: สิทธิ์ *:any ใช้ได้แค่ใน workspace ที่ตัวเองเป็นสมาชิก)
+func TestWorkspaceTenantIsolation(t *testing.T) {
+	ts := newTestServer(t)
+	alice := ts.signup(t, "alice")
+	wsA := alice.createWorkspace(t, "Alice Co")
+	projA := alice.createProject(t, wsA, "Launch")
+	body := alice.do(t, http.MethodPost, "/api/tasks", map[string]any{"title": "secret plan", "project_id": projA}).
+		expect(t, http.StatusCreated).json()
+	taskA := int64(body["id"].(float64))
+	body = alice.do(t, http.MethodPost, fmt.Sprintf("/api/tasks/%d/checklist", taskA), map[string]string{"title": "step"}).
+		expect(t, http.StatusCreated).json()
+	itemA := int64(body["id"].(float64))
+	body = alice.do(t, http.MethodPost, fmt.Sprintf("/api/workspaces/%d/invitations", wsA), map[string]string{"email": "carol@example.com", "role": "member"}).
+		expect(t, http.StatusCreated).json()
+	inviteA := int64(body["id"].(float64))
+
+	bob := ts.signup(t, "bob")
+	ts.exec(t, `UPDATE users SET role = $1 WHERE id = $2`, domain.RoleAdmin, bob.id)
+	bob.login(t)
+	wsB := bob.createWorkspace(t, "Bob Inc")
+	taskB := bob.createTask(t, wsB, "bob's task")
+
+	task := fmt.Sprintf("/api/tasks/%d", taskA)
+	project := fmt.Sprintf("/api/projects/%d", projA)
+	workspace := fmt.Sprintf("/api/workspaces/%d", wsA)
+	tests := []struct {
+		name, method, path string
+		body               any
+		status             int
+	}{
+		// งาน
+		{"list tasks", http.MethodGet, fmt.Sprintf("/api/tasks?workspace_id=%d", wsA), nil, http.StatusNotFound},
+		{"create task in workspace", http.MethodPost, "/api/tasks", map[string]any{"title": "x", "workspace_id": wsA}, http.StatusNotFound},
+		{"create task in project", http.MethodPost, "/api/tasks", map[string]any{"title": "x", "project_id": projA}, http.StatusNotFound},
+		// งานแม่ที่มองไม่เห็น = invalid_parent เหมือน id ที่ไม่มีอยู่
+		{"create subtask", http.MethodPost, "/api/tasks", map[string]any{"title": "x", "parent_id": taskA}, http.StatusBadRequest},
+		{"get task", http.MethodGet, task, nil, http.StatusNotFound},
+		{"update task", http.MethodPut, task, map[string]string{"title": "pwned"}, http.StatusNotFound},
+		{"delete task", http.MethodDelete, task, nil, http.StatusNotFound},
+		{"list assignees", http.MethodGet, task + "/assignees", nil, http.StatusNotFound},
+		{"assign self", http.MethodPut, fmt.Sprintf("%s/assignees/%d", task, bob.id), nil, http.StatusNotFound},
+		{"watch", http.MethodPut, fmt.Sprintf("%s/watchers/%d", task, bob.id), nil, http.StatusNotFound},
+		{"list checklist", http.MethodGet, task + "/checklist", nil, http.StatusNotFound},
+		{"add checklist item", http.MethodPost, task + "/checklist", map[string]string{"title": "x"}, http.StatusNotFound},
+		{"edit checklist item", http.MethodPatch, fmt.Sprintf("%s/checklist/%d", task, itemA), map[string]any{"done": true}, http.StatusNotFound},
+		{"delete checklist item", http.MethodDelete, fmt.Sprintf("%s/checklist/%d", task, itemA), nil, http.StatusNotFound},
+		// ย้ายงานของตัวเองไปอยู่ใน workspace อื่น
+		{"move own task into project", http.MethodPut, fmt.Sprintf("/api/tasks/%d", taskB), map[string]any{"project_id": projA}, http.StatusNotFound},
+		{"move own task under task", http.MethodPut, fmt.Sprintf("/api/tasks/%d", taskB), map[string]any{"parent_id": taskA}, http.StatusBadRequest},
+		{"assign non-member to own task", http.MethodPut, fmt.Sprintf("/api/tasks/%d/assignees/%d", taskB, alice.id), nil, http.StatusNotFound},
+
+		// โปรเจกต์
+		{"list projects", http.MethodGet, fmt.Sprintf("/api/projects?workspace_id=%d", wsA), nil, http.StatusNotFound},
+		{"create project", http.MethodPost, "/api/projects", map[string]any{"workspace_id": wsA, "name": "x"}, http.StatusNotFound},
+		{"get project", http.MethodGet, project, nil, http.StatusNotFound},
+		{"project members", http.MethodGet, project + "/members", nil, http.StatusNotFound},
+		{"project tasks", http.MethodGet, project + "/tasks", nil, http.StatusNotFound},
+		{"update project", http.MethodPatch, project, map[string]string{"name": "pwned"}, http.StatusNotFound},
+		{"delete project", http.MethodDelete, project, nil, http.StatusNotFound},
+		{"project statuses", http.MethodGet, fmt.Sprintf("/api/tasks/statuses?project_id=%d", projA), nil, http.StatusNotFound},
+		{"set project statuses", http.MethodPut, project + "/statuses", map[string]any{"statuses": []map[string]string{
+			{"status": "a", "label": "A", "category": "not_started"}, {"status": "b", "label": "B", "category": "done"},
+		}}, http.StatusNotFound},
+		{"join project", http.MethodPut, fmt.Sprintf("%s/members/%d", project, bob.id), map[string]string{"role": "admin"}, http.StatusNotFound},
+
+		// workspace
+		{"get workspace", http.MethodGet, workspace, nil, http.StatusNotFound},
+		{"workspace members", http.MethodGet, workspace + "/members", nil, http.StatusNotFound},
+		{"rename workspace", http.MethodPatch, workspace, map[string]string{"name": "pwned"}, http.StatusNotFound},
+		{"delete workspace", http.MethodDelete, workspace, nil, http.StatusNotFound},
+		{"change member role", http.MethodPatch, fmt.Sprintf("%s/members/%d", workspace, alice.id), map[string]string{"role": "member"}, http.StatusNotFound},
+		{"remove member", http.MethodDelete, fmt.Sprintf("%s/members/%d", workspace, alice.id), nil, http.StatusNotFound},
+		{"list invitations", http.MethodGet, workspace + "/invitations", nil, http.StatusNotFound},
+		{"invite self", http.MethodPost, workspace + "/invitations", map[string]string{"email": "bob@example.com", "role": "admin"}, http.StatusNotFound},
+		{"resend invitation", http.MethodPost, fmt.Sprintf("%s/invitations/%d/resend", workspace, inviteA), nil, http.StatusNotFound},
+		{"revoke invitation", http.MethodDelete, fmt.Sprintf("%s/invitations/%d", workspace, inviteA), nil, http.StatusNotFound},
+		// คำเชิญของ workspace ตัวเองใช้ id ของคำเชิญใน workspace อื่นไม่ได้
+		{"revoke invitation via own workspace", http.MethodDelete, fmt.Sprintf("/api/workspaces/%d/invitations/%d", wsB, inviteA), nil, http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bob.do(t, tt.method, tt.path, tt.body).expect(t, tt.status)
+		})
+	}
+
+	// ได้ลิงก์เชิญของคนอื่นมาก็รับแทนไม่ได้
+	link := ts.mail.waitFor(t, "carol@example.com").Text
+	token := link[strings.Index(link, "?token=")+len("?token="):]
+	token, _ = url.QueryUnescape(strings.Fields(token)[0])
+	if r := bob.do(t, http.MethodPost, "/api/invitations/accept", map[string]string{"token": token}); r.StatusCode != http.StatusForbidden || r.code() != "invitation_email_mismatch" {
+		t.Fatalf("accept someone else's invitation = %d %s, want 403 invitation_email_mismatch", r.StatusCode, r.raw)
+	}
+
+	// รายการของ bob ไม่มีอะไรของ alice
+	list := bob.do(t, http.MethodGet, "/api/workspaces", nil).expect(t, http.StatusOK).json()
+	for _, w := range list["workspaces"].([]any) {
+		if int64(w.(map[string]any)["id"].(float64)) == wsA {
+			t.Fatalf("bob's workspaces include alice's: %v", list)
+		}
+	}
+
+	// ข้อมูลของ alice ยังอยู่ครบไม่ถูกแก้
+	got := alice.do(t, http.MethodGet, task, nil).expect(t, http.StatusOK).json()
+	if got["title"] != "secret plan" {
+		t.Errorf("task title = %v", got["title"])
+	}
+	items := alice.do(t, http.MethodGet, task+"/checklist", nil).expect(t, http.StatusOK).json()["items"].([]any)
+	if len(items) != 1 || items[0].(map[string]any)["done"] == true {
+		t.Errorf("checklist = %v", items)
+	}
+	if got := alice.do(t, http.MethodGet, project, nil).expect(t, http.StatusOK).json(); got["name"] != "Launch" {
+		t.Errorf("project name = %v", got["name"])
+	}
+	if got := alice.do(t, http.MethodGet, workspace, nil).expect(t, http.StatusOK).json(); got["name"] != "Alice Co" {
+		t.Errorf("workspace name = %v", got["name"])
+	}
+	members := alice.do(t, http.MethodGet, workspace+"/members", nil).expect(t, http.StatusOK).json()["members"].([]any)
+	if len(members) != 1 || members[0].(map[string]any)["role"] != "admin" {
+		t.Errorf("members = %v", members)
+	}
+	invites := alice.do(t, http.MethodGet, workspace+"/invitations", nil).expect(t, http.StatusOK).json()
+	if raw := fmt.Sprint(invites); !strings.Contains(raw, "carol@example.com") || strings.Contains(raw, "bob@example.com") {
+		t.Errorf("invitations = %v", invites)
+	}
+}
+
+// ลบ workspace แล้วต้องไม่เหลืออะไรค้าง: อดีตสมาชิกเปิดงานเดิมด้วย id ไม่ได้
+// (SQLite ไม่ได้เปิด foreign_keys จึงพึ่ง ON DELETE CASCADE ไม่ได้)
+func TestDeleteWorkspaceRemovesEverything(t *testing.T) {
+	ts := newTestServer(t)
+	alice := ts.signup(t, "alice")
+	bob := ts.signup(t, "bob")
+	wsID := alice.createWorkspace(t, "Doomed")
+	ts.exec(t, `INSERT INTO workspace_members (workspace_id, user_id, role) VALUES ($1, $2, 'member')`, wsID, bob.id)
+	projID := alice.createProject(t, wsID, "Plan")
+	alice.do(t, http.MethodPut, fmt.Sprintf("/api/projects/%d/members/%d", projID, bob.id), map[string]string{"role": "member"}).
+		expect(t, http.StatusOK)
+	body := alice.do(t, http.MethodPost, "/api/tasks", map[string]any{"title": "keep out", "project_id": projID}).
+		expect(t, http.StatusCreated).json()
+	task := fmt.Sprintf("/api/tasks/%d", int64(body["id"].(float64)))
+	alice.do(t, http.MethodPost, task+"/checklist", map[string]string{"title": "step"}).expect(t, http.StatusCreated)
+	alice.do(t, http.MethodPut, fmt.Sprintf("%s/assignees/%d", task, bob.id), nil).expect(t, http.StatusNoContent)
+	alice.do(t, http.MethodPost, fmt.Sprintf("/api/workspaces/%d/invitations", wsID), map[string]string{"email": "carol@example.com", "role": "member"}).
+		expect(t, http.StatusCreated)
+	bob.do(t, http.MethodGet, task, nil).expect(t, http.StatusOK)
+
+	alice.do(t, http.MethodDelete, fmt.Sprintf("/api/workspaces/%d", wsID), nil).expect(t, http.StatusNoContent)
+
+	bob.do(t, http.MethodGet, task, nil).expect(t, http.StatusNotFound)
+	bob.do(t, http.MethodGet, task+"/checklist", nil).expect(t, http.StatusNotFound)
+	bob.do(t, http.MethodGet, fmt.Sprintf("/api/projects/%d", projID), nil).expect(t, http.StatusNotFound)
+	for _, q := range []string{
+		`SELECT COUNT(*) FROM tasks WHERE workspace_id = $1`,
+		`SELECT COUNT(*) FROM projects WHERE workspace_id = $1`,
+		`SELECT COUNT(*) FROM project_members WHERE workspace_id = $1`,
+		`SELECT COUNT(*) FROM workspace_members WHERE workspace_id = $1`,
+		`SELECT COUNT(*) FROM workspace_invitations WHERE workspace_id = $1`,
+		`SELECT COUNT(*) FROM task_checklist_items WHERE task_id NOT IN (SELECT id FROM tasks) AND $1 > 0`,
+		`SELECT COUNT(*) FROM task_assignees WHERE task_id NOT IN (SELECT id FROM tasks) AND $1 > 0`,
+	} {
+		var n int
+		if err := ts.db.QueryRow(q, wsID).Scan(&n); err != nil {
+			t.Fatal(err)
+		}
+		if n != 0 {
+			t.Errorf("%s = %d rows left", q, n)
+		}
+	}
+}
@@ -1,89 +1,755 @@
 package api
 
 import (
+	"errors"
 	"net/http"
+	"strconv"
+	"time"
+
+	"task-manager/internal/auth"
+	"task-manager/internal/authctx"
+	"task-manager/internal/domain"
+	"task-manager/internal/middleware"
+	"task-manager/internal/ratelimit"
 	"task-manager/internal/service"
+	"task-manager/internal/validate"
+	"task-manager/pkg/response"
 
 	"github.com/gin-gonic/gin"
 )
 
 type UserHandler struct {
-	UserSvc service.UserService
+	UserSvc    service.UserService
+	SessionSvc service.SessionService
+	VerifySvc  service.EmailVerificationService
+	MFASvc     service.MFAService
+	PasskeySvc service.PasskeyService
+
+	// ผูก/ยกเลิกการผูกบัญชี Google / OIDC
+	IdentitySvc service.IdentityService
+	Providers   *auth.OIDCRegistry
+	JWT         auth.JWT
+
+	// personal access tokens ของ script / CI
+	TokenSvc service.AccessTokenService
+
+	// ตัวนับรหัส 2FA ผิด (ใช้ร่วมกับ /api/auth/mfa/verify)
+	Limiter *ratelimit.Limiter
 }
 
-func RegisterUserRoutes(r *gin.Engine, userSvc service.UserService, authMw gin.HandlerFunc) {
-	h := &UserHandler{UserSvc: userSvc}
+func RegisterUserRoutes(r *gin.Engine, h *UserHandler, authMw gin.HandlerFunc) {
 
 	g := r.Group("/api/users")
 	g.Use(authMw) // Require authentication
+	g.GET("/me", middleware.RequireScope(domain.ScopeProfileRead), h.getMe)
+	g.GET("/me/preferences", middleware.RequireScope(domain.ScopeProfileRead), h.getPreferences)
+
+	// ที่เหลือเป็นการจัดการบัญชี personal access token ใช้ไม่ได้
+	g = g.Group("", middleware.RequireSession())
 	{
-		g.GET("/me", h.getMe)
 		g.PUT("/profile", h.updateProfile)
+
+		// ภาษา / theme / การแจ้งเตือน (DELETE = Reset to Default)
+		g.PATCH("/me/preferences", h.updatePreferences)
+		g.DELETE("/me/preferences", h.resetPreferences)
+
+		// อุปกรณ์ที่ล็อกอินอยู่ (หน้า settings)
+		g.GET("/me/sessions", h.listSessions)
+		g.DELETE("/me/sessions", h.revokeAllSessions)
+		g.DELETE("/me/sessions/:id", h.revokeSession)
+
+		// ส่งอีเมลยืนยันอีกครั้ง
+		g.POST("/me/email-verification", h.resendVerification)
+
+		// 2FA (authenticator app + recovery codes)
+		g.GET("/me/mfa", h.mfaStatus)
+		g.POST("/me/mfa/totp/setup", h.mfaSetup)
+		g.POST("/me/mfa/totp/enable", h.mfaEnable)
+		g.POST("/me/mfa/disable", h.mfaDisable)
+		g.POST("/me/mfa/recovery-codes", h.mfaRecoveryCodes)
+
+		// passkeys ที่ลงทะเบียนไว้ (ลงทะเบียนใหม่ที่ /api/auth/passkey/register)
+		g.GET("/me/passkeys", h.listPasskeys)
+		g.PATCH("/me/passkeys/:id", h.renamePasskey)
+		g.DELETE("/me/passkeys/:id", h.deletePasskey)
+
+		// บัญชีภายนอกที่ผูกไว้ (ต้องยืนยันตัวตนซ้ำก่อนผูก/ยกเลิก)
+		g.GET("/me/identities", h.listIdentities)
+		g.POST("/me/identities/:provider/link", h.linkIdentity)
+		g.DELETE("/me/identities/:id", h.unlinkIdentity)
+
+		// personal access tokens (token จริงแสดงครั้งเดียวตอนสร้าง)
+		g.GET("/me/tokens", h.listAccessTokens)
+		g.POST("/me/tokens", h.createAccessToken)
+		g.DELETE("/me/tokens/:id", h.revokeAccessToken)
 	}
 }
 
 func (h *UserHandler) getMe(c *gin.Context) {
-	userID, exists := c.Get("userID")
-	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+	userID, ok := authctx.UserID(c)
+	if !ok {
+		response.Fail(c, http.StatusUnauthorized, response.CodeUnauthorized, "unauthorized")
 		return
 	}
 
-	user, err := h.UserSvc.GetByID(c.Request.Context(), userID.(int64))
+	user, err := h.UserSvc.GetByID(c.Request.Context(), userID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get user"})
+		response.Error(c, err, "failed to get user")
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"id":    user.ID,
-		"email": user.Email,
-		"name":  user.Name,
+	var locale any // null = ตาม Accept-Language
+	if user.Locale.Valid {
+		locale = user.Locale.String
+	}
+	response.OK(c, gin.H{
+		"id":             user.ID,
+		"email":          user.Email,
+		"name":           user.Name,
+		"email_verified": user.EmailVerified(),
+		"role":           user.Role,
+		"permissions":    domain.PermissionsOf(user.Role),
+		"locale":         locale,
 	})
 }
 
 func (h *UserHandler) updateProfile(c *gin.Context) {
-	userID, exists := c.Get("userID")
-	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+	userID, ok := authctx.UserID(c)
+	if !ok {
+		response.Fail(c, http.StatusUnauthorized, response.CodeUnauthorized, "unauthorized")
 		return
 	}
 
 	var req struct {
-		Name     string `json:"name" binding:"required,min=2"`
-		Username string `json:"username,omitempty"`
-		Password string `json:"password,omitempty"`
+		Name     string  `json:"name" binding:"required,notblank,min=2,max=100"`
+		Username string  `json:"username,omitempty" binding:"omitempty,username"`
+		Password string  `json:"password,omitempty" binding:"omitempty,password"`
+		Locale   *string `json:"locale,omitempty" binding:"omitempty,locale"` // "" = ล้างค่า
 	}
-
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
+	if !validate.Bind(c, &req) {
 		return
 	}
 
+	// ภาษาใหม่มีผลกับ access token ใบถัดไป (หลัง refresh) ระหว่างนี้ใช้ Accept-Language
+	if req.Locale != nil {
+		if err := h.UserSvc.UpdateLocale(c.Request.Context(), userID, *req.Locale); err != nil {
+			response.Error(c, err, "failed to update language")
+			return
+		}
+	}
+
 	// Update name
-	err := h.UserSvc.UpdateName(c.Request.Context(), userID.(int64), req.Name)
+	err := h.UserSvc.UpdateName(c.Request.Context(), userID, req.Name)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update profile"})
+		response.Error(c, err, "failed to update profile")
 		return
 	}
 
 	// Update username if provided
 	if req.Username != "" {
-		err = h.UserSvc.UpdateUsername(c.Request.Context(), userID.(int64), req.Username)
+		err = h.UserSvc.UpdateUsername(c.Request.Context(), userID, req.Username)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update username"})
+			response.Error(c, err, "failed to update username")
 			return
 		}
 	}
 
 	// Update password if provided
 	if req.Password != "" {
-		err = h.UserSvc.UpdatePassword(c.Request.Context(), userID.(int64), req.Password)
+		err = h.UserSvc.UpdatePassword(c.Request.Context(), userID, req.Password)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update password"})
+			response.Error(c, err, "failed to update password")
+			return
+		}
+	}
+
+	response.OK(c, gin.H{"success": true, "message": "profile updated successfully"})
+}
+
+func (h *UserHandler) listSessions(c *gin.Context) {
+	userID, ok := authctx.UserID(c)
+	if !ok {
+		response.Fail(c, http.StatusUnauthorized, response.CodeUnauthorized, "unauthorized")
+		return
+	}
+
+	sessions, err := h.SessionSvc.List(c.Request.Context(), userID)
+	if err != nil {
+		response.Error(c, err, "failed to get sessions")
+		return
+	}
+
+	current := authctx.SessionID(c)
+	out := make([]gin.H, 0, len(sessions))
+	for _, s := range sessions {
+		out = append(out, gin.H{
+			"id":           s.ID,
+			"user_agent":   s.UserAgent,
+			"ip":           s.IP,
+			"created_at":   s.CreatedAt,
+			"last_seen_at": s.LastSeenAt,
+			"current":      s.ID == current,
+		})
+	}
+	response.OK(c, gin.H{"sessions": out})
+}
+
+func (h *UserHandler) revokeSession(c *gin.Context) {
+	userID, ok := authctx.UserID(c)
+	if !ok {
+		response.Fail(c, http.StatusUnauthorized, response.CodeUnauthorized, "unauthorized")
+		return
+	}
+
+	err := h.SessionSvc.Revoke(c.Request.Context(), userID, c.Param("id"))
+	if err != nil {
+		if errors.Is(err, domain.ErrSessionNotFound) {
+			response.Fail(c, http.StatusNotFound, "session_not_found", "session not found")
+			return
+		}
+		response.Error(c, err, "failed to revoke session")
+		return
+	}
+	response.OK(c, gin.H{"success": true})
+}
+
+// revokeAllSessions ออกจากระบบทุกอุปกรณ์ ยกเว้นอุปกรณ์ปัจจุบัน
+// (?include_current=true เพื่อออกจากอุปกรณ์นี้ด้วย)
+func (h *UserHandler) revokeAllSessions(c *gin.Context) {
+	userID, ok := authctx.UserID(c)
+	if !ok {
+		response.Fail(c, http.StatusUnauthorized, response.CodeUnauthorized, "unauthorized")
+		return
+	}
+
+	except := authctx.SessionID(c)
+	if c.Query("include_current") == "true" {
+		except = ""
+	}
+	if err := h.SessionSvc.RevokeAll(c.Request.Context(), userID, except); err != nil {
+		response.Error(c, err, "failed to revoke sessions")
+		return
+	}
+	response.OK(c, gin.H{"success": true})
+}
+
+func (h *UserHandler) resendVerification(c *gin.Context) {
+	userID, ok := authctx.UserID(c)
+	if !ok {
+		response.Fail(c, http.StatusUnauthorized, response.CodeUnauthorized, "unauthorized")
+		return
+	}
+
+	if err := h.VerifySvc.SendVerification(c.Request.Context(), userID); err != nil {
+		if errors.Is(err, domain.ErrTooManyRequests) {
+			response.Fail(c, http.StatusTooManyRequests, response.CodeTooManyRequests, "please wait before requesting another email")
 			return
 		}
+		response.Error(c, err, "failed to send verification email")
+		return
+	}
+	response.OK(c, gin.H{"success": true, "message": "verification email sent"})
+}
+
+func (h *UserHandler) mfaStatus(c *gin.Context) {
+	userID, ok := authctx.UserID(c)
+	if !ok {
+		response.Fail(c, http.StatusUnauthorized, response.CodeUnauthorized, "unauthorized")
+		return
+	}
+
+	st, err := h.MFASvc.Status(c.Request.Context(), userID)
+	if err != nil {
+		response.Error(c, err, "failed to get 2fa status")
+		return
+	}
+	response.OK(c, st)
+}
+
+func (h *UserHandler) mfaSetup(c *gin.Context) {
+	userID, ok := authctx.UserID(c)
+	if !ok {
+		response.Fail(c, http.StatusUnauthorized, response.CodeUnauthorized, "unauthorized")
+		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"success": true, "message": "profile updated successfully"})
+	setup, err := h.MFASvc.BeginSetup(c.Request.Context(), userID)
+	if err != nil {
+		if errors.Is(err, domain.ErrMFAAlreadyEnabled) {
+			response.Fail(c, http.StatusConflict, "mfa_already_enabled", "2fa is already enabled")
+			return
+		}
+		response.Error(c, err, "failed to start 2fa setup")
+		return
+	}
+	response.OK(c, setup)
+}
+
+// mfaCode อ่าน {"code": "..."} จาก body (TOTP 6 หลัก หรือ recovery code)
+func mfaCode(c *gin.Context) (string, bool) {
+	var in struct {
+		Code string `json:"code" binding:"required,max=32"`
+	}
+	if !validate.Bind(c, &in) {
+		return "", false
+	}
+	return in.Code, true
+}
+
+// writeMFAError แปลง error ของ MFAService เป็น response
+func writeMFAError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, domain.ErrInvalidMFACode):
+		// 400 ไม่ใช่ 401: ผู้ใช้ login อยู่แล้ว แค่กรอกรหัสผิด (frontend ไม่ต้อง refresh token)
+		response.Fail(c, http.StatusBadRequest, "invalid_mfa_code", "invalid code")
+	case errors.Is(err, domain.ErrMFANotEnabled):
+		response.Fail(c, http.StatusBadRequest, "mfa_not_enabled", "2fa is not enabled")
+	case errors.Is(err, domain.ErrMFAAlreadyEnabled):
+		response.Fail(c, http.StatusConflict, "mfa_already_enabled", "2fa is already enabled")
+	default:
+		response.Error(c, err, fallback)
+	}
+}
+
+func (h *UserHandler) mfaEnable(c *gin.Context) {
+	userID, ok := authctx.UserID(c)
+	if !ok {
+		response.Fail(c, http.StatusUnauthorized, response.CodeUnauthorized, "unauthorized")
+		return
+	}
+	code, ok := mfaCode(c)
+	if !ok {
+		return
+	}
+
+	codes, err := h.MFASvc.Enable(c.Request.Context(), userID, code)
+	if err != nil {
+		writeMFAError(c, err, "failed to enable 2fa")
+		return
+	}
+	// recovery codes แสดงครั้งเดียว เก็บแค่ hash ไว้ใน DB
+	response.OK(c, gin.H{"success": true, "recovery_codes": codes})
+}
+
+func (h *UserHandler) mfaDisable(c *gin.Context) {
+	userID, ok := authctx.UserID(c)
+	if !ok {
+		response.Fail(c, http.StatusUnauthorized, response.CodeUnauthorized, "unauthorized")
+		return
+	}
+	code, ok := mfaCode(c)
+	if !ok {
+		return
+	}
+
+	if mfaThrottled(c, h.Limiter, userID) {
+		return
+	}
+	err := h.MFASvc.Disable(c.Request.Context(), userID, code)
+	mfaAttempt(c, h.Limiter, userID, err)
+	if err != nil {
+		writeMFAError(c, err, "failed to disable 2fa")
+		return
+	}
+	response.OK(c, gin.H{"success": true})
+}
+
+func (h *UserHandler) mfaRecoveryCodes(c *gin.Context) {
+	userID, ok := authctx.UserID(c)
+	if !ok {
+		response.Fail(c, http.StatusUnauthorized, response.CodeUnauthorized, "unauthorized")
+		return
+	}
+	code, ok := mfaCode(c)
+	if !ok {
+		return
+	}
+
+	if mfaThrottled(c, h.Limiter, userID) {
+		return
+	}
+	codes, err := h.MFASvc.RegenerateRecoveryCodes(c.Request.Context(), userID, code)
+	mfaAttempt(c, h.Limiter, userID, err)
+	if err != nil {
+		writeMFAError(c, err, "failed to regenerate recovery codes")
+		return
+	}
+	response.OK(c, gin.H{"success": true, "recovery_codes": codes})
+}
+
+func (h *UserHandler) listPasskeys(c *gin.Context) {
+	userID, ok := authctx.UserID(c)
+	if !ok {
+		response.Fail(c, http.StatusUnauthorized, response.CodeUnauthorized, "unauthorized")
+		return
+	}
+
+	passkeys, err := h.PasskeySvc.List(c.Request.Context(), userID)
+	if err != nil {
+		response.Error(c, err, "failed to get passkeys")
+		return
+	}
+	out := make([]gin.H, 0, len(passkeys))
+	for i := range passkeys {
+		out = append(out, passkeyResponse(&passkeys[i]))
+	}
+	response.OK(c, gin.H{"passkeys": out})
+}
+
+func (h *UserHandler) renamePasskey(c *gin.Context) {
+	userID, ok := authctx.UserID(c)
+	if !ok {
+		response.Fail(c, http.StatusUnauthorized, response.CodeUnauthorized, "unauthorized")
+		return
+	}
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.Fail(c, http.StatusBadRequest, "invalid_passkey_id", "invalid passkey id")
+		return
+	}
+	var in struct {
+		Name string `json:"name" binding:"required,notblank,max=100"`
+	}
+	if !validate.Bind(c, &in) {
+		return
+	}
+
+	if err := h.PasskeySvc.Rename(c.Request.Context(), userID, id, in.Name); err != nil {
+		switch {
+		case errors.Is(err, domain.ErrPasskeyNotFound):
+			response.Fail(c, http.StatusNotFound, "passkey_not_found", "passkey not found")
+		default:
+			response.Error(c, err, "failed to rename passkey")
+		}
+		return
+	}
+	response.OK(c, gin.H{"success": true})
+}
+
+func (h *UserHandler) deletePasskey(c *gin.Context) {
+	userID, ok := authctx.UserID(c)
+	if !ok {
+		response.Fail(c, http.StatusUnauthorized, response.CodeUnauthorized, "unauthorized")
+		return
+	}
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.Fail(c, http.StatusBadRequest, "invalid_passkey_id", "invalid passkey id")
+		return
+	}
+
+	if err := h.PasskeySvc.Delete(c.Request.Context(), userID, id); err != nil {
+		switch {
+		case errors.Is(err, domain.ErrPasskeyNotFound):
+			response.Fail(c, http.StatusNotFound, "passkey_not_found", "passkey not found")
+		case errors.Is(err, domain.ErrLastLoginMethod):
+			response.Fail(c, http.StatusConflict, "last_login_method", "cannot remove your last login method")
+		default:
+			response.Error(c, err, "failed to delete passkey")
+		}
+		return
+	}
+	response.OK(c, gin.H{"success": true})
+}
+
+func (h *UserHandler) listIdentities(c *gin.Context) {
+	userID, ok := authctx.UserID(c)
+	if !ok {
+		response.Fail(c, http.StatusUnauthorized, response.CodeUnauthorized, "unauthorized")
+		return
+	}
+
+	user, err := h.UserSvc.GetByID(c.Request.Context(), userID)
+	if err != nil {
+		response.Error(c, err, "failed to get user")
+		return
+	}
+	identities, err := h.IdentitySvc.List(c.Request.Context(), userID)
+	if err != nil {
+		response.Error(c, err, "failed to get linked accounts")
+		return
+	}
+	out := make([]gin.H, 0, len(identities))
+	for _, i := range identities {
+		var lastUsed interface{}
+		if i.LastUsedAt.Valid {
+			lastUsed = i.LastUsedAt.Time
+		}
+		out = append(out, gin.H{
+			"id":           i.ID,
+			"provider":     i.Provider,
+			"email":        i.Email,
+			"created_at":   i.CreatedAt,
+			"last_used_at": lastUsed,
+		})
+	}
+	// has_password บอกหน้า settings ว่าต้องถามรหัสผ่านก่อนผูก/ยกเลิกหรือไม่
+	response.OK(c, gin.H{
+		"identities":   out,
+		"has_password": user.PasswordHash.Valid && user.PasswordHash.String != "",
+	})
+}
+
+// reauthenticate ตรวจ {"password": "..."} จาก body ตอบ error ให้แล้วถ้าไม่ผ่าน
+func (h *UserHandler) reauthenticate(c *gin.Context, userID int64) bool {
+	var in struct {
+		Password string `json:"password"`
+	}
+	_ = c.ShouldBindJSON(&in)
+
+	err := h.IdentitySvc.Reauthenticate(c.Request.Context(), userID, authctx.SessionID(c), in.Password)
+	switch {
+	case err == nil:
+		return true
+	case errors.Is(err, domain.ErrInvalidCredentials):
+		response.Fail(c, http.StatusUnauthorized, "incorrect_password", "incorrect password")
+	case errors.Is(err, domain.ErrReauthRequired):
+		response.FailWith(c, http.StatusUnauthorized, "reauth_required",
+			"please confirm your password or sign in again", gin.H{"reauth_required": true})
+	default:
+		response.Error(c, err, "failed to verify identity")
+	}
+	return false
+}
+
+// linkIdentity ออก link ticket อายุสั้น ให้หน้า settings POST ไปที่ /api/auth/{provider}/link
+func (h *UserHandler) linkIdentity(c *gin.Context) {
+	userID, ok := authctx.UserID(c)
+	if !ok {
+		response.Fail(c, http.StatusUnauthorized, response.CodeUnauthorized, "unauthorized")
+		return
+	}
+	p, ok := h.Providers.Get(c.Param("provider"))
+	if !ok {
+		response.Fail(c, http.StatusNotFound, "unknown_login_provider", "unknown login provider")
+		return
+	}
+	if !h.reauthenticate(c, userID) {
+		return
+	}
+
+	ticket, ttl, err := h.JWT.GenerateLinkTicket(userID, p.Name())
+	if err != nil {
+		response.Error(c, err, "failed to start linking")
+		return
+	}
+	response.OK(c, gin.H{
+		"ticket":     ticket,
+		"expires_in": int(ttl.Seconds()),
+		"action":     "/api/auth/" + p.Name() + "/link",
+	})
+}
+
+func (h *UserHandler) unlinkIdentity(c *gin.Context) {
+	userID, ok := authctx.UserID(c)
+	if !ok {
+		response.Fail(c, http.StatusUnauthorized, response.CodeUnauthorized, "unauthorized")
+		return
+	}
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.Fail(c, http.StatusBadRequest, "invalid_account_id", "invalid account id")
+		return
+	}
+	if !h.reauthenticate(c, userID) {
+		return
+	}
+
+	if err := h.IdentitySvc.Unlink(c.Request.Context(), userID, id); err != nil {
+		switch {
+		case errors.Is(err, domain.ErrIdentityNotFound):
+			response.Fail(c, http.StatusNotFound, "identity_not_found", "linked account not found")
+		case errors.Is(err, domain.ErrLastLoginMethod):
+			response.Fail(c, http.StatusConflict, "last_login_method", "cannot remove your last login method")
+		default:
+			response.Error(c, err, "failed to unlink account")
+		}
+		return
+	}
+	response.OK(c, gin.H{"success": true})
+}
+
+func accessTokenResponse(t *domain.PersonalAccessToken) gin.H {
+	var lastUsed interface{}
+	if t.LastUsedAt.Valid {
+		lastUsed = t.LastUsedAt.Time
+	}
+	return gin.H{
+		"id":           t.ID,
+		"name":         t.Name,
+		"prefix":       t.Prefix,
+		"scopes":       t.Scopes,
+		"expires_at":   t.ExpiresAt,
+		"last_used_at": lastUsed,
+		"created_at":   t.CreatedAt,
+	}
+}
+
+func (h *UserHandler) listAccessTokens(c *gin.Context) {
+	userID, ok := authctx.UserID(c)
+	if !ok {
+		response.Fail(c, http.StatusUnauthorized, response.CodeUnauthorized, "unauthorized")
+		return
+	}
+
+	tokens, err := h.TokenSvc.List(c.Request.Context(), userID)
+	if err != nil {
+		response.Error(c, err, "failed to get access tokens")
+		return
+	}
+	out := make([]gin.H, 0, len(tokens))
+	for i := range tokens {
+		out = append(out, accessTokenResponse(&tokens[i]))
+	}
+	response.OK(c, gin.H{"tokens": out, "available_scopes": domain.AllScopes})
+}
+
+func (h *UserHandler) createAccessToken(c *gin.Context) {
+	userID, ok := authctx.UserID(c)
+	if !ok {
+		response.Fail(c, http.StatusUnauthorized, response.CodeUnauthorized, "unauthorized")
+		return
+	}
+	var in struct {
+		Name          string   `json:"name" binding:"required,notblank,max=100"`
+		Scopes        []string `json:"scopes" binding:"required,min=1"`
+		ExpiresInDays int      `json:"expires_in_days" binding:"min=0,max=365"` // 0 = 30 วัน, สูงสุด 365
+	}
+	if !validate.Bind(c, &in) {
+		return
+	}
+
+	ttl := time.Duration(in.ExpiresInDays) * 24 * time.Hour
+	raw, t, err := h.TokenSvc.Create(c.Request.Context(), userID, in.Name, in.Scopes, ttl)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrInvalidInput):
+			response.Fail(c, http.StatusBadRequest, response.CodeInvalidInput, "name must be 1-100 characters and expiry 1-365 days")
+		case errors.Is(err, domain.ErrInvalidScope):
+			response.FailWith(c, http.StatusBadRequest, "invalid_scope", err.Error(), gin.H{"available_scopes": domain.AllScopes})
+		default:
+			response.Error(c, err, "failed to create access token")
+		}
+		return
+	}
+	resp := accessTokenResponse(t)
+	resp["token"] = raw
+	response.Created(c, resp)
+}
+
+func (h *UserHandler) revokeAccessToken(c *gin.Context) {
+	userID, ok := authctx.UserID(c)
+	if !ok {
+		response.Fail(c, http.StatusUnauthorized, response.CodeUnauthorized, "unauthorized")
+		return
+	}
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.Fail(c, http.StatusBadRequest, "invalid_token_id", "invalid token id")
+		return
+	}
+
+	if err := h.TokenSvc.Revoke(c.Request.Context(), userID, id); err != nil {
+		if errors.Is(err, domain.ErrAccessTokenNotFound) {
+			response.Fail(c, http.StatusNotFound, "access_token_not_found", "access token not found")
+			return
+		}
+		response.Error(c, err, "failed to revoke access token")
+		return
+	}
+	response.OK(c, gin.H{"success": true})
+}
+
+// preferencesPatch: ส่งเฉพาะค่าที่เปลี่ยน key ที่ไม่มีใน schema = 400
+type preferencesPatch struct {
+	Version       *int    `json:"version"`                             // ส่ง object จาก GET กลับมาทั้งก้อนได้ (ไม่ได้ใช้ค่า)
+	Language      *string `json:"language" binding:"omitempty,locale"` // "" = ตาม browser
+	Theme         *string `json:"theme" binding:"omitempty,oneof=light dark system"`
+	Timezone      *string `json:"timezone" binding:"omitempty,timezone"` // "" = UTC
+	Notifications *struct {
+		Email         *bool `json:"email"`
+		Push          *bool `json:"push"`
+		TaskReminders *bool `json:"task_reminders"`
+	} `json:"notifications"`
+}
+
+func (in *preferencesPatch) applyTo(p *domain.Preferences) {
+	if in.Language != nil {
+		p.Language = *in.Language
+	}
+	if in.Theme != nil {
+		p.Theme = *in.Theme
+	}
+	if in.Timezone != nil {
+		p.Timezone = *in.Timezone
+	}
+	if n := in.Notifications; n != nil {
+		if n.Email != nil {
+			p.Notifications.Email = *n.Email
+		}
+		if n.Push != nil {
+			p.Notifications.Push = *n.Push
+		}
+		if n.TaskReminders != nil {
+			p.Notifications.TaskReminders = *n.TaskReminders
+		}
+	}
+}
+
+func (h *UserHandler) getPreferences(c *gin.Context) {
+	userID, ok := authctx.UserID(c)
+	if !ok {
+		response.Fail(c, http.StatusUnauthorized, response.CodeUnauthorized, "unauthorized")
+		return
+	}
+	p, err := h.UserSvc.GetPreferences(c.Request.Context(), userID)
+	if err != nil {
+		response.Error(c, err, "failed to load preferences")
+		return
+	}
+	response.OK(c, p)
+}
+
+func (h *UserHandler) updatePreferences(c *gin.Context) {
+	userID, ok := authctx.UserID(c)
+	if !ok {
+		response.Fail(c, http.StatusUnauthorized, response.CodeUnauthorized, "unauthorized")
+		return
+	}
+	var in preferencesPatch
+	if !validate.BindStrict(c, &in) {
+		return
+	}
+
+	ctx := c.Request.Context()
+	p, err := h.UserSvc.GetPreferences(ctx, userID)
+	if err != nil {
+		response.Error(c, err, "failed to load preferences")
+		return
+	}
+	in.applyTo(p)
+	if err := h.UserSvc.UpdatePreferences(ctx, userID, p); err != nil {
+		response.Error(c, err, "failed to save preferences")
+		return
+	}
+	response.OK(c, p)
+}
+
+func (h *UserHandler) resetPreferences(c *gin.Context) {
+	userID, ok := authctx.UserID(c)
+	if !ok {
+		response.Fail(c, http.StatusUnauthorized, response.CodeUnauthorized, "unauthorized")
+		return
+	}
+	ctx := c.Request.Context()
+	if err := h.UserSvc.ResetPreferences(ctx, userID); err != nil {
+		response.Error(c, err, "failed to reset preferences")
+		return
+	}
+	p, err := h.UserSvc.GetPreferences(ctx, userID)
+	if err != nil {
+		response.Error(c, err, "failed to load preferences")
+		return
+	}
+	response.OK(c, p)
 }
@@ -0,0 +1,373 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	"task-manager/internal/authctx"
+	"task-manager/internal/domain"
+	"task-manager/internal/middleware"
+	"task-manager/internal/service"
+	"task-manager/internal/validate"
+	"task-manager/pkg/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+type WorkspaceHandler struct {
+	Svc       service.WorkspaceService
+	InviteSvc service.InvitationService
+}
+
+// mws: เหมือนของ task (JWTMiddleware + RequireVerifiedEmail ถ้าเปิดไว้)
+func RegisterWorkspaceRoutes(r *gin.Engine, h *WorkspaceHandler, mws ...gin.HandlerFunc) {
+	readScope := middleware.RequireScope(domain.ScopeTasksRead)
+
+	g := r.Group("/api/workspaces")
+	g.Use(mws...)
+	{
+		g.GET("", readScope, h.list)
+		g.GET("/:id", readScope, h.get)
+		g.GET("/:id/members", readScope, h.members)
+	}
+
+	// สร้าง/แก้ไขทีมต้องล็อกอินจริง personal access token ใช้ไม่ได้
+	g = g.Group("", middleware.RequireSession())
+	{
+		g.POST("", h.create)
+		g.PATCH("/:id", h.rename)
+		g.DELETE("/:id", h.delete)
+		g.PATCH("/:id/members/:userId", h.setMemberRole)
+		g.DELETE("/:id/members/:userId", h.removeMember)
+
+		// คำเชิญ (admin ของทีมเท่านั้น) ฝั่งผู้ถูกเชิญอยู่ที่ /api/invitations
+		g.GET("/:id/invitations", h.listInvitations)
+		g.POST("/:id/invitations", h.invite)
+		g.POST("/:id/invitations/:inviteId/resend", h.resendInvitation)
+		g.DELETE("/:id/invitations/:inviteId", h.revokeInvitation)
+	}
+}
+
+func workspaceResponse(w *domain.Workspace) gin.H {
+	return gin.H{
+		"id":         w.ID,
+		"name":       w.Name,
+		"personal":   w.Personal(),
+		"role":       w.Role,
+		"created_at": w.CreatedAt,
+		"updated_at": w.UpdatedAt,
+	}
+}
+
+func workspaceMemberResponse(m domain.WorkspaceMember) gin.H {
+	var name any
+	if m.Name.Valid {
+		name = m.Name.String
+	}
+	return gin.H{
+		"user_id":   m.UserID,
+		"email":     m.Email,
+		"name":      name,
+		"role":      m.Role,
+		"joined_at": m.JoinedAt,
+	}
+}
+
+func workspaceIDParam(c *gin.Context) (int64, bool) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || id <= 0 {
+		response.Fail(c, http.StatusBadRequest, "invalid_workspace_id", "invalid workspace id")
+		return 0, false
+	}
+	return id, true
+}
+
+func memberIDParam(c *gin.Context) (int64, bool) {
+	id, err := strconv.ParseInt(c.Param("userId"), 10, 64)
+	if err != nil || id <= 0 {
+		response.Fail(c, http.StatusBadRequest, "invalid_user_id", "invalid user id")
+		return 0, false
+	}
+	return id, true
+}
+
+func invitationIDParam(c *gin.Context) (int64, bool) {
+	id, err := strconv.ParseInt(c.Param("inviteId"), 10, 64)
+	if err != nil || id <= 0 {
+		response.Fail(c, http.StatusBadRequest, "invalid_invitation_id", "invalid invitation id")
+		return 0, false
+	}
+	return id, true
+}
+
+type workspaceInput struct {
+	Name string `json:"name" binding:"required,notblank,max=100"`
+}
+
+func (h *WorkspaceHandler) list(c *gin.Context) {
+	userID, ok := authctx.UserID(c)
+	if !ok {
+		response.Fail(c, http.StatusUnauthorized, response.CodeUnauthorized, "unauthorized")
+		return
+	}
+
+	ws, err := h.Svc.List(c.Request.Context(), userID)
+	if err != nil {
+		response.Error(c, err, "failed to list workspaces")
+		return
+	}
+	out := make([]gin.H, 0, len(ws))
+	for _, w := range ws {
+		out = append(out, workspaceResponse(w))
+	}
+	response.OK(c, gin.H{"workspaces": out})
+}
+
+func (h *WorkspaceHandler) get(c *gin.Context) {
+	userID, ok := authctx.UserID(c)
+	if !ok {
+		response.Fail(c, http.StatusUnauthorized, response.CodeUnauthorized, "unauthorized")
+		return
+	}
+	id, ok := workspaceIDParam(c)
+	if !ok {
+		return
+	}
+
+	w, err := h.Svc.Get(c.Request.Context(), userID, id)
+	if err != nil {
+		response.Error(c, err, "failed to get workspace")
+		return
+	}
+	response.OK(c, workspaceResponse(w))
+}
+
+func (h *WorkspaceHandler) create(c *gin.Context) {
+	userID, ok := authctx.UserID(c)
+	if !ok {
+		response.Fail(c, http.StatusUnauthorized, response.CodeUnauthorized, "unauthorized")
+		return
+	}
+	var in workspaceInput
+	if !validate.Bind(c, &in) {
+		return
+	}
+
+	w, err := h.Svc.Create(c.Request.Context(), userID, in.Name)
+	if err != nil {
+		response.Error(c, err, "failed to create workspace")
+		return
+	}
+	response.Created(c, workspaceResponse(w))
+}
+
+func (h *WorkspaceHandler) rename(c *gin.Context) {
+	userID, ok := authctx.UserID(c)
+	if !ok {
+		response.Fail(c, http.StatusUnauthorized, response.CodeUnauthorized, "unauthorized")
+		return
+	}
+	id, ok := workspaceIDParam(c)
+	if !ok {
+		return
+	}
+	var in workspaceInput
+	if !validate.Bind(c, &in) {
+		return
+	}
+
+	w, err := h.Svc.Rename(c.Request.Context(), userID, id, in.Name)
+	if err != nil {
+		response.Error(c, err, "failed to rename workspace")
+		return
+	}
+	response.OK(c, workspaceResponse(w))
+}
+
+// delete ลบ workspace ของทีมพร้อมงานทั้งหมด (workspace ส่วนตัวลบไม่ได้)
+func (h *WorkspaceHandler) delete(c *gin.Context) {
+	userID, ok := authctx.UserID(c)
+	if !ok {
+		response.Fail(c, http.StatusUnauthorized, response.CodeUnauthorized, "unauthorized")
+		return
+	}
+	id, ok := workspaceIDParam(c)
+	if !ok {
+		return
+	}
+
+	if err := h.Svc.Delete(c.Request.Context(), userID, id); err != nil {
+		response.Error(c, err, "failed to delete workspace")
+		return
+	}
+	response.NoContent(c)
+}
+
+func (h *WorkspaceHandler) members(c *gin.Context) {
+	userID, ok := authctx.UserID(c)
+	if !ok {
+		response.Fail(c, http.StatusUnauthorized, response.CodeUnauthorized, "unauthorized")
+		return
+	}
+	id, ok := workspaceIDParam(c)
+	if !ok {
+		return
+	}
+
+	members, err := h.Svc.Members(c.Request.Context(), userID, id)
+	if err != nil {
+		response.Error(c, err, "failed to list members")
+		return
+	}
+	out := make([]gin.H, 0, len(members))
+	for _, m := range members {
+		out = append(out, workspaceMemberResponse(m))
+	}
+	response.OK(c, gin.H{"members": out})
+}
+
+func (h *WorkspaceHandler) setMemberRole(c *gin.Context) {
+	userID, ok := authctx.UserID(c)
+	if !ok {
+		response.Fail(c, http.StatusUnauthorized, response.CodeUnauthorized, "unauthorized")
+		return
+	}
+	id, ok := workspaceIDParam(c)
+	if !ok {
+		return
+	}
+	targetID, ok := memberIDParam(c)
+	if !ok {
+		return
+	}
+	var in struct {
+		Role string `json:"role" binding:"required,oneof=admin member"`
+	}
+	if !validate.Bind(c, &in) {
+		return
+	}
+
+	if err := h.Svc.SetMemberRole(c.Request.Context(), userID, id, targetID, in.Role); err != nil {
+		response.Error(c, err, "failed to update member")
+		return
+	}
+	response.OK(c, gin.H{"success": true})
+}
+
+// removeMember: admin เอาสมาชิกออก หรือสมาชิกออกจากทีมเอง (userId = ตัวเอง)
+func (h *WorkspaceHandler) removeMember(c *gin.Context) {
+	userID, ok := authctx.UserID(c)
+	if !ok {
+		response.Fail(c, http.StatusUnauthorized, response.CodeUnauthorized, "unauthorized")
+		return
+	}
+	id, ok := workspaceIDParam(c)
+	if !ok {
+		return
+	}
+	targetID, ok := memberIDParam(c)
+	if !ok {
+		return
+	}
+
+	if err := h.Svc.RemoveMember(c.Request.Context(), userID, id, targetID); err != nil {
+		response.Error(c, err, "failed to remove member")
+		return
+	}
+	response.NoContent(c)
+}
+
+func (h *WorkspaceHandler) listInvitations(c *gin.Context) {
+	userID, ok := authctx.UserID(c)
+	if !ok {
+		response.Fail(c, http.StatusUnauthorized, response.CodeUnauthorized, "unauthorized")
+		return
+	}
+	id, ok := workspaceIDParam(c)
+	if !ok {
+		return
+	}
+
+	invs, err := h.InviteSvc.ListPending(c.Request.Context(), userID, id)
+	if err != nil {
+		response.Error(c, err, "failed to list invitations")
+		return
+	}
+	out := make([]gin.H, 0, len(invs))
+	for _, inv := range invs {
+		out = append(out, invitationResponse(inv))
+	}
+	response.OK(c, gin.H{"invitations": out})
+}
+
+// invite ส่งคำเชิญทางอีเมล (เชิญอีเมลที่ยังรออยู่ซ้ำ = เปลี่ยน role แล้วส่งลิงก์ใหม่)
+func (h *WorkspaceHandler) invite(c *gin.Context) {
+	userID, ok := authctx.UserID(c)
+	if !ok {
+		response.Fail(c, http.StatusUnauthorized, response.CodeUnauthorized, "unauthorized")
+		return
+	}
+	id, ok := workspaceIDParam(c)
+	if !ok {
+		return
+	}
+	var in struct {
+		Email string `json:"email" binding:"required,email,max=254"`
+		Role  string `json:"role" binding:"required,oneof=admin member"`
+	}
+	if !validate.Bind(c, &in) {
+		return
+	}
+
+	inv, err := h.InviteSvc.Invite(c.Request.Context(), userID, id, in.Email, in.Role)
+	if err != nil {
+		response.Error(c, err, "failed to send invitation")
+		return
+	}
+	response.Created(c, invitationResponse(inv))
+}
+
+func (h *WorkspaceHandler) resendInvitation(c *gin.Context) {
+	userID, ok := authctx.UserID(c)
+	if !ok {
+		response.Fail(c, http.StatusUnauthorized, response.CodeUnauthorized, "unauthorized")
+		return
+	}
+	id, ok := workspaceIDParam(c)
+	if !ok {
+		return
+	}
+	inviteID, ok := invitationIDParam(c)
+	if !ok {
+		return
+	}
+
+	inv, err := h.InviteSvc.Resend(c.Request.Context(), userID, id, inviteID)
+	if err != nil {
+		response.Error(c, err, "failed to resend invitation")
+		return
+	}
+	response.OK(c, invitationResponse(inv))
+}
+
+func (h *WorkspaceHandler) revokeInvitation(c *gin.Context) {
+	userID, ok := authctx.UserID(c)
+	if !ok {
+		response.Fail(c, http.StatusUnauthorized, response.CodeUnauthorized, "unauthorized")
+		return
+	}
+	id, ok := workspaceIDParam(c)
+	if !ok {
+		return
+	}
+	inviteID, ok := invitationIDParam(c)
+	if !ok {
+		return
+	}
+
+	if err := h.InviteSvc.Revoke(c.Request.Context(), userID, id, inviteID); err != nil {
+		response.Error(c, err, "failed to revoke invitation")
+		return
+	}
+	response.NoContent(c)
+}
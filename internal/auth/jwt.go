@@ -1,51 +1,158 @@
 package auth
 
 import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
+	"strconv"
 	"time"
 
 	"task-manager/internal/config"
 
 	"github.com/golang-jwt/jwt/v5"
+	"golang.org/x/oauth2"
 )
 
+// Claims of an access token; RegisteredClaims.ID (jti) คือ session id
 type Claims struct {
 	UserID int64  `json:"uid"`
 	Role   string `json:"role"`
+	Locale string `json:"loc,omitempty"` // ภาษาที่ผู้ใช้ตั้งไว้ (ว่าง = ใช้ Accept-Language)
 	jwt.RegisteredClaims
 }
 
+// SessionID returns the session this access token belongs to
+func (c *Claims) SessionID() string { return c.ID }
+
+// RefreshClaims: sub = user id, jti = id ของแถวใน refresh_tokens, fam = token family
+type RefreshClaims struct {
+	FamilyID string `json:"fam"`
+	jwt.RegisteredClaims
+}
+
+// UserID returns the user id carried in sub
+func (c *RefreshClaims) UserID() (int64, error) {
+	return strconv.ParseInt(c.Subject, 10, 64)
+}
+
 type JWT interface {
-	GenerateAccessToken(userID int64, role string) (string, time.Duration, error)
-	GenerateRefreshToken(userID int64) (string, time.Duration, error)
+	GenerateAccessToken(userID int64, role, locale, sessionID string) (string, time.Duration, error)
+	GenerateRefreshToken(userID int64, jti, familyID string) (string, time.Duration, error)
 	ParseAccess(token string) (*Claims, error)
+	ParseRefresh(token string) (*RefreshClaims, error)
 	ValidateToken(token string) (*Claims, error)
+
+	// MFA pending token: รหัสผ่านถูกแล้ว รอ TOTP (ใช้เป็น access token ไม่ได้)
+	GenerateMFAToken(userID int64) (string, time.Duration, error)
+	ParseMFAToken(token string) (int64, error)
+
+	// OAuth state cookie: ผูก callback กับ browser ที่เริ่ม login (กัน login CSRF)
+	GenerateOAuthState(st *OAuthState) (string, time.Duration, error)
+	ParseOAuthState(token string) (*OAuthState, error)
+
+	// link ticket: ออกให้หลังยืนยันตัวตนซ้ำ ใช้เริ่ม OAuth เพื่อผูกบัญชี provider นั้นกับผู้ใช้
+	GenerateLinkTicket(userID int64, provider string) (string, time.Duration, error)
+	ParseLinkTicket(token, provider string) (int64, error)
+
+	// onboarding ticket: OAuth callback ออกให้เฉพาะผู้ใช้ที่เพิ่งสร้าง ใช้ตั้ง username/รหัสผ่านครั้งแรก
+	GenerateOnboardingTicket(userID int64) (string, time.Duration, error)
+	ParseOnboardingTicket(token string) (int64, error)
+
+	// ลิงก์เชิญเข้า workspace: sub = id ของคำเชิญ, jti = token id ปัจจุบันของแถวนั้น
+	// (ส่งซ้ำ = เปลี่ยน token id ลิงก์เก่าจึงใช้ไม่ได้ แม้ลายเซ็นยังไม่หมดอายุ)
+	GenerateInviteToken(invitationID int64, tokenID string, expiresAt time.Time) (string, error)
+	ParseInviteToken(token string) (invitationID int64, tokenID string, err error)
 }
 
+// OAuthState is kept in a signed, short-lived cookie between /{provider}/login and /callback
+type OAuthState struct {
+	State        string `json:"st"`  // ค่าที่ส่งไปกับ ?state= ต้องตรงกันตอน callback
+	Provider     string `json:"prv"` // กันเอา cookie ของ provider หนึ่งไปใช้กับอีกตัว
+	CodeVerifier string `json:"cv"`  // PKCE
+	Nonce        string `json:"nonce"`
+	Next         string `json:"next,omitempty"`
+	OnboardIfNew string `json:"onb,omitempty"`
+	LinkUserID   int64  `json:"lnk,omitempty"` // ไม่ใช่ 0 = ผูก identity กับผู้ใช้นี้แทนการ login
+	Invite       string `json:"inv,omitempty"` // token คำเชิญเข้าทีม รับให้หลัง login/สมัครสำเร็จ
+	jwt.RegisteredClaims
+}
+
+// NewOAuthState creates a fresh state/nonce/PKCE verifier for one login attempt
+func NewOAuthState(provider, next, onboardIfNew string) (*OAuthState, error) {
+	state, err := NewTokenID()
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := NewTokenID()
+	if err != nil {
+		return nil, err
+	}
+	return &OAuthState{
+		State:        state,
+		Provider:     provider,
+		CodeVerifier: oauth2.GenerateVerifier(),
+		Nonce:        nonce,
+		Next:         next,
+		OnboardIfNew: onboardIfNew,
+	}, nil
+}
+
+const (
+	// อายุของ MFA pending token
+	mfaTokenTTL = 5 * time.Minute
+	// เวลาที่ให้ผู้ใช้ล็อกอินที่ provider ให้เสร็จ
+	oauthStateTTL = 10 * time.Minute
+	// link ticket ใช้ทันทีหลังยืนยันตัวตน
+	linkTicketTTL = 2 * time.Minute
+	// เวลาที่ให้กรอกหน้า create account หลังสมัครด้วย provider
+	onboardingTicketTTL = 15 * time.Minute
+)
+
 type jwtImpl struct {
 	accessSecret  []byte
 	refreshSecret []byte
+	mfaSecret     []byte
+	stateSecret   []byte
+	linkSecret    []byte
+	onboardSecret []byte
+	inviteSecret  []byte
 	accessTTL     time.Duration
 	refreshTTL    time.Duration
 }
 
 func NewJWT(cfg config.Config) JWT {
+	// แยก key ของ MFA token / OAuth state / link ticket / onboarding ticket / คำเชิญ ออกจาก access token เพื่อไม่ให้ใช้แทนกันได้
 	return &jwtImpl{
 		accessSecret:  []byte(cfg.JWTAccessSecret),
 		refreshSecret: []byte(cfg.JWTRefreshSecret),
+		mfaSecret:     deriveKey(cfg.JWTAccessSecret, "mfa-pending"),
+		stateSecret:   deriveKey(cfg.JWTAccessSecret, "oauth-state"),
+		linkSecret:    deriveKey(cfg.JWTAccessSecret, "oauth-link"),
+		onboardSecret: deriveKey(cfg.JWTAccessSecret, "oauth-onboarding"),
+		inviteSecret:  deriveKey(cfg.JWTAccessSecret, "workspace-invite"),
 		accessTTL:     time.Duration(cfg.AccessTTLMin) * time.Minute,
 		refreshTTL:    time.Duration(cfg.RefreshTTLHours) * time.Hour,
 	}
 }
 
-func (j *jwtImpl) GenerateAccessToken(userID int64, role string) (string, time.Duration, error) {
+func deriveKey(secret, purpose string) []byte {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(purpose))
+	return mac.Sum(nil)
+}
+
+func (j *jwtImpl) GenerateAccessToken(userID int64, role, locale, sessionID string) (string, time.Duration, error) {
 	ttl := j.accessTTL
 	claims := &Claims{
 		UserID: userID,
 		Role:   role,
+		Locale: locale,
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(time.Now().Add(ttl)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			ID:        sessionID,
 		},
 	}
 	tok := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
@@ -53,13 +160,16 @@ func (j *jwtImpl) GenerateAccessToken(userID int64, role string) (string, time.D
 	return s, ttl, err
 }
 
-func (j *jwtImpl) GenerateRefreshToken(userID int64) (string, time.Duration, error) {
+func (j *jwtImpl) GenerateRefreshToken(userID int64, jti, familyID string) (string, time.Duration, error) {
 	ttl := j.refreshTTL
-	claims := jwt.RegisteredClaims{
-		ExpiresAt: jwt.NewNumericDate(time.Now().Add(ttl)),
-		IssuedAt:  jwt.NewNumericDate(time.Now()),
-		Subject:   "refresh",
-		ID:        "", // ถ้าต้องการ jti ค่อยเติม
+	claims := &RefreshClaims{
+		FamilyID: familyID,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(ttl)),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			Subject:   strconv.FormatInt(userID, 10),
+			ID:        jti,
+		},
 	}
 	tok := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 	s, err := tok.SignedString(j.refreshSecret)
@@ -79,6 +189,161 @@ func (j *jwtImpl) ParseAccess(tokenStr string) (*Claims, error) {
 	return nil, errors.New("invalid token")
 }
 
+func (j *jwtImpl) ParseRefresh(tokenStr string) (*RefreshClaims, error) {
+	tok, err := jwt.ParseWithClaims(tokenStr, &RefreshClaims{}, func(t *jwt.Token) (interface{}, error) {
+		return j.refreshSecret, nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}))
+	if err != nil {
+		return nil, err
+	}
+	if c, ok := tok.Claims.(*RefreshClaims); ok && tok.Valid && c.ID != "" && c.FamilyID != "" {
+		return c, nil
+	}
+	return nil, errors.New("invalid refresh token")
+}
+
+func (j *jwtImpl) GenerateMFAToken(userID int64) (string, time.Duration, error) {
+	claims := jwt.RegisteredClaims{
+		ExpiresAt: jwt.NewNumericDate(time.Now().Add(mfaTokenTTL)),
+		IssuedAt:  jwt.NewNumericDate(time.Now()),
+		Subject:   strconv.FormatInt(userID, 10),
+		Audience:  jwt.ClaimStrings{"mfa"},
+	}
+	tok := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	s, err := tok.SignedString(j.mfaSecret)
+	return s, mfaTokenTTL, err
+}
+
+func (j *jwtImpl) ParseMFAToken(tokenStr string) (int64, error) {
+	tok, err := jwt.ParseWithClaims(tokenStr, &jwt.RegisteredClaims{}, func(t *jwt.Token) (interface{}, error) {
+		return j.mfaSecret, nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}), jwt.WithAudience("mfa"))
+	if err != nil {
+		return 0, err
+	}
+	c, ok := tok.Claims.(*jwt.RegisteredClaims)
+	if !ok || !tok.Valid {
+		return 0, errors.New("invalid mfa token")
+	}
+	return strconv.ParseInt(c.Subject, 10, 64)
+}
+
+func (j *jwtImpl) GenerateOAuthState(st *OAuthState) (string, time.Duration, error) {
+	st.RegisteredClaims = jwt.RegisteredClaims{
+		ExpiresAt: jwt.NewNumericDate(time.Now().Add(oauthStateTTL)),
+		IssuedAt:  jwt.NewNumericDate(time.Now()),
+		Audience:  jwt.ClaimStrings{"oauth-state"},
+	}
+	tok := jwt.NewWithClaims(jwt.SigningMethodHS256, st)
+	s, err := tok.SignedString(j.stateSecret)
+	return s, oauthStateTTL, err
+}
+
+func (j *jwtImpl) ParseOAuthState(tokenStr string) (*OAuthState, error) {
+	tok, err := jwt.ParseWithClaims(tokenStr, &OAuthState{}, func(t *jwt.Token) (interface{}, error) {
+		return j.stateSecret, nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}), jwt.WithAudience("oauth-state"))
+	if err != nil {
+		return nil, err
+	}
+	st, ok := tok.Claims.(*OAuthState)
+	if !ok || !tok.Valid {
+		return nil, errors.New("invalid oauth state")
+	}
+	return st, nil
+}
+
+func (j *jwtImpl) GenerateLinkTicket(userID int64, provider string) (string, time.Duration, error) {
+	claims := jwt.RegisteredClaims{
+		ExpiresAt: jwt.NewNumericDate(time.Now().Add(linkTicketTTL)),
+		IssuedAt:  jwt.NewNumericDate(time.Now()),
+		Subject:   strconv.FormatInt(userID, 10),
+		Audience:  jwt.ClaimStrings{"link:" + provider},
+	}
+	tok := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	s, err := tok.SignedString(j.linkSecret)
+	return s, linkTicketTTL, err
+}
+
+func (j *jwtImpl) ParseLinkTicket(tokenStr, provider string) (int64, error) {
+	tok, err := jwt.ParseWithClaims(tokenStr, &jwt.RegisteredClaims{}, func(t *jwt.Token) (interface{}, error) {
+		return j.linkSecret, nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}), jwt.WithAudience("link:"+provider))
+	if err != nil {
+		return 0, err
+	}
+	c, ok := tok.Claims.(*jwt.RegisteredClaims)
+	if !ok || !tok.Valid {
+		return 0, errors.New("invalid link ticket")
+	}
+	return strconv.ParseInt(c.Subject, 10, 64)
+}
+
+func (j *jwtImpl) GenerateOnboardingTicket(userID int64) (string, time.Duration, error) {
+	claims := jwt.RegisteredClaims{
+		ExpiresAt: jwt.NewNumericDate(time.Now().Add(onboardingTicketTTL)),
+		IssuedAt:  jwt.NewNumericDate(time.Now()),
+		Subject:   strconv.FormatInt(userID, 10),
+		Audience:  jwt.ClaimStrings{"onboarding"},
+	}
+	tok := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	s, err := tok.SignedString(j.onboardSecret)
+	return s, onboardingTicketTTL, err
+}
+
+func (j *jwtImpl) ParseOnboardingTicket(tokenStr string) (int64, error) {
+	tok, err := jwt.ParseWithClaims(tokenStr, &jwt.RegisteredClaims{}, func(t *jwt.Token) (interface{}, error) {
+		return j.onboardSecret, nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}), jwt.WithAudience("onboarding"))
+	if err != nil {
+		return 0, err
+	}
+	c, ok := tok.Claims.(*jwt.RegisteredClaims)
+	if !ok || !tok.Valid {
+		return 0, errors.New("invalid onboarding ticket")
+	}
+	return strconv.ParseInt(c.Subject, 10, 64)
+}
+
+func (j *jwtImpl) GenerateInviteToken(invitationID int64, tokenID string, expiresAt time.Time) (string, error) {
+	claims := jwt.RegisteredClaims{
+		ExpiresAt: jwt.NewNumericDate(expiresAt),
+		IssuedAt:  jwt.NewNumericDate(time.Now()),
+		Subject:   strconv.FormatInt(invitationID, 10),
+		ID:        tokenID,
+		Audience:  jwt.ClaimStrings{"invite"},
+	}
+	tok := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	return tok.SignedString(j.inviteSecret)
+}
+
+func (j *jwtImpl) ParseInviteToken(tokenStr string) (int64, string, error) {
+	tok, err := jwt.ParseWithClaims(tokenStr, &jwt.RegisteredClaims{}, func(t *jwt.Token) (interface{}, error) {
+		return j.inviteSecret, nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}), jwt.WithAudience("invite"))
+	if err != nil {
+		return 0, "", err
+	}
+	c, ok := tok.Claims.(*jwt.RegisteredClaims)
+	if !ok || !tok.Valid || c.ID == "" {
+		return 0, "", errors.New("invalid invite token")
+	}
+	id, err := strconv.ParseInt(c.Subject, 10, 64)
+	if err != nil {
+		return 0, "", err
+	}
+	return id, c.ID, nil
+}
+
+// NewTokenID returns a random 128-bit hex id (ใช้เป็น jti / family id)
+func NewTokenID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
 // ValidateToken is an alias for ParseAccess for middleware compatibility
 func (j *jwtImpl) ValidateToken(token string) (*Claims, error) {
 	return j.ParseAccess(token)
@@ -0,0 +1,220 @@
+package auth
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"task-manager/internal/config"
+
+	"github.com/coreos/go-oidc/v3/oidc"
+	"golang.org/x/oauth2"
+)
+
+// ExternalUser is the identity an OIDC provider vouches for (อ่านจาก ID token ที่ตรวจลายเซ็นแล้ว)
+type ExternalUser struct {
+	Provider      string
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Name          string
+	Picture       string
+}
+
+// ProviderInfo is what the login page needs to draw a button
+type ProviderInfo struct {
+	Name        string `json:"name"`
+	DisplayName string `json:"display_name"`
+}
+
+// OIDCProvider is one configured issuer (Google, Keycloak, ...).
+// discovery ทำตอนใช้งานครั้งแรก issuer ล่มตอน start ก็ไม่ทำให้ server ล้ม
+type OIDCProvider struct {
+	cfg        config.OIDCProviderConfig
+	httpClient *http.Client
+
+	mu       sync.Mutex
+	oauth    *oauth2.Config
+	provider *oidc.Provider
+	verifier *oidc.IDTokenVerifier
+}
+
+func NewOIDCProvider(cfg config.OIDCProviderConfig) *OIDCProvider {
+	if cfg.DisplayName == "" {
+		cfg.DisplayName = cfg.Name
+	}
+	if len(cfg.Scopes) == 0 {
+		cfg.Scopes = []string{oidc.ScopeOpenID, "email", "profile"}
+	}
+	return &OIDCProvider{cfg: cfg, httpClient: &http.Client{Timeout: 30 * time.Second}}
+}
+
+func (p *OIDCProvider) Name() string { return p.cfg.Name }
+
+func (p *OIDCProvider) Info() ProviderInfo {
+	return ProviderInfo{Name: p.cfg.Name, DisplayName: p.cfg.DisplayName}
+}
+
+// discover โหลด /.well-known/openid-configuration และ JWKS (ครั้งเดียว ถ้าสำเร็จ)
+func (p *OIDCProvider) discover() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.provider != nil {
+		return nil
+	}
+
+	// ctx นี้ถูกเก็บไว้ใช้ดึง JWKS ภายหลัง จึงไม่ผูกกับ request (timeout มาจาก httpClient)
+	ctx := oidc.ClientContext(context.Background(), p.httpClient)
+	provider, err := oidc.NewProvider(ctx, p.cfg.Issuer)
+	if err != nil {
+		return fmt.Errorf("oidc discovery for %s: %w", p.cfg.Name, err)
+	}
+
+	p.provider = provider
+	p.verifier = provider.Verifier(&oidc.Config{ClientID: p.cfg.ClientID})
+	p.oauth = &oauth2.Config{
+		ClientID:     p.cfg.ClientID,
+		ClientSecret: p.cfg.ClientSecret,
+		RedirectURL:  p.cfg.RedirectURL,
+		Scopes:       p.cfg.Scopes,
+		Endpoint:     provider.Endpoint(),
+	}
+	return nil
+}
+
+// LoginURL สร้างลิงก์ไปหน้าล็อกอินของ provider พร้อม PKCE (S256) และ nonce
+func (p *OIDCProvider) LoginURL(state, nonce, codeVerifier string) (string, error) {
+	if err := p.discover(); err != nil {
+		return "", err
+	}
+	return p.oauth.AuthCodeURL(state, oidc.Nonce(nonce), oauth2.S256ChallengeOption(codeVerifier)), nil
+}
+
+// Exchange แลก code เป็น token ตรวจ ID token ผ่าน JWKS (และ nonce) แล้วคืนข้อมูลผู้ใช้
+func (p *OIDCProvider) Exchange(ctx context.Context, code, nonce, codeVerifier string) (*ExternalUser, error) {
+	if code == "" {
+		return nil, errors.New("empty code")
+	}
+	if err := p.discover(); err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+	ctx = oidc.ClientContext(ctx, p.httpClient)
+
+	tok, err := p.oauth.Exchange(ctx, code, oauth2.VerifierOption(codeVerifier))
+	if err != nil {
+		return nil, err
+	}
+	rawIDToken, ok := tok.Extra("id_token").(string)
+	if !ok || rawIDToken == "" {
+		return nil, errors.New("oidc: token response has no id_token")
+	}
+	idToken, err := p.verifier.Verify(ctx, rawIDToken)
+	if err != nil {
+		return nil, err
+	}
+	if subtle.ConstantTimeCompare([]byte(idToken.Nonce), []byte(nonce)) != 1 {
+		return nil, errors.New("oidc: id token nonce mismatch")
+	}
+
+	var claims oidcClaims
+	if err := idToken.Claims(&claims); err != nil {
+		return nil, err
+	}
+	// บาง provider (เช่น Keycloak บางค่าตั้ง) ไม่ใส่ email ใน ID token ไปถามจาก userinfo แทน
+	if claims.Email == "" {
+		info, err := p.provider.UserInfo(ctx, oauth2.StaticTokenSource(tok))
+		if err != nil {
+			return nil, err
+		}
+		if info.Subject != idToken.Subject {
+			return nil, errors.New("oidc: userinfo subject mismatch")
+		}
+		if err := info.Claims(&claims); err != nil {
+			return nil, err
+		}
+	}
+	if claims.Email == "" {
+		return nil, errors.New("oidc: provider did not return an email")
+	}
+
+	return &ExternalUser{
+		Provider:      p.cfg.Name,
+		Subject:       idToken.Subject,
+		Email:         claims.Email,
+		EmailVerified: bool(claims.EmailVerified),
+		Name:          claims.Name,
+		Picture:       claims.Picture,
+	}, nil
+}
+
+type oidcClaims struct {
+	Email         string   `json:"email"`
+	EmailVerified flexBool `json:"email_verified"`
+	Name          string   `json:"name"`
+	Picture       string   `json:"picture"`
+}
+
+// flexBool รับได้ทั้ง true และ "true" (บาง provider ส่ง email_verified เป็น string)
+type flexBool bool
+
+func (b *flexBool) UnmarshalJSON(data []byte) error {
+	var v interface{}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	switch t := v.(type) {
+	case bool:
+		*b = flexBool(t)
+	case string:
+		*b = flexBool(t == "true")
+	default:
+		*b = false
+	}
+	return nil
+}
+
+// OIDCRegistry holds the providers enabled by config
+type OIDCRegistry struct {
+	providers map[string]*OIDCProvider
+	order     []string
+}
+
+// NewOIDCRegistry ข้าม provider ที่ตั้งค่าไม่ครบ (log ไว้) แทนการ panic
+func NewOIDCRegistry(cfgs []config.OIDCProviderConfig) *OIDCRegistry {
+	r := &OIDCRegistry{providers: map[string]*OIDCProvider{}}
+	for _, c := range cfgs {
+		if c.Name == "" || c.Issuer == "" || c.ClientID == "" || c.RedirectURL == "" {
+			log.Printf("oidc provider %q is missing issuer/client id/redirect url, skipped", c.Name)
+			continue
+		}
+		if _, dup := r.providers[c.Name]; dup {
+			log.Printf("oidc provider %q configured twice, skipped", c.Name)
+			continue
+		}
+		r.providers[c.Name] = NewOIDCProvider(c)
+		r.order = append(r.order, c.Name)
+	}
+	return r
+}
+
+func (r *OIDCRegistry) Get(name string) (*OIDCProvider, bool) {
+	p, ok := r.providers[name]
+	return p, ok
+}
+
+func (r *OIDCRegistry) List() []ProviderInfo {
+	out := make([]ProviderInfo, 0, len(r.order))
+	for _, name := range r.order {
+		out = append(out, r.providers[name].Info())
+	}
+	return out
+}
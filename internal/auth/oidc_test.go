@@ -0,0 +1,166 @@
+package auth
+
+import (
+	"context"
+	"net/url"
+	"testing"
+
+	"task-manager/internal/auth/oidctest"
+	"task-manager/internal/config"
+)
+
+func newTestProvider(iss *oidctest.Issuer) *OIDCProvider {
+	return NewOIDCProvider(config.OIDCProviderConfig{
+		Name:         "mock",
+		Issuer:       iss.URL,
+		ClientID:     iss.ClientID,
+		ClientSecret: iss.ClientSecret,
+		RedirectURL:  "http://app.test/api/auth/oauth/mock/callback",
+	})
+}
+
+// signIn ทำ flow เต็ม: LoginURL -> ผู้ใช้กดยอมรับที่ issuer -> Exchange ด้วย code ที่ได้กลับมา
+func signIn(t *testing.T, iss *oidctest.Issuer, p *OIDCProvider, u oidctest.User, exchangeNonce, exchangeVerifier string) (*ExternalUser, error) {
+	t.Helper()
+	loginURL, err := p.LoginURL("state-1", "nonce-1", "verifier-0123456789-0123456789-0123456789")
+	if err != nil {
+		t.Fatalf("LoginURL: %v", err)
+	}
+	back := iss.Approve(t, loginURL, u)
+	if got := back.Query().Get("state"); got != "state-1" {
+		t.Fatalf("state = %q, want state-1", got)
+	}
+	return p.Exchange(context.Background(), back.Query().Get("code"), exchangeNonce, exchangeVerifier)
+}
+
+func TestOIDCProviderLoginURL(t *testing.T) {
+	iss := oidctest.New(t)
+	p := newTestProvider(iss)
+
+	raw, err := p.LoginURL("st", "nn", "verifier-0123456789-0123456789-0123456789")
+	if err != nil {
+		t.Fatal(err)
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	q := u.Query()
+	if u.Scheme+"://"+u.Host+u.Path != iss.URL+"/authorize" {
+		t.Errorf("authorize endpoint = %s", u.Path)
+	}
+	want := map[string]string{
+		"client_id":             iss.ClientID,
+		"state":                 "st",
+		"nonce":                 "nn",
+		"code_challenge_method": "S256",
+		"scope":                 "openid email profile",
+	}
+	for k, v := range want {
+		if q.Get(k) != v {
+			t.Errorf("%s = %q, want %q", k, q.Get(k), v)
+		}
+	}
+	if q.Get("code_challenge") == "" {
+		t.Error("missing code_challenge")
+	}
+}
+
+func TestOIDCProviderExchange(t *testing.T) {
+	const verifier = "verifier-0123456789-0123456789-0123456789"
+	alice := oidctest.User{Subject: "sub-alice", Email: "alice@example.com", EmailVerified: true, Name: "Alice"}
+
+	tests := []struct {
+		name         string
+		user         oidctest.User
+		nonce        string
+		verifier     string
+		wantErr      bool
+		wantVerified bool
+	}{
+		{name: "ok", user: alice, nonce: "nonce-1", verifier: verifier, wantVerified: true},
+		{
+			name:         "email_verified as string",
+			user:         oidctest.User{Subject: "sub-bob", Email: "bob@example.com", EmailVerified: "true"},
+			nonce:        "nonce-1",
+			verifier:     verifier,
+			wantVerified: true,
+		},
+		{
+			name:     "unverified email",
+			user:     oidctest.User{Subject: "sub-carol", Email: "carol@example.com", EmailVerified: false},
+			nonce:    "nonce-1",
+			verifier: verifier,
+		},
+		{
+			name:         "email only in userinfo",
+			user:         oidctest.User{Subject: "sub-dave", Email: "dave@example.com", EmailVerified: true, EmailInUserInfoOnly: true},
+			nonce:        "nonce-1",
+			verifier:     verifier,
+			wantVerified: true,
+		},
+		{name: "nonce mismatch", user: alice, nonce: "other-nonce", verifier: verifier, wantErr: true},
+		{name: "wrong pkce verifier", user: alice, nonce: "nonce-1", verifier: "verifier-wrong-0123456789-0123456789-01", wantErr: true},
+		{name: "no email", user: oidctest.User{Subject: "sub-eve", EmailVerified: true}, nonce: "nonce-1", verifier: verifier, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			iss := oidctest.New(t)
+			p := newTestProvider(iss)
+
+			ext, err := signIn(t, iss, p, tt.user, tt.nonce, tt.verifier)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("Exchange succeeded, want error (got %+v)", ext)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Exchange: %v", err)
+			}
+			if ext.Provider != "mock" || ext.Subject != tt.user.Subject || ext.Email != tt.user.Email {
+				t.Errorf("got %+v", ext)
+			}
+			if ext.EmailVerified != tt.wantVerified {
+				t.Errorf("EmailVerified = %v, want %v", ext.EmailVerified, tt.wantVerified)
+			}
+		})
+	}
+}
+
+// ID token ที่ออกให้ client อื่น (aud ไม่ตรง) ต้องไม่ผ่าน
+func TestOIDCProviderRejectsOtherAudience(t *testing.T) {
+	const verifier = "verifier-0123456789-0123456789-0123456789"
+	iss := oidctest.New(t)
+	iss.Audience = "another-client"
+	p := newTestProvider(iss)
+
+	loginURL, err := p.LoginURL("s", "n", verifier)
+	if err != nil {
+		t.Fatal(err)
+	}
+	back := iss.Approve(t, loginURL, oidctest.User{Subject: "x", Email: "x@example.com", EmailVerified: true})
+	if _, err := p.Exchange(context.Background(), back.Query().Get("code"), "n", verifier); err == nil {
+		t.Fatal("Exchange accepted an id token issued to another client")
+	}
+}
+
+// code ใช้ได้ครั้งเดียว
+func TestOIDCProviderCodeReplay(t *testing.T) {
+	const verifier = "verifier-0123456789-0123456789-0123456789"
+	iss := oidctest.New(t)
+	p := newTestProvider(iss)
+
+	loginURL, err := p.LoginURL("s", "n", verifier)
+	if err != nil {
+		t.Fatal(err)
+	}
+	code := iss.Approve(t, loginURL, oidctest.User{Subject: "x", Email: "x@example.com", EmailVerified: true}).Query().Get("code")
+	if _, err := p.Exchange(context.Background(), code, "n", verifier); err != nil {
+		t.Fatalf("first exchange: %v", err)
+	}
+	if _, err := p.Exchange(context.Background(), code, "n", verifier); err == nil {
+		t.Fatal("second exchange with the same code succeeded")
+	}
+}
@@ -1,20 +1,152 @@
 package auth
 
-import "golang.org/x/crypto/bcrypt"
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
 
 type PasswordHasher interface {
 	Hash(pw string) (string, error)
+
+	// Compare ตรวจได้ทุกรูปแบบที่เคยใช้ (argon2id, bcrypt) ไม่ขึ้นกับว่าตอนนี้ตั้งค่าเป็นแบบไหน
 	Compare(hash, pw string) error
+
+	// NeedsRehash บอกว่า hash นี้เป็นรูปแบบเก่า/ค่าอ่อนกว่าที่ตั้งไว้ ควร hash ใหม่ตอน login สำเร็จ
+	NeedsRehash(hash string) bool
+}
+
+// ErrPasswordMismatch is returned by Compare when the password is wrong
+var ErrPasswordMismatch = errors.New("password does not match")
+
+var errUnknownHash = errors.New("unknown password hash format")
+
+// Argon2Params tunes Argon2id (ค่าเริ่มต้นตาม RFC 9106 ข้อแนะนำที่สอง: 64 MiB, 3 รอบ)
+type Argon2Params struct {
+	MemoryKiB   uint32
+	Iterations  uint32
+	Parallelism uint8
+	SaltLength  uint32
+	KeyLength   uint32
 }
 
-type bcryptHasher struct{}
+var DefaultArgon2Params = Argon2Params{
+	MemoryKiB:   64 * 1024,
+	Iterations:  3,
+	Parallelism: 2,
+	SaltLength:  16,
+	KeyLength:   32,
+}
+
+// NewPasswordHasher returns the default hasher (Argon2id with DefaultArgon2Params)
+func NewPasswordHasher() PasswordHasher { return NewArgon2idHasher(DefaultArgon2Params) }
+
+type argon2idHasher struct{ p Argon2Params }
+
+func NewArgon2idHasher(p Argon2Params) PasswordHasher { return &argon2idHasher{p: p} }
+
+// Hash คืนรูปแบบ PHC: $argon2id$v=19$m=65536,t=3,p=2$<salt>$<key> (base64 ไม่มี padding)
+func (h *argon2idHasher) Hash(pw string) (string, error) {
+	salt := make([]byte, h.p.SaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+	key := argon2.IDKey([]byte(pw), salt, h.p.Iterations, h.p.MemoryKiB, h.p.Parallelism, h.p.KeyLength)
+	return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2.Version, h.p.MemoryKiB, h.p.Iterations, h.p.Parallelism,
+		b64raw.EncodeToString(salt), b64raw.EncodeToString(key)), nil
+}
 
-func NewPasswordHasher() PasswordHasher { return &bcryptHasher{} }
+func (h *argon2idHasher) Compare(hash, pw string) error { return comparePassword(hash, pw) }
 
-func (*bcryptHasher) Hash(pw string) (string, error) {
-	b, err := bcrypt.GenerateFromPassword([]byte(pw), bcrypt.DefaultCost)
+func (h *argon2idHasher) NeedsRehash(hash string) bool {
+	p, _, _, err := decodeArgon2id(hash)
+	if err != nil {
+		return true
+	}
+	return p.MemoryKiB < h.p.MemoryKiB || p.Iterations < h.p.Iterations ||
+		p.Parallelism < h.p.Parallelism || p.KeyLength < h.p.KeyLength
+}
+
+type bcryptHasher struct{ cost int }
+
+// NewBcryptHasher keeps producing bcrypt hashes (cost 0 = bcrypt.DefaultCost)
+func NewBcryptHasher(cost int) PasswordHasher {
+	if cost == 0 {
+		cost = bcrypt.DefaultCost
+	}
+	return &bcryptHasher{cost: cost}
+}
+
+func (h *bcryptHasher) Hash(pw string) (string, error) {
+	b, err := bcrypt.GenerateFromPassword([]byte(pw), h.cost)
 	return string(b), err
 }
-func (*bcryptHasher) Compare(hash, pw string) error {
-	return bcrypt.CompareHashAndPassword([]byte(hash), []byte(pw))
+
+func (h *bcryptHasher) Compare(hash, pw string) error { return comparePassword(hash, pw) }
+
+func (h *bcryptHasher) NeedsRehash(hash string) bool {
+	cost, err := bcrypt.Cost([]byte(hash))
+	return err != nil || cost < h.cost
+}
+
+var b64raw = base64.RawStdEncoding
+
+// comparePassword เลือกวิธีตรวจจาก prefix ของ hash
+func comparePassword(hash, pw string) error {
+	switch {
+	case strings.HasPrefix(hash, "$argon2id$"):
+		p, salt, key, err := decodeArgon2id(hash)
+		if err != nil {
+			return err
+		}
+		got := argon2.IDKey([]byte(pw), salt, p.Iterations, p.MemoryKiB, p.Parallelism, p.KeyLength)
+		if subtle.ConstantTimeCompare(got, key) != 1 {
+			return ErrPasswordMismatch
+		}
+		return nil
+	case strings.HasPrefix(hash, "$2a$"), strings.HasPrefix(hash, "$2b$"), strings.HasPrefix(hash, "$2y$"):
+		if err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(pw)); err != nil {
+			if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+				return ErrPasswordMismatch
+			}
+			return err
+		}
+		return nil
+	default:
+		return errUnknownHash
+	}
+}
+
+func decodeArgon2id(hash string) (Argon2Params, []byte, []byte, error) {
+	var p Argon2Params
+	// "", "argon2id", "v=19", "m=..,t=..,p=..", salt, key
+	parts := strings.Split(hash, "$")
+	if len(parts) != 6 || parts[1] != "argon2id" {
+		return p, nil, nil, errUnknownHash
+	}
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return p, nil, nil, errUnknownHash
+	}
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &p.MemoryKiB, &p.Iterations, &p.Parallelism); err != nil ||
+		p.MemoryKiB == 0 || p.Iterations == 0 || p.Parallelism == 0 {
+		return p, nil, nil, errUnknownHash
+	}
+	salt, err := b64raw.DecodeString(parts[4])
+	if err != nil {
+		return p, nil, nil, errUnknownHash
+	}
+	key, err := b64raw.DecodeString(parts[5])
+	if err != nil || len(key) == 0 {
+		return p, nil, nil, errUnknownHash
+	}
+	p.SaltLength, p.KeyLength = uint32(len(salt)), uint32(len(key))
+	return p, salt, key, nil
 }
@@ -0,0 +1,100 @@
+package auth
+
+import (
+	"bufio"
+	_ "embed"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"task-manager/internal/domain"
+)
+
+//go:embed common_passwords.txt
+var commonPasswordsFile string
+
+// commonPasswords โหลดครั้งเดียวตอน init (ตัวพิมพ์เล็ก)
+var commonPasswords = func() map[string]struct{} {
+	m := map[string]struct{}{}
+	sc := bufio.NewScanner(strings.NewReader(commonPasswordsFile))
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		m[strings.ToLower(line)] = struct{}{}
+	}
+	return m
+}()
+
+// PasswordPolicy checks new passwords before they are hashed
+type PasswordPolicy struct {
+	MinLength int // จำนวนตัวอักษร
+	MaxLength int // กัน hash ข้อความยาวมากๆ (0 = ไม่จำกัด)
+	MaxBytes  int // ความยาวเป็น byte (bcrypt รับได้แค่ BcryptMaxPasswordBytes) 0 = ไม่จำกัด
+}
+
+// BcryptMaxPasswordBytes คือความยาวสูงสุดที่ bcrypt รับ (ยาวกว่านี้ GenerateFromPassword คืน error)
+const BcryptMaxPasswordBytes = 72
+
+// ForHasher ปรับ policy ให้เข้ากับ hasher ที่ใช้ hash รหัสผ่านใหม่ (PASSWORD_HASHER)
+func (p PasswordPolicy) ForHasher(name string) PasswordPolicy {
+	if name == "bcrypt" {
+		p.MaxBytes = BcryptMaxPasswordBytes
+	}
+	return p
+}
+
+var DefaultPasswordPolicy = PasswordPolicy{MinLength: 8, MaxLength: 128}
+
+// PasswordPolicyError says why a password was rejected (errors.Is(err, domain.ErrWeakPassword))
+// Code ใช้เลือกข้อความแปล ส่วน Reason เป็นข้อความภาษาอังกฤษเดิม
+type PasswordPolicyError struct {
+	Code   string // password_too_short, password_too_long(_bytes), password_too_common, password_matches_identity
+	Reason string
+	Limit  int // ความยาวที่เกี่ยวข้อง (too_short / too_long)
+}
+
+func (e *PasswordPolicyError) Error() string { return e.Reason }
+func (e *PasswordPolicyError) Unwrap() error { return domain.ErrWeakPassword }
+
+// I18nKey implements i18n.Keyed
+func (e *PasswordPolicyError) I18nKey() (string, []any) {
+	if e.Limit > 0 {
+		return e.Code, []any{e.Limit}
+	}
+	return e.Code, nil
+}
+
+// Validate ตรวจความยาว รายการรหัสผ่านยอดนิยม และห้ามตรงกับ userInputs (อีเมล / username)
+func (p PasswordPolicy) Validate(pw string, userInputs ...string) error {
+	n := utf8.RuneCountInString(pw)
+	if n < p.MinLength {
+		return &PasswordPolicyError{Code: "password_too_short", Limit: p.MinLength,
+			Reason: fmt.Sprintf("password must be at least %d characters", p.MinLength)}
+	}
+	if p.MaxLength > 0 && n > p.MaxLength {
+		return &PasswordPolicyError{Code: "password_too_long", Limit: p.MaxLength,
+			Reason: fmt.Sprintf("password must be at most %d characters", p.MaxLength)}
+	}
+	if p.MaxBytes > 0 && len(pw) > p.MaxBytes {
+		return &PasswordPolicyError{Code: "password_too_long_bytes", Limit: p.MaxBytes,
+			Reason: fmt.Sprintf("password must be at most %d bytes", p.MaxBytes)}
+	}
+
+	lower := strings.ToLower(pw)
+	if _, ok := commonPasswords[lower]; ok {
+		return &PasswordPolicyError{Code: "password_too_common", Reason: "password is too common, please choose another one"}
+	}
+	for _, in := range userInputs {
+		in = strings.ToLower(strings.TrimSpace(in))
+		if in == "" {
+			continue
+		}
+		local, _, _ := strings.Cut(in, "@")
+		if lower == in || lower == local {
+			return &PasswordPolicyError{Code: "password_matches_identity", Reason: "password must not be the same as your username or email"}
+		}
+	}
+	return nil
+}
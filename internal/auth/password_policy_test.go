@@ -0,0 +1,31 @@
+package auth
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"task-manager/internal/domain"
+)
+
+func TestPasswordPolicyForBcryptLimitsBytes(t *testing.T) {
+	// 30 ตัวอักษรไทย = 90 byte: ไม่เกิน MaxLength แต่เกินที่ bcrypt รับได้
+	thai := strings.Repeat("ก", 30)
+
+	if err := DefaultPasswordPolicy.ForHasher("argon2id").Validate(thai); err != nil {
+		t.Fatalf("argon2id policy rejected a 30-character password: %v", err)
+	}
+
+	bcryptPolicy := DefaultPasswordPolicy.ForHasher("bcrypt")
+	err := bcryptPolicy.Validate(thai)
+	var pe *PasswordPolicyError
+	if !errors.As(err, &pe) || pe.Code != "password_too_long_bytes" || !errors.Is(err, domain.ErrWeakPassword) {
+		t.Fatalf("bcrypt policy = %v, want password_too_long_bytes", err)
+	}
+	if err := bcryptPolicy.Validate(strings.Repeat("ก", 24)); err != nil {
+		t.Fatalf("bcrypt policy rejected 72 bytes: %v", err)
+	}
+	if _, err := NewBcryptHasher(4).Hash(strings.Repeat("ก", 24)); err != nil {
+		t.Fatalf("bcrypt could not hash a password the policy accepts: %v", err)
+	}
+}
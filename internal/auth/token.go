@@ -0,0 +1,38 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+)
+
+// NewOpaqueToken returns a random URL-safe token (ส่งให้ผู้ใช้) and its hash (เก็บใน DB)
+func NewOpaqueToken() (raw, hash string, err error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", err
+	}
+	raw = base64.RawURLEncoding.EncodeToString(b)
+	return raw, HashToken(raw), nil
+}
+
+// HashToken is the lookup key for an opaque token; DB ไม่เคยเห็น token จริง
+func HashToken(raw string) string {
+	sum := sha256.Sum256([]byte(raw))
+	return hex.EncodeToString(sum[:])
+}
+
+// PersonalTokenPrefix marks personal access tokens so middleware can tell them from JWTs
+// (และให้ secret scanner จับได้ถ้าหลุดขึ้น repo)
+const PersonalTokenPrefix = "tm_pat_"
+
+// NewPersonalToken returns a personal access token and its hash
+func NewPersonalToken() (raw, hash string, err error) {
+	raw, _, err = NewOpaqueToken()
+	if err != nil {
+		return "", "", err
+	}
+	raw = PersonalTokenPrefix + raw
+	return raw, HashToken(raw), nil
+}
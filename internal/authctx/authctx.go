@@ -0,0 +1,71 @@
+// Package authctx carries the authenticated caller through gin.Context with typed getters,
+// so middleware and handlers agree on key names and ID types (แทน c.Get("userID").(int64))
+package authctx
+
+import (
+	"task-manager/internal/domain"
+
+	"github.com/gin-gonic/gin"
+)
+
+// วิธียืนยันตัวตนของ request
+const (
+	MethodSession = "session" // access token (JWT) จากการ login
+	MethodToken   = "token"   // personal access token
+)
+
+// Principal is who is calling, set once by JWTMiddleware
+type Principal struct {
+	UserID    int64
+	Role      string
+	Method    string
+	SessionID string   // ว่างถ้าเป็น personal access token
+	Scopes    []string // ใช้เฉพาะ MethodToken
+}
+
+func (p *Principal) IsSession() bool { return p.Method == MethodSession }
+
+// HasScope: login session ผ่านทุก scope, personal access token ต้องได้รับ scope นั้น
+func (p *Principal) HasScope(scope string) bool {
+	if p.IsSession() {
+		return true
+	}
+	for _, s := range p.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *Principal) Can(perm domain.Permission) bool { return domain.HasPermission(p.Role, perm) }
+
+const principalKey = "authctx.principal"
+
+func Set(c *gin.Context, p *Principal) { c.Set(principalKey, p) }
+
+// From returns the principal, false ถ้า route ไม่ได้ผ่าน JWTMiddleware
+func From(c *gin.Context) (*Principal, bool) {
+	v, ok := c.Get(principalKey)
+	if !ok {
+		return nil, false
+	}
+	p, ok := v.(*Principal)
+	return p, ok && p != nil && p.UserID > 0
+}
+
+func UserID(c *gin.Context) (int64, bool) {
+	p, ok := From(c)
+	if !ok {
+		return 0, false
+	}
+	return p.UserID, true
+}
+
+// SessionID ของ login ปัจจุบัน ("" ถ้าไม่มี)
+func SessionID(c *gin.Context) string {
+	if p, ok := From(c); ok {
+		return p.SessionID
+	}
+	return ""
+}
@@ -1,8 +1,12 @@
 package config
 
 import (
+	"fmt"
 	"os"
 	"strconv"
+	"strings"
+
+	"golang.org/x/crypto/bcrypt"
 )
 
 type Config struct {
@@ -16,10 +20,68 @@ type Config struct {
 	// เพิ่มสองฟิลด์นี้
 	GinMode     string
 	FrontendURL string
+
+	// SMTP (ว่าง = พิมพ์อีเมลลง log แทนการส่ง)
+	SMTPHost     string
+	SMTPPort     int
+	SMTPUsername string
+	SMTPPassword string
+	MailFrom     string
+
+	PasswordResetTTLMin int
+
+	// URL สาธารณะของ API (ใช้สร้างลิงก์ในอีเมลที่ชี้กลับมาที่ backend)
+	PublicURL string
+
+	// true = ผู้ใช้ที่ยังไม่ยืนยันอีเมลใช้งาน task/team ไม่ได้
+	RequireVerifiedEmail bool
+	EmailVerifyTTLHours  int
+	EmailVerifyResendMin int
+	InviteTTLHours       int
+
+	// WebAuthn relying party (ว่าง = ใช้ host ของ FRONTEND_URL / origin ของ FRONTEND_URL และ PUBLIC_URL)
+	WebAuthnRPID    string
+	WebAuthnOrigins []string
+
+	// OIDC login providers (Google + อะไรก็ได้ที่รองรับ discovery เช่น Keycloak)
+	OIDCProviders []OIDCProviderConfig
+
+	// origin ที่ ?next= หลัง OAuth login redirect ไปได้ (ว่าง = FRONTEND_URL เท่านั้น)
+	OAuthNextOrigins []string
+
+	// ที่เก็บตัวนับกัน brute-force: "db" (ใช้ร่วมกันทุก replica) หรือ "memory" (instance เดียว)
+	RateLimitStore string
+
+	// IP / CIDR ของ reverse proxy ที่เชื่อ X-Forwarded-For ได้ (ว่าง = ไม่เชื่อ ใช้ IP ที่ต่อเข้ามาตรงๆ)
+	// อยู่หลัง proxy แล้วไม่ตั้ง = ทุก request มี IP ของ proxy ตัวนับต่อ IP จะล็อกทุกคนพร้อมกัน
+	TrustedProxies []string
+
+	// hash รหัสผ่านใหม่ด้วย "argon2id" (ค่าเริ่มต้น) หรือ "bcrypt"; hash เดิมตรวจได้ทุกแบบและถูก rehash ตอน login
+	PasswordHasher    string
+	Argon2MemoryKiB   int
+	Argon2Iterations  int
+	Argon2Parallelism int
+	BcryptCost        int
+	PasswordMinLength int
+
+	// ลบงานที่มีงานย่อยโดยไม่ได้ระบุ ?children=: "orphan" (งานย่อยกลายเป็นงานระดับบน) หรือ "cascade" (ลบทั้งต้นไม้)
+	TaskDeleteChildren string
+}
+
+// OIDCProviderConfig is one login provider, route: /api/auth/{Name}/login
+type OIDCProviderConfig struct {
+	Name         string
+	DisplayName  string
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	Scopes       []string
 }
 
 func MustLoad() Config {
-	return Config{
+	publicURL := get("PUBLIC_URL", "http://localhost:8080")
+	cfg := Config{
 		Port:             get("APP_PORT", "8080"),
 		DBDSN:            must("DB_DSN"),
 		JWTAccessSecret:  must("JWT_ACCESS_SECRET"),
@@ -28,7 +90,102 @@ func MustLoad() Config {
 		RefreshTTLHours:  atoi(get("JWT_REFRESH_TTL_HR", "168")),
 		GinMode:          get("GIN_MODE", "release"),
 		FrontendURL:      get("FRONTEND_URL", "http://localhost:5173"),
+
+		SMTPHost:     get("SMTP_HOST", ""),
+		SMTPPort:     atoi(get("SMTP_PORT", "1025")),
+		SMTPUsername: get("SMTP_USERNAME", ""),
+		SMTPPassword: get("SMTP_PASSWORD", ""),
+		MailFrom:     get("MAIL_FROM", "Task Manager <no-reply@localhost>"),
+
+		PasswordResetTTLMin: atoi(get("PASSWORD_RESET_TTL_MIN", "30")),
+
+		PublicURL: publicURL,
+
+		RequireVerifiedEmail: atob(get("REQUIRE_VERIFIED_EMAIL", "false")),
+		EmailVerifyTTLHours:  atoi(get("EMAIL_VERIFY_TTL_HR", "48")),
+		EmailVerifyResendMin: atoi(get("EMAIL_VERIFY_RESEND_MIN", "1")),
+		InviteTTLHours:       atoi(get("INVITE_TTL_HR", "168")),
+
+		WebAuthnRPID:    get("WEBAUTHN_RP_ID", ""),
+		WebAuthnOrigins: list(get("WEBAUTHN_ORIGINS", "")),
+
+		OIDCProviders:    loadOIDCProviders(publicURL),
+		OAuthNextOrigins: list(get("OAUTH_NEXT_ORIGINS", "")),
+
+		RateLimitStore: get("RATE_LIMIT_STORE", "db"),
+		TrustedProxies: list(get("TRUSTED_PROXIES", "")),
+
+		PasswordHasher:    get("PASSWORD_HASHER", "argon2id"),
+		Argon2MemoryKiB:   atoi(get("ARGON2_MEMORY_KIB", "65536")),
+		Argon2Iterations:  atoi(get("ARGON2_ITERATIONS", "3")),
+		Argon2Parallelism: atoi(get("ARGON2_PARALLELISM", "2")),
+		BcryptCost:        atoi(get("BCRYPT_COST", "10")),
+		PasswordMinLength: atoi(get("PASSWORD_MIN_LENGTH", "8")),
+
+		TaskDeleteChildren: get("TASK_DELETE_CHILDREN", "orphan"),
+	}
+	if err := cfg.checkPasswordHashing(); err != nil {
+		panic(err.Error())
+	}
+	return cfg
+}
+
+// checkPasswordHashing ตรวจค่าของ hasher ที่เลือกตอนเริ่ม (ค่าผิดจะไม่พังจนกว่าจะมีคนสมัคร/เปลี่ยนรหัสผ่าน)
+func (c Config) checkPasswordHashing() error {
+	switch c.PasswordHasher {
+	case "argon2id":
+		if c.Argon2Parallelism < 1 || c.Argon2Parallelism > 255 {
+			return fmt.Errorf("invalid ARGON2_PARALLELISM %d (want 1-255)", c.Argon2Parallelism)
+		}
+		if c.Argon2Iterations < 1 {
+			return fmt.Errorf("invalid ARGON2_ITERATIONS %d (want at least 1)", c.Argon2Iterations)
+		}
+		// argon2 ต้องการหน่วยความจำอย่างน้อย 8 KiB ต่อ lane
+		if c.Argon2MemoryKiB < 8*c.Argon2Parallelism || c.Argon2MemoryKiB > 1<<32-1 {
+			return fmt.Errorf("invalid ARGON2_MEMORY_KIB %d (want at least %d for ARGON2_PARALLELISM=%d)",
+				c.Argon2MemoryKiB, 8*c.Argon2Parallelism, c.Argon2Parallelism)
+		}
+	case "bcrypt":
+		if c.BcryptCost < bcrypt.MinCost || c.BcryptCost > bcrypt.MaxCost {
+			return fmt.Errorf("invalid BCRYPT_COST %d (want %d-%d)", c.BcryptCost, bcrypt.MinCost, bcrypt.MaxCost)
+		}
+	default:
+		return fmt.Errorf("invalid PASSWORD_HASHER %q (want argon2id or bcrypt)", c.PasswordHasher)
+	}
+	if c.PasswordMinLength < 1 {
+		return fmt.Errorf("invalid PASSWORD_MIN_LENGTH %d (want at least 1)", c.PasswordMinLength)
 	}
+	return nil
+}
+
+// loadOIDCProviders อ่าน GOOGLE_* (แบบเดิม) และ OIDC_PROVIDERS=keycloak,...
+// โดยแต่ละตัวใช้ OIDC_<NAME>_ISSUER / _CLIENT_ID / _CLIENT_SECRET / _REDIRECT_URL / _SCOPES / _DISPLAY_NAME
+func loadOIDCProviders(publicURL string) []OIDCProviderConfig {
+	var out []OIDCProviderConfig
+	if id := get("GOOGLE_CLIENT_ID", ""); id != "" {
+		out = append(out, OIDCProviderConfig{
+			Name:         "google",
+			DisplayName:  "Google",
+			Issuer:       "https://accounts.google.com",
+			ClientID:     id,
+			ClientSecret: get("GOOGLE_CLIENT_SECRET", ""),
+			RedirectURL:  get("GOOGLE_REDIRECT_URL", publicURL+"/api/auth/google/callback"),
+		})
+	}
+	for _, name := range list(get("OIDC_PROVIDERS", "")) {
+		name = strings.ToLower(name)
+		prefix := "OIDC_" + strings.ToUpper(strings.ReplaceAll(name, "-", "_")) + "_"
+		out = append(out, OIDCProviderConfig{
+			Name:         name,
+			DisplayName:  get(prefix+"DISPLAY_NAME", name),
+			Issuer:       get(prefix+"ISSUER", ""),
+			ClientID:     get(prefix+"CLIENT_ID", ""),
+			ClientSecret: get(prefix+"CLIENT_SECRET", ""),
+			RedirectURL:  get(prefix+"REDIRECT_URL", publicURL+"/api/auth/"+name+"/callback"),
+			Scopes:       list(get(prefix+"SCOPES", "")),
+		})
+	}
+	return out
 }
 
 func get(key, def string) string {
@@ -43,6 +200,22 @@ func must(key string) string {
 	}
 	panic("missing required environment variable: " + key)
 }
+func atob(s string) bool {
+	b, err := strconv.ParseBool(s)
+	if err != nil {
+		panic("invalid bool for " + s)
+	}
+	return b
+}
+func list(s string) []string {
+	var out []string
+	for _, v := range strings.Split(s, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			out = append(out, v)
+		}
+	}
+	return out
+}
 func atoi(s string) int {
 	n, err := strconv.Atoi(s)
 	if err != nil {
@@ -0,0 +1,42 @@
+package config
+
+import "testing"
+
+func TestCheckPasswordHashing(t *testing.T) {
+	valid := Config{
+		PasswordHasher:    "argon2id",
+		Argon2MemoryKiB:   65536,
+		Argon2Iterations:  3,
+		Argon2Parallelism: 2,
+		BcryptCost:        10,
+		PasswordMinLength: 8,
+	}
+	tests := []struct {
+		name    string
+		edit    func(c *Config)
+		wantErr bool
+	}{
+		{"defaults", func(c *Config) {}, false},
+		{"bcrypt", func(c *Config) { c.PasswordHasher = "bcrypt" }, false},
+		{"unknown hasher", func(c *Config) { c.PasswordHasher = "md5" }, true},
+		{"argon2 parallelism 0", func(c *Config) { c.Argon2Parallelism = 0 }, true},
+		{"argon2 parallelism over uint8", func(c *Config) { c.Argon2Parallelism = 256 }, true},
+		{"argon2 memory 0", func(c *Config) { c.Argon2MemoryKiB = 0 }, true},
+		{"argon2 memory below 8 KiB per lane", func(c *Config) { c.Argon2MemoryKiB = 15 }, true},
+		{"argon2 iterations 0", func(c *Config) { c.Argon2Iterations = 0 }, true},
+		// ค่า argon2 ไม่ถูกใช้เมื่อเลือก bcrypt
+		{"bcrypt ignores argon2 values", func(c *Config) { c.PasswordHasher = "bcrypt"; c.Argon2MemoryKiB = 0 }, false},
+		{"bcrypt cost too low", func(c *Config) { c.PasswordHasher = "bcrypt"; c.BcryptCost = 3 }, true},
+		{"bcrypt cost too high", func(c *Config) { c.PasswordHasher = "bcrypt"; c.BcryptCost = 32 }, true},
+		{"min length 0", func(c *Config) { c.PasswordMinLength = 0 }, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := valid
+			tt.edit(&c)
+			if err := c.checkPasswordHashing(); (err != nil) != tt.wantErr {
+				t.Fatalf("checkPasswordHashing() = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"database/sql"
 	"fmt"
 	"sort"
+	"strings"
 
 	_ "embed"
 )
@@ -17,6 +18,71 @@ var migration0002 string
 //go:embed migrate/0003_add_username.sql
 var migration0003 string
 
+//go:embed migrate/0004_task_status_priority.sql
+var migration0004 string
+
+//go:embed migrate/0005_refresh_tokens.sql
+var migration0005 string
+
+//go:embed migrate/0006_sessions.sql
+var migration0006 string
+
+//go:embed migrate/0007_user_tokens.sql
+var migration0007 string
+
+//go:embed migrate/0008_email_verification.sql
+var migration0008 string
+
+//go:embed migrate/0009_user_mfa.sql
+var migration0009 string
+
+//go:embed migrate/0010_passkeys.sql
+var migration0010 string
+
+//go:embed migrate/0011_user_identities.sql
+var migration0011 string
+
+//go:embed migrate/0012_auth_throttle.sql
+var migration0012 string
+
+//go:embed migrate/0013_personal_access_tokens.sql
+var migration0013 string
+
+//go:embed migrate/0014_task_bigint_ids.sql
+var migration0014 string
+
+//go:embed migrate/0015_user_locale.sql
+var migration0015 string
+
+//go:embed migrate/0016_user_preferences.sql
+var migration0016 string
+
+//go:embed migrate/0017_workspaces.sql
+var migration0017 string
+
+//go:embed migrate/0018_workspace_invitations.sql
+var migration0018 string
+
+//go:embed migrate/0019_projects.sql
+var migration0019 string
+
+//go:embed migrate/0020_task_assignees_watchers.sql
+var migration0020 string
+
+//go:embed migrate/0021_subtasks_checklists.sql
+var migration0021 string
+
+// SQLite variants for migrations that cannot be expressed portably
+//
+//go:embed migrate/sqlite/0004_task_status_priority.sql
+var migration0004SQLite string
+
+//go:embed migrate/sqlite/0014_task_bigint_ids.sql
+var migration0014SQLite string
+
+//go:embed migrate/sqlite/0017_workspaces.sql
+var migration0017SQLite string
+
 // RunMigrations runs all database migrations
 func RunMigrations(db *sql.DB) error {
 	// Create migrations table if it doesn't exist
@@ -31,9 +97,32 @@ func RunMigrations(db *sql.DB) error {
 	}
 
 	migrations := map[string]string{
-		"0001_init.sql":              migration0001,
-		"0002_add_oauth_columns.sql": migration0002,
-		"0003_add_username.sql":      migration0003,
+		"0001_init.sql":                    migration0001,
+		"0002_add_oauth_columns.sql":       migration0002,
+		"0003_add_username.sql":            migration0003,
+		"0004_task_status_priority.sql":    migration0004,
+		"0005_refresh_tokens.sql":          migration0005,
+		"0006_sessions.sql":                migration0006,
+		"0007_user_tokens.sql":             migration0007,
+		"0008_email_verification.sql":      migration0008,
+		"0009_user_mfa.sql":                migration0009,
+		"0010_passkeys.sql":                migration0010,
+		"0011_user_identities.sql":         migration0011,
+		"0012_auth_throttle.sql":           migration0012,
+		"0013_personal_access_tokens.sql":  migration0013,
+		"0014_task_bigint_ids.sql":         migration0014,
+		"0015_user_locale.sql":             migration0015,
+		"0016_user_preferences.sql":        migration0016,
+		"0017_workspaces.sql":              migration0017,
+		"0018_workspace_invitations.sql":   migration0018,
+		"0019_projects.sql":                migration0019,
+		"0020_task_assignees_watchers.sql": migration0020,
+		"0021_subtasks_checklists.sql":     migration0021,
+	}
+	sqliteMigrations := map[string]string{
+		"0004_task_status_priority.sql": migration0004SQLite,
+		"0014_task_bigint_ids.sql":      migration0014SQLite,
+		"0017_workspaces.sql":           migration0017SQLite,
 	}
 
 	// Get list of migration files and sort them
@@ -58,7 +147,14 @@ func RunMigrations(db *sql.DB) error {
 
 		// Apply migration
 		fmt.Printf("Applying migration %s\n", filename)
-		_, err = db.Exec(migrations[filename])
+		stmt := migrations[filename]
+		if isSQLite(db) {
+			if override, ok := sqliteMigrations[filename]; ok {
+				stmt = override
+			}
+			stmt = sqliteCompat(stmt)
+		}
+		_, err = db.Exec(stmt)
 		if err != nil {
 			return fmt.Errorf("failed to apply migration %s: %w", filename, err)
 		}
@@ -73,4 +169,15 @@ func RunMigrations(db *sql.DB) error {
 	}
 
 	return nil
-}
\ No newline at end of file
+}
+
+// isSQLite reports whether db was opened with the sqlite driver (see repo.MustOpen)
+func isSQLite(db *sql.DB) bool {
+	return strings.Contains(fmt.Sprintf("%T", db.Driver()), "sqlite")
+}
+
+// sqliteCompat rewrites Postgres-only DDL so the same migration files run on SQLite.
+// SERIAL is not an alias for rowid in SQLite, so ids would otherwise stay NULL.
+func sqliteCompat(stmt string) string {
+	return strings.ReplaceAll(stmt, "SERIAL PRIMARY KEY", "INTEGER PRIMARY KEY AUTOINCREMENT")
+}
@@ -0,0 +1,18 @@
+-- Canonical task statuses: drop the hard-coded CHECK so per-project sets can be used,
+-- and add the priority column that domain.Task already exposes
+ALTER TABLE tasks DROP CONSTRAINT IF EXISTS tasks_status_check;
+ALTER TABLE tasks ADD COLUMN priority VARCHAR(20) NOT NULL DEFAULT 'medium'
+  CHECK (priority IN ('low','medium','high'));
+
+CREATE INDEX IF NOT EXISTS idx_tasks_owner_id ON tasks(owner_id);
+
+-- Custom status sets per project (ไม่มีแถว = ใช้ domain.DefaultStatuses)
+CREATE TABLE task_statuses (
+  id SERIAL PRIMARY KEY,
+  project_id INTEGER NOT NULL,
+  status VARCHAR(50) NOT NULL,
+  label TEXT NOT NULL,
+  category VARCHAR(20) NOT NULL CHECK (category IN ('not_started','active','blocked','done')),
+  position INTEGER NOT NULL DEFAULT 0,
+  UNIQUE (project_id, status)
+);
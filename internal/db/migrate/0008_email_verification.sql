@@ -0,0 +1,2 @@
+-- Email ownership proof for local sign-ups (Google sets it when email_verified=true)
+ALTER TABLE users ADD COLUMN email_verified_at TIMESTAMP;
@@ -0,0 +1,25 @@
+-- WebAuthn / passkeys: one row per registered authenticator credential
+-- credential_id, public_key และ aaguid เก็บเป็น base64url
+CREATE TABLE webauthn_credentials (
+  id SERIAL PRIMARY KEY,
+  user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  credential_id VARCHAR(1400) NOT NULL UNIQUE,
+  public_key TEXT NOT NULL,
+  attestation_type VARCHAR(32) NOT NULL DEFAULT '',
+  transports VARCHAR(255) NOT NULL DEFAULT '',
+  aaguid VARCHAR(64) NOT NULL DEFAULT '',
+  flags SMALLINT NOT NULL DEFAULT 0,
+  sign_count BIGINT NOT NULL DEFAULT 0,
+  name VARCHAR(100) NOT NULL,
+  created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  last_used_at TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_webauthn_credentials_user ON webauthn_credentials(user_id);
+
+-- challenge ระหว่าง begin/finish ของแต่ละ ceremony (ใช้ได้ครั้งเดียว)
+CREATE TABLE webauthn_sessions (
+  id VARCHAR(64) PRIMARY KEY,
+  data TEXT NOT NULL,
+  expires_at TIMESTAMP NOT NULL
+);
@@ -0,0 +1,20 @@
+-- External login identities (Google, OIDC ...) แยกจาก users เพื่อให้ผูกได้หลาย provider ต่อบัญชี
+-- users.provider / provider_id เหลือไว้เพื่อความเข้ากันได้ ไม่ใช้จับคู่ตอน login แล้ว
+CREATE TABLE user_identities (
+  id SERIAL PRIMARY KEY,
+  user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  provider VARCHAR(32) NOT NULL,
+  subject VARCHAR(191) NOT NULL,
+  email VARCHAR(255) NOT NULL DEFAULT '',
+  created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  last_used_at TIMESTAMP,
+  UNIQUE (provider, subject)
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_identities_user ON user_identities(user_id);
+
+-- ย้ายคู่ provider/provider_id เดิมของผู้ใช้ OAuth
+INSERT INTO user_identities (user_id, provider, subject, email, created_at)
+SELECT id, provider, provider_id, email, created_at
+FROM users
+WHERE provider IS NOT NULL AND provider_id IS NOT NULL AND provider_id <> '';
@@ -0,0 +1,20 @@
+-- ตัวนับ login ที่ผิด / การเรียก check-email ต่อ key (rule:ip หรือ rule:บัญชี) ใช้ร่วมกันทุก replica
+CREATE TABLE auth_throttle (
+  throttle_key VARCHAR(255) PRIMARY KEY,
+  failures INTEGER NOT NULL DEFAULT 0,
+  window_start TIMESTAMP NOT NULL,
+  blocked_until TIMESTAMP
+);
+
+-- audit: ทุกครั้งที่ key ถูกล็อกชั่วคราว
+CREATE TABLE auth_lockouts (
+  id SERIAL PRIMARY KEY,
+  rule VARCHAR(32) NOT NULL,
+  subject VARCHAR(255) NOT NULL,
+  ip VARCHAR(64) NOT NULL DEFAULT '',
+  failures INTEGER NOT NULL,
+  locked_until TIMESTAMP NOT NULL,
+  created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_auth_lockouts_created ON auth_lockouts(created_at);
@@ -0,0 +1,3 @@
+-- task id เป็น int64 ในโค้ดแล้ว ขยายคอลัมน์ให้ตรงกัน (SERIAL = int4 เต็มที่ 2^31)
+ALTER TABLE tasks ALTER COLUMN id TYPE BIGINT;
+ALTER SEQUENCE tasks_id_seq AS BIGINT;
@@ -0,0 +1,2 @@
+-- Preferred UI/email language (NULL = follow Accept-Language)
+ALTER TABLE users ADD COLUMN locale VARCHAR(8);
@@ -0,0 +1,7 @@
+-- Per-user settings as versioned JSON (setting ใหม่ไม่ต้องเพิ่มคอลัมน์)
+CREATE TABLE user_preferences (
+  user_id INTEGER PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+  version INTEGER NOT NULL,
+  data TEXT NOT NULL,
+  updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
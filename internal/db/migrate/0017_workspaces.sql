@@ -0,0 +1,30 @@
+-- Workspaces (ทีม): งานทุกชิ้นอยู่ใน workspace และทุกคนมี workspace ส่วนตัวหนึ่งอัน
+-- personal_owner_id เป็น NULL สำหรับ workspace ของทีม (UNIQUE = ส่วนตัวได้คนละอันเดียว)
+CREATE TABLE workspaces (
+  id SERIAL PRIMARY KEY,
+  name VARCHAR(100) NOT NULL,
+  personal_owner_id INTEGER UNIQUE REFERENCES users(id) ON DELETE CASCADE,
+  created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE workspace_members (
+  workspace_id INTEGER NOT NULL REFERENCES workspaces(id) ON DELETE CASCADE,
+  user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  role VARCHAR(20) NOT NULL CHECK (role IN ('admin','member')),
+  created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (workspace_id, user_id)
+);
+
+CREATE INDEX idx_workspace_members_user_id ON workspace_members(user_id);
+
+-- ย้ายงานเดิมเข้า workspace ส่วนตัวของเจ้าของ
+INSERT INTO workspaces (name, personal_owner_id) SELECT 'Personal', id FROM users;
+INSERT INTO workspace_members (workspace_id, user_id, role)
+  SELECT id, personal_owner_id, 'admin' FROM workspaces WHERE personal_owner_id IS NOT NULL;
+
+ALTER TABLE tasks ADD COLUMN workspace_id INTEGER REFERENCES workspaces(id) ON DELETE CASCADE;
+UPDATE tasks SET workspace_id = (SELECT id FROM workspaces WHERE personal_owner_id = tasks.owner_id);
+ALTER TABLE tasks ALTER COLUMN workspace_id SET NOT NULL;
+
+CREATE INDEX idx_tasks_workspace_id ON tasks(workspace_id, created_at);
@@ -0,0 +1,20 @@
+-- คำเชิญเข้า workspace: ลิงก์ในอีเมลเป็น token ที่เซ็นไว้ (sub = id, jti = token_id)
+-- ส่งซ้ำ = เปลี่ยน token_id (ลิงก์เก่าใช้ไม่ได้), ยกเลิก = status revoked
+CREATE TABLE workspace_invitations (
+  id SERIAL PRIMARY KEY,
+  workspace_id INTEGER NOT NULL REFERENCES workspaces(id) ON DELETE CASCADE,
+  email VARCHAR(255) NOT NULL,
+  role VARCHAR(20) NOT NULL CHECK (role IN ('admin','member')),
+  token_id VARCHAR(64) NOT NULL,
+  status VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending','accepted','revoked')),
+  invited_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+  accepted_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+  expires_at TIMESTAMP NOT NULL,
+  sent_at TIMESTAMP NOT NULL,
+  accepted_at TIMESTAMP,
+  created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- อีเมลหนึ่งมีคำเชิญที่รออยู่ได้อันเดียวต่อ workspace (เชิญซ้ำ = อัปเดตอันเดิม)
+CREATE UNIQUE INDEX idx_workspace_invitations_pending
+  ON workspace_invitations(workspace_id, email) WHERE status = 'pending';
@@ -0,0 +1,31 @@
+-- Projects (บอร์ด) ภายใน workspace: งานอยู่ในโปรเจกต์ได้อันเดียว หรือไม่อยู่ในโปรเจกต์ไหนเลย
+-- งานในโปรเจกต์เห็นได้เฉพาะสมาชิกของโปรเจกต์และ admin ของ workspace
+CREATE TABLE projects (
+  id SERIAL PRIMARY KEY,
+  workspace_id INTEGER NOT NULL REFERENCES workspaces(id) ON DELETE CASCADE,
+  name VARCHAR(100) NOT NULL,
+  color VARCHAR(7) NOT NULL DEFAULT '#3b82f6',
+  archived BOOLEAN NOT NULL DEFAULT FALSE,
+  created_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+  created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_projects_workspace_id ON projects(workspace_id);
+
+-- สมาชิกของโปรเจกต์ต้องเป็นสมาชิกของ workspace (ออกจาก workspace = หลุดจากทุกโปรเจกต์)
+CREATE TABLE project_members (
+  project_id INTEGER NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+  workspace_id INTEGER NOT NULL,
+  user_id INTEGER NOT NULL,
+  role VARCHAR(20) NOT NULL CHECK (role IN ('admin','member')),
+  created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (project_id, user_id),
+  FOREIGN KEY (workspace_id, user_id) REFERENCES workspace_members(workspace_id, user_id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_project_members_user_id ON project_members(user_id);
+
+ALTER TABLE tasks ADD COLUMN project_id INTEGER REFERENCES projects(id) ON DELETE SET NULL;
+
+CREATE INDEX idx_tasks_project_id ON tasks(project_id, created_at);
@@ -0,0 +1,21 @@
+-- ผู้รับผิดชอบงาน (หลายคนต่องาน แยกจาก owner_id ซึ่งคือผู้สร้าง) และผู้ติดตามที่ได้รับแจ้งเมื่องานเปลี่ยน
+-- ทั้งสองตารางต้องเป็นสมาชิกของ workspace ของงาน (ตรวจที่ repo ตอนเพิ่ม)
+CREATE TABLE task_assignees (
+  task_id BIGINT NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+  user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  assigned_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+  created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (task_id, user_id)
+);
+
+-- My Work: งานที่มอบหมายให้ฉัน
+CREATE INDEX idx_task_assignees_user_id ON task_assignees(user_id);
+
+CREATE TABLE task_watchers (
+  task_id BIGINT NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+  user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (task_id, user_id)
+);
+
+CREATE INDEX idx_task_watchers_user_id ON task_watchers(user_id);
@@ -0,0 +1,18 @@
+-- งานย่อย: parent_id ชี้งานแม่ใน workspace และโปรเจกต์เดียวกัน (ความลึกตรวจที่ service: domain.MaxTaskDepth)
+-- ลบงานแม่ service เลือกว่าลบทั้งต้นไม้หรือปล่อยงานย่อยเป็นงานระดับบน (SET NULL กันพลาดไว้อีกชั้น)
+ALTER TABLE tasks ADD COLUMN parent_id BIGINT REFERENCES tasks(id) ON DELETE SET NULL;
+
+CREATE INDEX idx_tasks_parent_id ON tasks(parent_id);
+
+-- checklist ในงาน: รายการสั้นๆ ติ๊กเสร็จได้ ไม่มีสถานะ/ผู้รับผิดชอบเหมือนงานย่อย
+CREATE TABLE task_checklist_items (
+  id SERIAL PRIMARY KEY,
+  task_id BIGINT NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+  title VARCHAR(200) NOT NULL,
+  done BOOLEAN NOT NULL DEFAULT FALSE,
+  position INTEGER NOT NULL DEFAULT 0,
+  created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_task_checklist_items_task_id ON task_checklist_items(task_id, position);
@@ -0,0 +1,30 @@
+-- SQLite variant of 0004: SQLite cannot drop a CHECK constraint, so rebuild tasks
+CREATE TABLE tasks_new (
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  owner_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  title TEXT NOT NULL,
+  description TEXT,
+  status VARCHAR(50) NOT NULL DEFAULT 'todo',
+  priority VARCHAR(20) NOT NULL DEFAULT 'medium' CHECK (priority IN ('low','medium','high')),
+  due_date DATE,
+  created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+INSERT INTO tasks_new (id, owner_id, title, description, status, due_date, created_at, updated_at)
+SELECT id, owner_id, title, description, status, due_date, created_at, updated_at FROM tasks;
+
+DROP TABLE tasks;
+ALTER TABLE tasks_new RENAME TO tasks;
+
+CREATE INDEX IF NOT EXISTS idx_tasks_owner_id ON tasks(owner_id);
+
+CREATE TABLE task_statuses (
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  project_id INTEGER NOT NULL,
+  status VARCHAR(50) NOT NULL,
+  label TEXT NOT NULL,
+  category VARCHAR(20) NOT NULL CHECK (category IN ('not_started','active','blocked','done')),
+  position INTEGER NOT NULL DEFAULT 0,
+  UNIQUE (project_id, status)
+);
@@ -0,0 +1,2 @@
+-- SQLite: INTEGER PRIMARY KEY เป็น 64 บิตอยู่แล้ว ไม่ต้องเปลี่ยน
+SELECT 1;
@@ -0,0 +1,50 @@
+package domain
+
+import (
+	"database/sql"
+	"time"
+)
+
+// PersonalAccessToken lets scripts and CI call the API without a login session
+// (ตาราง personal_access_tokens, token จริงแสดงครั้งเดียวตอนสร้าง)
+type PersonalAccessToken struct {
+	ID         int64        `db:"id"`
+	UserID     int64        `db:"user_id"`
+	Name       string       `db:"name"`
+	TokenHash  string       `db:"token_hash"`
+	Prefix     string       `db:"token_prefix"` // ต้นของ token ไว้ให้ผู้ใช้จำได้ว่าตัวไหน
+	Scopes     []string     `db:"scopes"`
+	ExpiresAt  time.Time    `db:"expires_at"`
+	LastUsedAt sql.NullTime `db:"last_used_at"`
+	CreatedAt  time.Time    `db:"created_at"`
+}
+
+func (t *PersonalAccessToken) Expired(now time.Time) bool { return !now.Before(t.ExpiresAt) }
+
+func (t *PersonalAccessToken) HasScope(scope string) bool {
+	for _, s := range t.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// Token scopes: แต่ละกลุ่ม route ต้องการ scope หนึ่งตัว (login ปกติผ่านทุก scope)
+const (
+	ScopeTasksRead   = "tasks:read"
+	ScopeTasksWrite  = "tasks:write"
+	ScopeProfileRead = "profile:read"
+	ScopeAdmin       = "admin" // ยังต้องมี permission ของ role ด้วย
+)
+
+var AllScopes = []string{ScopeTasksRead, ScopeTasksWrite, ScopeProfileRead, ScopeAdmin}
+
+func ValidScope(s string) bool {
+	for _, v := range AllScopes {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
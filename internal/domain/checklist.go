@@ -0,0 +1,17 @@
+package domain
+
+import "time"
+
+// MaxChecklistItems จำกัดจำนวนรายการต่องาน (งานที่ใหญ่กว่านี้ควรแตกเป็นงานย่อย)
+const MaxChecklistItems = 100
+
+// ChecklistItem is one checkbox line inside a task (ตาราง task_checklist_items)
+type ChecklistItem struct {
+	ID        int64     `db:"id"`
+	TaskID    int64     `db:"task_id"`
+	Title     string    `db:"title"`
+	Done      bool      `db:"done"`
+	Position  int       `db:"position"`
+	CreatedAt time.Time `db:"created_at"`
+	UpdatedAt time.Time `db:"updated_at"`
+}
@@ -12,4 +12,43 @@ var (
 	ErrEmailNotFound         = errors.New("email not found")
 	ErrUsernameAlreadyExists = errors.New("username already exists")
 	ErrUnauthorized          = errors.New("unauthorized")
-)
\ No newline at end of file
+	ErrTaskNotFound          = errors.New("task not found")
+	ErrInvalidStatus         = errors.New("invalid task status")
+	ErrInvalidStatusSet      = errors.New("invalid status set")
+	ErrInvalidRefreshToken   = errors.New("invalid refresh token")
+	ErrRefreshTokenReused    = errors.New("refresh token reused")
+	ErrSessionNotFound       = errors.New("session not found")
+	ErrSessionRevoked        = errors.New("session revoked")
+	ErrInvalidToken          = errors.New("invalid or expired token")
+	ErrEmailNotVerified      = errors.New("email not verified")
+	ErrTooManyRequests       = errors.New("too many requests")
+	ErrForbidden             = errors.New("forbidden")
+	ErrMFANotEnabled         = errors.New("two-factor authentication is not enabled")
+	ErrMFAAlreadyEnabled     = errors.New("two-factor authentication is already enabled")
+	ErrInvalidMFACode        = errors.New("invalid two-factor code")
+	ErrPasskeyNotFound       = errors.New("passkey not found")
+	ErrInvalidPasskey        = errors.New("passkey verification failed")
+	ErrIdentityNotFound      = errors.New("linked account not found")
+	ErrIdentityInUse         = errors.New("this account is already linked to another user")
+	ErrIdentityNotLinked     = errors.New("an account with this email already exists, sign in and link it from settings")
+	ErrLastLoginMethod       = errors.New("cannot remove the last login method")
+	ErrReauthRequired        = errors.New("re-authentication required")
+	ErrWeakPassword          = errors.New("password does not meet the password policy")
+	ErrInvalidRole           = errors.New("invalid role")
+	ErrLastAdmin             = errors.New("cannot remove the last admin")
+	ErrAccessTokenNotFound   = errors.New("access token not found")
+	ErrAccessTokenLimit      = errors.New("too many access tokens, revoke one first")
+	ErrInvalidScope          = errors.New("invalid token scope")
+	ErrWorkspaceNotFound     = errors.New("workspace not found")
+	ErrPersonalWorkspace     = errors.New("not allowed on a personal workspace")
+	ErrMemberNotFound        = errors.New("workspace member not found")
+	ErrAlreadyMember         = errors.New("already a member of this workspace")
+	ErrInvitationNotFound    = errors.New("invitation not found")
+	ErrInviteEmailMismatch   = errors.New("this invitation was sent to a different email address")
+	ErrProjectNotFound       = errors.New("project not found")
+	ErrProjectArchived       = errors.New("project is archived")
+	ErrInvalidParent         = errors.New("invalid parent task")
+	ErrTaskTooDeep           = errors.New("subtasks are nested too deeply")
+	ErrChecklistItemNotFound = errors.New("checklist item not found")
+	ErrChecklistFull         = errors.New("checklist has too many items")
+)
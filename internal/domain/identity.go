@@ -0,0 +1,17 @@
+package domain
+
+import (
+	"database/sql"
+	"time"
+)
+
+// Identity is an external login (Google, OIDC provider ...) linked to a user (ตาราง user_identities)
+type Identity struct {
+	ID         int64        `db:"id"`
+	UserID     int64        `db:"user_id"`
+	Provider   string       `db:"provider"`
+	Subject    string       `db:"subject"` // sub ของ provider
+	Email      string       `db:"email"`   // อีเมลที่ provider ส่งมาตอนผูก (แสดงในหน้า settings)
+	CreatedAt  time.Time    `db:"created_at"`
+	LastUsedAt sql.NullTime `db:"last_used_at"`
+}
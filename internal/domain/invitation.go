@@ -0,0 +1,33 @@
+package domain
+
+import (
+	"database/sql"
+	"time"
+)
+
+// Invitation statuses (ตรงกับ CHECK ของ workspace_invitations.status)
+const (
+	InvitationPending  = "pending"
+	InvitationAccepted = "accepted"
+	InvitationRevoked  = "revoked"
+)
+
+// Invitation asks someone (by email) to join a workspace with a role (ตาราง workspace_invitations)
+type Invitation struct {
+	ID          int64         `db:"id"`
+	WorkspaceID int64         `db:"workspace_id"`
+	Email       string        `db:"email"` // ตัวพิมพ์เล็กเสมอ
+	Role        string        `db:"role"`  // WorkspaceRoleAdmin / WorkspaceRoleMember
+	TokenID     string        `db:"token_id"`
+	Status      string        `db:"status"`
+	InvitedBy   sql.NullInt64 `db:"invited_by"`
+	ExpiresAt   time.Time     `db:"expires_at"`
+	SentAt      time.Time     `db:"sent_at"`
+	CreatedAt   time.Time     `db:"created_at"`
+
+	// join มาจาก workspaces / users ไว้แสดงในอีเมลและหน้ารับคำเชิญ
+	WorkspaceName string         `db:"-"`
+	InviterName   sql.NullString `db:"-"`
+}
+
+func (i *Invitation) Expired(now time.Time) bool { return !now.Before(i.ExpiresAt) }
@@ -0,0 +1,30 @@
+package domain
+
+import (
+	"database/sql"
+	"time"
+)
+
+// UserMFA is a user's TOTP enrolment (ตาราง user_mfa).
+// EnabledAt ว่าง = เริ่ม setup แล้วแต่ยังไม่ได้ยืนยันรหัสแรก
+type UserMFA struct {
+	UserID       int64        `db:"user_id"`
+	Secret       string       `db:"secret"`
+	LastUsedStep int64        `db:"last_used_step"`
+	EnabledAt    sql.NullTime `db:"enabled_at"`
+	CreatedAt    time.Time    `db:"created_at"`
+}
+
+func (m *UserMFA) Enabled() bool { return m != nil && m.EnabledAt.Valid }
+
+// MFASetup is returned when a user starts enrolling an authenticator app
+type MFASetup struct {
+	Secret     string `json:"secret"`
+	OTPAuthURI string `json:"otpauth_uri"`
+}
+
+// MFAStatus is what the settings page shows
+type MFAStatus struct {
+	Enabled                bool `json:"enabled"`
+	RecoveryCodesRemaining int  `json:"recovery_codes_remaining"`
+}
@@ -0,0 +1,22 @@
+package domain
+
+import (
+	"database/sql"
+	"time"
+)
+
+// Passkey is a WebAuthn credential registered by a user (ตาราง webauthn_credentials)
+type Passkey struct {
+	ID              int64        `db:"id"`
+	UserID          int64        `db:"user_id"`
+	CredentialID    []byte       `db:"credential_id"`
+	PublicKey       []byte       `db:"public_key"`
+	AttestationType string       `db:"attestation_type"`
+	Transports      []string     `db:"transports"`
+	AAGUID          []byte       `db:"aaguid"`
+	Flags           uint8        `db:"flags"` // authenticator data flags ตอนลงทะเบียน (BE ต้องไม่เปลี่ยน)
+	SignCount       uint32       `db:"sign_count"`
+	Name            string       `db:"name"`
+	CreatedAt       time.Time    `db:"created_at"`
+	LastUsedAt      sql.NullTime `db:"last_used_at"`
+}
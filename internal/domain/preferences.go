@@ -0,0 +1,67 @@
+package domain
+
+import "time"
+
+// PreferencesVersion is the schema version written with every save. เพิ่ม setting ใหม่
+// ที่มีค่า default ไม่ต้องเพิ่ม version (ค่าเก่าที่ไม่มี key ได้ default เอง) เพิ่มเมื่อต้องแปลงค่าเก่าเท่านั้น
+const PreferencesVersion = 1
+
+const (
+	ThemeLight  = "light"
+	ThemeDark   = "dark"
+	ThemeSystem = "system"
+)
+
+func ValidTheme(t string) bool { return t == ThemeLight || t == ThemeDark || t == ThemeSystem }
+
+// ValidTimezone: ชื่อใน IANA tz database เช่น Asia/Bangkok ("" = UTC)
+func ValidTimezone(tz string) bool {
+	if tz == "" {
+		return true
+	}
+	if tz == "Local" {
+		return false
+	}
+	_, err := time.LoadLocation(tz)
+	return err == nil
+}
+
+// Location returns the user's time zone (ค่าว่าง / ชื่อที่เลิกใช้ = UTC)
+func (p *Preferences) Location() *time.Location {
+	loc, err := time.LoadLocation(p.Timezone)
+	if err != nil || p.Timezone == "Local" {
+		return time.UTC
+	}
+	return loc
+}
+
+// Preferences are per-user UI settings (หน้า settings)
+type Preferences struct {
+	Version int `json:"version"`
+
+	// ภาษาเก็บที่ users.locale (middleware / อีเมลใช้) ไม่ได้อยู่ใน JSON ที่บันทึก; "" = ตาม browser
+	Language string `json:"language"`
+
+	Theme         string                  `json:"theme"`
+	Timezone      string                  `json:"timezone"` // ใช้จัดกลุ่ม My Work (Today / This week ...)
+	Notifications NotificationPreferences `json:"notifications"`
+}
+
+type NotificationPreferences struct {
+	Email         bool `json:"email"`
+	Push          bool `json:"push"`
+	TaskReminders bool `json:"task_reminders"`
+}
+
+// DefaultPreferences ตรงกับปุ่ม "Reset to Default" ในหน้า settings
+func DefaultPreferences() Preferences {
+	return Preferences{
+		Version: PreferencesVersion,
+		Theme:   ThemeLight,
+		Notifications: NotificationPreferences{
+			Email:         true,
+			Push:          true,
+			TaskReminders: false,
+		},
+	}
+}
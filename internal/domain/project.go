@@ -0,0 +1,41 @@
+package domain
+
+import (
+	"database/sql"
+	"regexp"
+	"time"
+)
+
+// DefaultProjectColor ตรงกับ DEFAULT ของ projects.color
+const DefaultProjectColor = "#3b82f6"
+
+var projectColorRe = regexp.MustCompile(`^#[0-9a-f]{6}$`)
+
+// ValidProjectColor: #rrggbb ตัวพิมพ์เล็ก
+func ValidProjectColor(c string) bool { return projectColorRe.MatchString(c) }
+
+// Project groups tasks inside a workspace, shown as a board (ตาราง projects)
+type Project struct {
+	ID          int64         `db:"id"`
+	WorkspaceID int64         `db:"workspace_id"`
+	Name        string        `db:"name"`
+	Color       string        `db:"color"`
+	Archived    bool          `db:"archived"` // เก็บถาวร: ยังเปิดดูได้ แต่เพิ่ม/ย้ายงานเข้าไม่ได้
+	CreatedBy   sql.NullInt64 `db:"created_by"`
+	CreatedAt   time.Time     `db:"created_at"`
+	UpdatedAt   time.Time     `db:"updated_at"`
+
+	// role ของผู้ใช้ที่ดึงข้อมูล (admin ของ workspace = admin ของทุกโปรเจกต์)
+	Role string `db:"-"`
+}
+
+// ProjectMember is one project membership with the user's public profile (ตาราง project_members)
+// role ใช้ชุดเดียวกับ workspace: admin แก้ไขโปรเจกต์/สมาชิกได้
+type ProjectMember struct {
+	ProjectID int64          `db:"project_id"`
+	UserID    int64          `db:"user_id"`
+	Email     string         `db:"email"`
+	Name      sql.NullString `db:"name"`
+	Role      string         `db:"role"`
+	JoinedAt  time.Time      `db:"created_at"`
+}
@@ -0,0 +1,63 @@
+package domain
+
+import "sort"
+
+// Roles (ตรงกับ CHECK ของคอลัมน์ users.role)
+const (
+	RoleAdmin   = "admin"
+	RoleManager = "manager"
+	RoleUser    = "user"
+)
+
+func ValidRole(r string) bool {
+	return r == RoleAdmin || r == RoleManager || r == RoleUser
+}
+
+// Permission is one action a role may perform, in the form resource:action[:scope]
+type Permission string
+
+const (
+	// งานของตัวเอง
+	PermTaskRead   Permission = "task:read"
+	PermTaskCreate Permission = "task:create"
+	PermTaskUpdate Permission = "task:update"
+	PermTaskDelete Permission = "task:delete"
+
+	// แก้/ลบงานของทุกคนใน workspace ที่ตัวเองเป็นสมาชิก (ไม่ต้องเป็นเจ้าของ)
+	// ไม่มีสิทธิ์ "อ่านทุกงาน" แยก: สมาชิก workspace ทุกคนอ่านงานใน workspace ได้อยู่แล้ว
+	PermTaskUpdateAny Permission = "task:update:any"
+	PermTaskDeleteAny Permission = "task:delete:any"
+
+	// จัดการผู้ใช้คนอื่น (เปลี่ยน role, reset 2FA)
+	PermUserManage Permission = "user:manage"
+)
+
+var userPermissions = []Permission{PermTaskRead, PermTaskCreate, PermTaskUpdate, PermTaskDelete}
+
+// rolePermissions: role สูงกว่าได้สิทธิ์ของ role ที่ต่ำกว่าทั้งหมด
+var rolePermissions = map[string][]Permission{
+	RoleUser:    userPermissions,
+	RoleManager: append(append([]Permission{}, userPermissions...), PermTaskUpdateAny),
+	RoleAdmin: append(append([]Permission{}, userPermissions...),
+		PermTaskUpdateAny, PermTaskDeleteAny, PermUserManage),
+}
+
+// HasPermission reports whether role grants p (role ที่ไม่รู้จัก = ไม่มีสิทธิ์อะไรเลย)
+func HasPermission(role string, p Permission) bool {
+	for _, granted := range rolePermissions[role] {
+		if granted == p {
+			return true
+		}
+	}
+	return false
+}
+
+// PermissionsOf lists what role grants, sorted (ให้ frontend ใช้ซ่อน/แสดงปุ่ม)
+func PermissionsOf(role string) []string {
+	out := make([]string, 0, len(rolePermissions[role]))
+	for _, p := range rolePermissions[role] {
+		out = append(out, string(p))
+	}
+	sort.Strings(out)
+	return out
+}
@@ -0,0 +1,30 @@
+package domain
+
+import (
+	"database/sql"
+	"time"
+)
+
+// Session is one signed-in device (ตาราง sessions).
+// ID ตรงกับ jti ใน access token และ family ของ refresh token
+type Session struct {
+	ID         string       `db:"id"`
+	UserID     int64        `db:"user_id"`
+	UserAgent  string       `db:"user_agent"`
+	IP         string       `db:"ip"`
+	CreatedAt  time.Time    `db:"created_at"`
+	LastSeenAt time.Time    `db:"last_seen_at"`
+	ExpiresAt  time.Time    `db:"expires_at"`
+	RevokedAt  sql.NullTime `db:"revoked_at"`
+}
+
+// Active reports whether the session can still be used at time now
+func (s *Session) Active(now time.Time) bool {
+	return !s.RevokedAt.Valid && now.Before(s.ExpiresAt)
+}
+
+// ClientInfo describes where a login came from (เก็บลง sessions)
+type ClientInfo struct {
+	UserAgent string
+	IP        string
+}
@@ -5,15 +5,166 @@ import (
 	"time"
 )
 
-// Task represents a task in the system
+// Task represents a task in the system (ตาราง tasks)
 type Task struct {
-	ID          int            `json:"id" db:"id"`
-	UserID      int            `json:"user_id" db:"user_id"`
+	ID          int64          `json:"id" db:"id"`
+	WorkspaceID int64          `json:"workspace_id" db:"workspace_id"`
+	ProjectID   sql.NullInt64  `json:"project_id" db:"project_id"` // NULL = ไม่อยู่ในโปรเจกต์ (สมาชิกทุกคนใน workspace เห็น)
+	ParentID    sql.NullInt64  `json:"parent_id" db:"parent_id"`   // งานแม่ (NULL = งานระดับบน)
+	OwnerID     int64          `json:"owner_id" db:"owner_id"`     // ผู้สร้าง
 	Title       string         `json:"title" db:"title"`
 	Description sql.NullString `json:"description" db:"description"`
-	Status      string         `json:"status" db:"status"` // pending, in_progress, completed
+	Status      string         `json:"status" db:"status"`     // key ใน StatusSet เช่น todo, doing, stuck, done
 	Priority    string         `json:"priority" db:"priority"` // low, medium, high
 	DueDate     sql.NullTime   `json:"due_date" db:"due_date"`
 	CreatedAt   time.Time      `json:"created_at" db:"created_at"`
 	UpdatedAt   time.Time      `json:"updated_at" db:"updated_at"`
+
+	// คำนวณจาก StatusSet ตอนอ่าน ไม่ได้เก็บใน DB
+	StatusCategory StatusCategory `json:"status_category" db:"-"`
+
+	// ผู้รับผิดชอบ (ตาราง task_assignees) service ใส่ให้ตอนอ่าน
+	AssigneeIDs []int64 `json:"assignee_ids" db:"-"`
+
+	// roll-up: งานย่อยชั้นถัดไปที่เสร็จ (category done) / ทั้งหมด และรายการ checklist ที่ติ๊กแล้ว / ทั้งหมด
+	Subtasks  Progress `json:"subtasks" db:"-"`
+	Checklist Progress `json:"checklist" db:"-"`
+
+	// งานย่อยทั้งต้นไม้ ใส่ให้เฉพาะเมื่อขอ include=subtree (nil = ไม่ได้ขอ)
+	Children []*Task `json:"children,omitempty" db:"-"`
+}
+
+// Progress counts finished items out of all items
+type Progress struct {
+	Done  int `json:"done"`
+	Total int `json:"total"`
+}
+
+// MaxTaskDepth: งานระดับบน = ชั้น 1 งานย่อยซ้อนได้ถึงชั้นนี้
+const MaxTaskDepth = 3
+
+// ChildDeleteMode: ลบงานที่มีงานย่อยแล้วงานย่อยเป็นอย่างไร
+type ChildDeleteMode string
+
+const (
+	ChildrenOrphan  ChildDeleteMode = "orphan"  // งานย่อยชั้นถัดไปกลายเป็นงานระดับบน
+	ChildrenCascade ChildDeleteMode = "cascade" // ลบงานย่อยทั้งต้นไม้ด้วย
+)
+
+func (m ChildDeleteMode) Valid() bool { return m == ChildrenOrphan || m == ChildrenCascade }
+
+// TaskPerson is an assignee or watcher of a task with the user's public profile
+type TaskPerson struct {
+	TaskID  int64          `db:"task_id"`
+	UserID  int64          `db:"user_id"`
+	Email   string         `db:"email"`
+	Name    sql.NullString `db:"name"`
+	AddedAt time.Time      `db:"created_at"`
+}
+
+// Priorities
+const (
+	PriorityLow    = "low"
+	PriorityMedium = "medium"
+	PriorityHigh   = "high"
+)
+
+func ValidPriority(p string) bool {
+	return p == PriorityLow || p == PriorityMedium || p == PriorityHigh
+}
+
+// StatusCategory คือกลุ่มความหมายของสถานะ ใช้ร่วมกันระหว่าง board และ report
+// ไม่ว่าแต่ละโปรเจกต์จะตั้งชื่อสถานะเองอย่างไร
+type StatusCategory string
+
+const (
+	CategoryNotStarted StatusCategory = "not_started"
+	CategoryActive     StatusCategory = "active"
+	CategoryBlocked    StatusCategory = "blocked"
+	CategoryDone       StatusCategory = "done"
+)
+
+func (c StatusCategory) Valid() bool {
+	switch c {
+	case CategoryNotStarted, CategoryActive, CategoryBlocked, CategoryDone:
+		return true
+	}
+	return false
+}
+
+// TaskStatus is one entry of a status set (ตาราง task_statuses)
+type TaskStatus struct {
+	Status   string         `json:"status" db:"status"`
+	Label    string         `json:"label" db:"label"`
+	Category StatusCategory `json:"category" db:"category"`
+	Position int            `json:"position" db:"position"`
+}
+
+// StatusSet is the ordered list of statuses a task may take
+type StatusSet []TaskStatus
+
+// DefaultStatuses is the canonical set used when a project has no custom set.
+// ค่าตรงกับที่ dashboard ส่งมา (todo/doing/done) และ "Stuck" ของหน้า reporting
+func DefaultStatuses() StatusSet {
+	return StatusSet{
+		{Status: "todo", Label: "Todo", Category: CategoryNotStarted, Position: 0},
+		{Status: "doing", Label: "Doing", Category: CategoryActive, Position: 1},
+		{Status: "stuck", Label: "Stuck", Category: CategoryBlocked, Position: 2},
+		{Status: "done", Label: "Done", Category: CategoryDone, Position: 3},
+	}
+}
+
+// Lookup returns the entry for status key s
+func (set StatusSet) Lookup(s string) (TaskStatus, bool) {
+	for _, st := range set {
+		if st.Status == s {
+			return st, true
+		}
+	}
+	return TaskStatus{}, false
+}
+
+// Initial returns the first "not started" status (ใช้เป็นค่าเริ่มต้นของงานใหม่)
+func (set StatusSet) Initial() string {
+	for _, st := range set {
+		if st.Category == CategoryNotStarted {
+			return st.Status
+		}
+	}
+	if len(set) > 0 {
+		return set[0].Status
+	}
+	return ""
+}
+
+// ForCategory returns the first status in category c (ย้ายงานข้ามโปรเจกต์ที่ตั้งชื่อสถานะต่างกัน)
+func (set StatusSet) ForCategory(c StatusCategory) (string, bool) {
+	for _, st := range set {
+		if st.Category == c {
+			return st.Status, true
+		}
+	}
+	return "", false
+}
+
+// Validate checks a custom set: unique non-empty keys, known categories,
+// and at least one not-started and one done status
+func (set StatusSet) Validate() error {
+	if len(set) == 0 {
+		return ErrInvalidStatusSet
+	}
+	seen := map[string]bool{}
+	var hasStart, hasDone bool
+	for _, st := range set {
+		if st.Status == "" || len(st.Status) > 50 || st.Label == "" || !st.Category.Valid() || seen[st.Status] {
+			return ErrInvalidStatusSet
+		}
+		seen[st.Status] = true
+		hasStart = hasStart || st.Category == CategoryNotStarted
+		hasDone = hasDone || st.Category == CategoryDone
+	}
+	if !hasStart || !hasDone {
+		return ErrInvalidStatusSet
+	}
+	return nil
 }
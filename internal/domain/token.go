@@ -0,0 +1,23 @@
+package domain
+
+import (
+	"database/sql"
+	"time"
+)
+
+// RefreshToken is the server-side record of an issued refresh token (ตาราง refresh_tokens).
+// ทุก token ที่หมุนต่อกันมาจาก login เดียวกันจะมี FamilyID เดียวกัน
+type RefreshToken struct {
+	ID         string         `db:"id"` // jti
+	UserID     int64          `db:"user_id"`
+	FamilyID   string         `db:"family_id"`
+	ExpiresAt  time.Time      `db:"expires_at"`
+	CreatedAt  time.Time      `db:"created_at"`
+	RevokedAt  sql.NullTime   `db:"revoked_at"`
+	ReplacedBy sql.NullString `db:"replaced_by"`
+}
+
+// Used reports whether the token was already rotated or revoked
+func (t *RefreshToken) Used() bool {
+	return t.RevokedAt.Valid || t.ReplacedBy.Valid
+}
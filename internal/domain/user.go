@@ -17,5 +17,12 @@ type User struct {
 	ProviderID sql.NullString `db:"provider_id"`
 	AvatarURL  sql.NullString `db:"avatar_url"`
 
+	EmailVerifiedAt sql.NullTime `db:"email_verified_at"`
+
+	Locale sql.NullString `db:"locale"` // en / th, NULL = ตาม Accept-Language
+
 	CreatedAt time.Time `db:"created_at"`
 }
+
+// EmailVerified reports whether the user proved ownership of Email
+func (u *User) EmailVerified() bool { return u.EmailVerifiedAt.Valid }
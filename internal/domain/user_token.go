@@ -0,0 +1,23 @@
+package domain
+
+import (
+	"database/sql"
+	"time"
+)
+
+// Purposes of single-use user tokens
+const (
+	TokenPurposePasswordReset = "password_reset"
+	TokenPurposeEmailVerify   = "email_verify"
+)
+
+// UserToken is a hashed, single-use, expiring token emailed to a user (ตาราง user_tokens)
+type UserToken struct {
+	ID        int64        `db:"id"`
+	UserID    int64        `db:"user_id"`
+	Purpose   string       `db:"purpose"`
+	TokenHash string       `db:"token_hash"`
+	ExpiresAt time.Time    `db:"expires_at"`
+	CreatedAt time.Time    `db:"created_at"`
+	UsedAt    sql.NullTime `db:"used_at"`
+}
@@ -0,0 +1,49 @@
+package domain
+
+import "time"
+
+// WorkGroup is a section of the My Work page, decided by the due date in the user's time zone
+type WorkGroup string
+
+const (
+	WorkToday    WorkGroup = "today"
+	WorkThisWeek WorkGroup = "this_week" // หลังวันนี้ ถึงวันอาทิตย์ของสัปดาห์นี้ (สัปดาห์เริ่มวันจันทร์)
+	WorkNextWeek WorkGroup = "next_week"
+	WorkLater    WorkGroup = "later" // หลังสัปดาห์หน้า หรือไม่มีกำหนดส่ง
+	WorkOverdue  WorkGroup = "overdue"
+)
+
+// WorkGroups are the sections in the order the UI shows them
+var WorkGroups = []WorkGroup{WorkToday, WorkThisWeek, WorkNextWeek, WorkLater, WorkOverdue}
+
+// GroupForDue returns the section of a task. today is midnight of the user's current date
+// in UTC (due_date เก็บเป็นวันที่ล้วน จึงเทียบเป็นวันปฏิทิน ไม่แปลง time zone ของ due)
+// งานที่เสร็จแล้วไม่นับว่าเลยกำหนด: เลยวันไปแล้ว = ไม่แสดง (ok = false)
+func GroupForDue(due time.Time, hasDue, done bool, today time.Time) (WorkGroup, bool) {
+	if !hasDue {
+		return WorkLater, true
+	}
+	due = time.Date(due.Year(), due.Month(), due.Day(), 0, 0, 0, 0, time.UTC)
+	if due.Before(today) {
+		return WorkOverdue, !done
+	}
+	if due.Equal(today) {
+		return WorkToday, true
+	}
+
+	// วันจันทร์ของสัปดาห์หน้า (Weekday: อาทิตย์ = 0)
+	nextMonday := today.AddDate(0, 0, 7-(int(today.Weekday())+6)%7)
+	switch {
+	case due.Before(nextMonday):
+		return WorkThisWeek, true
+	case due.Before(nextMonday.AddDate(0, 0, 7)):
+		return WorkNextWeek, true
+	}
+	return WorkLater, true
+}
+
+// LocalToday returns the current date in loc as midnight UTC (ใช้กับ GroupForDue)
+func LocalToday(now time.Time, loc *time.Location) time.Time {
+	y, m, d := now.In(loc).Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,41 @@
+package domain
+
+import (
+	"database/sql"
+	"time"
+)
+
+// Workspace roles (ตรงกับ CHECK ของ workspace_members.role) คนละชุดกับ users.role
+const (
+	WorkspaceRoleAdmin  = "admin"  // เชิญ/จัดการสมาชิก แก้ไขงานของทุกคนใน workspace
+	WorkspaceRoleMember = "member" // เพิ่มและแก้ไขงานของตัวเอง
+)
+
+func ValidWorkspaceRole(r string) bool {
+	return r == WorkspaceRoleAdmin || r == WorkspaceRoleMember
+}
+
+// Workspace is a team that owns tasks (ตาราง workspaces)
+type Workspace struct {
+	ID              int64         `db:"id"`
+	Name            string        `db:"name"`
+	PersonalOwnerID sql.NullInt64 `db:"personal_owner_id"` // NULL = workspace ของทีม
+	CreatedAt       time.Time     `db:"created_at"`
+	UpdatedAt       time.Time     `db:"updated_at"`
+
+	// role ของผู้ใช้ที่ดึงข้อมูล ไม่ได้เป็นคอลัมน์ของ workspaces
+	Role string `db:"-"`
+}
+
+// Personal reports whether w is a user's own workspace (ลบไม่ได้ เชิญคนอื่นไม่ได้)
+func (w *Workspace) Personal() bool { return w.PersonalOwnerID.Valid }
+
+// WorkspaceMember is one membership with the user's public profile (ตาราง workspace_members)
+type WorkspaceMember struct {
+	WorkspaceID int64          `db:"workspace_id"`
+	UserID      int64          `db:"user_id"`
+	Email       string         `db:"email"`
+	Name        sql.NullString `db:"name"`
+	Role        string         `db:"role"`
+	JoinedAt    time.Time      `db:"created_at"`
+}
@@ -0,0 +1,170 @@
+package i18n
+
+// catalog: key -> locale -> format string
+//
+// key ของ error คือ code เดียวกับใน response (สัญญากับ client) ส่วน validation ขึ้นต้นด้วย
+// "validation." และอีเมลขึ้นต้นด้วย "email." ตัวแปรใน format เรียงตามที่ผู้เรียกส่งมา
+var catalog = map[string]map[string]string{
+	// generic
+	"invalid_input":     {EN: "invalid input", TH: "ข้อมูลไม่ถูกต้อง"},
+	"validation_failed": {EN: "some fields are invalid", TH: "ข้อมูลบางช่องไม่ถูกต้อง"},
+	"unauthorized":      {EN: "unauthorized", TH: "กรุณาเข้าสู่ระบบ"},
+	"forbidden":         {EN: "forbidden", TH: "คุณไม่มีสิทธิ์ทำรายการนี้"},
+	"not_found":         {EN: "not found", TH: "ไม่พบข้อมูล"},
+	"conflict":          {EN: "conflict", TH: "ข้อมูลขัดแย้งกับที่มีอยู่"},
+	"too_many_requests": {EN: "too many attempts, please try again later", TH: "ลองหลายครั้งเกินไป กรุณาลองใหม่ภายหลัง"},
+	"internal_error":    {EN: "something went wrong", TH: "เกิดข้อผิดพลาดในระบบ กรุณาลองใหม่อีกครั้ง"},
+
+	// auth / account
+	"invalid_credentials":         {EN: "invalid credentials", TH: "ชื่อผู้ใช้หรือรหัสผ่านไม่ถูกต้อง"},
+	"incorrect_password":          {EN: "incorrect password", TH: "รหัสผ่านไม่ถูกต้อง"},
+	"user_not_found":              {EN: "user not found", TH: "ไม่พบผู้ใช้"},
+	"email_exists":                {EN: "email already registered", TH: "อีเมลนี้ถูกใช้สมัครแล้ว"},
+	"email_not_found":             {EN: "email not found", TH: "ไม่พบอีเมลนี้"},
+	"username_exists":             {EN: "username already taken", TH: "ชื่อผู้ใช้นี้ถูกใช้แล้ว"},
+	"email_not_verified":          {EN: "please verify your email first", TH: "กรุณายืนยันอีเมลก่อน"},
+	"invalid_token":               {EN: "invalid or expired token", TH: "ลิงก์หรือโทเค็นไม่ถูกต้องหรือหมดอายุแล้ว"},
+	"invalid_refresh_token":       {EN: "invalid refresh token", TH: "เซสชันหมดอายุ กรุณาเข้าสู่ระบบใหม่"},
+	"refresh_token_required":      {EN: "refresh token required", TH: "เซสชันหมดอายุ กรุณาเข้าสู่ระบบใหม่"},
+	"refresh_token_reused":        {EN: "refresh token reused", TH: "ตรวจพบการใช้เซสชันซ้ำ กรุณาเข้าสู่ระบบใหม่"},
+	"session_not_found":           {EN: "session not found", TH: "ไม่พบเซสชัน"},
+	"session_revoked":             {EN: "session has been signed out", TH: "เซสชันนี้ถูกออกจากระบบแล้ว"},
+	"session_required":            {EN: "this endpoint requires a login session", TH: "ต้องเข้าสู่ระบบด้วยรหัสผ่าน ใช้ access token ไม่ได้"},
+	"reauth_required":             {EN: "please confirm your password or sign in again", TH: "กรุณายืนยันรหัสผ่านหรือเข้าสู่ระบบใหม่"},
+	"weak_password":               {EN: "password does not meet the requirements", TH: "รหัสผ่านไม่ผ่านเงื่อนไข"},
+	"unknown_login_provider":      {EN: "unknown login provider", TH: "ไม่รู้จักผู้ให้บริการเข้าสู่ระบบนี้"},
+	"identity_not_found":          {EN: "linked account not found", TH: "ไม่พบบัญชีที่เชื่อมไว้"},
+	"identity_in_use":             {EN: "this account is already linked to another user", TH: "บัญชีนี้ถูกเชื่อมกับผู้ใช้อื่นแล้ว"},
+	"identity_not_linked":         {EN: "this account is not linked", TH: "บัญชีนี้ยังไม่ได้เชื่อม"},
+	"last_login_method":           {EN: "cannot remove your last login method", TH: "ลบวิธีเข้าสู่ระบบสุดท้ายไม่ได้"},
+	"mfa_not_enabled":             {EN: "2fa is not enabled", TH: "ยังไม่ได้เปิดการยืนยันสองขั้นตอน"},
+	"mfa_already_enabled":         {EN: "2fa is already enabled", TH: "เปิดการยืนยันสองขั้นตอนอยู่แล้ว"},
+	"invalid_mfa_code":            {EN: "invalid code", TH: "รหัสยืนยันไม่ถูกต้อง"},
+	"invalid_mfa_token":           {EN: "invalid or expired mfa token", TH: "ขั้นตอนยืนยันหมดอายุ กรุณาเข้าสู่ระบบใหม่"},
+	"passkey_not_found":           {EN: "passkey not found", TH: "ไม่พบพาสคีย์"},
+	"invalid_passkey":             {EN: "passkey verification failed", TH: "ยืนยันพาสคีย์ไม่สำเร็จ"},
+	"passkey_verification_failed": {EN: "passkey verification failed", TH: "ยืนยันพาสคีย์ไม่สำเร็จ"},
+	"invalid_role":                {EN: "invalid role", TH: "บทบาทไม่ถูกต้อง"},
+	"last_admin":                  {EN: "cannot demote the last admin", TH: "ลดสิทธิ์ผู้ดูแลคนสุดท้ายไม่ได้"},
+	"insufficient_scope":          {EN: "insufficient scope", TH: "access token นี้ไม่มีสิทธิ์ทำรายการนี้"},
+	"invalid_scope":               {EN: "invalid scope", TH: "ขอบเขตสิทธิ์ไม่ถูกต้อง"},
+	"access_token_not_found":      {EN: "access token not found", TH: "ไม่พบ access token"},
+	"access_token_limit":          {EN: "too many access tokens", TH: "มี access token ครบจำนวนสูงสุดแล้ว"},
+
+	// tasks
+	"task_not_found":     {EN: "task not found", TH: "ไม่พบงาน"},
+	"invalid_status":     {EN: "invalid status", TH: "สถานะไม่ถูกต้อง"},
+	"invalid_status_set": {EN: "invalid status set", TH: "ชุดสถานะไม่ถูกต้อง"},
+
+	// workspaces
+	"workspace_not_found":  {EN: "workspace not found", TH: "ไม่พบ workspace"},
+	"personal_workspace":   {EN: "not allowed on a personal workspace", TH: "ทำรายการนี้กับ workspace ส่วนตัวไม่ได้"},
+	"member_not_found":     {EN: "member not found", TH: "ไม่พบสมาชิกใน workspace นี้"},
+	"already_member":       {EN: "this person is already a member", TH: "ผู้ใช้นี้เป็นสมาชิกอยู่แล้ว"},
+	"invitation_not_found": {EN: "invitation not found", TH: "ไม่พบคำเชิญ"},
+	"invitation_email_mismatch": {
+		EN: "this invitation was sent to a different email address",
+		TH: "คำเชิญนี้ส่งถึงอีเมลอื่น กรุณาเข้าสู่ระบบด้วยอีเมลที่ได้รับคำเชิญ",
+	},
+	"project_not_found": {EN: "project not found", TH: "ไม่พบโปรเจกต์"},
+	"project_archived":  {EN: "project is archived", TH: "โปรเจกต์นี้ถูกเก็บถาวรแล้ว ย้ายงานเข้าไม่ได้"},
+	"invalid_parent": {
+		EN: "invalid parent task: it must be another task in the same workspace and project, and not one of its subtasks",
+		TH: "งานแม่ไม่ถูกต้อง ต้องเป็นงานอื่นใน workspace และโปรเจกต์เดียวกัน และไม่ใช่งานย่อยของงานนี้",
+	},
+	"task_too_deep":            {EN: "subtasks are nested too deeply", TH: "ซ้อนงานย่อยลึกเกินกว่าที่กำหนด"},
+	"checklist_item_not_found": {EN: "checklist item not found", TH: "ไม่พบรายการใน checklist"},
+	"checklist_full":           {EN: "this checklist already has the maximum number of items", TH: "checklist นี้มีรายการครบจำนวนสูงสุดแล้ว"},
+
+	// path parameters
+	"invalid_task_id":       {EN: "invalid task id", TH: "รหัสงานไม่ถูกต้อง"},
+	"invalid_user_id":       {EN: "invalid user id", TH: "รหัสผู้ใช้ไม่ถูกต้อง"},
+	"invalid_project_id":    {EN: "invalid project id", TH: "รหัสโปรเจกต์ไม่ถูกต้อง"},
+	"invalid_passkey_id":    {EN: "invalid passkey id", TH: "รหัสพาสคีย์ไม่ถูกต้อง"},
+	"invalid_token_id":      {EN: "invalid token id", TH: "รหัส access token ไม่ถูกต้อง"},
+	"invalid_account_id":    {EN: "invalid account id", TH: "รหัสบัญชีไม่ถูกต้อง"},
+	"invalid_workspace_id":  {EN: "invalid workspace id", TH: "รหัส workspace ไม่ถูกต้อง"},
+	"invalid_invitation_id": {EN: "invalid invitation id", TH: "รหัสคำเชิญไม่ถูกต้อง"},
+	"invalid_item_id":       {EN: "invalid checklist item id", TH: "รหัสรายการ checklist ไม่ถูกต้อง"},
+
+	// validation (ดู internal/validate)
+	"validation.invalid_json": {EN: "request body must be valid JSON", TH: "รูปแบบข้อมูลที่ส่งมาไม่ถูกต้อง"},
+	"validation.invalid":      {EN: "this value is invalid", TH: "ค่านี้ไม่ถูกต้อง"},
+	"validation.type":         {EN: "this value has the wrong type", TH: "ชนิดข้อมูลไม่ถูกต้อง"},
+	"validation.unknown":      {EN: "unknown field", TH: "ไม่รู้จักช่องข้อมูลนี้"},
+	"validation.required":     {EN: "%s is required", TH: "กรุณากรอก %s"},
+	"validation.email":        {EN: "must be a valid email address", TH: "รูปแบบอีเมลไม่ถูกต้อง"},
+	"validation.username": {
+		EN: "must be 3-30 characters using letters, numbers, _ or .",
+		TH: "ต้องยาว 3-30 ตัว ใช้ได้เฉพาะตัวอักษรอังกฤษ ตัวเลข _ และ .",
+	},
+	"validation.locale":     {EN: "must be one of: en, th", TH: "ต้องเป็น en หรือ th"},
+	"validation.color":      {EN: "must be a color like #3b82f6", TH: "ต้องเป็นรหัสสีรูปแบบ #3b82f6"},
+	"validation.timezone":   {EN: "must be a time zone like Asia/Bangkok", TH: "ต้องเป็นชื่อเขตเวลา เช่น Asia/Bangkok"},
+	"validation.min":        {EN: "must be at least %s", TH: "ต้องไม่น้อยกว่า %s"},
+	"validation.max":        {EN: "must be at most %s", TH: "ต้องไม่เกิน %s"},
+	"validation.len":        {EN: "must be exactly %s", TH: "ต้องเท่ากับ %s"},
+	"validation.min_length": {EN: "must be at least %s characters", TH: "ต้องยาวอย่างน้อย %s ตัวอักษร"},
+	"validation.max_length": {EN: "must be at most %s characters", TH: "ต้องยาวไม่เกิน %s ตัวอักษร"},
+	"validation.len_length": {EN: "must be exactly %s characters", TH: "ต้องยาว %s ตัวอักษร"},
+	"validation.oneof":      {EN: "must be one of: %s", TH: "ต้องเป็นค่าใดค่าหนึ่งต่อไปนี้: %s"},
+	"validation.date": {
+		EN: "must be a date in YYYY-MM-DD format between %s and %s",
+		TH: "ต้องเป็นวันที่รูปแบบ YYYY-MM-DD ระหว่าง %s ถึง %s",
+	},
+
+	// password policy (code จาก auth.PasswordPolicyError)
+	"password_too_short":  {EN: "password must be at least %d characters", TH: "รหัสผ่านต้องยาวอย่างน้อย %d ตัวอักษร"},
+	"password_too_long":   {EN: "password must be at most %d characters", TH: "รหัสผ่านต้องยาวไม่เกิน %d ตัวอักษร"},
+	"password_too_common": {EN: "password is too common, please choose another one", TH: "รหัสผ่านนี้ถูกใช้บ่อยเกินไป กรุณาตั้งรหัสใหม่"},
+	"password_too_long_bytes": {
+		EN: "password must be at most %d bytes (some characters, such as Thai, take 3 bytes each)",
+		TH: "รหัสผ่านต้องยาวไม่เกิน %d ไบต์ (ตัวอักษรไทยใช้ 3 ไบต์ต่อตัว)",
+	},
+	"password_matches_identity": {
+		EN: "password must not be the same as your username or email",
+		TH: "รหัสผ่านต้องไม่ซ้ำกับชื่อผู้ใช้หรืออีเมล",
+	},
+
+	// emails: subject / body (body รับ ระยะเวลา, ลิงก์)
+	"email.password_reset.subject": {EN: "Reset your Task Manager password", TH: "ตั้งรหัสผ่าน Task Manager ใหม่"},
+	"email.password_reset.body": {
+		EN: "Someone asked to reset the password for your Task Manager account.\n\n" +
+			"Open this link to choose a new password (valid for %d minutes):\n%s\n\n" +
+			"If you did not ask for this, you can ignore this email.\n",
+		TH: "มีคำขอตั้งรหัสผ่านใหม่สำหรับบัญชี Task Manager ของคุณ\n\n" +
+			"เปิดลิงก์นี้เพื่อตั้งรหัสผ่านใหม่ (ใช้ได้ %d นาที):\n%s\n\n" +
+			"หากคุณไม่ได้ขอ ไม่ต้องทำอะไร อีเมลนี้จะหมดอายุเอง\n",
+	},
+	"email.verify.subject": {EN: "Confirm your Task Manager email", TH: "ยืนยันอีเมลสำหรับ Task Manager"},
+	"email.verify.body": {
+		EN: "Welcome to Task Manager!\n\n" +
+			"Please confirm your email address by opening this link (valid for %d hours):\n%s\n",
+		TH: "ยินดีต้อนรับสู่ Task Manager!\n\n" +
+			"กรุณายืนยันอีเมลของคุณโดยเปิดลิงก์นี้ (ใช้ได้ %d ชั่วโมง):\n%s\n",
+	},
+	// invite: subject รับชื่อทีม, body รับ ผู้เชิญ, ชื่อทีม, ระยะเวลา (ชั่วโมง), ลิงก์
+	"email.invite.subject": {EN: "You're invited to join %s on Task Manager", TH: "คุณได้รับคำเชิญเข้าร่วมทีม %s บน Task Manager"},
+	"email.invite.body": {
+		EN: "%s invited you to join the team \"%s\" on Task Manager.\n\n" +
+			"Open this link to accept (valid for %d hours):\n%s\n\n" +
+			"If you don't have an account yet, you can sign up with this email address from the same link.\n",
+		TH: "%s เชิญคุณเข้าร่วมทีม \"%s\" บน Task Manager\n\n" +
+			"เปิดลิงก์นี้เพื่อตอบรับคำเชิญ (ใช้ได้ %d ชั่วโมง):\n%s\n\n" +
+			"ถ้ายังไม่มีบัญชี สมัครด้วยอีเมลนี้จากลิงก์เดียวกันได้เลย\n",
+	},
+	// task_updated: subject รับชื่องาน, body รับ ผู้แก้ไข, ชื่องาน, รายการที่เปลี่ยน, ลิงก์ My Work
+	"email.task_updated.subject": {EN: "Task updated: %s", TH: "งานมีการเปลี่ยนแปลง: %s"},
+	"email.task_updated.body": {
+		EN: "%s updated the task \"%s\":\n\n%s\n\nOpen My Work: %s\n\n" +
+			"You are receiving this because you watch this task.\n",
+		TH: "%s แก้ไขงาน \"%s\":\n\n%s\n\nเปิด My Work: %s\n\n" +
+			"คุณได้รับอีเมลนี้เพราะติดตามงานนี้อยู่\n",
+	},
+	// task_assigned: subject รับชื่องาน, body รับ ผู้มอบหมาย, ชื่องาน, ลิงก์ My Work
+	"email.task_assigned.subject": {EN: "You were assigned: %s", TH: "คุณได้รับมอบหมายงาน: %s"},
+	"email.task_assigned.body": {
+		EN: "%s assigned you to the task \"%s\".\n\nOpen My Work: %s\n",
+		TH: "%s มอบหมายงาน \"%s\" ให้คุณ\n\nเปิด My Work: %s\n",
+	},
+}
@@ -0,0 +1,125 @@
+// Package i18n holds the Thai/English message catalogue for API errors,
+// validation messages and emails, and picks a locale per request.
+//
+// ลำดับการเลือกภาษา: ค่าที่ผู้ใช้บันทึกไว้ (users.locale) > Accept-Language > EN
+package i18n
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// ภาษาที่ UI รองรับ
+const (
+	EN = "en"
+	TH = "th"
+
+	Default = EN
+)
+
+var supported = []string{EN, TH}
+
+// Supported lists the locales every catalogue entry must have
+func Supported() []string { return append([]string(nil), supported...) }
+
+// Normalize maps a language tag (th, th-TH, EN_us) to a supported locale
+func Normalize(tag string) (string, bool) {
+	tag = strings.ToLower(strings.TrimSpace(tag))
+	base, _, _ := strings.Cut(strings.ReplaceAll(tag, "_", "-"), "-")
+	for _, l := range supported {
+		if base == l {
+			return l, true
+		}
+	}
+	return "", false
+}
+
+// Negotiate picks the best supported locale from an Accept-Language header (คำนึงถึง q=)
+func Negotiate(header string) string {
+	best, bestQ := "", 0.0
+	for _, part := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if _, err := fmt.Sscanf(v, "%g", &q); err != nil {
+				continue
+			}
+		}
+		l, ok := Normalize(tag)
+		if !ok || q <= bestQ {
+			continue
+		}
+		best, bestQ = l, q
+	}
+	if best == "" {
+		return Default
+	}
+	return best
+}
+
+// Pick returns stored if it is a supported locale, otherwise fallback
+func Pick(stored, fallback string) string {
+	if l, ok := Normalize(stored); ok {
+		return l
+	}
+	if l, ok := Normalize(fallback); ok {
+		return l
+	}
+	return Default
+}
+
+type ctxKey struct{}
+
+// WithLocale stores the request locale in ctx (service ใช้เลือกภาษาอีเมล)
+func WithLocale(ctx context.Context, locale string) context.Context {
+	return context.WithValue(ctx, ctxKey{}, locale)
+}
+
+// FromContext returns the locale set by WithLocale, or Default
+func FromContext(ctx context.Context) string {
+	if l, ok := ctx.Value(ctxKey{}).(string); ok && l != "" {
+		return l
+	}
+	return Default
+}
+
+// Keyed is implemented by errors that carry their own catalogue key and arguments
+// (เช่น auth.PasswordPolicyError ที่บอกความยาวขั้นต่ำ) แทนการแปลจาก code อย่างเดียว
+type Keyed interface {
+	I18nKey() (key string, args []any)
+}
+
+// Has reports whether key is in the catalogue
+func Has(key string) bool {
+	_, ok := catalog[key]
+	return ok
+}
+
+// T formats the message for key in locale (ไม่มีคำแปล = อังกฤษ, ไม่มี key = คืน key)
+func T(locale, key string, args ...any) string {
+	m, ok := catalog[key]
+	if !ok {
+		return key
+	}
+	format, ok := m[locale]
+	if !ok {
+		format = m[Default]
+	}
+	if len(args) == 0 {
+		return format
+	}
+	return fmt.Sprintf(format, args...)
+}
+
+// Lookup is T without the fallbacks (ok = false ถ้าไม่มี key หรือไม่มีภาษานี้)
+func Lookup(locale, key string, args ...any) (string, bool) {
+	format, ok := catalog[key][locale]
+	if !ok {
+		return "", false
+	}
+	if len(args) == 0 {
+		return format, true
+	}
+	return fmt.Sprintf(format, args...), true
+}
@@ -0,0 +1,17 @@
+package i18n
+
+import (
+	"strings"
+	"testing"
+)
+
+// ทุก key ต้องมีครบทุกภาษา (ลืมแปล = เทสต์ไม่ผ่าน แทนที่จะไปเจอข้อความว่างตอนใช้งานจริง)
+func TestCatalogueHasEveryLocale(t *testing.T) {
+	for key, m := range catalog {
+		for _, l := range supported {
+			if strings.TrimSpace(m[l]) == "" {
+				t.Errorf("%s: missing %s translation", key, l)
+			}
+		}
+	}
+}
@@ -0,0 +1,134 @@
+package mail
+
+import (
+	"context"
+	"mime"
+	"net"
+	"net/textproto"
+	"strings"
+	"testing"
+	"time"
+)
+
+// smtpSession คือสิ่งที่ stub ได้รับจาก client หนึ่งครั้ง
+type smtpSession struct {
+	from string
+	rcpt []string
+	data string
+}
+
+// startSMTPStub เปิด SMTP server ปลอมใน process (ไม่มี STARTTLS/AUTH) รับได้หนึ่ง connection
+// rejectRcpt = ตอบ 550 ตอน RCPT TO แทน
+func startSMTPStub(t *testing.T, rejectRcpt bool) (SMTPConfig, <-chan smtpSession) {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	done := make(chan smtpSession, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		_ = conn.SetDeadline(time.Now().Add(5 * time.Second))
+		tp := textproto.NewConn(conn)
+		var s smtpSession
+		defer func() { done <- s }()
+
+		_ = tp.PrintfLine("220 stub ready")
+		for {
+			line, err := tp.ReadLine()
+			if err != nil {
+				return
+			}
+			verb, arg, _ := strings.Cut(line, " ")
+			switch strings.ToUpper(verb) {
+			case "EHLO", "HELO":
+				_ = tp.PrintfLine("250 stub")
+			case "MAIL":
+				s.from = strings.Trim(strings.TrimPrefix(arg, "FROM:"), "<>")
+				_ = tp.PrintfLine("250 ok")
+			case "RCPT":
+				if rejectRcpt {
+					_ = tp.PrintfLine("550 no such user")
+					continue
+				}
+				s.rcpt = append(s.rcpt, strings.Trim(strings.TrimPrefix(arg, "TO:"), "<>"))
+				_ = tp.PrintfLine("250 ok")
+			case "DATA":
+				_ = tp.PrintfLine("354 go ahead")
+				b, err := tp.ReadDotBytes()
+				if err != nil {
+					return
+				}
+				s.data = string(b)
+				_ = tp.PrintfLine("250 queued")
+			case "QUIT":
+				_ = tp.PrintfLine("221 bye")
+				return
+			default:
+				_ = tp.PrintfLine("502 not implemented")
+			}
+		}
+	}()
+
+	addr := ln.Addr().(*net.TCPAddr)
+	return SMTPConfig{Host: "127.0.0.1", Port: addr.Port, From: "Task Manager <noreply@example.com>"}, done
+}
+
+func TestSMTPMailerSend(t *testing.T) {
+	cfg, done := startSMTPStub(t, false)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	msg := Message{To: "alice@example.com", Subject: "ยืนยันอีเมล", Text: "สวัสดี\nคลิกลิงก์นี้"}
+	if err := NewSMTPMailer(cfg).Send(ctx, msg); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	s := <-done
+
+	// envelope sender เป็นแค่ที่อยู่ ไม่มีชื่อที่แสดง
+	if s.from != "noreply@example.com" {
+		t.Errorf("MAIL FROM = %q, want noreply@example.com", s.from)
+	}
+	if len(s.rcpt) != 1 || s.rcpt[0] != msg.To {
+		t.Errorf("RCPT TO = %v, want [%s]", s.rcpt, msg.To)
+	}
+
+	header, body, ok := strings.Cut(s.data, "\n\n")
+	if !ok {
+		t.Fatalf("message has no header/body separator:\n%s", s.data)
+	}
+	for _, want := range []string{
+		"From: Task Manager <noreply@example.com>",
+		"To: alice@example.com",
+		"Subject: " + mime.QEncoding.Encode("UTF-8", msg.Subject),
+		"Content-Type: text/plain; charset=UTF-8",
+	} {
+		if !strings.Contains(header, want+"\n") {
+			t.Errorf("header lacks %q:\n%s", want, header)
+		}
+	}
+	// ReadDotBytes แปลง CRLF เป็น LF ให้แล้ว
+	if body != "สวัสดี\nคลิกลิงก์นี้\n" {
+		t.Errorf("body = %q", body)
+	}
+}
+
+func TestSMTPMailerRejectedRecipient(t *testing.T) {
+	cfg, done := startSMTPStub(t, true)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	err := NewSMTPMailer(cfg).Send(ctx, Message{To: "nobody@example.com", Subject: "x", Text: "x"})
+	if err == nil || !strings.Contains(err.Error(), "smtp rcpt") {
+		t.Fatalf("Send = %v, want smtp rcpt error", err)
+	}
+	if s := <-done; s.data != "" {
+		t.Errorf("message was sent to a rejected recipient: %q", s.data)
+	}
+}
@@ -1,12 +1,31 @@
 package middleware
 
 import (
+	"context"
+	"errors"
+	"net/http"
 	"strings"
 	"task-manager/internal/auth"
+	"task-manager/internal/authctx"
+	"task-manager/internal/domain"
+	"task-manager/internal/i18n"
+	"task-manager/pkg/response"
 
 	"github.com/gin-gonic/gin"
 )
 
+// SessionChecker reports whether the session behind an access token is still valid
+// (implemented by service.SessionService)
+type SessionChecker interface {
+	CheckSession(ctx context.Context, sessionID string, userID int64) error
+}
+
+// TokenAuthenticator resolves a personal access token and its owner's current role
+// (implemented by service.AccessTokenService)
+type TokenAuthenticator interface {
+	Authenticate(ctx context.Context, raw string) (*domain.PersonalAccessToken, string, error)
+}
+
 // Authentication middleware
 func Authn(j *auth.JWT) gin.HandlerFunc {
 	return func(c *gin.Context) {
@@ -14,34 +33,64 @@ func Authn(j *auth.JWT) gin.HandlerFunc {
 	}
 }
 
-// JWTMiddleware validates JWT tokens
-func JWTMiddleware(jwtAuth *auth.JWT) gin.HandlerFunc {
+// JWTMiddleware validates JWT tokens and rejects tokens whose session was revoked.
+// Personal access tokens (tm_pat_...) are accepted too; route groups limit them with RequireScope
+func JWTMiddleware(jwtAuth *auth.JWT, sessions SessionChecker, tokens TokenAuthenticator) gin.HandlerFunc {
 	return gin.HandlerFunc(func(c *gin.Context) {
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
-			c.JSON(401, gin.H{"error": "authorization header required"})
-			c.Abort()
+			response.Fail(c, http.StatusUnauthorized, response.CodeUnauthorized, "authorization header required")
 			return
 		}
 
 		// Extract token from "Bearer <token>"
 		parts := strings.SplitN(authHeader, " ", 2)
 		if len(parts) != 2 || parts[0] != "Bearer" {
-			c.JSON(401, gin.H{"error": "invalid authorization header format"})
-			c.Abort()
+			response.Fail(c, http.StatusUnauthorized, response.CodeUnauthorized, "invalid authorization header format")
 			return
 		}
 
 		token := parts[1]
+		if strings.HasPrefix(token, auth.PersonalTokenPrefix) {
+			pat, role, err := tokens.Authenticate(c.Request.Context(), token)
+			if err != nil {
+				if errors.Is(err, domain.ErrInvalidToken) {
+					response.Fail(c, http.StatusUnauthorized, "invalid_token", "invalid token")
+				} else {
+					response.Error(c, err, "server error")
+				}
+				return
+			}
+			authctx.Set(c, &authctx.Principal{
+				UserID: pat.UserID,
+				Role:   role,
+				Method: authctx.MethodToken,
+				Scopes: pat.Scopes,
+			})
+			c.Next()
+			return
+		}
+
 		claims, err := (*jwtAuth).ParseAccess(token)
 		if err != nil {
-			c.JSON(401, gin.H{"error": "invalid token"})
-			c.Abort()
+			response.Fail(c, http.StatusUnauthorized, "invalid_token", "invalid token")
 			return
 		}
 
-		// Set user ID in context
-		c.Set("userID", claims.UserID)
+		if err := sessions.CheckSession(c.Request.Context(), claims.SessionID(), claims.UserID); err != nil {
+			response.Error(c, err, "server error")
+			return
+		}
+
+		authctx.Set(c, &authctx.Principal{
+			UserID:    claims.UserID,
+			Role:      claims.Role,
+			Method:    authctx.MethodSession,
+			SessionID: claims.SessionID(),
+		})
+		if l, ok := i18n.Normalize(claims.Locale); ok {
+			setLocale(c, l)
+		}
 		c.Next()
 	})
 }
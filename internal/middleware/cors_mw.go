@@ -7,9 +7,9 @@ import (
 )
 
 var allowed = map[string]bool{
-	"http://localhost:5173": true, // Vite
-	"http://localhost:5500": true, // Live Server
-	"http://127.0.0.1:5500": true, // Live Server (127)
+	"http://localhost:5173":                               true, // Vite
+	"http://localhost:5500":                               true, // Live Server
+	"http://127.0.0.1:5500":                               true, // Live Server (127)
 	"https://task-manager-production-6c61.up.railway.app": true, // Railway Production
 }
 
@@ -22,9 +22,10 @@ func CORSMiddleware() gin.HandlerFunc {
 		if allowed[origin] {
 			h.Set("Access-Control-Allow-Origin", origin)
 		}
-		h.Set("Vary", "Origin")
+		h.Add("Vary", "Origin")
 		h.Set("Access-Control-Allow-Methods", "GET,POST,PUT,PATCH,DELETE,OPTIONS")
-		h.Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
+		h.Set("Access-Control-Allow-Headers", "Content-Type, Authorization, X-Request-ID")
+		h.Set("Access-Control-Expose-Headers", "X-Request-ID, Retry-After")
 
 		// ไม่ใช้ cookies จึงไม่ต้อง Allow-Credentials
 
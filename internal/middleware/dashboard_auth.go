@@ -5,6 +5,7 @@ import (
 	"net/http"
 	"strings"
 	"task-manager/internal/auth"
+	"task-manager/internal/authctx"
 
 	"github.com/gin-gonic/gin"
 )
@@ -15,7 +16,7 @@ func DashboardAuthMiddleware(jwtAuth *auth.JWT, frontendURL string) gin.HandlerF
 		// Check for token in Authorization header first
 		authHeader := c.GetHeader("Authorization")
 		var token string
-		
+
 		if authHeader != "" {
 			// Extract token from "Bearer <token>"
 			parts := strings.SplitN(authHeader, " ", 2)
@@ -23,14 +24,14 @@ func DashboardAuthMiddleware(jwtAuth *auth.JWT, frontendURL string) gin.HandlerF
 				token = parts[1]
 			}
 		}
-		
+
 		// If no Authorization header, check cookie
 		if token == "" {
 			if cookie, err := c.Cookie("access_token"); err == nil {
 				token = cookie
 			}
 		}
-		
+
 		// If still no token, redirect to login
 		if token == "" {
 			log.Printf("Dashboard auth: No token found, redirecting to login")
@@ -38,7 +39,7 @@ func DashboardAuthMiddleware(jwtAuth *auth.JWT, frontendURL string) gin.HandlerF
 			c.Abort()
 			return
 		}
-		
+
 		// Validate token
 		claims, err := (*jwtAuth).ParseAccess(token)
 		if err != nil {
@@ -48,9 +49,14 @@ func DashboardAuthMiddleware(jwtAuth *auth.JWT, frontendURL string) gin.HandlerF
 			c.Abort()
 			return
 		}
-		
+
 		// Set user ID in context for potential API calls
-		c.Set("userID", claims.UserID)
+		authctx.Set(c, &authctx.Principal{
+			UserID:    claims.UserID,
+			Role:      claims.Role,
+			Method:    authctx.MethodSession,
+			SessionID: claims.SessionID(),
+		})
 		c.Next()
 	})
-}
\ No newline at end of file
+}
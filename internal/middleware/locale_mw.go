@@ -0,0 +1,23 @@
+package middleware
+
+import (
+	"task-manager/internal/i18n"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Locale picks the response language from Accept-Language; JWTMiddleware
+// overrides it with the user's saved locale (ภาษาที่ตั้งไว้ชนะภาษาของ browser)
+func Locale() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Writer.Header().Add("Vary", "Accept-Language")
+		setLocale(c, i18n.Negotiate(c.GetHeader("Accept-Language")))
+		c.Next()
+	}
+}
+
+// setLocale เก็บภาษาไว้ใน request context ให้ response / service (อีเมล) อ่านต่อได้
+func setLocale(c *gin.Context, locale string) {
+	c.Request = c.Request.WithContext(i18n.WithLocale(c.Request.Context(), locale))
+	c.Header("Content-Language", locale)
+}
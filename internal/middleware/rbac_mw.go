@@ -3,6 +3,10 @@ package middleware
 import (
 	"net/http"
 
+	"task-manager/internal/authctx"
+	"task-manager/internal/domain"
+	"task-manager/pkg/response"
+
 	"github.com/gin-gonic/gin"
 )
 
@@ -13,11 +17,34 @@ func RequireRoles(roles ...string) gin.HandlerFunc {
 		allowed[r] = struct{}{}
 	}
 	return func(c *gin.Context) {
-		role := c.GetString("role")
-		if _, ok := allowed[role]; !ok {
-			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "forbidden"})
+		p, ok := authctx.From(c)
+		if !ok {
+			response.Fail(c, http.StatusUnauthorized, response.CodeUnauthorized, "unauthorized")
+			return
+		}
+		if _, ok := allowed[p.Role]; !ok {
+			response.Fail(c, http.StatusForbidden, response.CodeForbidden, "forbidden")
 			return
 		}
 		c.Next()
 	}
 }
+
+// RequirePermission allows the request only if the role set by JWTMiddleware grants every perm
+// (ต้องวางหลัง JWTMiddleware)
+func RequirePermission(perms ...domain.Permission) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		p, ok := authctx.From(c)
+		if !ok {
+			response.Fail(c, http.StatusUnauthorized, response.CodeUnauthorized, "unauthorized")
+			return
+		}
+		for _, perm := range perms {
+			if !p.Can(perm) {
+				response.FailWith(c, http.StatusForbidden, response.CodeForbidden, "forbidden", gin.H{"permission": perm})
+				return
+			}
+		}
+		c.Next()
+	}
+}
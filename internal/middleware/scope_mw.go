@@ -0,0 +1,44 @@
+package middleware
+
+import (
+	"net/http"
+
+	"task-manager/internal/authctx"
+	"task-manager/pkg/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequireScope limits personal access tokens to route groups they were granted;
+// login session ปกติผ่านได้เสมอ (ต้องวางหลัง JWTMiddleware)
+func RequireScope(scope string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		p, ok := authctx.From(c)
+		if !ok {
+			response.Fail(c, http.StatusUnauthorized, response.CodeUnauthorized, "unauthorized")
+			return
+		}
+		if !p.HasScope(scope) {
+			response.FailWith(c, http.StatusForbidden, "insufficient_scope", "insufficient scope", gin.H{"scope": scope})
+			return
+		}
+		c.Next()
+	}
+}
+
+// RequireSession rejects personal access tokens: จัดการบัญชี (session, 2FA, passkey, token)
+// ต้องล็อกอินจริง token ที่หลุดจะยึดบัญชีไม่ได้
+func RequireSession() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		p, ok := authctx.From(c)
+		if !ok {
+			response.Fail(c, http.StatusUnauthorized, response.CodeUnauthorized, "unauthorized")
+			return
+		}
+		if !p.IsSession() {
+			response.Fail(c, http.StatusForbidden, "session_required", "this endpoint requires a login session")
+			return
+		}
+		c.Next()
+	}
+}
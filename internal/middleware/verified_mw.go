@@ -0,0 +1,39 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+
+	"task-manager/internal/authctx"
+	"task-manager/internal/domain"
+	"task-manager/pkg/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+// EmailVerificationChecker is implemented by service.EmailVerificationService
+type EmailVerificationChecker interface {
+	IsEmailVerified(ctx context.Context, userID int64) (bool, error)
+}
+
+// RequireVerifiedEmail blocks users who have not confirmed their email yet.
+// ต้องวางหลัง JWTMiddleware (อ่าน principal จาก context)
+func RequireVerifiedEmail(checker EmailVerificationChecker) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, ok := authctx.UserID(c)
+		if !ok {
+			response.Fail(c, http.StatusUnauthorized, response.CodeUnauthorized, "unauthorized")
+			return
+		}
+		verified, err := checker.IsEmailVerified(c.Request.Context(), userID)
+		if err != nil {
+			response.Error(c, err, "server error")
+			return
+		}
+		if !verified {
+			response.Error(c, domain.ErrEmailNotVerified, "email not verified")
+			return
+		}
+		c.Next()
+	}
+}
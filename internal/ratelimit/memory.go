@@ -0,0 +1,86 @@
+package ratelimit
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+)
+
+// เก็บ lockout ล่าสุดไว้เท่านี้ (backend นี้ไม่มีที่เก็บถาวร จึงพิมพ์ลง log ด้วย)
+const memoryLockoutHistory = 1000
+
+type memoryStore struct {
+	mu       sync.Mutex
+	entries  map[string]*Entry
+	lockouts []Lockout
+}
+
+// NewMemoryStore keeps counters in this process only (dev / single instance)
+func NewMemoryStore() Store {
+	return &memoryStore{entries: map[string]*Entry{}}
+}
+
+func (s *memoryStore) Get(ctx context.Context, key string) (Entry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if e, ok := s.entries[key]; ok {
+		return *e, nil
+	}
+	return Entry{}, nil
+}
+
+func (s *memoryStore) Fail(ctx context.Context, key string, now time.Time, window time.Duration) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	e, ok := s.entries[key]
+	if !ok {
+		e = &Entry{}
+		s.entries[key] = e
+	}
+	if e.Failures == 0 || e.WindowStart.Before(now.Add(-window)) {
+		e.Failures = 0
+		e.WindowStart = now
+	}
+	e.Failures++
+	return e.Failures, nil
+}
+
+func (s *memoryStore) Block(ctx context.Context, key string, until time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if e, ok := s.entries[key]; ok {
+		e.BlockedUntil = until
+	}
+	return nil
+}
+
+func (s *memoryStore) Reset(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.entries, key)
+	return nil
+}
+
+func (s *memoryStore) RecordLockout(ctx context.Context, l Lockout) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	log.Printf("ratelimit audit: lockout rule=%s subject=%q ip=%s failures=%d until=%s",
+		l.Rule, l.Subject, l.IP, l.Failures, l.LockedUntil.Format(time.RFC3339))
+	s.lockouts = append(s.lockouts, l)
+	if len(s.lockouts) > memoryLockoutHistory {
+		s.lockouts = s.lockouts[len(s.lockouts)-memoryLockoutHistory:]
+	}
+	return nil
+}
+
+func (s *memoryStore) Prune(ctx context.Context, now, before time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for k, e := range s.entries {
+		if e.WindowStart.Before(before) && !e.BlockedUntil.After(now) {
+			delete(s.entries, k)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,151 @@
+package ratelimit
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"log"
+	"time"
+)
+
+// Entry is the throttle state of one key
+type Entry struct {
+	Failures     int
+	WindowStart  time.Time
+	BlockedUntil time.Time // zero = ไม่ถูกบล็อก
+}
+
+// Lockout is one audit record: key ถูกล็อกเพราะผิดครบ Rule.LockAfter ครั้ง
+type Lockout struct {
+	Rule        string
+	Subject     string
+	IP          string
+	Failures    int
+	LockedUntil time.Time
+	CreatedAt   time.Time
+}
+
+// Store keeps counters; ใช้ interface เพื่อสลับ in-memory (instance เดียว) / database (หลาย replica)
+type Store interface {
+	Get(ctx context.Context, key string) (Entry, error)
+
+	// Fail เพิ่มตัวนับแบบ atomic (เริ่มนับใหม่ถ้าครั้งแรกเก่ากว่า window) แล้วคืนจำนวนล่าสุด
+	Fail(ctx context.Context, key string, now time.Time, window time.Duration) (int, error)
+	Block(ctx context.Context, key string, until time.Time) error
+	Reset(ctx context.Context, key string) error
+
+	RecordLockout(ctx context.Context, l Lockout) error
+
+	// Prune ลบ key ที่ window เก่ากว่า before และไม่ได้ถูกบล็อกอยู่
+	Prune(ctx context.Context, now, before time.Time) error
+}
+
+// Rule describes backoff and lockout for one kind of key
+type Rule struct {
+	Name      string        // prefix ของ key และชื่อใน audit
+	Free      int           // ผิดได้กี่ครั้งก่อนเริ่มหน่วง
+	BaseDelay time.Duration // หน่วงครั้งแรก แล้วเพิ่มเป็นสองเท่าทุกครั้งที่ผิด
+	LockAfter int           // ผิดครบกี่ครั้งใน Window แล้วล็อก
+	LockFor   time.Duration
+	Window    time.Duration
+}
+
+// ค่าเริ่มต้นของ login: ต่อบัญชีเข้มกว่าต่อ IP (IP เดียวอาจมีหลายคนหลัง NAT)
+var (
+	LoginByAccount = Rule{Name: "login-account", Free: 5, BaseDelay: time.Second, LockAfter: 10, LockFor: 15 * time.Minute, Window: time.Hour}
+	LoginByIP      = Rule{Name: "login-ip", Free: 20, BaseDelay: time.Second, LockAfter: 100, LockFor: 15 * time.Minute, Window: time.Hour}
+
+	// check-email นับทุกครั้งที่เรียก (กันไล่เช็คอีเมล)
+	CheckEmailByIP = Rule{Name: "check-email-ip", Free: 20, BaseDelay: time.Second, LockAfter: 60, LockFor: 15 * time.Minute, Window: 15 * time.Minute}
+
+	// รหัส 2FA (TOTP / recovery code) ผิดต่อผู้ใช้: TOTP มีแค่ล้านค่า และ login ด้วยรหัสผ่านใหม่ได้ mfa_token ใหม่เรื่อยๆ
+	MFAByUser = Rule{Name: "mfa-user", Free: 3, BaseDelay: time.Second, LockAfter: 10, LockFor: 15 * time.Minute, Window: time.Hour}
+)
+
+// delay คืนเวลาที่ต้องรอหลังผิดครบ failures ครั้ง และบอกว่าเป็นการล็อกหรือไม่
+func (r Rule) delay(failures int) (time.Duration, bool) {
+	if failures >= r.LockAfter {
+		return r.LockFor, true
+	}
+	if failures < r.Free {
+		return 0, false
+	}
+	shift := failures - r.Free
+	if shift > 20 {
+		return r.LockFor, false
+	}
+	d := r.BaseDelay << shift
+	if d > r.LockFor {
+		d = r.LockFor
+	}
+	return d, false
+}
+
+func (r Rule) key(subject string) string {
+	// subject มาจากผู้ใช้ ยาวเกินก็ hash ให้ขนาด key คงที่
+	if len(subject) > 128 {
+		sum := sha256.Sum256([]byte(subject))
+		subject = hex.EncodeToString(sum[:])
+	}
+	return r.Name + ":" + subject
+}
+
+type Limiter struct {
+	store Store
+	now   func() time.Time
+}
+
+func New(store Store) *Limiter {
+	return &Limiter{store: store, now: time.Now}
+}
+
+// Check คืนเวลาที่ต้องรอก่อนลองใหม่ (0 = ลองได้)
+func (l *Limiter) Check(ctx context.Context, rule Rule, subject string) (time.Duration, error) {
+	e, err := l.store.Get(ctx, rule.key(subject))
+	if err != nil {
+		return 0, err
+	}
+	if wait := e.BlockedUntil.Sub(l.now()); wait > 0 {
+		return wait, nil
+	}
+	return 0, nil
+}
+
+// Fail บันทึกความล้มเหลวหนึ่งครั้ง แล้วบล็อก key ตาม backoff (ip ใช้บันทึก audit ตอนล็อก)
+func (l *Limiter) Fail(ctx context.Context, rule Rule, subject, ip string) (time.Duration, error) {
+	now := l.now()
+	key := rule.key(subject)
+	failures, err := l.store.Fail(ctx, key, now, rule.Window)
+	if err != nil {
+		return 0, err
+	}
+	wait, locked := rule.delay(failures)
+	if wait <= 0 {
+		return 0, nil
+	}
+	if err := l.store.Block(ctx, key, now.Add(wait)); err != nil {
+		return 0, err
+	}
+	// บันทึกเฉพาะครั้งที่เพิ่งถึงเกณฑ์ ไม่ใช่ทุกครั้งที่ยังผิดต่อ
+	if locked && failures == rule.LockAfter {
+		log.Printf("ratelimit: %s locked %q for %s after %d failures (ip %s)", rule.Name, subject, wait, failures, ip)
+		if err := l.store.RecordLockout(ctx, Lockout{
+			Rule: rule.Name, Subject: subject, IP: ip, Failures: failures,
+			LockedUntil: now.Add(wait), CreatedAt: now,
+		}); err != nil {
+			return 0, err
+		}
+	}
+	return wait, nil
+}
+
+// Reset ล้างตัวนับ (เช่น หลัง login สำเร็จ)
+func (l *Limiter) Reset(ctx context.Context, rule Rule, subject string) error {
+	return l.store.Reset(ctx, rule.key(subject))
+}
+
+// Prune ลบ key ที่ไม่มีความเคลื่อนไหวนานกว่า maxAge
+func (l *Limiter) Prune(ctx context.Context, maxAge time.Duration) error {
+	now := l.now()
+	return l.store.Prune(ctx, now, now.Add(-maxAge))
+}
@@ -0,0 +1,100 @@
+package ratelimit
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+type sqlStore struct{ db *sql.DB }
+
+// NewSQLStore keeps counters in the auth_throttle table so every replica sees the same state
+// (SQL ใช้ได้ทั้ง Postgres และ SQLite)
+func NewSQLStore(db *sql.DB) Store { return &sqlStore{db: db} }
+
+func (s *sqlStore) Get(ctx context.Context, key string) (Entry, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	var (
+		e       Entry
+		blocked sql.NullTime
+	)
+	err := s.db.QueryRowContext(ctx, `
+		SELECT failures, window_start, blocked_until FROM auth_throttle WHERE throttle_key = $1
+	`, key).Scan(&e.Failures, &e.WindowStart, &blocked)
+	if errors.Is(err, sql.ErrNoRows) {
+		return Entry{}, nil
+	}
+	if err != nil {
+		return Entry{}, err
+	}
+	if blocked.Valid {
+		e.BlockedUntil = blocked.Time
+	}
+	return e, nil
+}
+
+func (s *sqlStore) Fail(ctx context.Context, key string, now time.Time, window time.Duration) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	// upsert ใน statement เดียว replica หลายตัวนับพร้อมกันได้โดยไม่หาย
+	var failures int
+	err := s.db.QueryRowContext(ctx, `
+		INSERT INTO auth_throttle (throttle_key, failures, window_start)
+		VALUES ($1, 1, $2)
+		ON CONFLICT (throttle_key) DO UPDATE SET
+			failures = CASE WHEN auth_throttle.window_start < $3 THEN 1 ELSE auth_throttle.failures + 1 END,
+			window_start = CASE WHEN auth_throttle.window_start < $3 THEN $2 ELSE auth_throttle.window_start END
+		RETURNING failures
+	`, key, now.UTC(), now.Add(-window).UTC()).Scan(&failures)
+	return failures, err
+}
+
+func (s *sqlStore) Block(ctx context.Context, key string, until time.Time) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	_, err := s.db.ExecContext(ctx, `UPDATE auth_throttle SET blocked_until = $1 WHERE throttle_key = $2`, until.UTC(), key)
+	return err
+}
+
+func (s *sqlStore) Reset(ctx context.Context, key string) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	_, err := s.db.ExecContext(ctx, `DELETE FROM auth_throttle WHERE throttle_key = $1`, key)
+	return err
+}
+
+func (s *sqlStore) RecordLockout(ctx context.Context, l Lockout) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO auth_lockouts (rule, subject, ip, failures, locked_until, created_at)
+		VALUES ($1,$2,$3,$4,$5,$6)
+	`, l.Rule, truncate(l.Subject, 255), l.IP, l.Failures, l.LockedUntil.UTC(), l.CreatedAt.UTC())
+	return err
+}
+
+func (s *sqlStore) Prune(ctx context.Context, now, before time.Time) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	_, err := s.db.ExecContext(ctx, `
+		DELETE FROM auth_throttle
+		WHERE window_start < $1 AND (blocked_until IS NULL OR blocked_until < $2)
+	`, before.UTC(), now.UTC())
+	return err
+}
+
+// truncate ตัดตามจำนวนตัวอักษร (ไม่ตัดกลาง UTF-8)
+func truncate(s string, n int) string {
+	if r := []rune(s); len(r) > n {
+		return string(r[:n])
+	}
+	return s
+}
@@ -0,0 +1,137 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+
+	"task-manager/internal/domain"
+)
+
+type AccessTokenRepo interface {
+	Create(ctx context.Context, t *domain.PersonalAccessToken) error
+	ListByUser(ctx context.Context, userID int64) ([]domain.PersonalAccessToken, error)
+	CountByUser(ctx context.Context, userID int64) (int, error)
+
+	// ค้นด้วย hash คืน role ปัจจุบันของเจ้าของมาด้วย (ไม่ต้อง query users ซ้ำทุก request)
+	GetByHash(ctx context.Context, hash string) (*domain.PersonalAccessToken, string, error)
+
+	// อัปเดต last_used_at ไม่เกินนาทีละครั้ง กันเขียน DB ทุก request
+	MarkUsed(ctx context.Context, id int64, at time.Time) error
+
+	// คืน ErrNotFound ถ้าไม่พบ หรือไม่ใช่ของผู้ใช้นี้
+	Delete(ctx context.Context, userID, id int64) error
+}
+
+type accessTokenRepo struct{ db *sql.DB }
+
+func NewAccessTokenRepo(db *sql.DB) AccessTokenRepo { return &accessTokenRepo{db: db} }
+
+const accessTokenColumns = `t.id, t.user_id, t.name, t.token_hash, t.token_prefix, t.scopes, t.expires_at, t.last_used_at, t.created_at`
+
+func scanAccessToken(row interface{ Scan(...any) error }, extra ...any) (*domain.PersonalAccessToken, error) {
+	var (
+		t      domain.PersonalAccessToken
+		scopes string
+	)
+	dest := append([]any{&t.ID, &t.UserID, &t.Name, &t.TokenHash, &t.Prefix, &scopes,
+		&t.ExpiresAt, &t.LastUsedAt, &t.CreatedAt}, extra...)
+	if err := row.Scan(dest...); err != nil {
+		return nil, err
+	}
+	// scopes เก็บคั่นด้วยช่องว่างแบบ OAuth
+	t.Scopes = strings.Fields(scopes)
+	return &t, nil
+}
+
+func (r *accessTokenRepo) Create(ctx context.Context, t *domain.PersonalAccessToken) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	t.CreatedAt = time.Now().UTC()
+	return r.db.QueryRowContext(ctx, `
+		INSERT INTO personal_access_tokens
+			(user_id, name, token_hash, token_prefix, scopes, expires_at, created_at)
+		VALUES ($1,$2,$3,$4,$5,$6,$7)
+		RETURNING id
+	`, t.UserID, t.Name, t.TokenHash, t.Prefix, strings.Join(t.Scopes, " "), t.ExpiresAt.UTC(), t.CreatedAt,
+	).Scan(&t.ID)
+}
+
+func (r *accessTokenRepo) ListByUser(ctx context.Context, userID int64) ([]domain.PersonalAccessToken, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+accessTokenColumns+`
+		FROM personal_access_tokens t
+		WHERE t.user_id = $1
+		ORDER BY t.created_at DESC, t.id DESC
+	`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []domain.PersonalAccessToken
+	for rows.Next() {
+		t, err := scanAccessToken(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, *t)
+	}
+	return out, rows.Err()
+}
+
+func (r *accessTokenRepo) CountByUser(ctx context.Context, userID int64) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	var n int
+	err := r.db.QueryRowContext(ctx,
+		`SELECT COUNT(*) FROM personal_access_tokens WHERE user_id = $1`, userID).Scan(&n)
+	return n, err
+}
+
+func (r *accessTokenRepo) GetByHash(ctx context.Context, hash string) (*domain.PersonalAccessToken, string, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	var role string
+	t, err := scanAccessToken(r.db.QueryRowContext(ctx, `
+		SELECT `+accessTokenColumns+`, u.role
+		FROM personal_access_tokens t
+		JOIN users u ON u.id = t.user_id
+		WHERE t.token_hash = $1
+	`, hash), &role)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, "", ErrNotFound
+		}
+		return nil, "", err
+	}
+	return t, role, nil
+}
+
+func (r *accessTokenRepo) MarkUsed(ctx context.Context, id int64, at time.Time) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE personal_access_tokens SET last_used_at = $1
+		WHERE id = $2 AND (last_used_at IS NULL OR last_used_at < $3)
+	`, at.UTC(), id, at.Add(-time.Minute).UTC())
+	return err
+}
+
+func (r *accessTokenRepo) Delete(ctx context.Context, userID, id int64) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	result, err := r.db.ExecContext(ctx,
+		`DELETE FROM personal_access_tokens WHERE id = $1 AND user_id = $2`, id, userID)
+	return affectedOrNotFound(result, err)
+}
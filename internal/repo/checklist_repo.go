@@ -0,0 +1,144 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"task-manager/internal/domain"
+)
+
+// ChecklistRepo: รายการ checklist ในงาน ผู้เรียกตรวจสิทธิ์ต่องานมาก่อนแล้ว
+// ทุก query กรองด้วย task_id ด้วย (รายการของงานอื่น = ErrNotFound)
+type ChecklistRepo interface {
+	List(ctx context.Context, taskID int64) ([]domain.ChecklistItem, error)
+
+	// Counts คืนจำนวนที่ติ๊กแล้ว / ทั้งหมดของหลายงานในครั้งเดียว (หน้า list) งานที่ไม่มีรายการไม่อยู่ใน map
+	Counts(ctx context.Context, taskIDs []int64) (map[int64]domain.Progress, error)
+
+	// Create ต่อท้ายรายการเดิม (position ถัดจากอันสุดท้าย)
+	Create(ctx context.Context, item *domain.ChecklistItem) (*domain.ChecklistItem, error)
+	Get(ctx context.Context, taskID, id int64) (*domain.ChecklistItem, error)
+
+	// แก้ title / done
+	Update(ctx context.Context, item *domain.ChecklistItem) error
+	Delete(ctx context.Context, taskID, id int64) error
+}
+
+type checklistRepo struct{ db *sql.DB }
+
+func NewChecklistRepo(db *sql.DB) ChecklistRepo { return &checklistRepo{db: db} }
+
+const checklistColumns = `id, task_id, title, done, position, created_at, updated_at`
+
+func scanChecklistItem(row interface{ Scan(...any) error }) (*domain.ChecklistItem, error) {
+	var it domain.ChecklistItem
+	if err := row.Scan(&it.ID, &it.TaskID, &it.Title, &it.Done, &it.Position, &it.CreatedAt, &it.UpdatedAt); err != nil {
+		return nil, err
+	}
+	return &it, nil
+}
+
+func (r *checklistRepo) List(ctx context.Context, taskID int64) ([]domain.ChecklistItem, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+checklistColumns+`
+		FROM task_checklist_items
+		WHERE task_id = $1
+		ORDER BY position, id
+	`, taskID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := []domain.ChecklistItem{}
+	for rows.Next() {
+		it, err := scanChecklistItem(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, *it)
+	}
+	return out, rows.Err()
+}
+
+func (r *checklistRepo) Counts(ctx context.Context, taskIDs []int64) (map[int64]domain.Progress, error) {
+	out := map[int64]domain.Progress{}
+	if len(taskIDs) == 0 {
+		return out, nil
+	}
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	marks, args := placeholders(1, taskIDs)
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT task_id, SUM(CASE WHEN done THEN 1 ELSE 0 END), COUNT(*)
+		FROM task_checklist_items
+		WHERE task_id IN (`+marks+`)
+		GROUP BY task_id
+	`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var taskID int64
+		var p domain.Progress
+		if err := rows.Scan(&taskID, &p.Done, &p.Total); err != nil {
+			return nil, err
+		}
+		out[taskID] = p
+	}
+	return out, rows.Err()
+}
+
+func (r *checklistRepo) Create(ctx context.Context, item *domain.ChecklistItem) (*domain.ChecklistItem, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	// CAST เพราะ Postgres เดาชนิดของ parameter ใน SELECT list ไม่ได้
+	return scanChecklistItem(r.db.QueryRowContext(ctx, `
+		INSERT INTO task_checklist_items (task_id, title, done, position)
+		SELECT CAST($1 AS BIGINT), CAST($2 AS VARCHAR(200)), CAST($3 AS BOOLEAN), COALESCE(MAX(position), 0) + 1
+		FROM task_checklist_items WHERE task_id = $1
+		RETURNING `+checklistColumns,
+		item.TaskID, item.Title, item.Done))
+}
+
+func (r *checklistRepo) Get(ctx context.Context, taskID, id int64) (*domain.ChecklistItem, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	it, err := scanChecklistItem(r.db.QueryRowContext(ctx, `
+		SELECT `+checklistColumns+` FROM task_checklist_items WHERE id = $1 AND task_id = $2
+	`, id, taskID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	return it, err
+}
+
+func (r *checklistRepo) Update(ctx context.Context, item *domain.ChecklistItem) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE task_checklist_items SET title = $1, done = $2, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $3 AND task_id = $4
+	`, item.Title, item.Done, item.ID, item.TaskID)
+	return affectedOrNotFound(result, err)
+}
+
+func (r *checklistRepo) Delete(ctx context.Context, taskID, id int64) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	result, err := r.db.ExecContext(ctx,
+		`DELETE FROM task_checklist_items WHERE id = $1 AND task_id = $2`, id, taskID)
+	return affectedOrNotFound(result, err)
+}
@@ -0,0 +1,142 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"task-manager/internal/domain"
+)
+
+type IdentityRepo interface {
+	// คืน ErrDuplicate ถ้า provider+subject ถูกผูกกับบัญชีใดอยู่แล้ว
+	Create(ctx context.Context, i *domain.Identity) error
+	GetByProviderSubject(ctx context.Context, provider, subject string) (*domain.Identity, error)
+	ListByUser(ctx context.Context, userID int64) ([]domain.Identity, error)
+	MarkUsed(ctx context.Context, id int64, usedAt time.Time) error
+
+	// ลบได้เฉพาะเมื่อผู้ใช้ยังเหลือวิธี login อื่น (คืน domain.ErrLastLoginMethod)
+	// คืน ErrNotFound ถ้าไม่พบ หรือไม่ใช่ของผู้ใช้นี้
+	Delete(ctx context.Context, userID, id int64) error
+}
+
+type identityRepo struct{ db *sql.DB }
+
+func NewIdentityRepo(db *sql.DB) IdentityRepo { return &identityRepo{db: db} }
+
+// ErrDuplicate is returned when a unique constraint would be violated
+var ErrDuplicate = errors.New("duplicate")
+
+// loginMethodCount นับวิธี login ที่เหลือของผู้ใช้ $2: identity ภายนอก + passkey + รหัสผ่าน
+const loginMethodCount = `(
+	(SELECT COUNT(*) FROM user_identities WHERE user_id = $2) +
+	(SELECT COUNT(*) FROM webauthn_credentials WHERE user_id = $2) +
+	(SELECT COUNT(*) FROM users WHERE id = $2 AND password_hash IS NOT NULL AND password_hash <> '')
+)`
+
+const identityColumns = `id, user_id, provider, subject, email, created_at, last_used_at`
+
+func scanIdentity(row interface{ Scan(...any) error }) (*domain.Identity, error) {
+	var i domain.Identity
+	if err := row.Scan(&i.ID, &i.UserID, &i.Provider, &i.Subject, &i.Email, &i.CreatedAt, &i.LastUsedAt); err != nil {
+		return nil, err
+	}
+	return &i, nil
+}
+
+func (r *identityRepo) Create(ctx context.Context, i *domain.Identity) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	// ON CONFLICT DO NOTHING ใช้ได้ทั้ง Postgres และ SQLite: ไม่มีแถวกลับมา = ถูกผูกไว้แล้ว
+	i.CreatedAt = time.Now().UTC()
+	err := r.db.QueryRowContext(ctx, `
+		INSERT INTO user_identities (user_id, provider, subject, email, created_at)
+		VALUES ($1,$2,$3,$4,$5)
+		ON CONFLICT (provider, subject) DO NOTHING
+		RETURNING id
+	`, i.UserID, i.Provider, i.Subject, i.Email, i.CreatedAt).Scan(&i.ID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrDuplicate
+	}
+	return err
+}
+
+func (r *identityRepo) GetByProviderSubject(ctx context.Context, provider, subject string) (*domain.Identity, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	i, err := scanIdentity(r.db.QueryRowContext(ctx,
+		`SELECT `+identityColumns+` FROM user_identities WHERE provider = $1 AND subject = $2`, provider, subject))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return i, nil
+}
+
+func (r *identityRepo) ListByUser(ctx context.Context, userID int64) ([]domain.Identity, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+identityColumns+`
+		FROM user_identities
+		WHERE user_id = $1
+		ORDER BY created_at ASC, id ASC
+	`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []domain.Identity
+	for rows.Next() {
+		i, err := scanIdentity(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, *i)
+	}
+	return out, rows.Err()
+}
+
+func (r *identityRepo) MarkUsed(ctx context.Context, id int64, usedAt time.Time) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	_, err := r.db.ExecContext(ctx, `UPDATE user_identities SET last_used_at = $1 WHERE id = $2`, usedAt.UTC(), id)
+	return err
+}
+
+func (r *identityRepo) Delete(ctx context.Context, userID, id int64) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	// เช็คจำนวนวิธี login ใน statement เดียวกับการลบ กันสองคำขอลบพร้อมกันจนไม่เหลือสักวิธี
+	result, err := r.db.ExecContext(ctx, `
+		DELETE FROM user_identities
+		WHERE id = $1 AND user_id = $2 AND `+loginMethodCount+` > 1
+	`, id, userID)
+	return deletedOrLastMethod(ctx, r.db, result, err,
+		`SELECT COUNT(*) FROM user_identities WHERE id = $1 AND user_id = $2`, id, userID)
+}
+
+// deletedOrLastMethod แยกกรณีลบไม่ได้เพราะไม่พบแถว (ErrNotFound) กับเพราะเป็นวิธี login สุดท้าย
+func deletedOrLastMethod(ctx context.Context, db *sql.DB, result sql.Result, err error, existsQuery string, id, userID int64) error {
+	err = affectedOrNotFound(result, err)
+	if !errors.Is(err, ErrNotFound) {
+		return err
+	}
+	var n int
+	if err := db.QueryRowContext(ctx, existsQuery, id, userID).Scan(&n); err != nil {
+		return err
+	}
+	if n > 0 {
+		return domain.ErrLastLoginMethod
+	}
+	return ErrNotFound
+}
@@ -0,0 +1,192 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"task-manager/internal/domain"
+)
+
+// InvitationRepo: query ฝั่ง admin กรองด้วย workspaceID เสมอ ส่วน Get/Accept ใช้ id จาก token ที่เซ็นไว้
+type InvitationRepo interface {
+	// สร้างคำเชิญ หรือถ้าอีเมลนี้มีคำเชิญที่รออยู่แล้ว อัปเดต role/token/วันหมดอายุของอันเดิม
+	UpsertPending(ctx context.Context, inv *domain.Invitation) (*domain.Invitation, error)
+
+	// คำเชิญที่ยังรออยู่ (รวมที่หมดอายุแล้ว ให้ admin กดส่งซ้ำได้)
+	ListPending(ctx context.Context, workspaceID int64) ([]*domain.Invitation, error)
+	GetInWorkspace(ctx context.Context, workspaceID, id int64) (*domain.Invitation, error)
+	Get(ctx context.Context, id int64) (*domain.Invitation, error)
+
+	// เปลี่ยน token id + วันหมดอายุ (ส่งซ้ำ) เฉพาะคำเชิญที่ยังรออยู่
+	Rotate(ctx context.Context, workspaceID, id int64, tokenID string, expiresAt, sentAt time.Time) error
+	Revoke(ctx context.Context, workspaceID, id int64) error
+
+	// รับคำเชิญและเพิ่มสมาชิกใน transaction เดียว (เป็นสมาชิกอยู่แล้ว = คง role เดิม)
+	// ใช้ไปแล้ว/ยกเลิก/หมดอายุ/token id ไม่ตรง = ErrNotFound
+	Accept(ctx context.Context, id int64, tokenID string, userID int64, now time.Time) error
+}
+
+type invitationRepo struct{ db *sql.DB }
+
+func NewInvitationRepo(db *sql.DB) InvitationRepo { return &invitationRepo{db: db} }
+
+const invitationSelect = `
+	SELECT i.id, i.workspace_id, i.email, i.role, i.token_id, i.status, i.invited_by,
+	       i.expires_at, i.sent_at, i.created_at, w.name, COALESCE(u.name, u.email)
+	FROM workspace_invitations i
+	JOIN workspaces w ON w.id = i.workspace_id
+	LEFT JOIN users u ON u.id = i.invited_by`
+
+func scanInvitation(row interface{ Scan(...any) error }) (*domain.Invitation, error) {
+	var i domain.Invitation
+	if err := row.Scan(
+		&i.ID, &i.WorkspaceID, &i.Email, &i.Role, &i.TokenID, &i.Status, &i.InvitedBy,
+		&i.ExpiresAt, &i.SentAt, &i.CreatedAt, &i.WorkspaceName, &i.InviterName,
+	); err != nil {
+		return nil, err
+	}
+	return &i, nil
+}
+
+func (r *invitationRepo) UpsertPending(ctx context.Context, inv *domain.Invitation) (*domain.Invitation, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var id int64
+	err = tx.QueryRowContext(ctx, `
+		UPDATE workspace_invitations
+		SET role = $1, token_id = $2, invited_by = $3, expires_at = $4, sent_at = $5
+		WHERE workspace_id = $6 AND email = $7 AND status = 'pending'
+		RETURNING id
+	`, inv.Role, inv.TokenID, inv.InvitedBy, inv.ExpiresAt.UTC(), inv.SentAt.UTC(),
+		inv.WorkspaceID, inv.Email).Scan(&id)
+	if errors.Is(err, sql.ErrNoRows) {
+		err = tx.QueryRowContext(ctx, `
+			INSERT INTO workspace_invitations
+			  (workspace_id, email, role, token_id, invited_by, expires_at, sent_at)
+			VALUES ($1,$2,$3,$4,$5,$6,$7)
+			RETURNING id
+		`, inv.WorkspaceID, inv.Email, inv.Role, inv.TokenID, inv.InvitedBy,
+			inv.ExpiresAt.UTC(), inv.SentAt.UTC()).Scan(&id)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return r.Get(ctx, id)
+}
+
+func (r *invitationRepo) ListPending(ctx context.Context, workspaceID int64) ([]*domain.Invitation, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	rows, err := r.db.QueryContext(ctx, invitationSelect+`
+		WHERE i.workspace_id = $1 AND i.status = 'pending'
+		ORDER BY i.created_at DESC, i.id DESC
+	`, workspaceID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := []*domain.Invitation{}
+	for rows.Next() {
+		i, err := scanInvitation(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, i)
+	}
+	return out, rows.Err()
+}
+
+func (r *invitationRepo) GetInWorkspace(ctx context.Context, workspaceID, id int64) (*domain.Invitation, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	i, err := scanInvitation(r.db.QueryRowContext(ctx, invitationSelect+`
+		WHERE i.id = $1 AND i.workspace_id = $2`, id, workspaceID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	return i, err
+}
+
+func (r *invitationRepo) Get(ctx context.Context, id int64) (*domain.Invitation, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	i, err := scanInvitation(r.db.QueryRowContext(ctx, invitationSelect+` WHERE i.id = $1`, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	return i, err
+}
+
+func (r *invitationRepo) Rotate(ctx context.Context, workspaceID, id int64, tokenID string, expiresAt, sentAt time.Time) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE workspace_invitations SET token_id = $1, expires_at = $2, sent_at = $3
+		WHERE id = $4 AND workspace_id = $5 AND status = 'pending'
+	`, tokenID, expiresAt.UTC(), sentAt.UTC(), id, workspaceID)
+	return affectedOrNotFound(result, err)
+}
+
+func (r *invitationRepo) Revoke(ctx context.Context, workspaceID, id int64) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE workspace_invitations SET status = 'revoked'
+		WHERE id = $1 AND workspace_id = $2 AND status = 'pending'
+	`, id, workspaceID)
+	return affectedOrNotFound(result, err)
+}
+
+func (r *invitationRepo) Accept(ctx context.Context, id int64, tokenID string, userID int64, now time.Time) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// status = pending อยู่ใน WHERE: สองคำขอพร้อมกันจะมีแค่อันเดียวที่เปลี่ยนแถวได้
+	var (
+		workspaceID int64
+		role        string
+	)
+	err = tx.QueryRowContext(ctx, `
+		UPDATE workspace_invitations
+		SET status = 'accepted', accepted_by = $1, accepted_at = $2
+		WHERE id = $3 AND token_id = $4 AND status = 'pending' AND expires_at > $2
+		RETURNING workspace_id, role
+	`, userID, now.UTC(), id, tokenID).Scan(&workspaceID, &role)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrNotFound
+		}
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `
+		INSERT INTO workspace_members (workspace_id, user_id, role) VALUES ($1, $2, $3)
+		ON CONFLICT (workspace_id, user_id) DO NOTHING
+	`, workspaceID, userID, role); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,183 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"task-manager/internal/domain"
+)
+
+type MFARepo interface {
+	// คืน ErrNotFound ถ้าผู้ใช้ยังไม่เคย setup
+	Get(ctx context.Context, userID int64) (*domain.UserMFA, error)
+
+	// สร้าง/แทนที่ secret ที่ยังไม่ได้เปิดใช้ (enabled_at = NULL)
+	UpsertPending(ctx context.Context, userID int64, secret string) error
+
+	// เปิดใช้ พร้อมบันทึก step ที่ใช้ยืนยัน และแทนที่ recovery codes ทั้งชุด
+	Enable(ctx context.Context, userID int64, step int64, codeHashes []string) error
+
+	// บันทึก step ล่าสุดที่ใช้ คืน false ถ้ามี step ที่ใหม่กว่าถูกใช้ไปแล้ว (กัน replay)
+	UseStep(ctx context.Context, userID int64, step int64) (bool, error)
+
+	// ใช้ recovery code (ครั้งเดียว) คืน false ถ้าไม่พบหรือถูกใช้แล้ว
+	UseRecoveryCode(ctx context.Context, userID int64, codeHash string) (bool, error)
+	ReplaceRecoveryCodes(ctx context.Context, userID int64, codeHashes []string) error
+	CountRecoveryCodes(ctx context.Context, userID int64) (int, error)
+
+	// ลบ MFA ทั้งหมดของผู้ใช้ (ปิดเอง / admin reset)
+	Delete(ctx context.Context, userID int64) error
+}
+
+type mfaRepo struct{ db *sql.DB }
+
+func NewMFARepo(db *sql.DB) MFARepo { return &mfaRepo{db: db} }
+
+func (r *mfaRepo) Get(ctx context.Context, userID int64) (*domain.UserMFA, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	var m domain.UserMFA
+	err := r.db.QueryRowContext(ctx, `
+		SELECT user_id, secret, last_used_step, enabled_at, created_at
+		FROM user_mfa WHERE user_id = $1
+	`, userID).Scan(&m.UserID, &m.Secret, &m.LastUsedStep, &m.EnabledAt, &m.CreatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return &m, nil
+}
+
+func (r *mfaRepo) UpsertPending(ctx context.Context, userID int64, secret string) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO user_mfa (user_id, secret, last_used_step, enabled_at, created_at)
+		VALUES ($1, $2, 0, NULL, $3)
+		ON CONFLICT (user_id) DO UPDATE
+		SET secret = EXCLUDED.secret, last_used_step = 0, enabled_at = NULL, created_at = EXCLUDED.created_at
+	`, userID, secret, time.Now().UTC())
+	return err
+}
+
+func (r *mfaRepo) Enable(ctx context.Context, userID int64, step int64, codeHashes []string) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, `
+		UPDATE user_mfa SET enabled_at = $1, last_used_step = $2
+		WHERE user_id = $3 AND enabled_at IS NULL
+	`, time.Now().UTC(), step, userID)
+	if err != nil {
+		return err
+	}
+	if n, err := result.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return ErrNotFound
+	}
+	if err := replaceRecoveryCodes(ctx, tx, userID, codeHashes); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (r *mfaRepo) UseStep(ctx context.Context, userID int64, step int64) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE user_mfa SET last_used_step = $1
+		WHERE user_id = $2 AND last_used_step < $1
+	`, step, userID)
+	if err != nil {
+		return false, err
+	}
+	n, err := result.RowsAffected()
+	return n == 1, err
+}
+
+func (r *mfaRepo) UseRecoveryCode(ctx context.Context, userID int64, codeHash string) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE mfa_recovery_codes SET used_at = $1
+		WHERE user_id = $2 AND code_hash = $3 AND used_at IS NULL
+	`, time.Now().UTC(), userID, codeHash)
+	if err != nil {
+		return false, err
+	}
+	n, err := result.RowsAffected()
+	return n > 0, err
+}
+
+func (r *mfaRepo) ReplaceRecoveryCodes(ctx context.Context, userID int64, codeHashes []string) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := replaceRecoveryCodes(ctx, tx, userID, codeHashes); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func replaceRecoveryCodes(ctx context.Context, tx *sql.Tx, userID int64, codeHashes []string) error {
+	if _, err := tx.ExecContext(ctx, `DELETE FROM mfa_recovery_codes WHERE user_id = $1`, userID); err != nil {
+		return err
+	}
+	for _, h := range codeHashes {
+		if _, err := tx.ExecContext(ctx,
+			`INSERT INTO mfa_recovery_codes (user_id, code_hash) VALUES ($1, $2)`, userID, h); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *mfaRepo) CountRecoveryCodes(ctx context.Context, userID int64) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	var n int
+	err := r.db.QueryRowContext(ctx,
+		`SELECT COUNT(*) FROM mfa_recovery_codes WHERE user_id = $1 AND used_at IS NULL`, userID).Scan(&n)
+	return n, err
+}
+
+func (r *mfaRepo) Delete(ctx context.Context, userID int64) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM mfa_recovery_codes WHERE user_id = $1`, userID); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM user_mfa WHERE user_id = $1`, userID); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
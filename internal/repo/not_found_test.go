@@ -0,0 +1,62 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"task-manager/internal/domain"
+)
+
+// แถวที่ไม่มี หรือเป็นของผู้ใช้อื่น = ErrNotFound (service แปลงเป็น error ของแต่ละชนิดเอง)
+func TestRepoNotFound(t *testing.T) {
+	database := newTestDB(t)
+	ctx := context.Background()
+	users, workspaces, projects, tokens := NewUserRepo(database), NewWorkspaceRepo(database), NewProjectRepo(database), NewAccessTokenRepo(database)
+
+	alice, bob := createTestUser(t, users, "alice"), createTestUser(t, users, "bob")
+	ws, err := workspaces.Create(ctx, "Alice's team", alice.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	proj, err := projects.Create(ctx, &domain.Project{WorkspaceID: ws.ID, Name: "Release", Color: "#3b82f6"}, alice.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	token := &domain.PersonalAccessToken{UserID: alice.ID, Name: "ci", TokenHash: "hash", Prefix: "tm_pat_abc", Scopes: []string{domain.ScopeTasksRead}, ExpiresAt: time.Now().Add(time.Hour)}
+	if err := tokens.Create(ctx, token); err != nil {
+		t.Fatal(err)
+	}
+	const missing = 999999
+
+	tests := []struct {
+		name string
+		call func() error
+	}{
+		{"user missing", func() error { _, err := users.GetByID(ctx, missing); return err }},
+		{"workspace missing", func() error { _, err := workspaces.GetForMember(ctx, missing, alice.ID); return err }},
+		{"workspace of someone else", func() error { _, err := workspaces.GetForMember(ctx, ws.ID, bob.ID); return err }},
+		{"rename missing workspace", func() error { return workspaces.Rename(ctx, missing, "x") }},
+		{"project missing", func() error { _, err := projects.GetForMember(ctx, missing, alice.ID); return err }},
+		{"project of someone else", func() error { _, err := projects.GetForMember(ctx, proj.ID, bob.ID); return err }},
+		{"update missing project", func() error { return projects.Update(ctx, &domain.Project{ID: missing, Name: "x", Color: "#000000"}) }},
+		{"delete missing token", func() error { return tokens.Delete(ctx, alice.ID, missing) }},
+		{"delete someone else's token", func() error { return tokens.Delete(ctx, bob.ID, token.ID) }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.call(); !errors.Is(err, ErrNotFound) {
+				t.Fatalf("got %v, want ErrNotFound", err)
+			}
+		})
+	}
+
+	// ของตัวเองยังอยู่ครบหลังเรียกข้างบน
+	if _, err := projects.GetForMember(ctx, proj.ID, alice.ID); err != nil {
+		t.Fatalf("own project: %v", err)
+	}
+	if list, err := tokens.ListByUser(ctx, alice.ID); err != nil || len(list) != 1 {
+		t.Fatalf("own tokens = %v, %v", list, err)
+	}
+}
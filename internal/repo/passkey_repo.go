@@ -0,0 +1,185 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"encoding/base64"
+	"errors"
+	"strings"
+	"time"
+
+	"task-manager/internal/domain"
+)
+
+type PasskeyRepo interface {
+	Create(ctx context.Context, p *domain.Passkey) error
+	ListByUser(ctx context.Context, userID int64) ([]domain.Passkey, error)
+
+	// อัปเดตหลัง login สำเร็จ (sign counter + เวลาใช้ล่าสุด)
+	MarkUsed(ctx context.Context, id int64, signCount uint32, usedAt time.Time) error
+
+	// คืน ErrNotFound ถ้าไม่พบ หรือไม่ใช่ของผู้ใช้นี้
+	Rename(ctx context.Context, userID, id int64, name string) error
+	// คืน domain.ErrLastLoginMethod ถ้าเป็นวิธี login สุดท้ายของผู้ใช้
+	Delete(ctx context.Context, userID, id int64) error
+}
+
+type passkeyRepo struct{ db *sql.DB }
+
+func NewPasskeyRepo(db *sql.DB) PasskeyRepo { return &passkeyRepo{db: db} }
+
+// ข้อมูล binary เก็บเป็น base64url เพื่อใช้ schema เดียวกันทั้ง Postgres และ SQLite
+var b64 = base64.RawURLEncoding
+
+func (r *passkeyRepo) Create(ctx context.Context, p *domain.Passkey) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	p.CreatedAt = time.Now().UTC()
+	return r.db.QueryRowContext(ctx, `
+		INSERT INTO webauthn_credentials
+			(user_id, credential_id, public_key, attestation_type, transports, aaguid, flags, sign_count, name, created_at)
+		VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10)
+		RETURNING id
+	`, p.UserID, b64.EncodeToString(p.CredentialID), b64.EncodeToString(p.PublicKey), p.AttestationType,
+		strings.Join(p.Transports, ","), b64.EncodeToString(p.AAGUID), int(p.Flags), int64(p.SignCount),
+		p.Name, p.CreatedAt,
+	).Scan(&p.ID)
+}
+
+func (r *passkeyRepo) ListByUser(ctx context.Context, userID int64) ([]domain.Passkey, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, user_id, credential_id, public_key, attestation_type, transports, aaguid,
+		       flags, sign_count, name, created_at, last_used_at
+		FROM webauthn_credentials
+		WHERE user_id = $1
+		ORDER BY created_at ASC, id ASC
+	`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []domain.Passkey
+	for rows.Next() {
+		var (
+			p                            domain.Passkey
+			credID, pubKey, aaguid, trns string
+			flags                        int
+			signCount                    int64
+		)
+		if err := rows.Scan(&p.ID, &p.UserID, &credID, &pubKey, &p.AttestationType, &trns, &aaguid,
+			&flags, &signCount, &p.Name, &p.CreatedAt, &p.LastUsedAt); err != nil {
+			return nil, err
+		}
+		if p.CredentialID, err = b64.DecodeString(credID); err != nil {
+			return nil, err
+		}
+		if p.PublicKey, err = b64.DecodeString(pubKey); err != nil {
+			return nil, err
+		}
+		if p.AAGUID, err = b64.DecodeString(aaguid); err != nil {
+			return nil, err
+		}
+		if trns != "" {
+			p.Transports = strings.Split(trns, ",")
+		}
+		p.Flags = uint8(flags)
+		p.SignCount = uint32(signCount)
+		out = append(out, p)
+	}
+	return out, rows.Err()
+}
+
+func (r *passkeyRepo) MarkUsed(ctx context.Context, id int64, signCount uint32, usedAt time.Time) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE webauthn_credentials SET sign_count = $1, last_used_at = $2 WHERE id = $3
+	`, int64(signCount), usedAt.UTC(), id)
+	return err
+}
+
+func (r *passkeyRepo) Rename(ctx context.Context, userID, id int64, name string) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE webauthn_credentials SET name = $1 WHERE id = $2 AND user_id = $3
+	`, name, id, userID)
+	return affectedOrNotFound(result, err)
+}
+
+func (r *passkeyRepo) Delete(ctx context.Context, userID, id int64) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	result, err := r.db.ExecContext(ctx, `
+		DELETE FROM webauthn_credentials
+		WHERE id = $1 AND user_id = $2 AND `+loginMethodCount+` > 1
+	`, id, userID)
+	return deletedOrLastMethod(ctx, r.db, result, err,
+		`SELECT COUNT(*) FROM webauthn_credentials WHERE id = $1 AND user_id = $2`, id, userID)
+}
+
+func affectedOrNotFound(result sql.Result, err error) error {
+	if err != nil {
+		return err
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+type WebAuthnSessionRepo interface {
+	Save(ctx context.Context, id string, data []byte, expiresAt time.Time) error
+
+	// ดึงแล้วลบทิ้งทันที (ใช้ได้ครั้งเดียว) คืน ErrNotFound ถ้าไม่มีหรือหมดอายุ
+	Consume(ctx context.Context, id string) ([]byte, error)
+}
+
+type webAuthnSessionRepo struct{ db *sql.DB }
+
+func NewWebAuthnSessionRepo(db *sql.DB) WebAuthnSessionRepo { return &webAuthnSessionRepo{db: db} }
+
+func (r *webAuthnSessionRepo) Save(ctx context.Context, id string, data []byte, expiresAt time.Time) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	now := time.Now().UTC()
+	// เก็บกวาด ceremony ที่หมดอายุไปพร้อมกัน
+	if _, err := r.db.ExecContext(ctx, `DELETE FROM webauthn_sessions WHERE expires_at < $1`, now); err != nil {
+		return err
+	}
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO webauthn_sessions (id, data, expires_at) VALUES ($1,$2,$3)
+	`, id, string(data), expiresAt.UTC())
+	return err
+}
+
+func (r *webAuthnSessionRepo) Consume(ctx context.Context, id string) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	var data string
+	err := r.db.QueryRowContext(ctx, `
+		DELETE FROM webauthn_sessions WHERE id = $1 AND expires_at > $2
+		RETURNING data
+	`, id, time.Now().UTC()).Scan(&data)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return []byte(data), nil
+}
@@ -0,0 +1,61 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+// PreferencesRepo stores each user's settings as a JSON document plus its schema version;
+// แปลง/ตรวจค่าอยู่ที่ service ส่วน repo ไม่รู้จัก key ข้างใน
+type PreferencesRepo interface {
+	// คืน ErrNotFound ถ้ายังไม่เคยบันทึก (ใช้ค่า default)
+	Get(ctx context.Context, userID int64) (version int, data []byte, err error)
+	Upsert(ctx context.Context, userID int64, version int, data []byte) error
+	Delete(ctx context.Context, userID int64) error
+}
+
+type preferencesRepo struct{ db *sql.DB }
+
+func NewPreferencesRepo(db *sql.DB) PreferencesRepo { return &preferencesRepo{db: db} }
+
+func (r *preferencesRepo) Get(ctx context.Context, userID int64) (int, []byte, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	var (
+		version int
+		data    string
+	)
+	err := r.db.QueryRowContext(ctx,
+		`SELECT version, data FROM user_preferences WHERE user_id = $1`, userID).Scan(&version, &data)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, nil, ErrNotFound
+		}
+		return 0, nil, err
+	}
+	return version, []byte(data), nil
+}
+
+func (r *preferencesRepo) Upsert(ctx context.Context, userID int64, version int, data []byte) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO user_preferences (user_id, version, data, updated_at)
+		VALUES ($1,$2,$3,$4)
+		ON CONFLICT (user_id) DO UPDATE
+		SET version = excluded.version, data = excluded.data, updated_at = excluded.updated_at
+	`, userID, version, string(data), time.Now().UTC())
+	return err
+}
+
+func (r *preferencesRepo) Delete(ctx context.Context, userID int64) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	_, err := r.db.ExecContext(ctx, `DELETE FROM user_preferences WHERE user_id = $1`, userID)
+	return err
+}
@@ -0,0 +1,218 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"task-manager/internal/domain"
+)
+
+// ProjectRepo: query ที่อ่านโปรเจกต์กรองด้วยสิทธิ์ของ userID เสมอ (สมาชิกของโปรเจกต์ หรือ admin ของ
+// workspace) ไม่มีสิทธิ์ = ErrNotFound (ไม่บอกว่ามีโปรเจกต์นี้อยู่)
+type ProjectRepo interface {
+	// สร้างโปรเจกต์ ผู้สร้างเป็น admin ของโปรเจกต์
+	Create(ctx context.Context, p *domain.Project, creatorID int64) (*domain.Project, error)
+
+	ListByWorkspace(ctx context.Context, workspaceID, userID int64, includeArchived bool) ([]*domain.Project, error)
+	GetForMember(ctx context.Context, id, userID int64) (*domain.Project, error)
+
+	// แก้ชื่อ/สี/เก็บถาวร
+	Update(ctx context.Context, p *domain.Project) error
+
+	// ลบโปรเจกต์ งานในโปรเจกต์ยังอยู่ใน workspace แต่ไม่มีโปรเจกต์
+	// remap: สถานะเดิม -> สถานะในชุด default (โปรเจกต์ที่ตั้งชุดสถานะเอง)
+	Delete(ctx context.Context, id int64, remap map[string]string) error
+
+	ListMembers(ctx context.Context, id int64) ([]domain.ProjectMember, error)
+
+	// เพิ่มสมาชิก (มีอยู่แล้ว = เปลี่ยน role) ต้องเป็นสมาชิกของ workspace ไม่งั้น ErrNotFound
+	SetMember(ctx context.Context, id, userID int64, role string) error
+	RemoveMember(ctx context.Context, id, userID int64) error
+}
+
+type projectRepo struct{ db *sql.DB }
+
+func NewProjectRepo(db *sql.DB) ProjectRepo { return &projectRepo{db: db} }
+
+// admin ของ workspace ได้ role admin ในทุกโปรเจกต์ แม้ไม่ได้เป็นสมาชิกของโปรเจกต์
+const projectColumns = `p.id, p.workspace_id, p.name, p.color, p.archived, p.created_by, p.created_at, p.updated_at,
+	CASE WHEN wm.role = 'admin' THEN 'admin' ELSE pm.role END`
+
+// accessibleProjects: โปรเจกต์ที่ $1 เข้าถึงได้
+const accessibleProjects = `
+	FROM projects p
+	JOIN workspace_members wm ON wm.workspace_id = p.workspace_id AND wm.user_id = $1
+	LEFT JOIN project_members pm ON pm.project_id = p.id AND pm.user_id = $1
+	WHERE (wm.role = 'admin' OR pm.user_id IS NOT NULL)`
+
+func scanProject(row interface{ Scan(...any) error }) (*domain.Project, error) {
+	var p domain.Project
+	if err := row.Scan(
+		&p.ID, &p.WorkspaceID, &p.Name, &p.Color, &p.Archived, &p.CreatedBy, &p.CreatedAt, &p.UpdatedAt, &p.Role,
+	); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+func (r *projectRepo) Create(ctx context.Context, p *domain.Project, creatorID int64) (*domain.Project, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var id int64
+	if err := tx.QueryRowContext(ctx, `
+		INSERT INTO projects (workspace_id, name, color, created_by) VALUES ($1, $2, $3, $4)
+		RETURNING id
+	`, p.WorkspaceID, p.Name, p.Color, creatorID).Scan(&id); err != nil {
+		return nil, err
+	}
+	if _, err := tx.ExecContext(ctx, `
+		INSERT INTO project_members (project_id, workspace_id, user_id, role) VALUES ($1, $2, $3, $4)
+	`, id, p.WorkspaceID, creatorID, domain.WorkspaceRoleAdmin); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return r.GetForMember(ctx, id, creatorID)
+}
+
+func (r *projectRepo) ListByWorkspace(ctx context.Context, workspaceID, userID int64, includeArchived bool) ([]*domain.Project, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	rows, err := r.db.QueryContext(ctx, `SELECT `+projectColumns+accessibleProjects+`
+		  AND p.workspace_id = $2 AND ($3 OR NOT p.archived)
+		ORDER BY p.archived, p.name, p.id`, userID, workspaceID, includeArchived)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := []*domain.Project{}
+	for rows.Next() {
+		p, err := scanProject(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, p)
+	}
+	return out, rows.Err()
+}
+
+func (r *projectRepo) GetForMember(ctx context.Context, id, userID int64) (*domain.Project, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	p, err := scanProject(r.db.QueryRowContext(ctx, `SELECT `+projectColumns+accessibleProjects+`
+		  AND p.id = $2`, userID, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	return p, err
+}
+
+func (r *projectRepo) Update(ctx context.Context, p *domain.Project) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE projects SET name = $1, color = $2, archived = $3, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $4
+	`, p.Name, p.Color, p.Archived, p.ID)
+	return affectedOrNotFound(result, err)
+}
+
+func (r *projectRepo) Delete(ctx context.Context, id int64, remap map[string]string) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for from, to := range remap {
+		if _, err := tx.ExecContext(ctx,
+			`UPDATE tasks SET status = $1 WHERE project_id = $2 AND status = $3`, to, id, from); err != nil {
+			return err
+		}
+	}
+	// ทำเองแทน ON DELETE SET NULL เพราะ SQLite ไม่ได้เปิด foreign_keys
+	if _, err := tx.ExecContext(ctx, `UPDATE tasks SET project_id = NULL WHERE project_id = $1`, id); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM task_statuses WHERE project_id = $1`, id); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM project_members WHERE project_id = $1`, id); err != nil {
+		return err
+	}
+	result, err := tx.ExecContext(ctx, `DELETE FROM projects WHERE id = $1`, id)
+	if err := affectedOrNotFound(result, err); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (r *projectRepo) ListMembers(ctx context.Context, id int64) ([]domain.ProjectMember, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT pm.project_id, pm.user_id, u.email, u.name, pm.role, pm.created_at
+		FROM project_members pm
+		JOIN users u ON u.id = pm.user_id
+		JOIN workspace_members wm ON wm.workspace_id = pm.workspace_id AND wm.user_id = pm.user_id
+		WHERE pm.project_id = $1
+		ORDER BY pm.created_at, pm.user_id
+	`, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := []domain.ProjectMember{}
+	for rows.Next() {
+		var m domain.ProjectMember
+		if err := rows.Scan(&m.ProjectID, &m.UserID, &m.Email, &m.Name, &m.Role, &m.JoinedAt); err != nil {
+			return nil, err
+		}
+		out = append(out, m)
+	}
+	return out, rows.Err()
+}
+
+func (r *projectRepo) SetMember(ctx context.Context, id, userID int64, role string) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	// SELECT จาก workspace_members: ไม่ใช่สมาชิกของ workspace = ไม่มีแถวให้ insert
+	result, err := r.db.ExecContext(ctx, `
+		INSERT INTO project_members (project_id, workspace_id, user_id, role)
+		SELECT p.id, p.workspace_id, wm.user_id, $3
+		FROM projects p
+		JOIN workspace_members wm ON wm.workspace_id = p.workspace_id AND wm.user_id = $2
+		WHERE p.id = $1
+		ON CONFLICT (project_id, user_id) DO UPDATE SET role = excluded.role
+	`, id, userID, role)
+	return affectedOrNotFound(result, err)
+}
+
+func (r *projectRepo) RemoveMember(ctx context.Context, id, userID int64) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	result, err := r.db.ExecContext(ctx,
+		`DELETE FROM project_members WHERE project_id = $1 AND user_id = $2`, id, userID)
+	return affectedOrNotFound(result, err)
+}
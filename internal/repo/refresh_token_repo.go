@@ -0,0 +1,99 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"task-manager/internal/domain"
+)
+
+type RefreshTokenRepo interface {
+	Create(ctx context.Context, t *domain.RefreshToken) error
+	GetByID(ctx context.Context, id string) (*domain.RefreshToken, error)
+
+	// ทำเครื่องหมายว่า token ถูกหมุนแล้ว คืน false ถ้าถูกใช้/เพิกถอนไปก่อนหน้า (แข่งกันใช้)
+	MarkRotated(ctx context.Context, id, replacedBy string) (bool, error)
+
+	// เพิกถอนทั้ง family (ใช้ตอน logout และตอนตรวจพบการ reuse)
+	RevokeFamily(ctx context.Context, familyID string) error
+
+	// เพิกถอนทุก token ของผู้ใช้
+	RevokeAllForUser(ctx context.Context, userID int64) error
+}
+
+type refreshTokenRepo struct{ db *sql.DB }
+
+func NewRefreshTokenRepo(db *sql.DB) RefreshTokenRepo { return &refreshTokenRepo{db: db} }
+
+func (r *refreshTokenRepo) Create(ctx context.Context, t *domain.RefreshToken) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO refresh_tokens (id, user_id, family_id, expires_at)
+		VALUES ($1,$2,$3,$4)
+	`, t.ID, t.UserID, t.FamilyID, t.ExpiresAt.UTC())
+	return err
+}
+
+func (r *refreshTokenRepo) GetByID(ctx context.Context, id string) (*domain.RefreshToken, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	var t domain.RefreshToken
+	err := r.db.QueryRowContext(ctx, `
+		SELECT id, user_id, family_id, expires_at, created_at, revoked_at, replaced_by
+		FROM refresh_tokens
+		WHERE id = $1
+	`, id).Scan(&t.ID, &t.UserID, &t.FamilyID, &t.ExpiresAt, &t.CreatedAt, &t.RevokedAt, &t.ReplacedBy)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return &t, nil
+}
+
+func (r *refreshTokenRepo) MarkRotated(ctx context.Context, id, replacedBy string) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE refresh_tokens
+		SET replaced_by = $1
+		WHERE id = $2 AND replaced_by IS NULL AND revoked_at IS NULL
+	`, replacedBy, id)
+	if err != nil {
+		return false, err
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return n == 1, nil
+}
+
+func (r *refreshTokenRepo) RevokeFamily(ctx context.Context, familyID string) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE refresh_tokens SET revoked_at = $1
+		WHERE family_id = $2 AND revoked_at IS NULL
+	`, time.Now().UTC(), familyID)
+	return err
+}
+
+func (r *refreshTokenRepo) RevokeAllForUser(ctx context.Context, userID int64) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE refresh_tokens SET revoked_at = $1
+		WHERE user_id = $2 AND revoked_at IS NULL
+	`, time.Now().UTC(), userID)
+	return err
+}
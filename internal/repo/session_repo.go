@@ -0,0 +1,172 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"task-manager/internal/domain"
+)
+
+type SessionRepo interface {
+	Create(ctx context.Context, s *domain.Session) error
+	GetByID(ctx context.Context, id string) (*domain.Session, error)
+
+	// ดึง session ที่ยังไม่ถูกเพิกถอนของผู้ใช้ (ล่าสุดก่อน)
+	ListByUser(ctx context.Context, userID int64) ([]*domain.Session, error)
+
+	// อัปเดต last_seen_at (และยืดอายุถ้า expiresAt ไม่ใช่ zero)
+	Touch(ctx context.Context, id string, lastSeen, expiresAt time.Time) error
+
+	// เพิกถอน session ของ userID คืน ErrNotFound ถ้าไม่พบ/ไม่ใช่ของผู้ใช้
+	Revoke(ctx context.Context, userID int64, id string) error
+
+	// เพิกถอนทุก session ของผู้ใช้ ยกเว้น exceptID (ว่าง = ทั้งหมด) คืน id ที่ถูกเพิกถอน
+	RevokeAll(ctx context.Context, userID int64, exceptID string) ([]string, error)
+}
+
+type sessionRepo struct{ db *sql.DB }
+
+func NewSessionRepo(db *sql.DB) SessionRepo { return &sessionRepo{db: db} }
+
+const sessionColumns = `id, user_id, user_agent, ip, created_at, last_seen_at, expires_at, revoked_at`
+
+func scanSession(row interface{ Scan(...any) error }) (*domain.Session, error) {
+	var s domain.Session
+	if err := row.Scan(
+		&s.ID, &s.UserID, &s.UserAgent, &s.IP, &s.CreatedAt, &s.LastSeenAt, &s.ExpiresAt, &s.RevokedAt,
+	); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+func (r *sessionRepo) Create(ctx context.Context, s *domain.Session) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	now := time.Now().UTC()
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO sessions (id, user_id, user_agent, ip, created_at, last_seen_at, expires_at)
+		VALUES ($1,$2,$3,$4,$5,$6,$7)
+	`, s.ID, s.UserID, s.UserAgent, s.IP, now, now, s.ExpiresAt.UTC())
+	return err
+}
+
+func (r *sessionRepo) GetByID(ctx context.Context, id string) (*domain.Session, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	s, err := scanSession(r.db.QueryRowContext(ctx,
+		`SELECT `+sessionColumns+` FROM sessions WHERE id = $1`, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return s, nil
+}
+
+func (r *sessionRepo) ListByUser(ctx context.Context, userID int64) ([]*domain.Session, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+sessionColumns+`
+		FROM sessions
+		WHERE user_id = $1 AND revoked_at IS NULL
+		ORDER BY last_seen_at DESC
+	`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []*domain.Session
+	for rows.Next() {
+		s, err := scanSession(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, s)
+	}
+	return out, rows.Err()
+}
+
+func (r *sessionRepo) Touch(ctx context.Context, id string, lastSeen, expiresAt time.Time) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	if expiresAt.IsZero() {
+		_, err := r.db.ExecContext(ctx,
+			`UPDATE sessions SET last_seen_at = $1 WHERE id = $2`, lastSeen.UTC(), id)
+		return err
+	}
+	_, err := r.db.ExecContext(ctx,
+		`UPDATE sessions SET last_seen_at = $1, expires_at = $2 WHERE id = $3`,
+		lastSeen.UTC(), expiresAt.UTC(), id)
+	return err
+}
+
+func (r *sessionRepo) Revoke(ctx context.Context, userID int64, id string) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE sessions SET revoked_at = $1
+		WHERE id = $2 AND user_id = $3 AND revoked_at IS NULL
+	`, time.Now().UTC(), id, userID)
+	if err != nil {
+		return err
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *sessionRepo) RevokeAll(ctx context.Context, userID int64, exceptID string) ([]string, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.QueryContext(ctx, `
+		SELECT id FROM sessions
+		WHERE user_id = $1 AND revoked_at IS NULL AND id <> $2
+	`, userID, exceptID)
+	if err != nil {
+		return nil, err
+	}
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE sessions SET revoked_at = $1
+		WHERE user_id = $2 AND revoked_at IS NULL AND id <> $3
+	`, time.Now().UTC(), userID, exceptID); err != nil {
+		return nil, err
+	}
+	return ids, tx.Commit()
+}
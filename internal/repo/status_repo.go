@@ -0,0 +1,77 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"task-manager/internal/domain"
+)
+
+type StatusRepo interface {
+	// ดึงชุดสถานะของโปรเจกต์ (ว่าง = ยังไม่ได้ตั้งค่าเอง)
+	ListByProject(ctx context.Context, projectID int64) (domain.StatusSet, error)
+
+	// แทนที่ชุดสถานะของโปรเจกต์ทั้งชุด พร้อมเปลี่ยนสถานะของงานในโปรเจกต์ตาม remap (เก่า -> ใหม่)
+	ReplaceForProject(ctx context.Context, projectID int64, set domain.StatusSet, remap map[string]string) error
+}
+
+type statusRepo struct{ db *sql.DB }
+
+func NewStatusRepo(db *sql.DB) StatusRepo { return &statusRepo{db: db} }
+
+func (r *statusRepo) ListByProject(ctx context.Context, projectID int64) (domain.StatusSet, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT status, label, category, position
+		FROM task_statuses
+		WHERE project_id = $1
+		ORDER BY position, id
+	`, projectID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var set domain.StatusSet
+	for rows.Next() {
+		var st domain.TaskStatus
+		if err := rows.Scan(&st.Status, &st.Label, &st.Category, &st.Position); err != nil {
+			return nil, err
+		}
+		set = append(set, st)
+	}
+	return set, rows.Err()
+}
+
+func (r *statusRepo) ReplaceForProject(ctx context.Context, projectID int64, set domain.StatusSet, remap map[string]string) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for from, to := range remap {
+		if _, err := tx.ExecContext(ctx,
+			`UPDATE tasks SET status = $1 WHERE project_id = $2 AND status = $3`, to, projectID, from); err != nil {
+			return err
+		}
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM task_statuses WHERE project_id = $1`, projectID); err != nil {
+		return err
+	}
+	for i, st := range set {
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO task_statuses (project_id, status, label, category, position)
+			VALUES ($1,$2,$3,$4,$5)
+		`, projectID, st.Status, st.Label, st.Category, i); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,183 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"strconv"
+	"strings"
+	"time"
+
+	"task-manager/internal/domain"
+)
+
+// TaskPeopleRepo: ผู้รับผิดชอบ (task_assignees) และผู้ติดตาม (task_watchers) ของงาน
+// ผู้เรียกตรวจสิทธิ์ต่องานมาก่อนแล้ว repo ตรวจแค่ว่าคนที่ถูกเพิ่มเห็นงานนั้นได้
+// คนที่เห็นงานไม่ได้แล้ว (ออกจาก workspace / โปรเจกต์) ไม่ถูกคืนจาก query อ่าน
+type TaskPeopleRepo interface {
+	ListAssignees(ctx context.Context, taskID int64) ([]domain.TaskPerson, error)
+
+	// AssigneeIDs คืน id ผู้รับผิดชอบของหลายงานในครั้งเดียว (หน้า list)
+	AssigneeIDs(ctx context.Context, taskIDs []int64) (map[int64][]int64, error)
+
+	// มอบหมายงานและให้ติดตามงานไปด้วย มีอยู่แล้ว = ไม่เปลี่ยน, userID เห็นงานไม่ได้ = ErrNotFound
+	Assign(ctx context.Context, taskID, userID, assignedBy int64) error
+	Unassign(ctx context.Context, taskID, userID int64) error
+
+	ListWatchers(ctx context.Context, taskID int64) ([]domain.TaskPerson, error)
+	Watch(ctx context.Context, taskID, userID int64) error
+	Unwatch(ctx context.Context, taskID, userID int64) error
+}
+
+type taskPeopleRepo struct{ db *sql.DB }
+
+func NewTaskPeopleRepo(db *sql.DB) TaskPeopleRepo { return &taskPeopleRepo{db: db} }
+
+// canSee: ผู้ใช้ในคอลัมน์ x.user_id ยังเห็นงาน t ได้ (เหมือน visibleTo ของ task_repo)
+const canSee = `
+	JOIN users u ON u.id = x.user_id
+	JOIN tasks t ON t.id = x.task_id
+	JOIN workspace_members wm ON wm.workspace_id = t.workspace_id AND wm.user_id = x.user_id
+	WHERE (t.project_id IS NULL OR wm.role = 'admin'
+	   OR EXISTS (SELECT 1 FROM project_members pm WHERE pm.project_id = t.project_id AND pm.user_id = x.user_id))`
+
+func (r *taskPeopleRepo) list(ctx context.Context, table string, taskID int64) ([]domain.TaskPerson, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT x.task_id, x.user_id, u.email, u.name, x.created_at
+		FROM `+table+` x`+canSee+`
+		  AND x.task_id = $1
+		ORDER BY x.created_at, x.user_id
+	`, taskID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := []domain.TaskPerson{}
+	for rows.Next() {
+		var p domain.TaskPerson
+		if err := rows.Scan(&p.TaskID, &p.UserID, &p.Email, &p.Name, &p.AddedAt); err != nil {
+			return nil, err
+		}
+		out = append(out, p)
+	}
+	return out, rows.Err()
+}
+
+func (r *taskPeopleRepo) ListAssignees(ctx context.Context, taskID int64) ([]domain.TaskPerson, error) {
+	return r.list(ctx, "task_assignees", taskID)
+}
+
+func (r *taskPeopleRepo) ListWatchers(ctx context.Context, taskID int64) ([]domain.TaskPerson, error) {
+	return r.list(ctx, "task_watchers", taskID)
+}
+
+func (r *taskPeopleRepo) AssigneeIDs(ctx context.Context, taskIDs []int64) (map[int64][]int64, error) {
+	out := map[int64][]int64{}
+	if len(taskIDs) == 0 {
+		return out, nil
+	}
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	marks, args := placeholders(1, taskIDs)
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT x.task_id, x.user_id
+		FROM task_assignees x`+canSee+`
+		  AND x.task_id IN (`+marks+`)
+		ORDER BY x.created_at, x.user_id
+	`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var taskID, userID int64
+		if err := rows.Scan(&taskID, &userID); err != nil {
+			return nil, err
+		}
+		out[taskID] = append(out[taskID], userID)
+	}
+	return out, rows.Err()
+}
+
+// placeholders คืน "$start, $start+1, ..." สำหรับ IN (...) พร้อม args
+func placeholders(start int, ids []int64) (string, []any) {
+	marks := make([]string, len(ids))
+	args := make([]any, len(ids))
+	for i, id := range ids {
+		marks[i] = "$" + strconv.Itoa(start+i)
+		args[i] = id
+	}
+	return strings.Join(marks, ", "), args
+}
+
+// insertVisible: INSERT ... SELECT จาก tasks เฉพาะเมื่อ $2 เห็นงาน $1 ได้ (ไม่มีแถว = เห็นไม่ได้)
+// CAST เพราะ Postgres เดาชนิดของ parameter ใน SELECT list ไม่ได้
+func insertVisible(table, columns, values string) string {
+	return `
+		INSERT INTO ` + table + ` (` + columns + `)
+		SELECT id, ` + values + ` FROM tasks
+		WHERE id = $1
+		  AND workspace_id IN (SELECT workspace_id FROM workspace_members WHERE user_id = $2)
+		  AND ` + visibleTo("$2")
+}
+
+func (r *taskPeopleRepo) Assign(ctx context.Context, taskID, userID, assignedBy int64) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// DO UPDATE ที่ไม่เปลี่ยนค่า แทน DO NOTHING เพื่อให้ "มีอยู่แล้ว" นับเป็น 1 แถว ต่างจาก "เห็นงานไม่ได้"
+	result, err := tx.ExecContext(ctx, insertVisible("task_assignees", "task_id, user_id, assigned_by",
+		`CAST($2 AS INTEGER), CAST($3 AS INTEGER)`)+`
+		ON CONFLICT (task_id, user_id) DO UPDATE SET assigned_by = task_assignees.assigned_by
+	`, taskID, userID, assignedBy)
+	if err := affectedOrNotFound(result, err); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `
+		INSERT INTO task_watchers (task_id, user_id) VALUES ($1, $2)
+		ON CONFLICT (task_id, user_id) DO NOTHING
+	`, taskID, userID); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (r *taskPeopleRepo) Watch(ctx context.Context, taskID, userID int64) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	result, err := r.db.ExecContext(ctx, insertVisible("task_watchers", "task_id, user_id",
+		`CAST($2 AS INTEGER)`)+`
+		ON CONFLICT (task_id, user_id) DO UPDATE SET created_at = task_watchers.created_at
+	`, taskID, userID)
+	return affectedOrNotFound(result, err)
+}
+
+func (r *taskPeopleRepo) Unassign(ctx context.Context, taskID, userID int64) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	result, err := r.db.ExecContext(ctx,
+		`DELETE FROM task_assignees WHERE task_id = $1 AND user_id = $2`, taskID, userID)
+	return affectedOrNotFound(result, err)
+}
+
+func (r *taskPeopleRepo) Unwatch(ctx context.Context, taskID, userID int64) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	result, err := r.db.ExecContext(ctx,
+		`DELETE FROM task_watchers WHERE task_id = $1 AND user_id = $2`, taskID, userID)
+	return affectedOrNotFound(result, err)
+}
@@ -3,15 +3,45 @@ package repo
 import (
 	"context"
 	"database/sql"
+	"errors"
+	"time"
 
 	"task-manager/internal/domain"
 )
 
+// TaskRepo: ทุก query กรองด้วย workspace (tenant) เสมอ ห้ามมี query ที่หางานด้วย id อย่างเดียว
+// งานในโปรเจกต์เห็นได้เฉพาะสมาชิกของโปรเจกต์และ admin ของ workspace (ดู visibleTo)
 type TaskRepo interface {
-	GetByUserID(ctx context.Context, userID int, limit int) ([]*domain.Task, error)
+	// ดึงงานใน workspace ที่ viewerID เห็นได้ทีละหน้า (ใหม่สุดก่อน) topLevel = เฉพาะงานที่ไม่มีงานแม่
+	ListByWorkspace(ctx context.Context, workspaceID, viewerID int64, topLevel bool, limit, offset int) ([]*domain.Task, error)
+	CountByWorkspace(ctx context.Context, workspaceID, viewerID int64, topLevel bool) (int, error)
+
+	// ดึงงานในโปรเจกต์ (ผู้เรียกตรวจสิทธิ์เข้าถึงโปรเจกต์มาก่อนแล้ว)
+	ListByProject(ctx context.Context, projectID int64, topLevel bool, limit, offset int) ([]*domain.Task, error)
+	CountByProject(ctx context.Context, projectID int64, topLevel bool) (int, error)
+
+	// งานย่อยชั้นถัดไป / ทั้งต้นไม้ (ไม่รวม parentIDs เอง) เรียงตาม created_at
+	// (ไม่รับประกันว่างานแม่มาก่อนงานย่อย: ย้ายงานเก่าไปไว้ใต้งานใหม่ได้)
+	// งานย่อยอยู่ workspace และโปรเจกต์เดียวกับงานแม่เสมอ ใครเห็นงานแม่ก็เห็นงานย่อย
+	ListChildren(ctx context.Context, parentIDs []int64) ([]*domain.Task, error)
+	ListDescendants(ctx context.Context, parentIDs []int64) ([]*domain.Task, error)
+
+	// Ancestors คืน id ของงานแม่ทุกชั้นของ id (งานระดับบน = ว่าง)
+	Ancestors(ctx context.Context, id int64) ([]int64, error)
+
+	// งานที่มอบหมายให้ userID จากทุก workspace ที่เป็นสมาชิก (My Work) เรียงตามกำหนดส่ง ไม่มีกำหนดอยู่ท้าย
+	ListAssignedTo(ctx context.Context, userID int64, limit int) ([]*domain.Task, error)
+
+	// ดึงงานเดียว เฉพาะใน workspace ที่ memberID เป็นสมาชิกและเห็นงานนั้นได้ (ไม่ใช่ = ErrTaskNotFound)
+	GetForMember(ctx context.Context, id, memberID int64) (*domain.Task, error)
+
 	Create(ctx context.Context, task *domain.Task) (*domain.Task, error)
-	Update(ctx context.Context, task *domain.Task) error
-	Delete(ctx context.Context, id int, userID int) error
+
+	// remap != nil = ย้ายโปรเจกต์: งานย่อยทั้งต้นไม้ย้ายตาม และเปลี่ยนสถานะเดิม -> สถานะในชุดของปลายทาง
+	Update(ctx context.Context, task *domain.Task, remap map[string]string) error
+
+	// mode: งานย่อยกลายเป็นงานระดับบน (orphan) หรือถูกลบทั้งต้นไม้ (cascade)
+	Delete(ctx context.Context, workspaceID, id int64, mode domain.ChildDeleteMode) error
 }
 
 type taskRepo struct {
@@ -22,22 +52,302 @@ func NewTaskRepo(db *sql.DB) TaskRepo {
 	return &taskRepo{db: db}
 }
 
-func (r *taskRepo) GetByUserID(ctx context.Context, userID int, limit int) ([]*domain.Task, error) {
-	// For now, return empty slice to prevent errors
-	return []*domain.Task{}, nil
+const taskColumns = `id, workspace_id, project_id, parent_id, owner_id, title, description, status, priority, due_date, created_at, updated_at`
+
+func scanTask(row interface{ Scan(...any) error }) (*domain.Task, error) {
+	var t domain.Task
+	if err := row.Scan(
+		&t.ID, &t.WorkspaceID, &t.ProjectID, &t.ParentID, &t.OwnerID, &t.Title, &t.Description, &t.Status, &t.Priority, &t.DueDate,
+		&t.CreatedAt, &t.UpdatedAt,
+	); err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// visibleTo: งานที่ user เห็นได้ = ไม่อยู่ในโปรเจกต์, อยู่ในโปรเจกต์ที่เป็นสมาชิก หรือเป็น admin ของ workspace
+// (ผู้เรียกยังต้องกรองด้วย workspace ที่ user เป็นสมาชิกเอง)
+func visibleTo(user string) string {
+	return `(project_id IS NULL
+		OR project_id IN (SELECT project_id FROM project_members WHERE user_id = ` + user + `)
+		OR workspace_id IN (SELECT workspace_id FROM workspace_members WHERE user_id = ` + user + ` AND role = 'admin'))`
+}
+
+// subtree: CTE "sub" = id ของงานย่อยทุกชั้นใต้ parents (UNION ตัดวนถ้าข้อมูลเสีย)
+func subtree(parents string) string {
+	return `WITH RECURSIVE sub(id) AS (
+			SELECT id FROM tasks WHERE parent_id IN (` + parents + `)
+			UNION
+			SELECT t.id FROM tasks t JOIN sub ON t.parent_id = sub.id
+		) `
+}
+
+func (r *taskRepo) list(ctx context.Context, query string, args ...any) ([]*domain.Task, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tasks := []*domain.Task{}
+	for rows.Next() {
+		t, err := scanTask(rows)
+		if err != nil {
+			return nil, err
+		}
+		tasks = append(tasks, t)
+	}
+	return tasks, rows.Err()
+}
+
+func (r *taskRepo) count(ctx context.Context, query string, args ...any) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	var n int
+	err := r.db.QueryRowContext(ctx, query, args...).Scan(&n)
+	return n, err
+}
+
+func (r *taskRepo) ListByWorkspace(ctx context.Context, workspaceID, viewerID int64, topLevel bool, limit, offset int) ([]*domain.Task, error) {
+	return r.list(ctx, `
+		SELECT `+taskColumns+`
+		FROM tasks
+		WHERE workspace_id = $1 AND `+visibleTo("$2")+` AND (NOT $3 OR parent_id IS NULL)
+		ORDER BY created_at DESC, id DESC
+		LIMIT $4 OFFSET $5
+	`, workspaceID, viewerID, topLevel, limit, offset)
+}
+
+func (r *taskRepo) CountByWorkspace(ctx context.Context, workspaceID, viewerID int64, topLevel bool) (int, error) {
+	return r.count(ctx, `SELECT COUNT(*) FROM tasks
+		WHERE workspace_id = $1 AND `+visibleTo("$2")+` AND (NOT $3 OR parent_id IS NULL)`,
+		workspaceID, viewerID, topLevel)
+}
+
+func (r *taskRepo) ListByProject(ctx context.Context, projectID int64, topLevel bool, limit, offset int) ([]*domain.Task, error) {
+	return r.list(ctx, `
+		SELECT `+taskColumns+`
+		FROM tasks
+		WHERE project_id = $1 AND (NOT $2 OR parent_id IS NULL)
+		ORDER BY created_at DESC, id DESC
+		LIMIT $3 OFFSET $4
+	`, projectID, topLevel, limit, offset)
+}
+
+func (r *taskRepo) CountByProject(ctx context.Context, projectID int64, topLevel bool) (int, error) {
+	return r.count(ctx, `SELECT COUNT(*) FROM tasks WHERE project_id = $1 AND (NOT $2 OR parent_id IS NULL)`,
+		projectID, topLevel)
+}
+
+func (r *taskRepo) ListChildren(ctx context.Context, parentIDs []int64) ([]*domain.Task, error) {
+	if len(parentIDs) == 0 {
+		return []*domain.Task{}, nil
+	}
+	marks, args := placeholders(1, parentIDs)
+	return r.list(ctx, `
+		SELECT `+taskColumns+`
+		FROM tasks
+		WHERE parent_id IN (`+marks+`)
+		ORDER BY created_at, id
+	`, args...)
+}
+
+func (r *taskRepo) ListDescendants(ctx context.Context, parentIDs []int64) ([]*domain.Task, error) {
+	if len(parentIDs) == 0 {
+		return []*domain.Task{}, nil
+	}
+	marks, args := placeholders(1, parentIDs)
+	return r.list(ctx, subtree(marks)+`
+		SELECT `+taskColumns+`
+		FROM tasks
+		WHERE id IN (SELECT id FROM sub)
+		ORDER BY created_at, id
+	`, args...)
+}
+
+func (r *taskRepo) Ancestors(ctx context.Context, id int64) ([]int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	rows, err := r.db.QueryContext(ctx, `
+		WITH RECURSIVE up(id, parent_id) AS (
+			SELECT id, parent_id FROM tasks WHERE id = $1
+			UNION
+			SELECT t.id, t.parent_id FROM tasks t JOIN up ON t.id = up.parent_id
+		)
+		SELECT id FROM up WHERE id <> $1
+	`, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := []int64{}
+	for rows.Next() {
+		var ancestor int64
+		if err := rows.Scan(&ancestor); err != nil {
+			return nil, err
+		}
+		out = append(out, ancestor)
+	}
+	return out, rows.Err()
+}
+
+func (r *taskRepo) ListAssignedTo(ctx context.Context, userID int64, limit int) ([]*domain.Task, error) {
+	return r.list(ctx, `
+		SELECT `+taskColumns+`
+		FROM tasks
+		WHERE id IN (SELECT task_id FROM task_assignees WHERE user_id = $1)
+		  AND workspace_id IN (SELECT workspace_id FROM workspace_members WHERE user_id = $1)
+		  AND `+visibleTo("$1")+`
+		ORDER BY due_date IS NULL, due_date, id
+		LIMIT $2
+	`, userID, limit)
+}
+
+func (r *taskRepo) GetForMember(ctx context.Context, id, memberID int64) (*domain.Task, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	row := r.db.QueryRowContext(ctx, `
+		SELECT `+taskColumns+`
+		FROM tasks
+		WHERE id = $1
+		  AND workspace_id IN (SELECT workspace_id FROM workspace_members WHERE user_id = $2)
+		  AND `+visibleTo("$2")+`
+	`, id, memberID)
+
+	t, err := scanTask(row)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrTaskNotFound
+		}
+		return nil, err
+	}
+	return t, nil
 }
 
 func (r *taskRepo) Create(ctx context.Context, task *domain.Task) (*domain.Task, error) {
-	// Placeholder implementation
-	return task, nil
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	row := r.db.QueryRowContext(ctx, `
+		INSERT INTO tasks (workspace_id, project_id, parent_id, owner_id, title, description, status, priority, due_date)
+		VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9)
+		RETURNING `+taskColumns,
+		task.WorkspaceID, task.ProjectID, task.ParentID, task.OwnerID, task.Title, task.Description, task.Status,
+		task.Priority, task.DueDate,
+	)
+	return scanTask(row)
 }
 
-func (r *taskRepo) Update(ctx context.Context, task *domain.Task) error {
-	// Placeholder implementation
-	return nil
+func (r *taskRepo) Update(ctx context.Context, task *domain.Task, remap map[string]string) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, `
+		UPDATE tasks
+		SET title = $1, description = $2, status = $3, priority = $4, due_date = $5, project_id = $6,
+		    parent_id = $7, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $8 AND workspace_id = $9
+	`, task.Title, task.Description, task.Status, task.Priority, task.DueDate, task.ProjectID,
+		task.ParentID, task.ID, task.WorkspaceID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return domain.ErrTaskNotFound
+	}
+
+	if remap != nil {
+		for from, to := range remap {
+			if _, err := tx.ExecContext(ctx, subtree("$3")+`
+				UPDATE tasks SET status = $1 WHERE status = $2 AND id IN (SELECT id FROM sub)
+			`, to, from, task.ID); err != nil {
+				return err
+			}
+		}
+		if _, err := tx.ExecContext(ctx, subtree("$2")+`
+			UPDATE tasks SET project_id = $1, updated_at = CURRENT_TIMESTAMP WHERE id IN (SELECT id FROM sub)
+		`, task.ProjectID, task.ID); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
 }
 
-func (r *taskRepo) Delete(ctx context.Context, id int, userID int) error {
-	// Placeholder implementation
-	return nil
+func (r *taskRepo) Delete(ctx context.Context, workspaceID, id int64, mode domain.ChildDeleteMode) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// เก็บ id ของงานย่อยก่อนลบงานแม่: บน Postgres ON DELETE SET NULL ล้าง parent_id ของงานย่อยทันที
+	ids := []int64{id}
+	if mode == domain.ChildrenCascade {
+		rows, err := tx.QueryContext(ctx, subtree("$1")+`SELECT id FROM sub`, id)
+		if err != nil {
+			return err
+		}
+		for rows.Next() {
+			var child int64
+			if err := rows.Scan(&child); err != nil {
+				rows.Close()
+				return err
+			}
+			ids = append(ids, child)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return err
+		}
+	}
+
+	result, err := tx.ExecContext(ctx,
+		`DELETE FROM tasks WHERE id = $1 AND workspace_id = $2`, id, workspaceID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return domain.ErrTaskNotFound
+	}
+
+	// ทำเองแทน ON DELETE CASCADE / SET NULL เพราะ SQLite ไม่ได้เปิด foreign_keys
+	marks, args := placeholders(1, ids)
+	for _, q := range []string{
+		`UPDATE tasks SET parent_id = NULL, updated_at = CURRENT_TIMESTAMP WHERE parent_id IN (` + marks + `)`,
+		`DELETE FROM tasks WHERE id IN (` + marks + `)`,
+		`DELETE FROM task_assignees WHERE task_id IN (` + marks + `)`,
+		`DELETE FROM task_watchers WHERE task_id IN (` + marks + `)`,
+		`DELETE FROM task_checklist_items WHERE task_id IN (` + marks + `)`,
+	} {
+		if _, err := tx.ExecContext(ctx, q, args...); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
 }
@@ -0,0 +1,113 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"task-manager/internal/domain"
+)
+
+// งานของ workspace อื่นต้องเหมือนไม่มีอยู่ (ErrTaskNotFound) ทั้งอ่าน แก้ และลบ
+func TestTaskRepoScopedToWorkspace(t *testing.T) {
+	database := newTestDB(t)
+	ctx := context.Background()
+	users, workspaces, tasks := NewUserRepo(database), NewWorkspaceRepo(database), NewTaskRepo(database)
+
+	alice, bob := createTestUser(t, users, "alice"), createTestUser(t, users, "bob")
+	wsA, err := workspaces.Create(ctx, "Alice's team", alice.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wsB, err := workspaces.Create(ctx, "Bob's team", bob.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	task, err := tasks.Create(ctx, &domain.Task{WorkspaceID: wsA.ID, OwnerID: alice.ID, Title: "write report", Status: "todo", Priority: "medium"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	const missing = 999999
+
+	t.Run("get", func(t *testing.T) {
+		tests := []struct {
+			name     string
+			id, user int64
+			wantErr  error
+		}{
+			{"member", task.ID, alice.ID, nil},
+			{"missing row", missing, alice.ID, domain.ErrTaskNotFound},
+			{"member of another workspace", task.ID, bob.ID, domain.ErrTaskNotFound},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				got, err := tasks.GetForMember(ctx, tt.id, tt.user)
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("GetForMember = %v, want %v", err, tt.wantErr)
+				}
+				if err == nil && got.Title != "write report" {
+					t.Fatalf("title = %q", got.Title)
+				}
+			})
+		}
+	})
+
+	t.Run("update", func(t *testing.T) {
+		tests := []struct {
+			name          string
+			id, workspace int64
+		}{
+			{"missing row", missing, wsA.ID},
+			{"another workspace", task.ID, wsB.ID},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				edit := *task
+				edit.ID, edit.WorkspaceID, edit.Title = tt.id, tt.workspace, "hijacked"
+				if err := tasks.Update(ctx, &edit, nil); !errors.Is(err, domain.ErrTaskNotFound) {
+					t.Fatalf("Update = %v, want ErrTaskNotFound", err)
+				}
+			})
+		}
+		got, err := tasks.GetForMember(ctx, task.ID, alice.ID)
+		if err != nil || got.Title != "write report" {
+			t.Fatalf("task after rejected updates = %v, %v", got, err)
+		}
+
+		edit := *task
+		edit.Title = "write final report"
+		if err := tasks.Update(ctx, &edit, nil); err != nil {
+			t.Fatalf("Update own workspace: %v", err)
+		}
+		if got, _ := tasks.GetForMember(ctx, task.ID, alice.ID); got.Title != "write final report" {
+			t.Fatalf("title after update = %q", got.Title)
+		}
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		tests := []struct {
+			name          string
+			id, workspace int64
+		}{
+			{"missing row", missing, wsA.ID},
+			{"another workspace", task.ID, wsB.ID},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				if err := tasks.Delete(ctx, tt.workspace, tt.id, domain.ChildrenOrphan); !errors.Is(err, domain.ErrTaskNotFound) {
+					t.Fatalf("Delete = %v, want ErrTaskNotFound", err)
+				}
+			})
+		}
+		if _, err := tasks.GetForMember(ctx, task.ID, alice.ID); err != nil {
+			t.Fatalf("task deleted by a rejected call: %v", err)
+		}
+
+		if err := tasks.Delete(ctx, wsA.ID, task.ID, domain.ChildrenOrphan); err != nil {
+			t.Fatalf("Delete own workspace: %v", err)
+		}
+		if _, err := tasks.GetForMember(ctx, task.ID, alice.ID); !errors.Is(err, domain.ErrTaskNotFound) {
+			t.Fatalf("GetForMember after delete = %v, want ErrTaskNotFound", err)
+		}
+	})
+}
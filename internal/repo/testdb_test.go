@@ -0,0 +1,38 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"testing"
+
+	"task-manager/internal/db"
+	"task-manager/internal/domain"
+)
+
+// newTestDB เปิด SQLite ไฟล์ใหม่ต่อ test แล้วรัน migration ชุดเดียวกับ production
+// (ไม่เปิด foreign_keys เหมือน MustOpen ใน production)
+func newTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+	database := MustOpen("file:" + filepath.Join(t.TempDir(), "test.db"))
+	t.Cleanup(func() { database.Close() })
+	if err := db.RunMigrations(database); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	return database
+}
+
+// createTestUser สร้างผู้ใช้ตรงที่ repo (password hash เป็นค่าหลอก)
+func createTestUser(t *testing.T, users UserRepo, name string) *domain.User {
+	t.Helper()
+	u, err := users.Create(context.Background(), &domain.User{
+		Email:        name + "@example.com",
+		Username:     sql.NullString{String: name, Valid: true},
+		PasswordHash: sql.NullString{String: "not-a-real-hash", Valid: true},
+		Role:         "user",
+	})
+	if err != nil {
+		t.Fatalf("create user %s: %v", name, err)
+	}
+	return u
+}
@@ -29,8 +29,9 @@ type UserRepo interface {
 	// ดึงด้วย ID
 	GetByID(ctx context.Context, id int64) (*domain.User, error)
 
-	// อัปเดต user
-	Update(ctx context.Context, u *domain.User) (*domain.User, error)
+	// ตั้ง username / รหัสผ่าน / ชื่อ ครั้งแรกให้บัญชีที่สมัครผ่าน OAuth
+	// บัญชีที่มีรหัสผ่านอยู่แล้ว = ErrNotFound (ทับของเดิมไม่ได้)
+	CompleteSignup(ctx context.Context, u *domain.User) (*domain.User, error)
 
 	// อัปเดตชื่อ
 	UpdateName(ctx context.Context, id int64, name string) error
@@ -46,6 +47,15 @@ type UserRepo interface {
 
 	// เช็กว่ามี username นี้หรือยัง
 	UsernameExists(ctx context.Context, username string) (bool, error)
+
+	// ยืนยันอีเมลแล้ว
+	MarkEmailVerified(ctx context.Context, id int64, at time.Time) error
+
+	// เปลี่ยน role (ลด admin คนสุดท้ายไม่ได้: domain.ErrLastAdmin)
+	UpdateRole(ctx context.Context, id int64, role string) error
+
+	// ตั้งภาษา ("" = ล้างกลับไปใช้ Accept-Language)
+	UpdateLocale(ctx context.Context, id int64, locale string) error
 }
 
 type userRepo struct{ db *sql.DB }
@@ -62,7 +72,7 @@ func MustOpen(dsn string) *sql.DB {
 	} else {
 		driver = "postgres"
 	}
-	
+
 	db, err := sql.Open(driver, dsn)
 	if err != nil {
 		panic(err)
@@ -87,12 +97,12 @@ func (r *userRepo) Create(ctx context.Context, u *domain.User) (*domain.User, er
 	row := r.db.QueryRowContext(ctx,
 		`INSERT INTO users (email, username, password_hash, role)
 		 VALUES ($1,$2,$3,$4)
-		 RETURNING id, email, username, password_hash, role, created_at`,
+		 RETURNING id, email, username, password_hash, role, email_verified_at, created_at`,
 		u.Email, u.Username, u.PasswordHash, u.Role,
 	)
 
 	var out domain.User
-	if err := row.Scan(&out.ID, &out.Email, &out.Username, &out.PasswordHash, &out.Role, &out.CreatedAt); err != nil {
+	if err := row.Scan(&out.ID, &out.Email, &out.Username, &out.PasswordHash, &out.Role, &out.EmailVerifiedAt, &out.CreatedAt); err != nil {
 		return nil, err
 	}
 	return &out, nil
@@ -107,10 +117,10 @@ func (r *userRepo) CreateFromOAuth(ctx context.Context, u *domain.User) (int64,
 	// แนะนำให้มี role ค่า default เป็น 'user'
 	var id int64
 	err := r.db.QueryRowContext(ctx, `
-		INSERT INTO users (email, name, provider, provider_id, avatar_url, role)
-		VALUES ($1,$2,$3,$4,$5, COALESCE($6,'user'))
+		INSERT INTO users (email, name, provider, provider_id, avatar_url, role, email_verified_at)
+		VALUES ($1,$2,$3,$4,$5, COALESCE($6,'user'), $7)
 		RETURNING id
-	`, u.Email, u.Name, u.Provider, u.ProviderID, u.AvatarURL, u.Role).Scan(&id)
+	`, u.Email, u.Name, u.Provider, u.ProviderID, u.AvatarURL, u.Role, u.EmailVerifiedAt).Scan(&id)
 	if err != nil {
 		return 0, err
 	}
@@ -124,7 +134,7 @@ func (r *userRepo) GetByEmail(ctx context.Context, email string) (*domain.User,
 	row := r.db.QueryRowContext(ctx, `
 		SELECT id, email, username, password_hash, role,
 		       name, provider, provider_id, avatar_url,
-		       created_at
+		       email_verified_at, locale, created_at
 		FROM users
 		WHERE email = $1
 	`, email)
@@ -133,7 +143,7 @@ func (r *userRepo) GetByEmail(ctx context.Context, email string) (*domain.User,
 	if err := row.Scan(
 		&u.ID, &u.Email, &u.Username, &u.PasswordHash, &u.Role,
 		&u.Name, &u.Provider, &u.ProviderID, &u.AvatarURL,
-		&u.CreatedAt,
+		&u.EmailVerifiedAt, &u.Locale, &u.CreatedAt,
 	); err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, ErrNotFound
@@ -150,7 +160,7 @@ func (r *userRepo) GetByUsername(ctx context.Context, username string) (*domain.
 	row := r.db.QueryRowContext(ctx, `
 		SELECT id, email, username, password_hash, role,
 		       name, provider, provider_id, avatar_url,
-		       created_at
+		       email_verified_at, locale, created_at
 		FROM users
 		WHERE username = $1
 	`, username)
@@ -159,7 +169,7 @@ func (r *userRepo) GetByUsername(ctx context.Context, username string) (*domain.
 	if err := row.Scan(
 		&u.ID, &u.Email, &u.Username, &u.PasswordHash, &u.Role,
 		&u.Name, &u.Provider, &u.ProviderID, &u.AvatarURL,
-		&u.CreatedAt,
+		&u.EmailVerifiedAt, &u.Locale, &u.CreatedAt,
 	); err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, ErrNotFound
@@ -194,11 +204,21 @@ func (r *userRepo) UsernameExists(ctx context.Context, username string) (bool, e
 }
 
 func (r *userRepo) GetByID(ctx context.Context, id int64) (*domain.User, error) {
-	query := `SELECT id, email, name, created_at FROM users WHERE id = $1`
+	// ดึงครบทุกคอลัมน์เหมือน GetByEmail (ตรวจรหัสผ่านตอนยืนยันตัวตนซ้ำ / เช็ค username หลัง OAuth)
+	query := `
+		SELECT id, email, username, password_hash, role,
+		       name, provider, provider_id, avatar_url,
+		       email_verified_at, locale, created_at
+		FROM users
+		WHERE id = $1`
 	row := r.db.QueryRowContext(ctx, query, id)
 
 	var u domain.User
-	err := row.Scan(&u.ID, &u.Email, &u.Name, &u.CreatedAt)
+	err := row.Scan(
+		&u.ID, &u.Email, &u.Username, &u.PasswordHash, &u.Role,
+		&u.Name, &u.Provider, &u.ProviderID, &u.AvatarURL,
+		&u.EmailVerifiedAt, &u.Locale, &u.CreatedAt,
+	)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, ErrNotFound
@@ -266,14 +286,15 @@ func (r *userRepo) UpdatePassword(ctx context.Context, id int64, hashedPassword
 	return nil
 }
 
-func (r *userRepo) Update(ctx context.Context, u *domain.User) (*domain.User, error) {
+func (r *userRepo) CompleteSignup(ctx context.Context, u *domain.User) (*domain.User, error) {
 	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
 	defer cancel()
 
+	// เงื่อนไข password_hash อยู่ใน UPDATE เดียวกัน: ส่ง ticket ซ้ำพร้อมกันก็ผ่านได้ครั้งเดียว
 	query := `
-		UPDATE users 
+		UPDATE users
 		SET username = $1, password_hash = $2, name = $3
-		WHERE id = $4
+		WHERE id = $4 AND (password_hash IS NULL OR password_hash = '')
 	`
 
 	result, err := r.db.ExecContext(ctx, query, u.Username, u.PasswordHash, u.Name, u.ID)
@@ -294,4 +315,53 @@ func (r *userRepo) Update(ctx context.Context, u *domain.User) (*domain.User, er
 	return r.GetByID(ctx, u.ID)
 }
 
+func (r *userRepo) MarkEmailVerified(ctx context.Context, id int64, at time.Time) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	result, err := r.db.ExecContext(ctx,
+		`UPDATE users SET email_verified_at = $1 WHERE id = $2 AND email_verified_at IS NULL`,
+		at.UTC(), id)
+	if err != nil {
+		return err
+	}
+	// 0 แถว = ยืนยันไปแล้ว หรือไม่มีผู้ใช้ ตรวจแยกไม่จำเป็นสำหรับผู้เรียก
+	_, err = result.RowsAffected()
+	return err
+}
+
+func (r *userRepo) UpdateRole(ctx context.Context, id int64, role string) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	// เงื่อนไขอยู่ใน statement เดียว สอง request ลด admin พร้อมกันจะไม่เหลือ 0 คน
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE users SET role = $1
+		WHERE id = $2
+		  AND (role <> 'admin' OR $1 = 'admin'
+		       OR (SELECT COUNT(*) FROM users WHERE role = 'admin') > 1)
+	`, role, id)
+	err = affectedOrNotFound(result, err)
+	if !errors.Is(err, ErrNotFound) {
+		return err
+	}
+	var exists bool
+	if err := r.db.QueryRowContext(ctx, `SELECT EXISTS(SELECT 1 FROM users WHERE id = $1)`, id).Scan(&exists); err != nil {
+		return err
+	}
+	if exists {
+		return domain.ErrLastAdmin
+	}
+	return ErrNotFound
+}
+
+func (r *userRepo) UpdateLocale(ctx context.Context, id int64, locale string) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	result, err := r.db.ExecContext(ctx, `UPDATE users SET locale = $1 WHERE id = $2`,
+		sql.NullString{String: locale, Valid: locale != ""}, id)
+	return affectedOrNotFound(result, err)
+}
+
 var ErrNotFound = errors.New("not found")
@@ -0,0 +1,91 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"task-manager/internal/domain"
+)
+
+type UserTokenRepo interface {
+	Create(ctx context.Context, t *domain.UserToken) error
+
+	// ใช้ token (ครั้งเดียว): ทำเครื่องหมาย used_at แล้วคืนแถวนั้น
+	// คืน ErrNotFound ถ้าไม่มี หรือถูกใช้ไปแล้ว (ผู้เรียกต้องเช็ก ExpiresAt เอง)
+	Consume(ctx context.Context, purpose, tokenHash string) (*domain.UserToken, error)
+
+	// ยกเลิก token ที่ยังไม่ได้ใช้ทั้งหมดของผู้ใช้สำหรับ purpose นี้
+	InvalidateForUser(ctx context.Context, userID int64, purpose string) error
+
+	// เวลาที่ออก token ล่าสุด (ใช้จำกัดการส่งซ้ำ) คืน ErrNotFound ถ้ายังไม่เคยออก
+	LatestCreatedAt(ctx context.Context, userID int64, purpose string) (time.Time, error)
+}
+
+type userTokenRepo struct{ db *sql.DB }
+
+func NewUserTokenRepo(db *sql.DB) UserTokenRepo { return &userTokenRepo{db: db} }
+
+func (r *userTokenRepo) Create(ctx context.Context, t *domain.UserToken) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO user_tokens (user_id, purpose, token_hash, expires_at, created_at)
+		VALUES ($1,$2,$3,$4,$5)
+	`, t.UserID, t.Purpose, t.TokenHash, t.ExpiresAt.UTC(), time.Now().UTC())
+	return err
+}
+
+func (r *userTokenRepo) Consume(ctx context.Context, purpose, tokenHash string) (*domain.UserToken, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	var t domain.UserToken
+	err := r.db.QueryRowContext(ctx, `
+		UPDATE user_tokens SET used_at = $1
+		WHERE token_hash = $2 AND purpose = $3 AND used_at IS NULL
+		RETURNING id, user_id, purpose, token_hash, expires_at, created_at, used_at
+	`, time.Now().UTC(), tokenHash, purpose).Scan(
+		&t.ID, &t.UserID, &t.Purpose, &t.TokenHash, &t.ExpiresAt, &t.CreatedAt, &t.UsedAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return &t, nil
+}
+
+func (r *userTokenRepo) InvalidateForUser(ctx context.Context, userID int64, purpose string) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE user_tokens SET used_at = $1
+		WHERE user_id = $2 AND purpose = $3 AND used_at IS NULL
+	`, time.Now().UTC(), userID, purpose)
+	return err
+}
+
+func (r *userTokenRepo) LatestCreatedAt(ctx context.Context, userID int64, purpose string) (time.Time, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	var at time.Time
+	err := r.db.QueryRowContext(ctx, `
+		SELECT created_at FROM user_tokens
+		WHERE user_id = $1 AND purpose = $2
+		ORDER BY created_at DESC
+		LIMIT 1
+	`, userID, purpose).Scan(&at)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return time.Time{}, ErrNotFound
+		}
+		return time.Time{}, err
+	}
+	return at, nil
+}
@@ -0,0 +1,291 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"task-manager/internal/domain"
+)
+
+// WorkspaceRepo: ทุก query ที่อ่านข้อมูลของ workspace กรองด้วยสมาชิกภาพของ userID
+// ไม่ใช่สมาชิก = ErrNotFound (ไม่บอกว่ามี workspace นี้อยู่)
+type WorkspaceRepo interface {
+	// สร้าง workspace ของทีม ผู้สร้างเป็น admin
+	Create(ctx context.Context, name string, creatorID int64) (*domain.Workspace, error)
+
+	// คืน workspace ส่วนตัวของผู้ใช้ (สร้างให้ถ้ายังไม่มี เช่น ผู้ใช้ที่สมัครหลัง migration)
+	EnsurePersonal(ctx context.Context, userID int64) (*domain.Workspace, error)
+
+	ListByMember(ctx context.Context, userID int64) ([]*domain.Workspace, error)
+	GetForMember(ctx context.Context, id, userID int64) (*domain.Workspace, error)
+	Rename(ctx context.Context, id int64, name string) error
+	Delete(ctx context.Context, id int64) error
+
+	ListMembers(ctx context.Context, id int64) ([]domain.WorkspaceMember, error)
+
+	// เปลี่ยน role / เอาออก (admin คนสุดท้ายทำไม่ได้: domain.ErrLastAdmin)
+	UpdateMemberRole(ctx context.Context, id, userID int64, role string) error
+	RemoveMember(ctx context.Context, id, userID int64) error
+}
+
+type workspaceRepo struct{ db *sql.DB }
+
+func NewWorkspaceRepo(db *sql.DB) WorkspaceRepo { return &workspaceRepo{db: db} }
+
+const workspaceColumns = `w.id, w.name, w.personal_owner_id, w.created_at, w.updated_at, m.role`
+
+// memberWorkspaces: workspace ที่ $1 เป็นสมาชิก พร้อม role ของเขา
+const memberWorkspaces = `
+	FROM workspaces w
+	JOIN workspace_members m ON m.workspace_id = w.id AND m.user_id = $1`
+
+func scanWorkspace(row interface{ Scan(...any) error }) (*domain.Workspace, error) {
+	var w domain.Workspace
+	if err := row.Scan(&w.ID, &w.Name, &w.PersonalOwnerID, &w.CreatedAt, &w.UpdatedAt, &w.Role); err != nil {
+		return nil, err
+	}
+	return &w, nil
+}
+
+func (r *workspaceRepo) Create(ctx context.Context, name string, creatorID int64) (*domain.Workspace, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var id int64
+	if err := tx.QueryRowContext(ctx,
+		`INSERT INTO workspaces (name) VALUES ($1) RETURNING id`, name).Scan(&id); err != nil {
+		return nil, err
+	}
+	if _, err := tx.ExecContext(ctx,
+		`INSERT INTO workspace_members (workspace_id, user_id, role) VALUES ($1, $2, $3)`,
+		id, creatorID, domain.WorkspaceRoleAdmin); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return r.GetForMember(ctx, id, creatorID)
+}
+
+func (r *workspaceRepo) EnsurePersonal(ctx context.Context, userID int64) (*domain.Workspace, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	row := r.db.QueryRowContext(ctx, `SELECT `+workspaceColumns+memberWorkspaces+`
+		WHERE w.personal_owner_id = $1`, userID)
+	w, err := scanWorkspace(row)
+	if err == nil || !errors.Is(err, sql.ErrNoRows) {
+		return w, err
+	}
+
+	// ON CONFLICT: สอง request แรกของผู้ใช้ใหม่มาพร้อมกันจะได้ workspace เดียวกัน
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `
+		INSERT INTO workspaces (name, personal_owner_id) VALUES ('Personal', $1)
+		ON CONFLICT (personal_owner_id) DO NOTHING
+	`, userID); err != nil {
+		return nil, err
+	}
+	if _, err := tx.ExecContext(ctx, `
+		INSERT INTO workspace_members (workspace_id, user_id, role)
+		SELECT id, $1, $2 FROM workspaces WHERE personal_owner_id = $1
+		ON CONFLICT (workspace_id, user_id) DO NOTHING
+	`, userID, domain.WorkspaceRoleAdmin); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	row = r.db.QueryRowContext(ctx, `SELECT `+workspaceColumns+memberWorkspaces+`
+		WHERE w.personal_owner_id = $1`, userID)
+	return scanWorkspace(row)
+}
+
+func (r *workspaceRepo) ListByMember(ctx context.Context, userID int64) ([]*domain.Workspace, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	// workspace ส่วนตัวขึ้นก่อน ที่เหลือเรียงตามชื่อ
+	rows, err := r.db.QueryContext(ctx, `SELECT `+workspaceColumns+memberWorkspaces+`
+		ORDER BY w.personal_owner_id IS NULL, w.name, w.id`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := []*domain.Workspace{}
+	for rows.Next() {
+		w, err := scanWorkspace(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, w)
+	}
+	return out, rows.Err()
+}
+
+func (r *workspaceRepo) GetForMember(ctx context.Context, id, userID int64) (*domain.Workspace, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	row := r.db.QueryRowContext(ctx, `SELECT `+workspaceColumns+memberWorkspaces+`
+		WHERE w.id = $2`, userID, id)
+	w, err := scanWorkspace(row)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return w, nil
+}
+
+func (r *workspaceRepo) Rename(ctx context.Context, id int64, name string) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	result, err := r.db.ExecContext(ctx,
+		`UPDATE workspaces SET name = $1, updated_at = CURRENT_TIMESTAMP WHERE id = $2`, name, id)
+	return affectedOrNotFound(result, err)
+}
+
+func (r *workspaceRepo) Delete(ctx context.Context, id int64) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// ลบข้อมูลของ workspace เองแทน ON DELETE CASCADE เพราะ SQLite ไม่ได้เปิด foreign_keys
+	// (ไม่งั้นอดีตสมาชิกยังเปิดงานที่ค้างอยู่ด้วย id ได้) ลูกก่อนแม่ ให้ผ่าน FK ของ Postgres ด้วย
+	for _, q := range []string{
+		`DELETE FROM task_assignees WHERE task_id IN (SELECT id FROM tasks WHERE workspace_id = $1)`,
+		`DELETE FROM task_watchers WHERE task_id IN (SELECT id FROM tasks WHERE workspace_id = $1)`,
+		`DELETE FROM task_checklist_items WHERE task_id IN (SELECT id FROM tasks WHERE workspace_id = $1)`,
+		`DELETE FROM tasks WHERE workspace_id = $1`,
+		`DELETE FROM task_statuses WHERE project_id IN (SELECT id FROM projects WHERE workspace_id = $1)`,
+		`DELETE FROM project_members WHERE workspace_id = $1`,
+		`DELETE FROM projects WHERE workspace_id = $1`,
+		`DELETE FROM workspace_invitations WHERE workspace_id = $1`,
+		`DELETE FROM workspace_members WHERE workspace_id = $1`,
+	} {
+		if _, err := tx.ExecContext(ctx, q, id); err != nil {
+			return err
+		}
+	}
+	result, err := tx.ExecContext(ctx, `DELETE FROM workspaces WHERE id = $1`, id)
+	if err := affectedOrNotFound(result, err); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (r *workspaceRepo) ListMembers(ctx context.Context, id int64) ([]domain.WorkspaceMember, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT m.workspace_id, m.user_id, u.email, u.name, m.role, m.created_at
+		FROM workspace_members m
+		JOIN users u ON u.id = m.user_id
+		WHERE m.workspace_id = $1
+		ORDER BY m.created_at, m.user_id
+	`, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := []domain.WorkspaceMember{}
+	for rows.Next() {
+		var m domain.WorkspaceMember
+		if err := rows.Scan(&m.WorkspaceID, &m.UserID, &m.Email, &m.Name, &m.Role, &m.JoinedAt); err != nil {
+			return nil, err
+		}
+		out = append(out, m)
+	}
+	return out, rows.Err()
+}
+
+// otherAdmins: ยังมี admin คนอื่นใน workspace นอกจาก user (ใช้ใน statement เดียวกับการแก้ไข
+// กันสอง request ลด admin พร้อมกันจนไม่เหลือใคร) ws/user คือ placeholder เช่น "$2"
+func otherAdmins(ws, user string) string {
+	return `EXISTS (SELECT 1 FROM workspace_members o
+		WHERE o.workspace_id = ` + ws + ` AND o.user_id <> ` + user + ` AND o.role = 'admin')`
+}
+
+func (r *workspaceRepo) UpdateMemberRole(ctx context.Context, id, userID int64, role string) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE workspace_members SET role = $1
+		WHERE workspace_id = $2 AND user_id = $3
+		  AND (role <> 'admin' OR $1 = 'admin' OR `+otherAdmins("$2", "$3")+`)
+	`, role, id, userID)
+	return memberChangedOrLastAdmin(ctx, r.db, result, err, id, userID)
+}
+
+func (r *workspaceRepo) RemoveMember(ctx context.Context, id, userID int64) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	result, err := r.db.ExecContext(ctx, `
+		DELETE FROM workspace_members
+		WHERE workspace_id = $1 AND user_id = $2
+		  AND (role <> 'admin' OR `+otherAdmins("$1", "$2")+`)
+	`, id, userID)
+	if err := memberChangedOrLastAdmin(ctx, r.db, result, err, id, userID); err != nil {
+		return err
+	}
+	// หลุดจากทุกโปรเจกต์ของ workspace ด้วย (Postgres ทำให้แล้วด้วย FK แต่ SQLite ไม่ได้เปิด foreign_keys)
+	if _, err := r.db.ExecContext(ctx,
+		`DELETE FROM project_members WHERE workspace_id = $1 AND user_id = $2`, id, userID); err != nil {
+		return err
+	}
+	// และเลิกรับผิดชอบ / ติดตามงานใน workspace นี้ (FK ผูกกับ users ไม่ใช่สมาชิก จึงต้องลบเองทั้งสอง DB)
+	for _, q := range []string{
+		`DELETE FROM task_assignees WHERE user_id = $2 AND task_id IN (SELECT id FROM tasks WHERE workspace_id = $1)`,
+		`DELETE FROM task_watchers WHERE user_id = $2 AND task_id IN (SELECT id FROM tasks WHERE workspace_id = $1)`,
+	} {
+		if _, err := r.db.ExecContext(ctx, q, id, userID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// memberChangedOrLastAdmin แยกกรณีไม่พบสมาชิก (ErrNotFound) กับเพราะเป็น admin คนสุดท้าย
+func memberChangedOrLastAdmin(ctx context.Context, db *sql.DB, result sql.Result, err error, id, userID int64) error {
+	err = affectedOrNotFound(result, err)
+	if !errors.Is(err, ErrNotFound) {
+		return err
+	}
+	var n int
+	if err := db.QueryRowContext(ctx,
+		`SELECT COUNT(*) FROM workspace_members WHERE workspace_id = $1 AND user_id = $2`,
+		id, userID).Scan(&n); err != nil {
+		return err
+	}
+	if n > 0 {
+		return domain.ErrLastAdmin
+	}
+	return ErrNotFound
+}
@@ -0,0 +1,143 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"task-manager/internal/auth"
+	"task-manager/internal/domain"
+	"task-manager/internal/repo"
+)
+
+const (
+	maxAccessTokensPerUser = 50
+	defaultAccessTokenTTL  = 30 * 24 * time.Hour
+	maxAccessTokenTTL      = 365 * 24 * time.Hour
+)
+
+type AccessTokenService interface {
+	// Create คืน token จริง (แสดงให้ผู้ใช้ครั้งเดียว) และข้อมูลที่เก็บไว้; ttl 0 = ค่าเริ่มต้น 30 วัน
+	Create(ctx context.Context, userID int64, name string, scopes []string, ttl time.Duration) (string, *domain.PersonalAccessToken, error)
+	List(ctx context.Context, userID int64) ([]domain.PersonalAccessToken, error)
+	Revoke(ctx context.Context, userID, id int64) error
+
+	// Authenticate ใช้ใน JWTMiddleware: คืน token และ role ปัจจุบันของเจ้าของ
+	Authenticate(ctx context.Context, raw string) (*domain.PersonalAccessToken, string, error)
+}
+
+type accessTokenService struct {
+	tokenRepo repo.AccessTokenRepo
+	userRepo  repo.UserRepo
+}
+
+func NewAccessTokenService(tokenRepo repo.AccessTokenRepo, userRepo repo.UserRepo) AccessTokenService {
+	return &accessTokenService{tokenRepo: tokenRepo, userRepo: userRepo}
+}
+
+func (s *accessTokenService) Create(ctx context.Context, userID int64, name string, scopes []string, ttl time.Duration) (string, *domain.PersonalAccessToken, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || len([]rune(name)) > 100 {
+		return "", nil, domain.ErrInvalidInput
+	}
+	if ttl == 0 {
+		ttl = defaultAccessTokenTTL
+	}
+	if ttl < 0 || ttl > maxAccessTokenTTL {
+		return "", nil, domain.ErrInvalidInput
+	}
+
+	u, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, repo.ErrNotFound) {
+			return "", nil, domain.ErrUserNotFound
+		}
+		return "", nil, err
+	}
+	scopes, err = normalizeScopes(scopes, u.Role)
+	if err != nil {
+		return "", nil, err
+	}
+
+	n, err := s.tokenRepo.CountByUser(ctx, userID)
+	if err != nil {
+		return "", nil, err
+	}
+	if n >= maxAccessTokensPerUser {
+		return "", nil, domain.ErrAccessTokenLimit
+	}
+
+	raw, hash, err := auth.NewPersonalToken()
+	if err != nil {
+		return "", nil, err
+	}
+	t := &domain.PersonalAccessToken{
+		UserID:    userID,
+		Name:      name,
+		TokenHash: hash,
+		Prefix:    raw[:len(auth.PersonalTokenPrefix)+4],
+		Scopes:    scopes,
+		ExpiresAt: time.Now().Add(ttl).UTC(),
+	}
+	if err := s.tokenRepo.Create(ctx, t); err != nil {
+		return "", nil, err
+	}
+	return raw, t, nil
+}
+
+// normalizeScopes ตัดซ้ำ/เรียงลำดับ และไม่ให้ขอ scope admin ถ้า role ไม่มีสิทธิ์จัดการผู้ใช้
+func normalizeScopes(scopes []string, role string) ([]string, error) {
+	if len(scopes) == 0 {
+		return nil, domain.ErrInvalidScope
+	}
+	seen := map[string]bool{}
+	for _, sc := range scopes {
+		if !domain.ValidScope(sc) {
+			return nil, domain.ErrInvalidScope
+		}
+		if sc == domain.ScopeAdmin && !domain.HasPermission(role, domain.PermUserManage) {
+			return nil, domain.ErrInvalidScope
+		}
+		seen[sc] = true
+	}
+	out := make([]string, 0, len(seen))
+	for _, sc := range domain.AllScopes {
+		if seen[sc] {
+			out = append(out, sc)
+		}
+	}
+	return out, nil
+}
+
+func (s *accessTokenService) List(ctx context.Context, userID int64) ([]domain.PersonalAccessToken, error) {
+	return s.tokenRepo.ListByUser(ctx, userID)
+}
+
+func (s *accessTokenService) Revoke(ctx context.Context, userID, id int64) error {
+	if err := s.tokenRepo.Delete(ctx, userID, id); err != nil {
+		if errors.Is(err, repo.ErrNotFound) {
+			return domain.ErrAccessTokenNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+func (s *accessTokenService) Authenticate(ctx context.Context, raw string) (*domain.PersonalAccessToken, string, error) {
+	t, role, err := s.tokenRepo.GetByHash(ctx, auth.HashToken(raw))
+	if err != nil {
+		if errors.Is(err, repo.ErrNotFound) {
+			return nil, "", domain.ErrInvalidToken
+		}
+		return nil, "", err
+	}
+	now := time.Now()
+	if t.Expired(now) {
+		return nil, "", domain.ErrInvalidToken
+	}
+	if err := s.tokenRepo.MarkUsed(ctx, t.ID, now); err != nil {
+		return nil, "", err
+	}
+	return t, role, nil
+}
@@ -3,71 +3,138 @@ package service
 import (
 	"context"
 	"database/sql"
+	"errors"
+	"log"
 	"strings"
+	"sync"
+	"time"
 
 	"task-manager/internal/auth"
 	"task-manager/internal/domain"
 	"task-manager/internal/repo"
 )
 
+// TokenPair is what every successful login hands back to the client
+type TokenPair struct {
+	AccessToken  string
+	AccessTTL    time.Duration
+	RefreshToken string
+	RefreshTTL   time.Duration
+}
+
 type AuthService interface {
-	// คืน 4 ค่า: user, accessToken, created(สมัครใหม่?), error
-	LoginOrSignupGoogle(ctx context.Context, email, name, sub, avatar string) (*domain.User, string, bool, error)
+	// คืน user, created(สมัครใหม่?), error — ผู้เรียกออก token เอง (อาจต้องผ่าน 2FA ก่อน)
+	// ext มาจาก ID token ที่ตรวจแล้วของ OIDC provider (Google, Keycloak, ...)
+	LoginOrSignupOAuth(ctx context.Context, ext *auth.ExternalUser) (*domain.User, bool, error)
 	Login(ctx context.Context, usernameOrEmail, password string) (*domain.User, error)
 	Register(ctx context.Context, email, username, password, name string) (*domain.User, error)
-	CompleteGoogleRegistration(ctx context.Context, email, username, password, name string) (*domain.User, error)
-	GenerateToken(userID int64) (string, error)
+
+	// ตั้ง username / รหัสผ่านให้บัญชีที่ OAuth callback เพิ่งสร้าง (userID มาจาก onboarding ticket)
+	// บัญชีที่ตั้งรหัสผ่านแล้ว = ErrInvalidToken
+	CompleteOAuthRegistration(ctx context.Context, userID int64, username, password, name string) (*domain.User, error)
+
+	// ออก access + refresh token ชุดใหม่ (เริ่ม token family และ session ใหม่)
+	IssueTokens(ctx context.Context, userID int64, client domain.ClientInfo) (*TokenPair, error)
+
+	// หมุน refresh token: token เดิมใช้ได้ครั้งเดียว ถ้าถูกนำกลับมาใช้ซ้ำจะเพิกถอนทั้ง family
+	Refresh(ctx context.Context, refreshToken string) (*TokenPair, error)
+
+	// เพิกถอน family ของ refresh token นี้ และ session ที่ผูกอยู่
+	Logout(ctx context.Context, refreshToken string) error
 }
 
 type authService struct {
-	UserRepo repo.UserRepo
-	Hasher   auth.PasswordHasher
-	JWT      auth.JWT
+	UserRepo     repo.UserRepo
+	RefreshRepo  repo.RefreshTokenRepo
+	SessionRepo  repo.SessionRepo
+	IdentityRepo repo.IdentityRepo
+	Hasher       auth.PasswordHasher
+	Policy       auth.PasswordPolicy
+	JWT          auth.JWT
+
+	// dummyHash ใช้ตรวจรหัสผ่านเมื่อไม่พบผู้ใช้ ให้เวลาตอบเท่ากับกรณีรหัสผิด (กันไล่เดา username/email)
+	dummyOnce sync.Once
+	dummyHash string
 }
 
-func NewAuthService(ur repo.UserRepo, hasher auth.PasswordHasher, jwt auth.JWT) AuthService {
-	return &authService{UserRepo: ur, Hasher: hasher, JWT: jwt}
+func NewAuthService(ur repo.UserRepo, rr repo.RefreshTokenRepo, sr repo.SessionRepo, ir repo.IdentityRepo, hasher auth.PasswordHasher, policy auth.PasswordPolicy, jwt auth.JWT) AuthService {
+	return &authService{UserRepo: ur, RefreshRepo: rr, SessionRepo: sr, IdentityRepo: ir, Hasher: hasher, Policy: policy, JWT: jwt}
 }
 
 func ns(s string) sql.NullString {
 	return sql.NullString{String: s, Valid: s != ""}
 }
 
-func (s *authService) LoginOrSignupGoogle(ctx context.Context, email, name, sub, avatar string) (*domain.User, string, bool, error) {
-	email = strings.TrimSpace(strings.ToLower(email))
-
-	// มีอยู่แล้ว?
-	u, err := s.UserRepo.GetByEmail(ctx, email)
-	if err != nil && err != repo.ErrNotFound {
-		return nil, "", false, err
-	}
-
-	created := false
-	if u == nil {
-		// สมัครใหม่
-		u = &domain.User{
-			Email:      email,
-			Name:       ns(name),
-			Provider:   ns("google"),
-			ProviderID: ns(sub),
-			AvatarURL:  ns(avatar),
-			Role:       "user",
+// compareDummy ตรวจรหัสผ่านกับ hash หลอกที่สร้างด้วย hasher ปัจจุบัน (สร้างครั้งแรกที่ใช้ ค่า cost เดียวกับของจริง)
+func (s *authService) compareDummy(password string) {
+	s.dummyOnce.Do(func() {
+		h, err := s.Hasher.Hash("dummy-password-for-unknown-users")
+		if err != nil {
+			log.Printf("dummy password hash failed: %v", err)
+			return
 		}
-		id, err := s.UserRepo.CreateFromOAuth(ctx, u)
+		s.dummyHash = h
+	})
+	_ = s.Hasher.Compare(s.dummyHash, password)
+}
+
+func (s *authService) LoginOrSignupOAuth(ctx context.Context, ext *auth.ExternalUser) (*domain.User, bool, error) {
+	email := strings.TrimSpace(strings.ToLower(ext.Email))
+
+	// จับคู่ด้วย provider + sub เท่านั้น อีเมลตรงกันไม่ได้แปลว่าเป็นเจ้าของบัญชีเดียวกัน
+	ident, err := s.IdentityRepo.GetByProviderSubject(ctx, ext.Provider, ext.Subject)
+	if err == nil {
+		u, err := s.UserRepo.GetByID(ctx, ident.UserID)
 		if err != nil {
-			return nil, "", false, err
+			return nil, false, err
+		}
+		if err := s.IdentityRepo.MarkUsed(ctx, ident.ID, time.Now()); err != nil {
+			return nil, false, err
 		}
-		u.ID = id
-		created = true
+		if !u.EmailVerified() && ext.EmailVerified && strings.EqualFold(u.Email, email) {
+			now := time.Now()
+			if err := s.UserRepo.MarkEmailVerified(ctx, u.ID, now); err != nil {
+				return nil, false, err
+			}
+			u.EmailVerifiedAt = sql.NullTime{Time: now, Valid: true}
+		}
+		return u, false, nil
+	}
+	if !errors.Is(err, repo.ErrNotFound) {
+		return nil, false, err
+	}
+
+	// มีบัญชีอีเมลนี้อยู่แล้วแต่ยังไม่ได้ผูก provider นี้: ต้อง login ด้วยวิธีเดิมแล้วผูกจากหน้า settings
+	if _, err := s.UserRepo.GetByEmail(ctx, email); err == nil {
+		return nil, false, domain.ErrIdentityNotLinked
+	} else if !errors.Is(err, repo.ErrNotFound) {
+		return nil, false, err
 	}
 
-	// ออก access token
-	token, _, err := s.JWT.GenerateAccessToken(u.ID, u.Role) // ฟังก์ชันนี้คืน (token, ttl, err)
+	// สมัครใหม่
+	u := &domain.User{
+		Email:      email,
+		Name:       ns(ext.Name),
+		Provider:   ns(ext.Provider),
+		ProviderID: ns(ext.Subject),
+		AvatarURL:  ns(ext.Picture),
+		Role:       domain.RoleUser,
+	}
+	if ext.EmailVerified {
+		u.EmailVerifiedAt = sql.NullTime{Time: time.Now(), Valid: true}
+	}
+	id, err := s.UserRepo.CreateFromOAuth(ctx, u)
 	if err != nil {
-		return nil, "", created, err
+		return nil, false, err
+	}
+	u.ID = id
+	if err := s.IdentityRepo.Create(ctx, &domain.Identity{
+		UserID: id, Provider: ext.Provider, Subject: ext.Subject, Email: email,
+	}); err != nil {
+		return nil, false, err
 	}
 
-	return u, token, created, nil
+	return u, true, nil
 }
 
 func (s *authService) Login(ctx context.Context, usernameOrEmail, password string) (*domain.User, error) {
@@ -90,13 +157,15 @@ func (s *authService) Login(ctx context.Context, usernameOrEmail, password strin
 
 	if err != nil {
 		if err == repo.ErrNotFound {
+			s.compareDummy(password)
 			return nil, domain.ErrInvalidCredentials
 		}
 		return nil, err
 	}
 
-	// Check password
+	// Check password (บัญชีที่ไม่มีรหัสผ่าน เช่น สมัครด้วย OAuth ก็ใช้เวลาเท่ากัน)
 	if !user.PasswordHash.Valid {
+		s.compareDummy(password)
 		return nil, domain.ErrInvalidCredentials
 	}
 
@@ -104,12 +173,164 @@ func (s *authService) Login(ctx context.Context, usernameOrEmail, password strin
 		return nil, domain.ErrInvalidCredentials
 	}
 
+	// hash เก่า (bcrypt / ค่า argon2 ต่ำกว่าที่ตั้งไว้) อัปเกรดตอนนี้ที่มีรหัสผ่านจริงอยู่ในมือ
+	if s.Hasher.NeedsRehash(user.PasswordHash.String) {
+		if hashed, err := s.Hasher.Hash(password); err != nil {
+			log.Printf("password rehash failed for user %d: %v", user.ID, err)
+		} else if err := s.UserRepo.UpdatePassword(ctx, user.ID, hashed); err != nil {
+			log.Printf("password rehash failed for user %d: %v", user.ID, err)
+		} else {
+			user.PasswordHash = ns(hashed)
+		}
+	}
+
 	return user, nil
 }
 
-func (s *authService) GenerateToken(userID int64) (string, error) {
-	token, _, err := s.JWT.GenerateAccessToken(userID, "user")
-	return token, err
+func (s *authService) IssueTokens(ctx context.Context, userID int64, client domain.ClientInfo) (*TokenPair, error) {
+	familyID, err := auth.NewTokenID()
+	if err != nil {
+		return nil, err
+	}
+	jti, err := auth.NewTokenID()
+	if err != nil {
+		return nil, err
+	}
+
+	tokens, err := s.issueWithID(ctx, userID, familyID, jti)
+	if err != nil {
+		return nil, err
+	}
+
+	// session id = token family ดังนั้นเพิกถอน session ได้ในครั้งเดียว
+	if err := s.SessionRepo.Create(ctx, &domain.Session{
+		ID:        familyID,
+		UserID:    userID,
+		UserAgent: client.UserAgent,
+		IP:        client.IP,
+		ExpiresAt: time.Now().Add(tokens.RefreshTTL),
+	}); err != nil {
+		return nil, err
+	}
+	return tokens, nil
+}
+
+func (s *authService) Refresh(ctx context.Context, refreshToken string) (*TokenPair, error) {
+	claims, err := s.JWT.ParseRefresh(refreshToken)
+	if err != nil {
+		return nil, domain.ErrInvalidRefreshToken
+	}
+	userID, err := claims.UserID()
+	if err != nil {
+		return nil, domain.ErrInvalidRefreshToken
+	}
+
+	rec, err := s.RefreshRepo.GetByID(ctx, claims.ID)
+	if err != nil {
+		if errors.Is(err, repo.ErrNotFound) {
+			return nil, domain.ErrInvalidRefreshToken
+		}
+		return nil, err
+	}
+	if rec.UserID != userID || rec.FamilyID != claims.FamilyID {
+		return nil, domain.ErrInvalidRefreshToken
+	}
+
+	// token ที่หมุนไปแล้วถูกส่งกลับมาอีก = มีคนขโมยไป → เพิกถอนทั้ง family
+	if rec.Used() {
+		if err := s.revokeFamily(ctx, rec.UserID, rec.FamilyID); err != nil {
+			return nil, err
+		}
+		return nil, domain.ErrRefreshTokenReused
+	}
+	if time.Now().After(rec.ExpiresAt) {
+		return nil, domain.ErrInvalidRefreshToken
+	}
+
+	nextID, err := auth.NewTokenID()
+	if err != nil {
+		return nil, err
+	}
+	ok, err := s.RefreshRepo.MarkRotated(ctx, rec.ID, nextID)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		// request อื่นหมุน token นี้ไปก่อนแล้ว
+		if err := s.revokeFamily(ctx, rec.UserID, rec.FamilyID); err != nil {
+			return nil, err
+		}
+		return nil, domain.ErrRefreshTokenReused
+	}
+
+	tokens, err := s.issueWithID(ctx, userID, rec.FamilyID, nextID)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	if err := s.SessionRepo.Touch(ctx, rec.FamilyID, now, now.Add(tokens.RefreshTTL)); err != nil {
+		return nil, err
+	}
+	return tokens, nil
+}
+
+func (s *authService) Logout(ctx context.Context, refreshToken string) error {
+	claims, err := s.JWT.ParseRefresh(refreshToken)
+	if err != nil {
+		// token หมดอายุ/ไม่ถูกต้อง ไม่มีอะไรให้เพิกถอน
+		return nil
+	}
+	userID, err := claims.UserID()
+	if err != nil {
+		return nil
+	}
+	return s.revokeFamily(ctx, userID, claims.FamilyID)
+}
+
+// revokeFamily ปิดทั้ง refresh token family และ session เดียวกัน (access token จะใช้ไม่ได้ทันที)
+func (s *authService) revokeFamily(ctx context.Context, userID int64, familyID string) error {
+	if err := s.RefreshRepo.RevokeFamily(ctx, familyID); err != nil {
+		return err
+	}
+	if err := s.SessionRepo.Revoke(ctx, userID, familyID); err != nil && !errors.Is(err, repo.ErrNotFound) {
+		return err
+	}
+	return nil
+}
+
+func (s *authService) issueWithID(ctx context.Context, userID int64, familyID, jti string) (*TokenPair, error) {
+	// role/locale อ่านจาก DB ทุกครั้งที่ออก token (refresh ด้วย) เปลี่ยนแล้วมีผลภายในอายุ access token
+	u, err := s.UserRepo.GetByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, repo.ErrNotFound) {
+			return nil, domain.ErrUserNotFound
+		}
+		return nil, err
+	}
+	access, accessTTL, err := s.JWT.GenerateAccessToken(userID, u.Role, u.Locale.String, familyID)
+	if err != nil {
+		return nil, err
+	}
+	refresh, refreshTTL, err := s.JWT.GenerateRefreshToken(userID, jti, familyID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.RefreshRepo.Create(ctx, &domain.RefreshToken{
+		ID:        jti,
+		UserID:    userID,
+		FamilyID:  familyID,
+		ExpiresAt: time.Now().Add(refreshTTL),
+	}); err != nil {
+		return nil, err
+	}
+
+	return &TokenPair{
+		AccessToken:  access,
+		AccessTTL:    accessTTL,
+		RefreshToken: refresh,
+		RefreshTTL:   refreshTTL,
+	}, nil
 }
 
 func (s *authService) Register(ctx context.Context, email, username, password, name string) (*domain.User, error) {
@@ -120,6 +341,9 @@ func (s *authService) Register(ctx context.Context, email, username, password, n
 	if email == "" || username == "" || password == "" || name == "" {
 		return nil, domain.ErrInvalidInput
 	}
+	if err := s.Policy.Validate(password, email, username); err != nil {
+		return nil, err
+	}
 
 	// Check if email or username already exists
 	emailExists, err := s.UserRepo.EmailExists(ctx, email)
@@ -150,7 +374,7 @@ func (s *authService) Register(ctx context.Context, email, username, password, n
 		Username:     ns(username),
 		PasswordHash: ns(hashedPassword),
 		Name:         ns(name),
-		Role:         "user",
+		Role:         domain.RoleUser,
 	}
 
 	createdUser, err := s.UserRepo.Create(ctx, user)
@@ -161,51 +385,44 @@ func (s *authService) Register(ctx context.Context, email, username, password, n
 	return createdUser, nil
 }
 
-func (s *authService) CompleteGoogleRegistration(ctx context.Context, email, username, password, name string) (*domain.User, error) {
-	email = strings.TrimSpace(strings.ToLower(email))
+func (s *authService) CompleteOAuthRegistration(ctx context.Context, userID int64, username, password, name string) (*domain.User, error) {
 	username = strings.TrimSpace(username)
 	name = strings.TrimSpace(name)
-
-	if email == "" || username == "" || password == "" || name == "" {
+	if username == "" || password == "" || name == "" {
 		return nil, domain.ErrInvalidInput
 	}
 
-	// Check if email already exists
-	existingUser, err := s.UserRepo.GetByEmail(ctx, email)
-	if err != nil && err != repo.ErrNotFound {
+	u, err := s.UserRepo.GetByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, repo.ErrNotFound) {
+			return nil, domain.ErrInvalidToken
+		}
 		return nil, err
 	}
-	
-	// If user exists, update their information
-	if existingUser != nil {
-		// Check if username already exists (but not for this user)
-		usernameExists, err := s.UserRepo.UsernameExists(ctx, username)
-		if err != nil {
-			return nil, err
-		}
-		if usernameExists {
-			return nil, domain.ErrUsernameAlreadyExists
-		}
-
-		// Hash password
-		hashedPassword, err := s.Hasher.Hash(password)
-		if err != nil {
-			return nil, err
-		}
-
-		// Update user with username and password
-		existingUser.Username = ns(username)
-		existingUser.PasswordHash = ns(hashedPassword)
-		existingUser.Name = ns(name)
-
-		updatedUser, err := s.UserRepo.Update(ctx, existingUser)
-		if err != nil {
-			return nil, err
-		}
+	if u.PasswordHash.Valid && u.PasswordHash.String != "" {
+		return nil, domain.ErrInvalidToken
+	}
+	if err := s.Policy.Validate(password, u.Email, username); err != nil {
+		return nil, err
+	}
+	usernameExists, err := s.UserRepo.UsernameExists(ctx, username)
+	if err != nil {
+		return nil, err
+	}
+	if usernameExists {
+		return nil, domain.ErrUsernameAlreadyExists
+	}
 
-		return updatedUser, nil
+	hashedPassword, err := s.Hasher.Hash(password)
+	if err != nil {
+		return nil, err
+	}
+	u.Username = ns(username)
+	u.PasswordHash = ns(hashedPassword)
+	u.Name = ns(name)
+	updated, err := s.UserRepo.CompleteSignup(ctx, u)
+	if errors.Is(err, repo.ErrNotFound) {
+		return nil, domain.ErrInvalidToken
 	}
-	
-	// If user doesn't exist, create a new one
-	return s.Register(ctx, email, username, password, name)
+	return updated, err
 }
@@ -0,0 +1,66 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"task-manager/internal/auth"
+	"task-manager/internal/domain"
+	"task-manager/internal/repo"
+)
+
+// countingHasher นับว่า Compare ถูกเรียกกี่ครั้ง
+type countingHasher struct {
+	auth.PasswordHasher
+	compares int
+}
+
+func (h *countingHasher) Compare(hash, pw string) error {
+	h.compares++
+	return h.PasswordHasher.Compare(hash, pw)
+}
+
+// ไม่พบผู้ใช้ / บัญชีไม่มีรหัสผ่าน ต้องตรวจ hash เหมือนรหัสผิด ไม่งั้นเวลาตอบบอกได้ว่าบัญชีมีอยู่จริง
+func TestLoginComparesHashForUnknownUsers(t *testing.T) {
+	database := newTestDB(t)
+	users := repo.NewUserRepo(database)
+	hasher := &countingHasher{PasswordHasher: auth.NewBcryptHasher(4)}
+	svc := NewAuthService(users, nil, nil, nil, hasher, auth.DefaultPasswordPolicy, nil)
+	ctx := context.Background()
+
+	hash, err := hasher.Hash("Corr3ct-Horse-Battery")
+	if err != nil {
+		t.Fatal(err)
+	}
+	alice := createTestUser(t, users, "alice@example.com")
+	if err := users.UpdatePassword(ctx, alice.ID, hash); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := users.Create(ctx, &domain.User{Email: "oauth@example.com", Username: sql.NullString{String: "oauth", Valid: true}, Role: "user"}); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name, login, password string
+		wantErr               error
+	}{
+		{"correct password", "alice@example.com", "Corr3ct-Horse-Battery", nil},
+		{"wrong password", "alice@example.com", "wrong", domain.ErrInvalidCredentials},
+		{"unknown email", "nobody@example.com", "wrong", domain.ErrInvalidCredentials},
+		{"unknown username", "nobody", "wrong", domain.ErrInvalidCredentials},
+		{"account without password", "oauth", "wrong", domain.ErrInvalidCredentials},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hasher.compares = 0
+			if _, err := svc.Login(ctx, tt.login, tt.password); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Login = %v, want %v", err, tt.wantErr)
+			}
+			if hasher.compares != 1 {
+				t.Errorf("Compare called %d times, want 1", hasher.compares)
+			}
+		})
+	}
+}
@@ -0,0 +1,121 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"time"
+
+	"task-manager/internal/auth"
+	"task-manager/internal/domain"
+	"task-manager/internal/i18n"
+	"task-manager/internal/mail"
+	"task-manager/internal/repo"
+)
+
+type EmailVerificationService interface {
+	// SendVerification ส่งลิงก์ยืนยันอีเมล (จำกัดความถี่ตาม resendInterval)
+	SendVerification(ctx context.Context, userID int64) error
+
+	// Verify ยืนยันอีเมลด้วย token จากลิงก์
+	Verify(ctx context.Context, token string) error
+
+	IsEmailVerified(ctx context.Context, userID int64) (bool, error)
+}
+
+type emailVerificationService struct {
+	userRepo       repo.UserRepo
+	tokenRepo      repo.UserTokenRepo
+	mailer         mail.Mailer
+	verifyURL      string
+	ttl            time.Duration
+	resendInterval time.Duration
+}
+
+// verifyURL รับ ?token=... (GET /api/auth/verify-email)
+func NewEmailVerificationService(
+	userRepo repo.UserRepo,
+	tokenRepo repo.UserTokenRepo,
+	mailer mail.Mailer,
+	verifyURL string,
+	ttl, resendInterval time.Duration,
+) EmailVerificationService {
+	return &emailVerificationService{
+		userRepo:       userRepo,
+		tokenRepo:      tokenRepo,
+		mailer:         mailer,
+		verifyURL:      verifyURL,
+		ttl:            ttl,
+		resendInterval: resendInterval,
+	}
+}
+
+func (s *emailVerificationService) SendVerification(ctx context.Context, userID int64) error {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, repo.ErrNotFound) {
+			return domain.ErrUserNotFound
+		}
+		return err
+	}
+	if user.EmailVerified() {
+		return nil
+	}
+
+	last, err := s.tokenRepo.LatestCreatedAt(ctx, userID, domain.TokenPurposeEmailVerify)
+	if err != nil && !errors.Is(err, repo.ErrNotFound) {
+		return err
+	}
+	if err == nil && time.Since(last) < s.resendInterval {
+		return domain.ErrTooManyRequests
+	}
+
+	if err := s.tokenRepo.InvalidateForUser(ctx, userID, domain.TokenPurposeEmailVerify); err != nil {
+		return err
+	}
+	raw, hash, err := auth.NewOpaqueToken()
+	if err != nil {
+		return err
+	}
+	if err := s.tokenRepo.Create(ctx, &domain.UserToken{
+		UserID:    userID,
+		Purpose:   domain.TokenPurposeEmailVerify,
+		TokenHash: hash,
+		ExpiresAt: time.Now().Add(s.ttl),
+	}); err != nil {
+		return err
+	}
+
+	link := s.verifyURL + "?token=" + url.QueryEscape(raw)
+	loc := i18n.Pick(user.Locale.String, i18n.FromContext(ctx))
+	return s.mailer.Send(ctx, mail.Message{
+		To:      user.Email,
+		Subject: i18n.T(loc, "email.verify.subject"),
+		Text:    i18n.T(loc, "email.verify.body", int(s.ttl.Hours()), link),
+	})
+}
+
+func (s *emailVerificationService) Verify(ctx context.Context, token string) error {
+	if token == "" {
+		return domain.ErrInvalidToken
+	}
+	t, err := s.tokenRepo.Consume(ctx, domain.TokenPurposeEmailVerify, auth.HashToken(token))
+	if err != nil {
+		if errors.Is(err, repo.ErrNotFound) {
+			return domain.ErrInvalidToken
+		}
+		return err
+	}
+	if time.Now().After(t.ExpiresAt) {
+		return domain.ErrInvalidToken
+	}
+	return s.userRepo.MarkEmailVerified(ctx, t.UserID, time.Now())
+}
+
+func (s *emailVerificationService) IsEmailVerified(ctx context.Context, userID int64) (bool, error) {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return false, err
+	}
+	return user.EmailVerified(), nil
+}
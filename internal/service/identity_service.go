@@ -0,0 +1,110 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"task-manager/internal/auth"
+	"task-manager/internal/domain"
+	"task-manager/internal/repo"
+)
+
+// ผู้ใช้ที่ไม่มีรหัสผ่านต้องเพิ่งล็อกอินภายในช่วงนี้ถึงจะแก้วิธี login ได้
+const reauthWindow = 10 * time.Minute
+
+type IdentityService interface {
+	List(ctx context.Context, userID int64) ([]domain.Identity, error)
+
+	// Reauthenticate ยืนยันตัวตนซ้ำก่อนผูก/ยกเลิกการผูก: รหัสผ่าน ถ้าบัญชีมีรหัสผ่าน
+	// ไม่เช่นนั้น session ปัจจุบันต้องเพิ่งล็อกอินไม่เกิน reauthWindow
+	Reauthenticate(ctx context.Context, userID int64, sessionID, password string) error
+
+	// Link ผูก identity ภายนอกกับผู้ใช้ (ผูกซ้ำกับคนเดิมไม่ถือว่าผิด)
+	Link(ctx context.Context, userID int64, ext *auth.ExternalUser) (*domain.Identity, error)
+
+	// Unlink คืน domain.ErrLastLoginMethod ถ้าจะไม่เหลือวิธี login เลย
+	Unlink(ctx context.Context, userID, id int64) error
+}
+
+type identityService struct {
+	identityRepo repo.IdentityRepo
+	userRepo     repo.UserRepo
+	sessionRepo  repo.SessionRepo
+	hasher       auth.PasswordHasher
+}
+
+func NewIdentityService(identityRepo repo.IdentityRepo, userRepo repo.UserRepo, sessionRepo repo.SessionRepo, hasher auth.PasswordHasher) IdentityService {
+	return &identityService{identityRepo: identityRepo, userRepo: userRepo, sessionRepo: sessionRepo, hasher: hasher}
+}
+
+func (s *identityService) List(ctx context.Context, userID int64) ([]domain.Identity, error) {
+	return s.identityRepo.ListByUser(ctx, userID)
+}
+
+func (s *identityService) Reauthenticate(ctx context.Context, userID int64, sessionID, password string) error {
+	u, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, repo.ErrNotFound) {
+			return domain.ErrUserNotFound
+		}
+		return err
+	}
+
+	if u.PasswordHash.Valid && u.PasswordHash.String != "" {
+		if password == "" {
+			return domain.ErrReauthRequired
+		}
+		if err := s.hasher.Compare(u.PasswordHash.String, password); err != nil {
+			return domain.ErrInvalidCredentials
+		}
+		return nil
+	}
+
+	sess, err := s.sessionRepo.GetByID(ctx, sessionID)
+	if err != nil {
+		if errors.Is(err, repo.ErrNotFound) {
+			return domain.ErrReauthRequired
+		}
+		return err
+	}
+	if sess.UserID != userID || time.Since(sess.CreatedAt) > reauthWindow {
+		return domain.ErrReauthRequired
+	}
+	return nil
+}
+
+func (s *identityService) Link(ctx context.Context, userID int64, ext *auth.ExternalUser) (*domain.Identity, error) {
+	ident := &domain.Identity{
+		UserID:   userID,
+		Provider: ext.Provider,
+		Subject:  ext.Subject,
+		Email:    strings.TrimSpace(strings.ToLower(ext.Email)),
+	}
+	err := s.identityRepo.Create(ctx, ident)
+	if errors.Is(err, repo.ErrDuplicate) {
+		existing, err := s.identityRepo.GetByProviderSubject(ctx, ext.Provider, ext.Subject)
+		if err != nil {
+			return nil, err
+		}
+		if existing.UserID != userID {
+			return nil, domain.ErrIdentityInUse
+		}
+		return existing, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return ident, nil
+}
+
+func (s *identityService) Unlink(ctx context.Context, userID, id int64) error {
+	if err := s.identityRepo.Delete(ctx, userID, id); err != nil {
+		if errors.Is(err, repo.ErrNotFound) {
+			return domain.ErrIdentityNotFound
+		}
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,273 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/url"
+	"strings"
+	"time"
+
+	"task-manager/internal/auth"
+	"task-manager/internal/domain"
+	"task-manager/internal/i18n"
+	"task-manager/internal/mail"
+	"task-manager/internal/repo"
+)
+
+// ส่งคำเชิญเดิมซ้ำได้ไม่ถี่กว่านี้ (กันกดรัวจนกล่องอีเมลผู้รับเต็ม)
+const inviteResendInterval = time.Minute
+
+type InvitationService interface {
+	// ฝั่ง admin ของ workspace: เชิญ (อีเมลเดิมที่ยังรออยู่ = อัปเดตแล้วส่งใหม่), ดูรายการ, ส่งซ้ำ, ยกเลิก
+	Invite(ctx context.Context, actorID, workspaceID int64, email, role string) (*domain.Invitation, error)
+	ListPending(ctx context.Context, actorID, workspaceID int64) ([]*domain.Invitation, error)
+	Resend(ctx context.Context, actorID, workspaceID, id int64) (*domain.Invitation, error)
+	Revoke(ctx context.Context, actorID, workspaceID, id int64) error
+
+	// Lookup ตรวจ token จากลิงก์ (หน้ารับคำเชิญแสดงชื่อทีม/ผู้เชิญก่อนล็อกอินหรือสมัคร)
+	Lookup(ctx context.Context, token string) (*domain.Invitation, error)
+
+	// ValidateFor ตรวจก่อนสมัครบัญชีใหม่ว่า token ใช้กับอีเมลนี้ได้
+	ValidateFor(ctx context.Context, token, email string) error
+
+	// Accept เพิ่ม userID เข้า workspace (อีเมลของผู้ใช้ต้องตรงกับที่ถูกเชิญ)
+	Accept(ctx context.Context, userID int64, token string) (*domain.Workspace, error)
+}
+
+type invitationService struct {
+	invitationRepo repo.InvitationRepo
+	workspaceRepo  repo.WorkspaceRepo
+	userRepo       repo.UserRepo
+	jwt            auth.JWT
+	mailer         mail.Mailer
+	acceptURL      string
+	ttl            time.Duration
+}
+
+// acceptURL คือหน้ารับคำเชิญของ frontend (รับ ?token=...)
+func NewInvitationService(
+	invitationRepo repo.InvitationRepo,
+	workspaceRepo repo.WorkspaceRepo,
+	userRepo repo.UserRepo,
+	jwt auth.JWT,
+	mailer mail.Mailer,
+	acceptURL string,
+	ttl time.Duration,
+) InvitationService {
+	return &invitationService{
+		invitationRepo: invitationRepo,
+		workspaceRepo:  workspaceRepo,
+		userRepo:       userRepo,
+		jwt:            jwt,
+		mailer:         mailer,
+		acceptURL:      acceptURL,
+		ttl:            ttl,
+	}
+}
+
+// teamAdmin คืน workspace ของทีมที่ actorID เป็น admin
+func (s *invitationService) teamAdmin(ctx context.Context, actorID, workspaceID int64) (*domain.Workspace, error) {
+	w, err := s.workspaceRepo.GetForMember(ctx, workspaceID, actorID)
+	if err != nil {
+		if errors.Is(err, repo.ErrNotFound) {
+			return nil, domain.ErrWorkspaceNotFound
+		}
+		return nil, err
+	}
+	if w.Role != domain.WorkspaceRoleAdmin {
+		return nil, domain.ErrForbidden
+	}
+	if w.Personal() {
+		return nil, domain.ErrPersonalWorkspace
+	}
+	return w, nil
+}
+
+func (s *invitationService) Invite(ctx context.Context, actorID, workspaceID int64, email, role string) (*domain.Invitation, error) {
+	if !domain.ValidWorkspaceRole(role) {
+		return nil, domain.ErrInvalidRole
+	}
+	email = strings.TrimSpace(strings.ToLower(email))
+	if email == "" {
+		return nil, domain.ErrInvalidInput
+	}
+	w, err := s.teamAdmin(ctx, actorID, workspaceID)
+	if err != nil {
+		return nil, err
+	}
+
+	if u, err := s.userRepo.GetByEmail(ctx, email); err == nil {
+		if _, err := s.workspaceRepo.GetForMember(ctx, w.ID, u.ID); err == nil {
+			return nil, domain.ErrAlreadyMember
+		} else if !errors.Is(err, repo.ErrNotFound) {
+			return nil, err
+		}
+	} else if !errors.Is(err, repo.ErrNotFound) {
+		return nil, err
+	}
+
+	tokenID, err := auth.NewTokenID()
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	inv, err := s.invitationRepo.UpsertPending(ctx, &domain.Invitation{
+		WorkspaceID: w.ID,
+		Email:       email,
+		Role:        role,
+		TokenID:     tokenID,
+		InvitedBy:   sql.NullInt64{Int64: actorID, Valid: true},
+		ExpiresAt:   now.Add(s.ttl),
+		SentAt:      now,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return inv, s.send(ctx, inv)
+}
+
+func (s *invitationService) ListPending(ctx context.Context, actorID, workspaceID int64) ([]*domain.Invitation, error) {
+	if _, err := s.teamAdmin(ctx, actorID, workspaceID); err != nil {
+		return nil, err
+	}
+	return s.invitationRepo.ListPending(ctx, workspaceID)
+}
+
+func (s *invitationService) Resend(ctx context.Context, actorID, workspaceID, id int64) (*domain.Invitation, error) {
+	if _, err := s.teamAdmin(ctx, actorID, workspaceID); err != nil {
+		return nil, err
+	}
+	inv, err := s.invitationRepo.GetInWorkspace(ctx, workspaceID, id)
+	if err != nil {
+		if errors.Is(err, repo.ErrNotFound) {
+			return nil, domain.ErrInvitationNotFound
+		}
+		return nil, err
+	}
+	if inv.Status != domain.InvitationPending {
+		return nil, domain.ErrInvitationNotFound
+	}
+	if time.Since(inv.SentAt) < inviteResendInterval {
+		return nil, domain.ErrTooManyRequests
+	}
+
+	// token id ใหม่ = ลิงก์ในอีเมลก่อนหน้าใช้ไม่ได้อีก
+	tokenID, err := auth.NewTokenID()
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	if err := s.invitationRepo.Rotate(ctx, workspaceID, id, tokenID, now.Add(s.ttl), now); err != nil {
+		if errors.Is(err, repo.ErrNotFound) {
+			return nil, domain.ErrInvitationNotFound
+		}
+		return nil, err
+	}
+	inv.TokenID, inv.ExpiresAt, inv.SentAt = tokenID, now.Add(s.ttl), now
+	return inv, s.send(ctx, inv)
+}
+
+func (s *invitationService) Revoke(ctx context.Context, actorID, workspaceID, id int64) error {
+	if _, err := s.teamAdmin(ctx, actorID, workspaceID); err != nil {
+		return err
+	}
+	if err := s.invitationRepo.Revoke(ctx, workspaceID, id); err != nil {
+		if errors.Is(err, repo.ErrNotFound) {
+			return domain.ErrInvitationNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+func (s *invitationService) send(ctx context.Context, inv *domain.Invitation) error {
+	token, err := s.jwt.GenerateInviteToken(inv.ID, inv.TokenID, inv.ExpiresAt)
+	if err != nil {
+		return err
+	}
+
+	// ผู้รับมีบัญชีอยู่แล้ว = ใช้ภาษาที่เขาตั้งไว้ ไม่งั้นใช้ภาษาของผู้เชิญ
+	loc := i18n.FromContext(ctx)
+	if u, err := s.userRepo.GetByEmail(ctx, inv.Email); err == nil {
+		loc = i18n.Pick(u.Locale.String, loc)
+	}
+	inviter := inv.InviterName.String
+	if inviter == "" {
+		inviter = "Task Manager"
+	}
+
+	link := s.acceptURL + "?token=" + url.QueryEscape(token)
+	return s.mailer.Send(ctx, mail.Message{
+		To:      inv.Email,
+		Subject: i18n.T(loc, "email.invite.subject", inv.WorkspaceName),
+		Text:    i18n.T(loc, "email.invite.body", inviter, inv.WorkspaceName, int(s.ttl.Hours()), link),
+	})
+}
+
+func (s *invitationService) Lookup(ctx context.Context, token string) (*domain.Invitation, error) {
+	id, tokenID, err := s.jwt.ParseInviteToken(token)
+	if err != nil {
+		return nil, domain.ErrInvalidToken
+	}
+	inv, err := s.invitationRepo.Get(ctx, id)
+	if err != nil {
+		if errors.Is(err, repo.ErrNotFound) {
+			return nil, domain.ErrInvalidToken
+		}
+		return nil, err
+	}
+	// ลายเซ็นถูกแต่ถูกส่งซ้ำ/ยกเลิก/ใช้ไปแล้ว
+	if inv.TokenID != tokenID || inv.Status != domain.InvitationPending || inv.Expired(time.Now()) {
+		return nil, domain.ErrInvalidToken
+	}
+	return inv, nil
+}
+
+func (s *invitationService) ValidateFor(ctx context.Context, token, email string) error {
+	inv, err := s.Lookup(ctx, token)
+	if err != nil {
+		return err
+	}
+	if !strings.EqualFold(strings.TrimSpace(email), inv.Email) {
+		return domain.ErrInviteEmailMismatch
+	}
+	return nil
+}
+
+func (s *invitationService) Accept(ctx context.Context, userID int64, token string) (*domain.Workspace, error) {
+	inv, err := s.Lookup(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+	u, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, repo.ErrNotFound) {
+			return nil, domain.ErrUserNotFound
+		}
+		return nil, err
+	}
+	if !strings.EqualFold(u.Email, inv.Email) {
+		return nil, domain.ErrInviteEmailMismatch
+	}
+
+	now := time.Now()
+	if err := s.invitationRepo.Accept(ctx, inv.ID, inv.TokenID, userID, now); err != nil {
+		if errors.Is(err, repo.ErrNotFound) {
+			return nil, domain.ErrInvalidToken
+		}
+		return nil, err
+	}
+	// เปิดลิงก์จากอีเมลนี้ได้ = เป็นเจ้าของอีเมลจริง
+	if !u.EmailVerified() {
+		if err := s.userRepo.MarkEmailVerified(ctx, userID, now); err != nil {
+			return nil, err
+		}
+	}
+
+	w, err := s.workspaceRepo.GetForMember(ctx, inv.WorkspaceID, userID)
+	if errors.Is(err, repo.ErrNotFound) {
+		return nil, domain.ErrWorkspaceNotFound
+	}
+	return w, err
+}
@@ -0,0 +1,247 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"strings"
+	"time"
+
+	"task-manager/internal/auth"
+	"task-manager/internal/domain"
+	"task-manager/internal/repo"
+)
+
+const (
+	mfaIssuer         = "Task Manager"
+	recoveryCodeCount = 10
+)
+
+type MFAService interface {
+	Status(ctx context.Context, userID int64) (*domain.MFAStatus, error)
+
+	// BeginSetup สร้าง secret ใหม่ (ยังไม่เปิดใช้จนกว่าจะ Enable ด้วยรหัสแรก)
+	BeginSetup(ctx context.Context, userID int64) (*domain.MFASetup, error)
+
+	// Enable ยืนยันรหัสแรกจากแอป แล้วคืน recovery codes (แสดงครั้งเดียว)
+	Enable(ctx context.Context, userID int64, code string) ([]string, error)
+
+	// Disable / RegenerateRecoveryCodes ต้องยืนยันด้วย TOTP หรือ recovery code
+	Disable(ctx context.Context, userID int64, code string) error
+	RegenerateRecoveryCodes(ctx context.Context, userID int64, code string) ([]string, error)
+
+	// Login ขั้นที่สอง
+	Required(ctx context.Context, userID int64) (bool, error)
+	StartLogin(userID int64) (string, time.Duration, error)
+	CompleteLogin(ctx context.Context, mfaToken, code string) (int64, error)
+
+	// AdminReset ลบ 2FA ของผู้ใช้ (เช่น ทำโทรศัพท์หาย) actor ต้องเป็น admin
+	AdminReset(ctx context.Context, actorID, targetID int64) error
+}
+
+type mfaService struct {
+	mfaRepo  repo.MFARepo
+	userRepo repo.UserRepo
+	jwt      auth.JWT
+}
+
+func NewMFAService(mfaRepo repo.MFARepo, userRepo repo.UserRepo, jwt auth.JWT) MFAService {
+	return &mfaService{mfaRepo: mfaRepo, userRepo: userRepo, jwt: jwt}
+}
+
+func (s *mfaService) get(ctx context.Context, userID int64) (*domain.UserMFA, error) {
+	m, err := s.mfaRepo.Get(ctx, userID)
+	if err != nil && !errors.Is(err, repo.ErrNotFound) {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (s *mfaService) Status(ctx context.Context, userID int64) (*domain.MFAStatus, error) {
+	m, err := s.get(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	st := &domain.MFAStatus{Enabled: m.Enabled()}
+	if st.Enabled {
+		if st.RecoveryCodesRemaining, err = s.mfaRepo.CountRecoveryCodes(ctx, userID); err != nil {
+			return nil, err
+		}
+	}
+	return st, nil
+}
+
+func (s *mfaService) BeginSetup(ctx context.Context, userID int64) (*domain.MFASetup, error) {
+	m, err := s.get(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if m.Enabled() {
+		return nil, domain.ErrMFAAlreadyEnabled
+	}
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	secret, err := auth.NewTOTPSecret()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.mfaRepo.UpsertPending(ctx, userID, secret); err != nil {
+		return nil, err
+	}
+	return &domain.MFASetup{
+		Secret:     secret,
+		OTPAuthURI: auth.TOTPURI(mfaIssuer, user.Email, secret),
+	}, nil
+}
+
+func (s *mfaService) Enable(ctx context.Context, userID int64, code string) ([]string, error) {
+	m, err := s.get(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if m == nil {
+		return nil, domain.ErrMFANotEnabled
+	}
+	if m.Enabled() {
+		return nil, domain.ErrMFAAlreadyEnabled
+	}
+
+	step, ok := auth.VerifyTOTP(m.Secret, code, time.Now(), m.LastUsedStep)
+	if !ok {
+		return nil, domain.ErrInvalidMFACode
+	}
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.mfaRepo.Enable(ctx, userID, step, hashes); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+func (s *mfaService) Disable(ctx context.Context, userID int64, code string) error {
+	if err := s.verifyEnabled(ctx, userID, code); err != nil {
+		return err
+	}
+	return s.mfaRepo.Delete(ctx, userID)
+}
+
+func (s *mfaService) RegenerateRecoveryCodes(ctx context.Context, userID int64, code string) ([]string, error) {
+	if err := s.verifyEnabled(ctx, userID, code); err != nil {
+		return nil, err
+	}
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.mfaRepo.ReplaceRecoveryCodes(ctx, userID, hashes); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+func (s *mfaService) Required(ctx context.Context, userID int64) (bool, error) {
+	m, err := s.get(ctx, userID)
+	if err != nil {
+		return false, err
+	}
+	return m.Enabled(), nil
+}
+
+func (s *mfaService) StartLogin(userID int64) (string, time.Duration, error) {
+	return s.jwt.GenerateMFAToken(userID)
+}
+
+func (s *mfaService) CompleteLogin(ctx context.Context, mfaToken, code string) (int64, error) {
+	userID, err := s.jwt.ParseMFAToken(mfaToken)
+	if err != nil {
+		return 0, domain.ErrInvalidToken
+	}
+	if err := s.verifyEnabled(ctx, userID, code); err != nil {
+		return 0, err
+	}
+	return userID, nil
+}
+
+func (s *mfaService) AdminReset(ctx context.Context, actorID, targetID int64) error {
+	actor, err := s.userRepo.GetByID(ctx, actorID)
+	if err != nil {
+		return err
+	}
+	if !domain.HasPermission(actor.Role, domain.PermUserManage) {
+		return domain.ErrForbidden
+	}
+	if _, err := s.userRepo.GetByID(ctx, targetID); err != nil {
+		if errors.Is(err, repo.ErrNotFound) {
+			return domain.ErrUserNotFound
+		}
+		return err
+	}
+	return s.mfaRepo.Delete(ctx, targetID)
+}
+
+// verifyEnabled รับได้ทั้ง TOTP 6 หลัก และ recovery code
+func (s *mfaService) verifyEnabled(ctx context.Context, userID int64, code string) error {
+	m, err := s.get(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if !m.Enabled() {
+		return domain.ErrMFANotEnabled
+	}
+
+	if step, ok := auth.VerifyTOTP(m.Secret, code, time.Now(), m.LastUsedStep); ok {
+		used, err := s.mfaRepo.UseStep(ctx, userID, step)
+		if err != nil {
+			return err
+		}
+		if !used {
+			return domain.ErrInvalidMFACode
+		}
+		return nil
+	}
+
+	normalized := normalizeRecoveryCode(code)
+	if normalized == "" {
+		return domain.ErrInvalidMFACode
+	}
+	used, err := s.mfaRepo.UseRecoveryCode(ctx, userID, auth.HashToken(normalized))
+	if err != nil {
+		return err
+	}
+	if !used {
+		return domain.ErrInvalidMFACode
+	}
+	return nil
+}
+
+const recoveryAlphabet = "abcdefghjkmnpqrstuvwxyz23456789" // ตัดตัวที่สับสนง่าย (0/o, 1/l/i)
+
+// newRecoveryCodes returns codes formatted as xxxxx-xxxxx and their hashes
+func newRecoveryCodes() ([]string, []string, error) {
+	codes := make([]string, 0, recoveryCodeCount)
+	hashes := make([]string, 0, recoveryCodeCount)
+	buf := make([]byte, 10)
+	for i := 0; i < recoveryCodeCount; i++ {
+		if _, err := rand.Read(buf); err != nil {
+			return nil, nil, err
+		}
+		raw := make([]byte, len(buf))
+		for j, b := range buf {
+			raw[j] = recoveryAlphabet[int(b)%len(recoveryAlphabet)]
+		}
+		code := string(raw[:5]) + "-" + string(raw[5:])
+		codes = append(codes, code)
+		hashes = append(hashes, auth.HashToken(normalizeRecoveryCode(code)))
+	}
+	return codes, hashes, nil
+}
+
+func normalizeRecoveryCode(code string) string {
+	code = strings.ToLower(strings.TrimSpace(code))
+	return strings.NewReplacer("-", "", " ", "").Replace(code)
+}
@@ -0,0 +1,321 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"task-manager/internal/auth"
+	"task-manager/internal/domain"
+	"task-manager/internal/repo"
+
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/webauthn"
+)
+
+const (
+	passkeyCeremonyTTL = 5 * time.Minute
+	passkeyMaxNameLen  = 100
+	passkeyDefaultName = "Passkey"
+)
+
+// PasskeyConfig describes the relying party (เว็บของเรา) ที่ passkey ผูกอยู่
+type PasskeyConfig struct {
+	RPID        string // โดเมน เช่น example.com (ห้ามมี scheme/port)
+	DisplayName string
+	Origins     []string // origin ที่หน้า frontend เรียก navigator.credentials จาก
+}
+
+type PasskeyService interface {
+	// ลงทะเบียน passkey ใหม่ให้ผู้ใช้ที่ล็อกอินอยู่
+	// Begin คืน ceremonyID ที่ต้องส่งกลับมาตอน Finish พร้อม options ให้ navigator.credentials.create
+	BeginRegistration(ctx context.Context, userID int64) (string, *protocol.CredentialCreation, error)
+	FinishRegistration(ctx context.Context, userID int64, ceremonyID, name string, credential []byte) (*domain.Passkey, error)
+
+	// login แบบ discoverable (ไม่ต้องกรอกอีเมล) คืน userID เมื่อลายเซ็นถูกต้อง
+	BeginLogin(ctx context.Context) (string, *protocol.CredentialAssertion, error)
+	FinishLogin(ctx context.Context, ceremonyID string, credential []byte) (int64, error)
+
+	List(ctx context.Context, userID int64) ([]domain.Passkey, error)
+	Rename(ctx context.Context, userID, id int64, name string) error
+	Delete(ctx context.Context, userID, id int64) error
+}
+
+type passkeyService struct {
+	webAuthn    *webauthn.WebAuthn
+	userRepo    repo.UserRepo
+	passkeyRepo repo.PasskeyRepo
+	sessionRepo repo.WebAuthnSessionRepo
+}
+
+func NewPasskeyService(cfg PasskeyConfig, userRepo repo.UserRepo, passkeyRepo repo.PasskeyRepo, sessionRepo repo.WebAuthnSessionRepo) (PasskeyService, error) {
+	w, err := webauthn.New(&webauthn.Config{
+		RPID:          cfg.RPID,
+		RPDisplayName: cfg.DisplayName,
+		RPOrigins:     cfg.Origins,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &passkeyService{webAuthn: w, userRepo: userRepo, passkeyRepo: passkeyRepo, sessionRepo: sessionRepo}, nil
+}
+
+// passkeyUser adapts domain.User to webauthn.User
+type passkeyUser struct {
+	user        *domain.User
+	credentials []webauthn.Credential
+}
+
+func (u *passkeyUser) WebAuthnID() []byte   { return userHandle(u.user.ID) }
+func (u *passkeyUser) WebAuthnName() string { return u.user.Email }
+func (u *passkeyUser) WebAuthnDisplayName() string {
+	if u.user.Name.Valid && u.user.Name.String != "" {
+		return u.user.Name.String
+	}
+	return u.user.Email
+}
+func (u *passkeyUser) WebAuthnCredentials() []webauthn.Credential { return u.credentials }
+
+// userHandle คือ user.id ในรูปข้อความ (ไม่ใช่อีเมล เพื่อไม่ให้ข้อมูลส่วนตัวติดไปกับ authenticator)
+func userHandle(userID int64) []byte { return []byte(strconv.FormatInt(userID, 10)) }
+
+func toCredential(p domain.Passkey) webauthn.Credential {
+	transports := make([]protocol.AuthenticatorTransport, 0, len(p.Transports))
+	for _, t := range p.Transports {
+		transports = append(transports, protocol.AuthenticatorTransport(t))
+	}
+	return webauthn.Credential{
+		ID:              p.CredentialID,
+		PublicKey:       p.PublicKey,
+		AttestationType: p.AttestationType,
+		Transport:       transports,
+		Flags:           webauthn.NewCredentialFlags(protocol.AuthenticatorFlags(p.Flags)),
+		Authenticator: webauthn.Authenticator{
+			AAGUID:    p.AAGUID,
+			SignCount: p.SignCount,
+		},
+	}
+}
+
+func (s *passkeyService) loadUser(ctx context.Context, userID int64) (*passkeyUser, []domain.Passkey, error) {
+	u, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, repo.ErrNotFound) {
+			return nil, nil, domain.ErrUserNotFound
+		}
+		return nil, nil, err
+	}
+	passkeys, err := s.passkeyRepo.ListByUser(ctx, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+	creds := make([]webauthn.Credential, 0, len(passkeys))
+	for _, p := range passkeys {
+		creds = append(creds, toCredential(p))
+	}
+	return &passkeyUser{user: u, credentials: creds}, passkeys, nil
+}
+
+func (s *passkeyService) saveCeremony(ctx context.Context, session *webauthn.SessionData) (string, error) {
+	id, err := auth.NewTokenID()
+	if err != nil {
+		return "", err
+	}
+	data, err := json.Marshal(session)
+	if err != nil {
+		return "", err
+	}
+	if err := s.sessionRepo.Save(ctx, id, data, time.Now().Add(passkeyCeremonyTTL)); err != nil {
+		return "", err
+	}
+	return id, nil
+}
+
+func (s *passkeyService) consumeCeremony(ctx context.Context, id string) (*webauthn.SessionData, error) {
+	if id == "" {
+		return nil, domain.ErrInvalidPasskey
+	}
+	data, err := s.sessionRepo.Consume(ctx, id)
+	if err != nil {
+		if errors.Is(err, repo.ErrNotFound) {
+			return nil, domain.ErrInvalidPasskey
+		}
+		return nil, err
+	}
+	var session webauthn.SessionData
+	if err := json.Unmarshal(data, &session); err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+func (s *passkeyService) BeginRegistration(ctx context.Context, userID int64) (string, *protocol.CredentialCreation, error) {
+	u, _, err := s.loadUser(ctx, userID)
+	if err != nil {
+		return "", nil, err
+	}
+
+	creation, session, err := s.webAuthn.BeginRegistration(u,
+		// resident key = ใช้ login แบบไม่ต้องกรอกอีเมลได้, UV = ผ่าน PIN/biometric แทน 2FA ได้
+		webauthn.WithAuthenticatorSelection(protocol.AuthenticatorSelection{
+			ResidentKey:      protocol.ResidentKeyRequirementRequired,
+			UserVerification: protocol.VerificationRequired,
+		}),
+		webauthn.WithExclusions(webauthn.Credentials(u.credentials).CredentialDescriptors()),
+	)
+	if err != nil {
+		return "", nil, err
+	}
+	id, err := s.saveCeremony(ctx, session)
+	if err != nil {
+		return "", nil, err
+	}
+	return id, creation, nil
+}
+
+func (s *passkeyService) FinishRegistration(ctx context.Context, userID int64, ceremonyID, name string, credential []byte) (*domain.Passkey, error) {
+	session, err := s.consumeCeremony(ctx, ceremonyID)
+	if err != nil {
+		return nil, err
+	}
+	// ceremony ต้องเริ่มโดยผู้ใช้คนเดียวกัน
+	if !bytes.Equal(session.UserID, userHandle(userID)) {
+		return nil, domain.ErrInvalidPasskey
+	}
+
+	u, _, err := s.loadUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	parsed, err := protocol.ParseCredentialCreationResponseBytes(credential)
+	if err != nil {
+		return nil, domain.ErrInvalidPasskey
+	}
+	cred, err := s.webAuthn.CreateCredential(u, *session, parsed)
+	if err != nil {
+		return nil, domain.ErrInvalidPasskey
+	}
+
+	transports := make([]string, 0, len(cred.Transport))
+	for _, t := range cred.Transport {
+		transports = append(transports, string(t))
+	}
+	p := &domain.Passkey{
+		UserID:          userID,
+		CredentialID:    cred.ID,
+		PublicKey:       cred.PublicKey,
+		AttestationType: cred.AttestationType,
+		Transports:      transports,
+		AAGUID:          cred.Authenticator.AAGUID,
+		Flags:           uint8(cred.Flags.ProtocolValue()),
+		SignCount:       cred.Authenticator.SignCount,
+		Name:            passkeyName(name),
+	}
+	if err := s.passkeyRepo.Create(ctx, p); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+func (s *passkeyService) BeginLogin(ctx context.Context) (string, *protocol.CredentialAssertion, error) {
+	assertion, session, err := s.webAuthn.BeginDiscoverableLogin(
+		webauthn.WithUserVerification(protocol.VerificationRequired),
+	)
+	if err != nil {
+		return "", nil, err
+	}
+	id, err := s.saveCeremony(ctx, session)
+	if err != nil {
+		return "", nil, err
+	}
+	return id, assertion, nil
+}
+
+func (s *passkeyService) FinishLogin(ctx context.Context, ceremonyID string, credential []byte) (int64, error) {
+	session, err := s.consumeCeremony(ctx, ceremonyID)
+	if err != nil {
+		return 0, err
+	}
+	parsed, err := protocol.ParseCredentialRequestResponseBytes(credential)
+	if err != nil {
+		return 0, domain.ErrInvalidPasskey
+	}
+
+	var passkeys []domain.Passkey
+	handler := func(_, handle []byte) (webauthn.User, error) {
+		userID, err := strconv.ParseInt(string(handle), 10, 64)
+		if err != nil {
+			return nil, domain.ErrInvalidPasskey
+		}
+		var u *passkeyUser
+		u, passkeys, err = s.loadUser(ctx, userID)
+		if err != nil {
+			return nil, err
+		}
+		return u, nil
+	}
+
+	user, cred, err := s.webAuthn.ValidatePasskeyLogin(handler, *session, parsed)
+	if err != nil {
+		return 0, domain.ErrInvalidPasskey
+	}
+	// sign counter ถอยหลัง = อาจมี authenticator ถูก clone ไม่ให้ผ่าน
+	if cred.Authenticator.CloneWarning {
+		return 0, domain.ErrInvalidPasskey
+	}
+
+	pu := user.(*passkeyUser)
+	for _, p := range passkeys {
+		if bytes.Equal(p.CredentialID, cred.ID) {
+			if err := s.passkeyRepo.MarkUsed(ctx, p.ID, cred.Authenticator.SignCount, time.Now()); err != nil {
+				return 0, err
+			}
+			break
+		}
+	}
+	return pu.user.ID, nil
+}
+
+func (s *passkeyService) List(ctx context.Context, userID int64) ([]domain.Passkey, error) {
+	return s.passkeyRepo.ListByUser(ctx, userID)
+}
+
+func (s *passkeyService) Rename(ctx context.Context, userID, id int64, name string) error {
+	name = strings.TrimSpace(name)
+	if name == "" || utf8.RuneCountInString(name) > passkeyMaxNameLen {
+		return domain.ErrInvalidInput
+	}
+	if err := s.passkeyRepo.Rename(ctx, userID, id, name); err != nil {
+		if errors.Is(err, repo.ErrNotFound) {
+			return domain.ErrPasskeyNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+func (s *passkeyService) Delete(ctx context.Context, userID, id int64) error {
+	if err := s.passkeyRepo.Delete(ctx, userID, id); err != nil {
+		if errors.Is(err, repo.ErrNotFound) {
+			return domain.ErrPasskeyNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+func passkeyName(name string) string {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return passkeyDefaultName
+	}
+	if r := []rune(name); len(r) > passkeyMaxNameLen {
+		name = string(r[:passkeyMaxNameLen])
+	}
+	return name
+}
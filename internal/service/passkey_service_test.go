@@ -0,0 +1,346 @@
+package service
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"task-manager/internal/domain"
+	"task-manager/internal/repo"
+
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/protocol/webauthncbor"
+	"github.com/go-webauthn/webauthn/protocol/webauthncose"
+)
+
+const (
+	testRPID   = "app.example.com"
+	testOrigin = "https://app.example.com"
+)
+
+// softAuthenticator เป็น platform authenticator ในหน่วยความจำ: กุญแจ P-256 หนึ่งคู่ต่อ credential
+// attestation "none" และตั้ง UP+UV ทุกครั้ง (เหมือนผู้ใช้ผ่าน PIN/biometric แล้ว)
+type softAuthenticator struct {
+	origin       string
+	credentialID []byte
+	key          *ecdsa.PrivateKey
+	userHandle   []byte
+	signCount    uint32
+}
+
+func newSoftAuthenticator(t *testing.T) *softAuthenticator {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		t.Fatal(err)
+	}
+	return &softAuthenticator{origin: testOrigin, credentialID: id, key: key}
+}
+
+const (
+	flagUserPresent  = 0x01
+	flagUserVerified = 0x04
+	flagAttestedData = 0x40
+)
+
+func (a *softAuthenticator) authData(flags byte, attested []byte) []byte {
+	rpHash := sha256.Sum256([]byte(testRPID))
+	out := append([]byte{}, rpHash[:]...)
+	out = append(out, flags)
+	out = binary.BigEndian.AppendUint32(out, a.signCount)
+	return append(out, attested...)
+}
+
+func (a *softAuthenticator) clientData(t *testing.T, typ string, challenge protocol.URLEncodedBase64) []byte {
+	t.Helper()
+	data, err := json.Marshal(map[string]any{
+		"type":      typ,
+		"challenge": challenge.String(),
+		"origin":    a.origin,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+// create ตอบ navigator.credentials.create (JSON แบบที่ frontend ส่งมา)
+func (a *softAuthenticator) create(t *testing.T, creation *protocol.CredentialCreation) []byte {
+	t.Helper()
+	a.userHandle = creation.Response.User.ID.(protocol.URLEncodedBase64)
+
+	coseKey, err := webauthncbor.Marshal(webauthncose.EC2PublicKeyData{
+		PublicKeyData: webauthncose.PublicKeyData{
+			KeyType:   int64(webauthncose.EllipticKey),
+			Algorithm: int64(webauthncose.AlgES256),
+		},
+		Curve:  int64(webauthncose.P256),
+		XCoord: a.key.X.FillBytes(make([]byte, 32)),
+		YCoord: a.key.Y.FillBytes(make([]byte, 32)),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	attested := make([]byte, 16) // AAGUID ศูนย์ทั้งหมด
+	attested = binary.BigEndian.AppendUint16(attested, uint16(len(a.credentialID)))
+	attested = append(attested, a.credentialID...)
+	attested = append(attested, coseKey...)
+
+	attObj, err := webauthncbor.Marshal(map[string]any{
+		"fmt":      "none",
+		"attStmt":  map[string]any{},
+		"authData": a.authData(flagUserPresent|flagUserVerified|flagAttestedData, attested),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return a.response(t, map[string]any{
+		"clientDataJSON":    b64(a.clientData(t, "webauthn.create", creation.Response.Challenge)),
+		"attestationObject": b64(attObj),
+		"transports":        []string{"internal"},
+	})
+}
+
+// get ตอบ navigator.credentials.get แบบ discoverable (ส่ง userHandle กลับมาด้วย)
+func (a *softAuthenticator) get(t *testing.T, assertion *protocol.CredentialAssertion) []byte {
+	t.Helper()
+	a.signCount++
+	authData := a.authData(flagUserPresent|flagUserVerified, nil)
+	clientData := a.clientData(t, "webauthn.get", assertion.Response.Challenge)
+
+	clientHash := sha256.Sum256(clientData)
+	digest := sha256.Sum256(append(append([]byte{}, authData...), clientHash[:]...))
+	sig, err := ecdsa.SignASN1(rand.Reader, a.key, digest[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	return a.response(t, map[string]any{
+		"clientDataJSON":    b64(clientData),
+		"authenticatorData": b64(authData),
+		"signature":         b64(sig),
+		"userHandle":        b64(a.userHandle),
+	})
+}
+
+func (a *softAuthenticator) response(t *testing.T, resp map[string]any) []byte {
+	t.Helper()
+	data, err := json.Marshal(map[string]any{
+		"id":       b64(a.credentialID),
+		"rawId":    b64(a.credentialID),
+		"type":     "public-key",
+		"response": resp,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func b64(b []byte) string { return base64.RawURLEncoding.EncodeToString(b) }
+
+type passkeyFixture struct {
+	svc      PasskeyService
+	users    repo.UserRepo
+	passkeys repo.PasskeyRepo
+}
+
+func newPasskeyFixture(t *testing.T) *passkeyFixture {
+	t.Helper()
+	database := newTestDB(t)
+	users := repo.NewUserRepo(database)
+	passkeys := repo.NewPasskeyRepo(database)
+	svc, err := NewPasskeyService(PasskeyConfig{RPID: testRPID, DisplayName: "Test", Origins: []string{testOrigin}},
+		users, passkeys, repo.NewWebAuthnSessionRepo(database))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &passkeyFixture{svc: svc, users: users, passkeys: passkeys}
+}
+
+// register ลงทะเบียน authenticator ใหม่ให้ผู้ใช้จนเสร็จ
+func (f *passkeyFixture) register(t *testing.T, userID int64) (*softAuthenticator, *domain.Passkey) {
+	t.Helper()
+	ctx := context.Background()
+	ceremony, creation, err := f.svc.BeginRegistration(ctx, userID)
+	if err != nil {
+		t.Fatalf("BeginRegistration: %v", err)
+	}
+	a := newSoftAuthenticator(t)
+	p, err := f.svc.FinishRegistration(ctx, userID, ceremony, "  Laptop  ", a.create(t, creation))
+	if err != nil {
+		t.Fatalf("FinishRegistration: %v", err)
+	}
+	return a, p
+}
+
+func TestPasskeyRegisterAndLogin(t *testing.T) {
+	f := newPasskeyFixture(t)
+	ctx := context.Background()
+	alice := createTestUser(t, f.users, "alice@example.com")
+
+	a, p := f.register(t, alice.ID)
+	if p.Name != "Laptop" || p.UserID != alice.ID {
+		t.Errorf("registered passkey = %+v", p)
+	}
+
+	for i := 0; i < 2; i++ {
+		ceremony, assertion, err := f.svc.BeginLogin(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		userID, err := f.svc.FinishLogin(ctx, ceremony, a.get(t, assertion))
+		if err != nil {
+			t.Fatalf("FinishLogin #%d: %v", i+1, err)
+		}
+		if userID != alice.ID {
+			t.Fatalf("FinishLogin = user %d, want %d", userID, alice.ID)
+		}
+	}
+
+	list, err := f.svc.List(ctx, alice.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 1 || list[0].SignCount != a.signCount || !list[0].LastUsedAt.Valid {
+		t.Errorf("after login passkeys = %+v, want sign count %d and last used set", list, a.signCount)
+	}
+}
+
+func TestPasskeyLoginRejected(t *testing.T) {
+	tests := []struct {
+		name   string
+		tamper func(t *testing.T, a *softAuthenticator, resp []byte) []byte
+		setup  func(a *softAuthenticator)
+	}{
+		{
+			name:  "wrong origin",
+			setup: func(a *softAuthenticator) { a.origin = "https://evil.example.net" },
+		},
+		{
+			name: "bad signature",
+			tamper: func(t *testing.T, a *softAuthenticator, resp []byte) []byte {
+				var m map[string]any
+				if err := json.Unmarshal(resp, &m); err != nil {
+					t.Fatal(err)
+				}
+				m["response"].(map[string]any)["signature"] = b64([]byte("not a signature"))
+				out, _ := json.Marshal(m)
+				return out
+			},
+		},
+		{
+			name:  "unknown user handle",
+			setup: func(a *softAuthenticator) { a.userHandle = userHandle(999999) },
+		},
+		{
+			// sign counter ไม่ขยับ = authenticator อาจถูก clone
+			name:  "sign counter went backwards",
+			setup: func(a *softAuthenticator) { a.signCount = 0 },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newPasskeyFixture(t)
+			ctx := context.Background()
+			alice := createTestUser(t, f.users, "alice@example.com")
+			a, _ := f.register(t, alice.ID)
+
+			// login สำเร็จหนึ่งครั้งก่อน ให้มี sign count ที่บันทึกไว้
+			ceremony, assertion, err := f.svc.BeginLogin(ctx)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := f.svc.FinishLogin(ctx, ceremony, a.get(t, assertion)); err != nil {
+				t.Fatalf("first login: %v", err)
+			}
+
+			if tt.setup != nil {
+				tt.setup(a)
+			}
+			ceremony, assertion, err = f.svc.BeginLogin(ctx)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp := a.get(t, assertion)
+			if tt.tamper != nil {
+				resp = tt.tamper(t, a, resp)
+			}
+			if _, err := f.svc.FinishLogin(ctx, ceremony, resp); !errors.Is(err, domain.ErrInvalidPasskey) {
+				t.Fatalf("FinishLogin err = %v, want ErrInvalidPasskey", err)
+			}
+		})
+	}
+}
+
+// ceremony ใช้ได้ครั้งเดียว ส่ง assertion เดิมซ้ำต้องไม่ผ่าน
+func TestPasskeyCeremonyIsSingleUse(t *testing.T) {
+	f := newPasskeyFixture(t)
+	ctx := context.Background()
+	alice := createTestUser(t, f.users, "alice@example.com")
+	a, _ := f.register(t, alice.ID)
+
+	ceremony, assertion, err := f.svc.BeginLogin(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp := a.get(t, assertion)
+	if _, err := f.svc.FinishLogin(ctx, ceremony, resp); err != nil {
+		t.Fatalf("first login: %v", err)
+	}
+	if _, err := f.svc.FinishLogin(ctx, ceremony, resp); !errors.Is(err, domain.ErrInvalidPasskey) {
+		t.Fatalf("replayed login err = %v, want ErrInvalidPasskey", err)
+	}
+}
+
+// ceremony ลงทะเบียนที่ alice เริ่ม bob เอาไปจบไม่ได้ (ไม่งั้น passkey ของ bob ไปผูกกับใครก็ได้)
+func TestPasskeyRegistrationBoundToUser(t *testing.T) {
+	f := newPasskeyFixture(t)
+	ctx := context.Background()
+	alice := createTestUser(t, f.users, "alice@example.com")
+	bob := createTestUser(t, f.users, "bob@example.com")
+
+	ceremony, creation, err := f.svc.BeginRegistration(ctx, alice.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	a := newSoftAuthenticator(t)
+	if _, err := f.svc.FinishRegistration(ctx, bob.ID, ceremony, "", a.create(t, creation)); !errors.Is(err, domain.ErrInvalidPasskey) {
+		t.Fatalf("FinishRegistration by another user err = %v, want ErrInvalidPasskey", err)
+	}
+	if list, _ := f.svc.List(ctx, bob.ID); len(list) != 0 {
+		t.Fatalf("bob has passkeys %+v", list)
+	}
+}
+
+func TestPasskeyManageOnlyOwn(t *testing.T) {
+	f := newPasskeyFixture(t)
+	ctx := context.Background()
+	alice := createTestUser(t, f.users, "alice@example.com")
+	bob := createTestUser(t, f.users, "bob@example.com")
+	_, p := f.register(t, alice.ID)
+
+	if err := f.svc.Rename(ctx, bob.ID, p.ID, "mine now"); !errors.Is(err, domain.ErrPasskeyNotFound) {
+		t.Errorf("Rename by other user err = %v, want ErrPasskeyNotFound", err)
+	}
+	if err := f.svc.Delete(ctx, bob.ID, p.ID); !errors.Is(err, domain.ErrPasskeyNotFound) {
+		t.Errorf("Delete by other user err = %v, want ErrPasskeyNotFound", err)
+	}
+	if err := f.svc.Rename(ctx, alice.ID, p.ID, "   "); !errors.Is(err, domain.ErrInvalidInput) {
+		t.Errorf("Rename to blank err = %v, want ErrInvalidInput", err)
+	}
+	if err := f.svc.Delete(ctx, alice.ID, p.ID); err != nil {
+		t.Errorf("Delete own passkey: %v", err)
+	}
+}
@@ -0,0 +1,133 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"strings"
+	"time"
+
+	"task-manager/internal/auth"
+	"task-manager/internal/domain"
+	"task-manager/internal/i18n"
+	"task-manager/internal/mail"
+	"task-manager/internal/repo"
+)
+
+type PasswordResetService interface {
+	// RequestReset ส่งลิงก์รีเซ็ตไปที่อีเมล ถ้าไม่มีบัญชีนี้จะคืน nil เฉยๆ (ไม่เปิดเผยว่ามีอีเมลหรือไม่)
+	RequestReset(ctx context.Context, email string) error
+
+	// Reset ตั้งรหัสผ่านใหม่ด้วย token จากอีเมล แล้วออกจากระบบทุกอุปกรณ์
+	Reset(ctx context.Context, token, newPassword string) error
+}
+
+type passwordResetService struct {
+	userRepo  repo.UserRepo
+	tokenRepo repo.UserTokenRepo
+	sessions  SessionService
+	hasher    auth.PasswordHasher
+	policy    auth.PasswordPolicy
+	mailer    mail.Mailer
+	resetURL  string
+	ttl       time.Duration
+}
+
+// resetURL คือหน้าเว็บที่รับ ?token=... แล้วเรียก POST /api/auth/reset-password
+func NewPasswordResetService(
+	userRepo repo.UserRepo,
+	tokenRepo repo.UserTokenRepo,
+	sessions SessionService,
+	hasher auth.PasswordHasher,
+	policy auth.PasswordPolicy,
+	mailer mail.Mailer,
+	resetURL string,
+	ttl time.Duration,
+) PasswordResetService {
+	return &passwordResetService{
+		userRepo:  userRepo,
+		tokenRepo: tokenRepo,
+		sessions:  sessions,
+		hasher:    hasher,
+		policy:    policy,
+		mailer:    mailer,
+		resetURL:  resetURL,
+		ttl:       ttl,
+	}
+}
+
+func (s *passwordResetService) RequestReset(ctx context.Context, email string) error {
+	email = strings.TrimSpace(strings.ToLower(email))
+	if email == "" {
+		return nil
+	}
+
+	user, err := s.userRepo.GetByEmail(ctx, email)
+	if err != nil {
+		if errors.Is(err, repo.ErrNotFound) {
+			return nil
+		}
+		return err
+	}
+
+	// ลิงก์เก่าที่ยังไม่ได้ใช้ใช้ไม่ได้อีกต่อไป
+	if err := s.tokenRepo.InvalidateForUser(ctx, user.ID, domain.TokenPurposePasswordReset); err != nil {
+		return err
+	}
+
+	raw, hash, err := auth.NewOpaqueToken()
+	if err != nil {
+		return err
+	}
+	if err := s.tokenRepo.Create(ctx, &domain.UserToken{
+		UserID:    user.ID,
+		Purpose:   domain.TokenPurposePasswordReset,
+		TokenHash: hash,
+		ExpiresAt: time.Now().Add(s.ttl),
+	}); err != nil {
+		return err
+	}
+
+	link := s.resetURL + "?token=" + url.QueryEscape(raw)
+	// ภาษาที่ผู้ใช้ตั้งไว้ ไม่งั้นใช้ภาษาของ request ที่ขอ reset
+	loc := i18n.Pick(user.Locale.String, i18n.FromContext(ctx))
+	return s.mailer.Send(ctx, mail.Message{
+		To:      user.Email,
+		Subject: i18n.T(loc, "email.password_reset.subject"),
+		Text:    i18n.T(loc, "email.password_reset.body", int(s.ttl.Minutes()), link),
+	})
+}
+
+func (s *passwordResetService) Reset(ctx context.Context, token, newPassword string) error {
+	if token == "" || newPassword == "" {
+		return domain.ErrInvalidInput
+	}
+	// ตรวจก่อนใช้ token ไม่งั้นรหัสไม่ผ่าน policy แล้ว token ถูกใช้ไปแล้ว
+	if err := s.policy.Validate(newPassword); err != nil {
+		return err
+	}
+
+	t, err := s.tokenRepo.Consume(ctx, domain.TokenPurposePasswordReset, auth.HashToken(token))
+	if err != nil {
+		if errors.Is(err, repo.ErrNotFound) {
+			return domain.ErrInvalidToken
+		}
+		return err
+	}
+	if time.Now().After(t.ExpiresAt) {
+		return domain.ErrInvalidToken
+	}
+
+	hashed, err := s.hasher.Hash(newPassword)
+	if err != nil {
+		return err
+	}
+	if err := s.userRepo.UpdatePassword(ctx, t.UserID, hashed); err != nil {
+		return err
+	}
+	if err := s.tokenRepo.InvalidateForUser(ctx, t.UserID, domain.TokenPurposePasswordReset); err != nil {
+		return err
+	}
+	// ใครที่ล็อกอินด้วยรหัสเดิมอยู่ต้องออกจากระบบ
+	return s.sessions.RevokeAll(ctx, t.UserID, "")
+}
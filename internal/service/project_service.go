@@ -0,0 +1,199 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"unicode/utf8"
+
+	"task-manager/internal/domain"
+	"task-manager/internal/repo"
+)
+
+const maxProjectName = 100
+
+// ProjectUpdate: field ที่เป็น nil = ไม่เปลี่ยน
+type ProjectUpdate struct {
+	Name     *string
+	Color    *string
+	Archived *bool
+}
+
+type ProjectService interface {
+	// List คืนโปรเจกต์ใน workspace ที่ผู้ใช้เข้าถึงได้ (workspaceID = 0 คือ workspace ส่วนตัว)
+	List(ctx context.Context, userID, workspaceID int64, includeArchived bool) ([]*domain.Project, error)
+	Get(ctx context.Context, userID, id int64) (*domain.Project, error)
+
+	// สมาชิกทุกคนของ workspace สร้างโปรเจกต์ได้ ผู้สร้างเป็น admin ของโปรเจกต์ (color "" = สี default)
+	Create(ctx context.Context, userID, workspaceID int64, name, color string) (*domain.Project, error)
+
+	// ต้องเป็น admin ของโปรเจกต์ (หรือของ workspace)
+	Update(ctx context.Context, userID, id int64, in ProjectUpdate) (*domain.Project, error)
+	Delete(ctx context.Context, userID, id int64) error
+
+	Members(ctx context.Context, userID, id int64) ([]domain.ProjectMember, error)
+	SetMember(ctx context.Context, actorID, id, targetID int64, role string) error
+
+	// admin เอาคนอื่นออกได้ สมาชิกทุกคนออกเองได้ (actorID == targetID)
+	RemoveMember(ctx context.Context, actorID, id, targetID int64) error
+}
+
+type projectService struct {
+	projectRepo   repo.ProjectRepo
+	workspaceRepo repo.WorkspaceRepo
+	statusRepo    repo.StatusRepo
+}
+
+func NewProjectService(projectRepo repo.ProjectRepo, workspaceRepo repo.WorkspaceRepo, statusRepo repo.StatusRepo) ProjectService {
+	return &projectService{projectRepo: projectRepo, workspaceRepo: workspaceRepo, statusRepo: statusRepo}
+}
+
+// workspaceFor: 0 = workspace ส่วนตัว ไม่ใช่สมาชิก = ErrWorkspaceNotFound
+func (s *projectService) workspaceFor(ctx context.Context, userID, workspaceID int64) (*domain.Workspace, error) {
+	if workspaceID == 0 {
+		return s.workspaceRepo.EnsurePersonal(ctx, userID)
+	}
+	w, err := s.workspaceRepo.GetForMember(ctx, workspaceID, userID)
+	if errors.Is(err, repo.ErrNotFound) {
+		return nil, domain.ErrWorkspaceNotFound
+	}
+	return w, err
+}
+
+func (s *projectService) List(ctx context.Context, userID, workspaceID int64, includeArchived bool) ([]*domain.Project, error) {
+	w, err := s.workspaceFor(ctx, userID, workspaceID)
+	if err != nil {
+		return nil, err
+	}
+	return s.projectRepo.ListByWorkspace(ctx, w.ID, userID, includeArchived)
+}
+
+func (s *projectService) Get(ctx context.Context, userID, id int64) (*domain.Project, error) {
+	p, err := s.projectRepo.GetForMember(ctx, id, userID)
+	if errors.Is(err, repo.ErrNotFound) {
+		return nil, domain.ErrProjectNotFound
+	}
+	return p, err
+}
+
+// admin คืนโปรเจกต์ถ้า userID เป็น admin (สมาชิกธรรมดา = ErrForbidden)
+func (s *projectService) admin(ctx context.Context, userID, id int64) (*domain.Project, error) {
+	p, err := s.Get(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	if p.Role != domain.WorkspaceRoleAdmin {
+		return nil, domain.ErrForbidden
+	}
+	return p, nil
+}
+
+func projectName(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || utf8.RuneCountInString(name) > maxProjectName {
+		return "", domain.ErrInvalidInput
+	}
+	return name, nil
+}
+
+func projectColor(color string) (string, error) {
+	color = strings.ToLower(strings.TrimSpace(color))
+	if !domain.ValidProjectColor(color) {
+		return "", domain.ErrInvalidInput
+	}
+	return color, nil
+}
+
+func (s *projectService) Create(ctx context.Context, userID, workspaceID int64, name, color string) (*domain.Project, error) {
+	name, err := projectName(name)
+	if err != nil {
+		return nil, err
+	}
+	if color == "" {
+		color = domain.DefaultProjectColor
+	}
+	if color, err = projectColor(color); err != nil {
+		return nil, err
+	}
+	w, err := s.workspaceFor(ctx, userID, workspaceID)
+	if err != nil {
+		return nil, err
+	}
+	return s.projectRepo.Create(ctx, &domain.Project{WorkspaceID: w.ID, Name: name, Color: color}, userID)
+}
+
+func (s *projectService) Update(ctx context.Context, userID, id int64, in ProjectUpdate) (*domain.Project, error) {
+	p, err := s.admin(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	if in.Name != nil {
+		if p.Name, err = projectName(*in.Name); err != nil {
+			return nil, err
+		}
+	}
+	if in.Color != nil {
+		if p.Color, err = projectColor(*in.Color); err != nil {
+			return nil, err
+		}
+	}
+	if in.Archived != nil {
+		p.Archived = *in.Archived
+	}
+
+	if err := s.projectRepo.Update(ctx, p); err != nil {
+		if errors.Is(err, repo.ErrNotFound) {
+			return nil, domain.ErrProjectNotFound
+		}
+		return nil, err
+	}
+	return s.Get(ctx, userID, id)
+}
+
+func (s *projectService) Delete(ctx context.Context, userID, id int64) error {
+	if _, err := s.admin(ctx, userID, id); err != nil {
+		return err
+	}
+
+	// งานกลับไปใช้ชุด default: ย้ายสถานะที่ตั้งเองไปสถานะแรกที่ category เดียวกัน
+	set, err := s.statusRepo.ListByProject(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := s.projectRepo.Delete(ctx, id, statusRemap(set, domain.DefaultStatuses())); err != nil {
+		if errors.Is(err, repo.ErrNotFound) {
+			return domain.ErrProjectNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+func (s *projectService) Members(ctx context.Context, userID, id int64) ([]domain.ProjectMember, error) {
+	if _, err := s.Get(ctx, userID, id); err != nil {
+		return nil, err
+	}
+	return s.projectRepo.ListMembers(ctx, id)
+}
+
+// SetMember เพิ่มสมาชิกหรือเปลี่ยน role (ต้องเป็นสมาชิกของ workspace อยู่แล้ว ไม่งั้น ErrMemberNotFound)
+func (s *projectService) SetMember(ctx context.Context, actorID, id, targetID int64, role string) error {
+	if !domain.ValidWorkspaceRole(role) {
+		return domain.ErrInvalidRole
+	}
+	if _, err := s.admin(ctx, actorID, id); err != nil {
+		return err
+	}
+	return memberErr(s.projectRepo.SetMember(ctx, id, targetID, role))
+}
+
+func (s *projectService) RemoveMember(ctx context.Context, actorID, id, targetID int64) error {
+	p, err := s.Get(ctx, actorID, id)
+	if err != nil {
+		return err
+	}
+	if actorID != targetID && p.Role != domain.WorkspaceRoleAdmin {
+		return domain.ErrForbidden
+	}
+	return memberErr(s.projectRepo.RemoveMember(ctx, id, targetID))
+}
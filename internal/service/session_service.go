@@ -0,0 +1,92 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"task-manager/internal/domain"
+	"task-manager/internal/repo"
+)
+
+// อัปเดต last_seen_at ไม่บ่อยกว่านี้ เพื่อไม่ให้ทุก request เขียน DB
+const sessionTouchInterval = time.Minute
+
+type SessionService interface {
+	List(ctx context.Context, userID int64) ([]*domain.Session, error)
+	Revoke(ctx context.Context, userID int64, sessionID string) error
+
+	// เพิกถอนทุก session ยกเว้น exceptID (ว่าง = ทั้งหมด)
+	RevokeAll(ctx context.Context, userID int64, exceptID string) error
+
+	// CheckSession ใช้ใน JWTMiddleware: session ต้องเป็นของ userID และยังไม่ถูกเพิกถอน
+	CheckSession(ctx context.Context, sessionID string, userID int64) error
+}
+
+type sessionService struct {
+	sessionRepo repo.SessionRepo
+	refreshRepo repo.RefreshTokenRepo
+}
+
+func NewSessionService(sessionRepo repo.SessionRepo, refreshRepo repo.RefreshTokenRepo) SessionService {
+	return &sessionService{sessionRepo: sessionRepo, refreshRepo: refreshRepo}
+}
+
+func (s *sessionService) List(ctx context.Context, userID int64) ([]*domain.Session, error) {
+	all, err := s.sessionRepo.ListByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	out := make([]*domain.Session, 0, len(all))
+	for _, sess := range all {
+		if sess.Active(now) {
+			out = append(out, sess)
+		}
+	}
+	return out, nil
+}
+
+func (s *sessionService) Revoke(ctx context.Context, userID int64, sessionID string) error {
+	if err := s.sessionRepo.Revoke(ctx, userID, sessionID); err != nil {
+		if errors.Is(err, repo.ErrNotFound) {
+			return domain.ErrSessionNotFound
+		}
+		return err
+	}
+	return s.refreshRepo.RevokeFamily(ctx, sessionID)
+}
+
+func (s *sessionService) RevokeAll(ctx context.Context, userID int64, exceptID string) error {
+	ids, err := s.sessionRepo.RevokeAll(ctx, userID, exceptID)
+	if err != nil {
+		return err
+	}
+	for _, id := range ids {
+		if err := s.refreshRepo.RevokeFamily(ctx, id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *sessionService) CheckSession(ctx context.Context, sessionID string, userID int64) error {
+	if sessionID == "" {
+		return domain.ErrSessionRevoked
+	}
+	sess, err := s.sessionRepo.GetByID(ctx, sessionID)
+	if err != nil {
+		if errors.Is(err, repo.ErrNotFound) {
+			return domain.ErrSessionRevoked
+		}
+		return err
+	}
+	now := time.Now()
+	if sess.UserID != userID || !sess.Active(now) {
+		return domain.ErrSessionRevoked
+	}
+	if now.Sub(sess.LastSeenAt) > sessionTouchInterval {
+		_ = s.sessionRepo.Touch(ctx, sess.ID, now, time.Time{})
+	}
+	return nil
+}
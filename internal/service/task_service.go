@@ -2,38 +2,964 @@ package service
 
 import (
 	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
 
 	"task-manager/internal/domain"
+	"task-manager/internal/i18n"
+	"task-manager/internal/mail"
 	"task-manager/internal/repo"
 )
 
+const (
+	defaultTaskLimit  = 200
+	maxTaskLimit      = 500
+	maxChecklistTitle = 200
+)
+
+// MyWork is the My Work page: tasks assigned to the user grouped by due date
+type MyWork struct {
+	Timezone string
+	Today    time.Time // วันที่ปัจจุบันของผู้ใช้ (เที่ยงคืน UTC)
+	Groups   map[domain.WorkGroup][]*domain.Task
+}
+
+// Actor คือผู้เรียก: สมาชิกทุกคนอ่านงานใน workspace ได้ แต่แก้/ลบงานของคนอื่นได้เฉพาะ admin ของ
+// workspace หรือ role ที่มีสิทธิ์ task:*:any (ซึ่งใช้ได้เฉพาะใน workspace ที่ตัวเองเป็นสมาชิก)
+type Actor struct {
+	UserID   int64
+	AnyOwner bool
+}
+
 type TaskService interface {
-	GetUserTasks(ctx context.Context, userID int, limit int) ([]*domain.Task, error)
+	// ListTasks คืนงานหนึ่งหน้าของ workspace กับจำนวนทั้งหมด (workspaceID = 0 คือ workspace ส่วนตัว,
+	// limit ปรับด้วย ClampTaskLimit) subtree = หน้าละงานระดับบน พร้อมงานย่อยทั้งต้นไม้ใน Children
+	ListTasks(ctx context.Context, userID, workspaceID int64, subtree bool, limit, offset int) ([]*domain.Task, int, error)
+	ListProjectTasks(ctx context.Context, userID, projectID int64, subtree bool, limit, offset int) ([]*domain.Task, int, error)
+	GetTask(ctx context.Context, id, userID int64) (*domain.Task, error)
+
+	// task.WorkspaceID = 0 คือ workspace ส่วนตัวของ task.OwnerID หรือ workspace ของ task.ProjectID
+	// มี task.ParentID = งานย่อย: ใช้ workspace และโปรเจกต์ของงานแม่
 	CreateTask(ctx context.Context, task *domain.Task) (*domain.Task, error)
-	UpdateTask(ctx context.Context, task *domain.Task) error
-	DeleteTask(ctx context.Context, id int, userID int) error
+
+	// ผู้รับผิดชอบแก้งานได้ด้วย (ลบไม่ได้)
+	// เปลี่ยน task.ProjectID = ย้ายงาน: ต้องแก้งานได้ และเข้าถึงโปรเจกต์ปลายทางใน workspace เดียวกันได้
+	// งานย่อยทั้งต้นไม้ย้ายตาม งานที่มีงานแม่ย้ายโปรเจกต์เองไม่ได้ (ErrInvalidParent)
+	UpdateTask(ctx context.Context, actor Actor, task *domain.Task) error
+
+	// mode = cascade ต้องแก้งานย่อยทุกงานได้ด้วย
+	DeleteTask(ctx context.Context, actor Actor, id int64, mode domain.ChildDeleteMode) error
+
+	// ชุดสถานะของโปรเจกต์ที่ userID เห็น (projectID = 0 หรือยังไม่ได้ตั้งค่า = ชุด default)
+	Statuses(ctx context.Context, userID, projectID int64) (domain.StatusSet, error)
+
+	// แทนที่ชุดสถานะทั้งชุด (admin ของโปรเจกต์เท่านั้น) งานที่ใช้สถานะที่ถูกเอาออก
+	// ย้ายไปสถานะแรกที่ category เดียวกัน
+	SetProjectStatuses(ctx context.Context, userID, projectID int64, set domain.StatusSet) error
+
+	// ผู้รับผิดชอบ / ผู้ติดตาม: ทุกคนที่เห็นงานอ่านรายชื่อได้
+	Assignees(ctx context.Context, userID, taskID int64) ([]domain.TaskPerson, error)
+	Watchers(ctx context.Context, userID, taskID int64) ([]domain.TaskPerson, error)
+
+	// มอบหมายงานต้องแก้งานได้ (ผู้รับผิดชอบเอาตัวเองออกได้) ผู้รับต้องเห็นงานได้ ไม่งั้น ErrMemberNotFound
+	Assign(ctx context.Context, actor Actor, taskID, targetID int64) error
+	Unassign(ctx context.Context, actor Actor, taskID, targetID int64) error
+
+	// ติดตาม/เลิกติดตามเอง = ทุกคนที่เห็นงาน ทำให้คนอื่น = ต้องแก้งานได้
+	Watch(ctx context.Context, actor Actor, taskID, targetID int64) error
+	Unwatch(ctx context.Context, actor Actor, taskID, targetID int64) error
+
+	// checklist: ทุกคนที่เห็นงานอ่านได้ แก้ได้เหมือนแก้งาน (รวมผู้รับผิดชอบ)
+	Checklist(ctx context.Context, userID, taskID int64) ([]domain.ChecklistItem, error)
+	AddChecklistItem(ctx context.Context, actor Actor, taskID int64, title string) (*domain.ChecklistItem, error)
+	UpdateChecklistItem(ctx context.Context, actor Actor, taskID, itemID int64, in ChecklistUpdate) (*domain.ChecklistItem, error)
+	DeleteChecklistItem(ctx context.Context, actor Actor, taskID, itemID int64) error
+
+	// MyWork จัดกลุ่มงานที่มอบหมายให้ userID ตาม time zone ใน preferences
+	// (ยังไม่ได้ตั้ง = fallbackTZ เช่น time zone ของ browser, ว่างทั้งคู่ = UTC)
+	MyWork(ctx context.Context, userID int64, fallbackTZ string) (*MyWork, error)
+
+	// แจ้งผู้ติดตาม (ยกเว้น actorID และคนที่ปิดการแจ้งเตือนทางอีเมล) ว่างานเปลี่ยนจาก before เป็น after
+	NotifyWatchers(ctx context.Context, actorID int64, before, after *domain.Task) error
+	// แจ้งผู้ที่เพิ่งได้รับมอบหมายงาน
+	NotifyAssigned(ctx context.Context, actorID, taskID, assigneeID int64) error
 }
 
 type taskService struct {
-	taskRepo repo.TaskRepo
+	taskRepo      repo.TaskRepo
+	statusRepo    repo.StatusRepo
+	workspaceRepo repo.WorkspaceRepo
+	projectRepo   repo.ProjectRepo
+	peopleRepo    repo.TaskPeopleRepo
+	checklistRepo repo.ChecklistRepo
+	users         UserService
+	mailer        mail.Mailer
+	workURL       string
+}
+
+// workURL คือหน้า My Work ของ frontend (ลิงก์ในอีเมลแจ้งเตือน)
+func NewTaskService(
+	taskRepo repo.TaskRepo,
+	statusRepo repo.StatusRepo,
+	workspaceRepo repo.WorkspaceRepo,
+	projectRepo repo.ProjectRepo,
+	peopleRepo repo.TaskPeopleRepo,
+	checklistRepo repo.ChecklistRepo,
+	users UserService,
+	mailer mail.Mailer,
+	workURL string,
+) TaskService {
+	return &taskService{
+		taskRepo:      taskRepo,
+		statusRepo:    statusRepo,
+		workspaceRepo: workspaceRepo,
+		projectRepo:   projectRepo,
+		peopleRepo:    peopleRepo,
+		checklistRepo: checklistRepo,
+		users:         users,
+		mailer:        mailer,
+		workURL:       workURL,
+	}
+}
+
+// ClampTaskLimit คืน page size ที่ ListTasks ใช้จริง (0 = ค่า default)
+func ClampTaskLimit(limit int) int {
+	if limit <= 0 {
+		return defaultTaskLimit
+	}
+	if limit > maxTaskLimit {
+		return maxTaskLimit
+	}
+	return limit
+}
+
+// workspaceFor คืน workspace ที่ userID เป็นสมาชิก (0 = ส่วนตัว) ไม่ใช่สมาชิก = ErrWorkspaceNotFound
+func (s *taskService) workspaceFor(ctx context.Context, userID, workspaceID int64) (*domain.Workspace, error) {
+	if workspaceID == 0 {
+		return s.workspaceRepo.EnsurePersonal(ctx, userID)
+	}
+	w, err := s.workspaceRepo.GetForMember(ctx, workspaceID, userID)
+	if errors.Is(err, repo.ErrNotFound) {
+		return nil, domain.ErrWorkspaceNotFound
+	}
+	return w, err
+}
+
+// projectFor คืนโปรเจกต์ที่ userID เข้าถึงได้ ไม่มีสิทธิ์ = ErrProjectNotFound
+func (s *taskService) projectFor(ctx context.Context, userID, projectID int64) (*domain.Project, error) {
+	p, err := s.projectRepo.GetForMember(ctx, projectID, userID)
+	if errors.Is(err, repo.ErrNotFound) {
+		return nil, domain.ErrProjectNotFound
+	}
+	return p, err
+}
+
+// intoProject ตรวจว่าเพิ่ม/ย้ายงานเข้าโปรเจกต์ได้: อยู่ใน workspace เดียวกันและยังไม่เก็บถาวร
+func (s *taskService) intoProject(ctx context.Context, userID, workspaceID, projectID int64) (*domain.Project, error) {
+	p, err := s.projectFor(ctx, userID, projectID)
+	if err != nil {
+		return nil, err
+	}
+	if p.WorkspaceID != workspaceID {
+		return nil, domain.ErrProjectNotFound
+	}
+	if p.Archived {
+		return nil, domain.ErrProjectArchived
+	}
+	return p, nil
+}
+
+func (s *taskService) ListTasks(ctx context.Context, userID, workspaceID int64, subtree bool, limit, offset int) ([]*domain.Task, int, error) {
+	w, err := s.workspaceFor(ctx, userID, workspaceID)
+	if err != nil {
+		return nil, 0, err
+	}
+	limit = ClampTaskLimit(limit)
+	if offset < 0 {
+		offset = 0
+	}
+	tasks, err := s.taskRepo.ListByWorkspace(ctx, w.ID, userID, subtree, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	total, err := s.taskRepo.CountByWorkspace(ctx, w.ID, userID, subtree)
+	if err != nil {
+		return nil, 0, err
+	}
+	if err := s.listed(ctx, tasks, subtree); err != nil {
+		return nil, 0, err
+	}
+	return tasks, total, nil
+}
+
+func (s *taskService) ListProjectTasks(ctx context.Context, userID, projectID int64, subtree bool, limit, offset int) ([]*domain.Task, int, error) {
+	p, err := s.projectFor(ctx, userID, projectID)
+	if err != nil {
+		return nil, 0, err
+	}
+	limit = ClampTaskLimit(limit)
+	if offset < 0 {
+		offset = 0
+	}
+	tasks, err := s.taskRepo.ListByProject(ctx, p.ID, subtree, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	total, err := s.taskRepo.CountByProject(ctx, p.ID, subtree)
+	if err != nil {
+		return nil, 0, err
+	}
+	if err := s.listed(ctx, tasks, subtree); err != nil {
+		return nil, 0, err
+	}
+	return tasks, total, nil
 }
 
-func NewTaskService(taskRepo repo.TaskRepo) TaskService {
-	return &taskService{taskRepo: taskRepo}
+// listed ใส่ข้อมูลให้หน้า list (subtree = แนบงานย่อยทั้งต้นไม้ไว้ใน Children ของงานแม่)
+func (s *taskService) listed(ctx context.Context, tasks []*domain.Task, subtree bool) error {
+	if !subtree {
+		return s.decorate(ctx, tasks)
+	}
+	ids := make([]int64, len(tasks))
+	for i, t := range tasks {
+		ids[i] = t.ID
+	}
+	children, err := s.taskRepo.ListDescendants(ctx, ids)
+	if err != nil {
+		return err
+	}
+	all := append(append([]*domain.Task{}, tasks...), children...)
+	if err := s.decorate(ctx, all); err != nil {
+		return err
+	}
+
+	byID := make(map[int64]*domain.Task, len(all))
+	for _, t := range all {
+		t.Children = []*domain.Task{}
+		byID[t.ID] = t
+	}
+	// byID มีครบทุกงานก่อนแนบ จึงไม่ขึ้นกับลำดับ (งานแม่อาจใหม่กว่างานย่อยถ้าเคยย้ายงานแม่)
+	for _, c := range children {
+		if parent, ok := byID[c.ParentID.Int64]; ok {
+			parent.Children = append(parent.Children, c)
+		}
+	}
+	return nil
 }
 
-func (s *taskService) GetUserTasks(ctx context.Context, userID int, limit int) ([]*domain.Task, error) {
-	return s.taskRepo.GetByUserID(ctx, userID, limit)
+func (s *taskService) GetTask(ctx context.Context, id, userID int64) (*domain.Task, error) {
+	t, err := s.taskRepo.GetForMember(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.decorate(ctx, []*domain.Task{t}); err != nil {
+		return nil, err
+	}
+	return t, nil
 }
 
 func (s *taskService) CreateTask(ctx context.Context, task *domain.Task) (*domain.Task, error) {
-	return s.taskRepo.Create(ctx, task)
+	if task.ParentID.Valid {
+		parent, err := s.parentFor(ctx, task.OwnerID, task.ParentID.Int64, 1)
+		if err != nil {
+			return nil, err
+		}
+		if task.WorkspaceID != 0 && task.WorkspaceID != parent.WorkspaceID {
+			return nil, domain.ErrInvalidParent
+		}
+		if task.ProjectID.Valid && task.ProjectID != parent.ProjectID {
+			return nil, domain.ErrInvalidParent
+		}
+		task.WorkspaceID, task.ProjectID = parent.WorkspaceID, parent.ProjectID
+	}
+	if task.ProjectID.Valid && task.WorkspaceID == 0 {
+		// ระบุแค่โปรเจกต์ = ใช้ workspace ของโปรเจกต์
+		p, err := s.projectFor(ctx, task.OwnerID, task.ProjectID.Int64)
+		if err != nil {
+			return nil, err
+		}
+		task.WorkspaceID = p.WorkspaceID
+	}
+	w, err := s.workspaceFor(ctx, task.OwnerID, task.WorkspaceID)
+	if err != nil {
+		return nil, err
+	}
+	task.WorkspaceID = w.ID
+	if task.ProjectID.Valid {
+		if _, err := s.intoProject(ctx, task.OwnerID, w.ID, task.ProjectID.Int64); err != nil {
+			return nil, err
+		}
+	}
+
+	set, err := s.statuses(ctx, task.ProjectID.Int64)
+	if err != nil {
+		return nil, err
+	}
+	if task.Status == "" {
+		task.Status = set.Initial()
+	}
+	if task.Priority == "" {
+		task.Priority = domain.PriorityMedium
+	}
+	if err := validateTask(set, task); err != nil {
+		return nil, err
+	}
+
+	created, err := s.taskRepo.Create(ctx, task)
+	if err != nil {
+		return nil, err
+	}
+	// ผู้สร้างติดตามงานของตัวเองอัตโนมัติ
+	if err := s.peopleRepo.Watch(ctx, created.ID, created.OwnerID); err != nil {
+		return nil, err
+	}
+	categorize(set, created)
+	created.AssigneeIDs = []int64{}
+	return created, nil
+}
+
+// parentFor ตรวจว่าวางงานที่สูง height ชั้น (ตัวมันเองกับงานย่อย) ไว้ใต้ parentID ได้
+// งานแม่ต้องเป็นงานที่ userID เห็น และความลึกรวมไม่เกิน domain.MaxTaskDepth
+func (s *taskService) parentFor(ctx context.Context, userID, parentID int64, height int) (*domain.Task, error) {
+	parent, err := s.taskRepo.GetForMember(ctx, parentID, userID)
+	if err != nil {
+		if errors.Is(err, domain.ErrTaskNotFound) {
+			return nil, domain.ErrInvalidParent
+		}
+		return nil, err
+	}
+	ancestors, err := s.taskRepo.Ancestors(ctx, parent.ID)
+	if err != nil {
+		return nil, err
+	}
+	if len(ancestors)+1+height > domain.MaxTaskDepth {
+		return nil, domain.ErrTaskTooDeep
+	}
+	return parent, nil
+}
+
+// subtreeHeight: จำนวนชั้นของงาน id รวมงานย่อยทุกชั้น (ไม่มีงานย่อย = 1) และ id ของงานย่อยทั้งหมด
+func (s *taskService) subtreeHeight(ctx context.Context, id int64) (int, map[int64]bool, error) {
+	children, err := s.taskRepo.ListDescendants(ctx, []int64{id})
+	if err != nil {
+		return 0, nil, err
+	}
+	// ไล่ทีละชั้นตาม parent_id (ลำดับจาก repo เป็นตาม created_at ซึ่งงานแม่อาจใหม่กว่างานย่อย)
+	byParent := map[int64][]int64{}
+	for _, c := range children {
+		byParent[c.ParentID.Int64] = append(byParent[c.ParentID.Int64], c.ID)
+	}
+	height := 0
+	inside := map[int64]bool{}
+	for level := []int64{id}; len(level) > 0; height++ {
+		var next []int64
+		for _, t := range level {
+			for _, c := range byParent[t] {
+				if !inside[c] {
+					inside[c] = true
+					next = append(next, c)
+				}
+			}
+		}
+		level = next
+	}
+	return height, inside, nil
+}
+
+// canModify: เจ้าของงาน, admin ของ workspace หรือของโปรเจกต์ที่งานอยู่ หรือ role ที่มีสิทธิ์ :any
+func (s *taskService) canModify(ctx context.Context, actor Actor, t *domain.Task) error {
+	w, err := s.workspaceFor(ctx, actor.UserID, t.WorkspaceID)
+	if err != nil {
+		if errors.Is(err, domain.ErrWorkspaceNotFound) {
+			return domain.ErrTaskNotFound
+		}
+		return err
+	}
+	if t.OwnerID == actor.UserID || actor.AnyOwner || w.Role == domain.WorkspaceRoleAdmin {
+		return nil
+	}
+	if t.ProjectID.Valid {
+		p, err := s.projectFor(ctx, actor.UserID, t.ProjectID.Int64)
+		if err != nil && !errors.Is(err, domain.ErrProjectNotFound) {
+			return err
+		}
+		if p != nil && p.Role == domain.WorkspaceRoleAdmin {
+			return nil
+		}
+	}
+	return domain.ErrForbidden
+}
+
+func (s *taskService) UpdateTask(ctx context.Context, actor Actor, task *domain.Task) error {
+	// ตรวจสิทธิ์กับงานที่เก็บไว้ (task มีโปรเจกต์ปลายทางแล้วถ้าเป็นการย้าย)
+	cur, err := s.taskRepo.GetForMember(ctx, task.ID, actor.UserID)
+	if err != nil {
+		return err
+	}
+	if err := s.canUpdate(ctx, actor, cur); err != nil {
+		return err
+	}
+	task.WorkspaceID, task.OwnerID = cur.WorkspaceID, cur.OwnerID
+
+	// งานย่อยอยู่โปรเจกต์เดียวกับงานแม่: ย้ายใต้งานแม่ใหม่ = ย้ายโปรเจกต์ตาม, มีงานแม่อยู่ = ย้ายโปรเจกต์เองไม่ได้
+	if task.ParentID != cur.ParentID && task.ParentID.Valid {
+		height, inside, err := s.subtreeHeight(ctx, cur.ID)
+		if err != nil {
+			return err
+		}
+		if task.ParentID.Int64 == cur.ID || inside[task.ParentID.Int64] {
+			return domain.ErrInvalidParent
+		}
+		parent, err := s.parentFor(ctx, actor.UserID, task.ParentID.Int64, height)
+		if err != nil {
+			return err
+		}
+		if parent.WorkspaceID != cur.WorkspaceID {
+			return domain.ErrInvalidParent
+		}
+		if task.ProjectID != cur.ProjectID && task.ProjectID != parent.ProjectID {
+			return domain.ErrInvalidParent
+		}
+		task.ProjectID = parent.ProjectID
+	} else if task.ParentID.Valid && task.ProjectID != cur.ProjectID {
+		return domain.ErrInvalidParent
+	}
+
+	set, err := s.statuses(ctx, task.ProjectID.Int64)
+	if err != nil {
+		return err
+	}
+
+	var remap map[string]string
+	if task.ProjectID != cur.ProjectID {
+		// งานย่อยทั้งต้นไม้ย้ายตาม: ต้องแก้ได้ทุกงาน
+		children, err := s.taskRepo.ListDescendants(ctx, []int64{cur.ID})
+		if err != nil {
+			return err
+		}
+		for _, c := range children {
+			if err := s.canUpdate(ctx, actor, c); err != nil {
+				return err
+			}
+		}
+		if task.ProjectID.Valid {
+			if _, err := s.intoProject(ctx, actor.UserID, cur.WorkspaceID, task.ProjectID.Int64); err != nil {
+				return err
+			}
+		}
+		// สถานะเดิมไม่มีในชุดของปลายทาง (และไม่ได้ส่งสถานะใหม่มา) = ใช้สถานะแรกที่ category เดียวกัน
+		if _, ok := set.Lookup(task.Status); !ok && task.Status == cur.Status {
+			if err := s.remapStatus(ctx, set, cur, task); err != nil {
+				return err
+			}
+		}
+		from, err := s.statuses(ctx, cur.ProjectID.Int64)
+		if err != nil {
+			return err
+		}
+		remap = statusRemap(from, set)
+	}
+
+	if err := validateTask(set, task); err != nil {
+		return err
+	}
+	return s.taskRepo.Update(ctx, task, remap)
+}
+
+// canUpdate: แก้งานได้ (canModify) หรือเป็นผู้รับผิดชอบ (แต่ลบ / มอบหมายต่อไม่ได้)
+func (s *taskService) canUpdate(ctx context.Context, actor Actor, t *domain.Task) error {
+	err := s.canModify(ctx, actor, t)
+	if !errors.Is(err, domain.ErrForbidden) {
+		return err
+	}
+	ok, err := s.isAssignee(ctx, t.ID, actor.UserID)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return domain.ErrForbidden
+	}
+	return nil
+}
+
+// statusRemap: สถานะใน from ที่ไม่มีใน to -> สถานะแรกใน to ที่ category เดียวกัน (ไม่มี = สถานะเริ่มต้น)
+func statusRemap(from, to domain.StatusSet) map[string]string {
+	remap := map[string]string{}
+	for _, st := range from {
+		if _, ok := to.Lookup(st.Status); ok {
+			continue
+		}
+		status, ok := to.ForCategory(st.Category)
+		if !ok {
+			status = to.Initial()
+		}
+		remap[st.Status] = status
+	}
+	return remap
+}
+
+func (s *taskService) remapStatus(ctx context.Context, set domain.StatusSet, cur, task *domain.Task) error {
+	from, err := s.statuses(ctx, cur.ProjectID.Int64)
+	if err != nil {
+		return err
+	}
+	task.Status = set.Initial()
+	if st, ok := from.Lookup(cur.Status); ok {
+		if status, ok := set.ForCategory(st.Category); ok {
+			task.Status = status
+		}
+	}
+	return nil
+}
+
+func (s *taskService) DeleteTask(ctx context.Context, actor Actor, id int64, mode domain.ChildDeleteMode) error {
+	if !mode.Valid() {
+		return domain.ErrInvalidInput
+	}
+	t, err := s.taskRepo.GetForMember(ctx, id, actor.UserID)
+	if err != nil {
+		return err
+	}
+	if err := s.canModify(ctx, actor, t); err != nil {
+		return err
+	}
+	if mode == domain.ChildrenCascade {
+		children, err := s.taskRepo.ListDescendants(ctx, []int64{id})
+		if err != nil {
+			return err
+		}
+		for _, c := range children {
+			if err := s.canModify(ctx, actor, c); err != nil {
+				return err
+			}
+		}
+	}
+	return s.taskRepo.Delete(ctx, t.WorkspaceID, id, mode)
+}
+
+func (s *taskService) Statuses(ctx context.Context, userID, projectID int64) (domain.StatusSet, error) {
+	if projectID != 0 {
+		if _, err := s.projectFor(ctx, userID, projectID); err != nil {
+			return nil, err
+		}
+	}
+	return s.statuses(ctx, projectID)
 }
 
-func (s *taskService) UpdateTask(ctx context.Context, task *domain.Task) error {
-	return s.taskRepo.Update(ctx, task)
+// statuses ไม่ตรวจสิทธิ์ (ใช้กับงาน/โปรเจกต์ที่ตรวจแล้ว)
+func (s *taskService) statuses(ctx context.Context, projectID int64) (domain.StatusSet, error) {
+	if projectID == 0 {
+		return domain.DefaultStatuses(), nil
+	}
+	set, err := s.statusRepo.ListByProject(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+	if len(set) == 0 {
+		return domain.DefaultStatuses(), nil
+	}
+	return set, nil
+}
+
+func (s *taskService) SetProjectStatuses(ctx context.Context, userID, projectID int64, set domain.StatusSet) error {
+	p, err := s.projectFor(ctx, userID, projectID)
+	if err != nil {
+		return err
+	}
+	if p.Role != domain.WorkspaceRoleAdmin {
+		return domain.ErrForbidden
+	}
+	for i := range set {
+		set[i].Status = strings.TrimSpace(set[i].Status)
+		set[i].Label = strings.TrimSpace(set[i].Label)
+	}
+	if err := set.Validate(); err != nil {
+		return err
+	}
+	from, err := s.statuses(ctx, projectID)
+	if err != nil {
+		return err
+	}
+	return s.statusRepo.ReplaceForProject(ctx, projectID, set, statusRemap(from, set))
+}
+
+func validateTask(set domain.StatusSet, task *domain.Task) error {
+	task.Title = strings.TrimSpace(task.Title)
+	if task.Title == "" || task.OwnerID == 0 || task.WorkspaceID == 0 {
+		return domain.ErrInvalidInput
+	}
+	if !domain.ValidPriority(task.Priority) {
+		return domain.ErrInvalidInput
+	}
+	if _, ok := set.Lookup(task.Status); !ok {
+		return domain.ErrInvalidStatus
+	}
+	return nil
+}
+
+// decorate ใส่ข้อมูลที่ไม่ได้อยู่ในแถวของ tasks: category ของสถานะ ผู้รับผิดชอบ
+// และความคืบหน้าของงานย่อยชั้นถัดไปกับ checklist
+func (s *taskService) decorate(ctx context.Context, tasks []*domain.Task) error {
+	if err := s.categorizeAll(ctx, tasks); err != nil {
+		return err
+	}
+	ids := make([]int64, len(tasks))
+	for i, t := range tasks {
+		ids[i] = t.ID
+	}
+	assignees, err := s.peopleRepo.AssigneeIDs(ctx, ids)
+	if err != nil {
+		return err
+	}
+	children, err := s.taskRepo.ListChildren(ctx, ids)
+	if err != nil {
+		return err
+	}
+	if err := s.categorizeAll(ctx, children); err != nil {
+		return err
+	}
+	subtasks := map[int64]domain.Progress{}
+	for _, c := range children {
+		p := subtasks[c.ParentID.Int64]
+		p.Total++
+		if c.StatusCategory == domain.CategoryDone {
+			p.Done++
+		}
+		subtasks[c.ParentID.Int64] = p
+	}
+	checklist, err := s.checklistRepo.Counts(ctx, ids)
+	if err != nil {
+		return err
+	}
+
+	for _, t := range tasks {
+		t.AssigneeIDs = assignees[t.ID]
+		if t.AssigneeIDs == nil {
+			t.AssigneeIDs = []int64{}
+		}
+		t.Subtasks = subtasks[t.ID]
+		t.Checklist = checklist[t.ID]
+	}
+	return nil
+}
+
+// categorizeAll ใส่ category ให้งานหลายโปรเจกต์ (อ่านชุดสถานะโปรเจกต์ละครั้ง)
+func (s *taskService) categorizeAll(ctx context.Context, tasks []*domain.Task) error {
+	sets := map[int64]domain.StatusSet{}
+	for _, t := range tasks {
+		set, ok := sets[t.ProjectID.Int64]
+		if !ok {
+			var err error
+			if set, err = s.statuses(ctx, t.ProjectID.Int64); err != nil {
+				return err
+			}
+			sets[t.ProjectID.Int64] = set
+		}
+		categorize(set, t)
+	}
+	return nil
+}
+
+func categorize(set domain.StatusSet, t *domain.Task) {
+	if st, ok := set.Lookup(t.Status); ok {
+		t.StatusCategory = st.Category
+	}
+}
+
+func (s *taskService) Assignees(ctx context.Context, userID, taskID int64) ([]domain.TaskPerson, error) {
+	if _, err := s.taskRepo.GetForMember(ctx, taskID, userID); err != nil {
+		return nil, err
+	}
+	return s.peopleRepo.ListAssignees(ctx, taskID)
+}
+
+func (s *taskService) Watchers(ctx context.Context, userID, taskID int64) ([]domain.TaskPerson, error) {
+	if _, err := s.taskRepo.GetForMember(ctx, taskID, userID); err != nil {
+		return nil, err
+	}
+	return s.peopleRepo.ListWatchers(ctx, taskID)
+}
+
+// people ตรวจสิทธิ์ก่อนเพิ่ม/เอาคนออก: ทำกับตัวเอง (self = true) ได้ถ้าเห็นงาน ไม่งั้นต้องแก้งานได้
+func (s *taskService) people(ctx context.Context, actor Actor, taskID, targetID int64, self bool) error {
+	t, err := s.taskRepo.GetForMember(ctx, taskID, actor.UserID)
+	if err != nil {
+		return err
+	}
+	if self && actor.UserID == targetID {
+		return nil
+	}
+	return s.canModify(ctx, actor, t)
+}
+
+func (s *taskService) isAssignee(ctx context.Context, taskID, userID int64) (bool, error) {
+	ids, err := s.peopleRepo.AssigneeIDs(ctx, []int64{taskID})
+	if err != nil {
+		return false, err
+	}
+	for _, id := range ids[taskID] {
+		if id == userID {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (s *taskService) Assign(ctx context.Context, actor Actor, taskID, targetID int64) error {
+	if err := s.people(ctx, actor, taskID, targetID, false); err != nil {
+		return err
+	}
+	return memberErr(s.peopleRepo.Assign(ctx, taskID, targetID, actor.UserID))
+}
+
+func (s *taskService) Unassign(ctx context.Context, actor Actor, taskID, targetID int64) error {
+	if err := s.people(ctx, actor, taskID, targetID, true); err != nil {
+		return err
+	}
+	return memberErr(s.peopleRepo.Unassign(ctx, taskID, targetID))
+}
+
+func (s *taskService) Watch(ctx context.Context, actor Actor, taskID, targetID int64) error {
+	if err := s.people(ctx, actor, taskID, targetID, true); err != nil {
+		return err
+	}
+	return memberErr(s.peopleRepo.Watch(ctx, taskID, targetID))
+}
+
+func (s *taskService) Unwatch(ctx context.Context, actor Actor, taskID, targetID int64) error {
+	if err := s.people(ctx, actor, taskID, targetID, true); err != nil {
+		return err
+	}
+	return memberErr(s.peopleRepo.Unwatch(ctx, taskID, targetID))
+}
+
+// ChecklistUpdate: field ที่เป็น nil = ไม่เปลี่ยน
+type ChecklistUpdate struct {
+	Title *string
+	Done  *bool
+}
+
+func checklistTitle(title string) (string, error) {
+	title = strings.TrimSpace(title)
+	if title == "" || utf8.RuneCountInString(title) > maxChecklistTitle {
+		return "", domain.ErrInvalidInput
+	}
+	return title, nil
+}
+
+func checklistErr(err error) error {
+	if errors.Is(err, repo.ErrNotFound) {
+		return domain.ErrChecklistItemNotFound
+	}
+	return err
+}
+
+func (s *taskService) Checklist(ctx context.Context, userID, taskID int64) ([]domain.ChecklistItem, error) {
+	if _, err := s.taskRepo.GetForMember(ctx, taskID, userID); err != nil {
+		return nil, err
+	}
+	return s.checklistRepo.List(ctx, taskID)
+}
+
+// updatable คืนงานที่ actor แก้ได้ (เห็นไม่ได้ = ErrTaskNotFound)
+func (s *taskService) updatable(ctx context.Context, actor Actor, taskID int64) (*domain.Task, error) {
+	t, err := s.taskRepo.GetForMember(ctx, taskID, actor.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.canUpdate(ctx, actor, t); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+func (s *taskService) AddChecklistItem(ctx context.Context, actor Actor, taskID int64, title string) (*domain.ChecklistItem, error) {
+	title, err := checklistTitle(title)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.updatable(ctx, actor, taskID); err != nil {
+		return nil, err
+	}
+	counts, err := s.checklistRepo.Counts(ctx, []int64{taskID})
+	if err != nil {
+		return nil, err
+	}
+	if counts[taskID].Total >= domain.MaxChecklistItems {
+		return nil, domain.ErrChecklistFull
+	}
+	return s.checklistRepo.Create(ctx, &domain.ChecklistItem{TaskID: taskID, Title: title})
+}
+
+func (s *taskService) UpdateChecklistItem(ctx context.Context, actor Actor, taskID, itemID int64, in ChecklistUpdate) (*domain.ChecklistItem, error) {
+	if _, err := s.updatable(ctx, actor, taskID); err != nil {
+		return nil, err
+	}
+	it, err := s.checklistRepo.Get(ctx, taskID, itemID)
+	if err != nil {
+		return nil, checklistErr(err)
+	}
+	if in.Title != nil {
+		if it.Title, err = checklistTitle(*in.Title); err != nil {
+			return nil, err
+		}
+	}
+	if in.Done != nil {
+		it.Done = *in.Done
+	}
+	if err := s.checklistRepo.Update(ctx, it); err != nil {
+		return nil, checklistErr(err)
+	}
+	it, err = s.checklistRepo.Get(ctx, taskID, itemID)
+	return it, checklistErr(err)
+}
+
+func (s *taskService) DeleteChecklistItem(ctx context.Context, actor Actor, taskID, itemID int64) error {
+	if _, err := s.updatable(ctx, actor, taskID); err != nil {
+		return err
+	}
+	return checklistErr(s.checklistRepo.Delete(ctx, taskID, itemID))
+}
+
+func (s *taskService) MyWork(ctx context.Context, userID int64, fallbackTZ string) (*MyWork, error) {
+	prefs, err := s.users.GetPreferences(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if prefs.Timezone == "" && domain.ValidTimezone(fallbackTZ) {
+		prefs.Timezone = fallbackTZ
+	}
+	loc := prefs.Location()
+
+	tasks, err := s.taskRepo.ListAssignedTo(ctx, userID, maxTaskLimit)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.decorate(ctx, tasks); err != nil {
+		return nil, err
+	}
+
+	w := &MyWork{
+		Timezone: loc.String(),
+		Today:    domain.LocalToday(time.Now(), loc),
+		Groups:   map[domain.WorkGroup][]*domain.Task{},
+	}
+	for _, g := range domain.WorkGroups {
+		w.Groups[g] = []*domain.Task{}
+	}
+	for _, t := range tasks {
+		done := t.StatusCategory == domain.CategoryDone
+		if g, ok := domain.GroupForDue(t.DueDate.Time, t.DueDate.Valid, done, w.Today); ok {
+			w.Groups[g] = append(w.Groups[g], t)
+		}
+	}
+	return w, nil
+}
+
+// taskChanges คืนบรรทัด "field: เดิม → ใหม่" ของ field ที่เปลี่ยน (ว่าง = ไม่มีอะไรต้องแจ้ง)
+func taskChanges(before, after *domain.Task) []string {
+	date := func(d sql.NullTime) string {
+		if !d.Valid {
+			return "-"
+		}
+		return d.Time.Format("2006-01-02")
+	}
+	var out []string
+	add := func(field, from, to string) {
+		if from != to {
+			out = append(out, fmt.Sprintf("%s: %s → %s", field, from, to))
+		}
+	}
+	add("title", before.Title, after.Title)
+	add("status", before.Status, after.Status)
+	add("priority", before.Priority, after.Priority)
+	add("due_date", date(before.DueDate), date(after.DueDate))
+	if before.Description != after.Description {
+		out = append(out, "description")
+	}
+	if before.ProjectID != after.ProjectID {
+		out = append(out, "project")
+	}
+	if before.ParentID != after.ParentID {
+		out = append(out, "parent")
+	}
+	return out
+}
+
+// notifyTarget คืนภาษาของผู้รับ ok = false ถ้าผู้รับปิดการแจ้งเตือนทางอีเมลไว้
+func (s *taskService) notifyTarget(ctx context.Context, userID int64) (string, bool, error) {
+	prefs, err := s.users.GetPreferences(ctx, userID)
+	if err != nil {
+		return "", false, err
+	}
+	return i18n.Pick(prefs.Language, i18n.FromContext(ctx)), prefs.Notifications.Email, nil
+}
+
+// actorName: ชื่อผู้แก้ไขในอีเมล (ไม่ได้ตั้งชื่อ = อีเมล)
+func (s *taskService) actorName(ctx context.Context, actorID int64) string {
+	u, err := s.users.GetByID(ctx, actorID)
+	if err != nil {
+		return "Task Manager"
+	}
+	if u.Name.Valid && u.Name.String != "" {
+		return u.Name.String
+	}
+	return u.Email
+}
+
+func (s *taskService) NotifyWatchers(ctx context.Context, actorID int64, before, after *domain.Task) error {
+	changes := taskChanges(before, after)
+	if len(changes) == 0 {
+		return nil
+	}
+	watchers, err := s.peopleRepo.ListWatchers(ctx, after.ID)
+	if err != nil {
+		return err
+	}
+	actor := s.actorName(ctx, actorID)
+
+	var errs []error
+	for _, w := range watchers {
+		if w.UserID == actorID {
+			continue
+		}
+		loc, ok, err := s.notifyTarget(ctx, w.UserID)
+		if err != nil || !ok {
+			errs = append(errs, err)
+			continue
+		}
+		errs = append(errs, s.mailer.Send(ctx, mail.Message{
+			To:      w.Email,
+			Subject: i18n.T(loc, "email.task_updated.subject", after.Title),
+			Text:    i18n.T(loc, "email.task_updated.body", actor, after.Title, strings.Join(changes, "\n"), s.workURL),
+		}))
+	}
+	return errors.Join(errs...)
 }
 
-func (s *taskService) DeleteTask(ctx context.Context, id int, userID int) error {
-	return s.taskRepo.Delete(ctx, id, userID)
+func (s *taskService) NotifyAssigned(ctx context.Context, actorID, taskID, assigneeID int64) error {
+	if actorID == assigneeID {
+		return nil
+	}
+	t, err := s.taskRepo.GetForMember(ctx, taskID, assigneeID)
+	if err != nil {
+		return err
+	}
+	u, err := s.users.GetByID(ctx, assigneeID)
+	if err != nil {
+		return err
+	}
+	loc, ok, err := s.notifyTarget(ctx, assigneeID)
+	if err != nil || !ok {
+		return err
+	}
+	return s.mailer.Send(ctx, mail.Message{
+		To:      u.Email,
+		Subject: i18n.T(loc, "email.task_assigned.subject", t.Title),
+		Text:    i18n.T(loc, "email.task_assigned.body", s.actorName(ctx, actorID), t.Title, s.workURL),
+	})
 }
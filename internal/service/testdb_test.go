@@ -0,0 +1,40 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"testing"
+
+	"task-manager/internal/db"
+	"task-manager/internal/domain"
+	"task-manager/internal/repo"
+)
+
+// newTestDB เปิด SQLite ไฟล์ใหม่ต่อ test แล้วรัน migration ชุดเดียวกับ production
+// (ไม่เปิด foreign_keys เหมือน MustOpen ใน production)
+func newTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+	database := repo.MustOpen("file:" + filepath.Join(t.TempDir(), "test.db"))
+	t.Cleanup(func() { database.Close() })
+	if err := db.RunMigrations(database); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	return database
+}
+
+// createTestUser สมัครผู้ใช้ตรงที่ repo (ไม่ผ่าน password policy / อีเมลยืนยัน)
+// password hash เป็นค่าหลอก แค่ให้มีวิธี login อื่นนอกจาก passkey / identity
+func createTestUser(t *testing.T, users repo.UserRepo, email string) *domain.User {
+	t.Helper()
+	u, err := users.Create(context.Background(), &domain.User{
+		Email:        email,
+		Username:     sql.NullString{String: email, Valid: true},
+		PasswordHash: sql.NullString{String: "not-a-real-hash", Valid: true},
+		Role:         "user",
+	})
+	if err != nil {
+		t.Fatalf("create user %s: %v", email, err)
+	}
+	return u
+}
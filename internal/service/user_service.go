@@ -2,8 +2,12 @@ package service
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"task-manager/internal/auth"
 	"task-manager/internal/domain"
+	"task-manager/internal/i18n"
 	"task-manager/internal/repo"
 )
 
@@ -12,17 +16,32 @@ type UserService interface {
 	UpdateName(ctx context.Context, id int64, name string) error
 	UpdateUsername(ctx context.Context, id int64, username string) error
 	UpdatePassword(ctx context.Context, id int64, password string) error
+
+	// UpdateLocale ตั้งภาษาที่ใช้กับข้อความ API และอีเมล ("" = ตาม Accept-Language)
+	UpdateLocale(ctx context.Context, id int64, locale string) error
+
+	// Preferences: ค่าที่ยังไม่เคยตั้ง = domain.DefaultPreferences()
+	GetPreferences(ctx context.Context, id int64) (*domain.Preferences, error)
+	UpdatePreferences(ctx context.Context, id int64, p *domain.Preferences) error
+	ResetPreferences(ctx context.Context, id int64) error
+
+	// SetRole: actor ต้องมี user:manage (ตรวจจาก role ใน DB ไม่ใช่ใน token) และเปลี่ยน role ตัวเองไม่ได้
+	SetRole(ctx context.Context, actorID, targetID int64, role string) error
 }
 
 type userService struct {
-	userRepo repo.UserRepo
-	hasher   auth.PasswordHasher
+	userRepo  repo.UserRepo
+	prefsRepo repo.PreferencesRepo
+	hasher    auth.PasswordHasher
+	policy    auth.PasswordPolicy
 }
 
-func NewUserService(userRepo repo.UserRepo, hasher auth.PasswordHasher) UserService {
+func NewUserService(userRepo repo.UserRepo, prefsRepo repo.PreferencesRepo, hasher auth.PasswordHasher, policy auth.PasswordPolicy) UserService {
 	return &userService{
-		userRepo: userRepo,
-		hasher:   hasher,
+		userRepo:  userRepo,
+		prefsRepo: prefsRepo,
+		hasher:    hasher,
+		policy:    policy,
 	}
 }
 
@@ -39,9 +58,129 @@ func (s *userService) UpdateUsername(ctx context.Context, id int64, username str
 }
 
 func (s *userService) UpdatePassword(ctx context.Context, id int64, password string) error {
+	u, err := s.userRepo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := s.policy.Validate(password, u.Email, u.Username.String); err != nil {
+		return err
+	}
 	hashedPassword, err := s.hasher.Hash(password)
 	if err != nil {
 		return err
 	}
 	return s.userRepo.UpdatePassword(ctx, id, hashedPassword)
 }
+
+func (s *userService) UpdateLocale(ctx context.Context, id int64, locale string) error {
+	if locale != "" {
+		l, ok := i18n.Normalize(locale)
+		if !ok {
+			return domain.ErrInvalidInput
+		}
+		locale = l
+	}
+	if err := s.userRepo.UpdateLocale(ctx, id, locale); err != nil {
+		if errors.Is(err, repo.ErrNotFound) {
+			return domain.ErrUserNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+func (s *userService) SetRole(ctx context.Context, actorID, targetID int64, role string) error {
+	if !domain.ValidRole(role) {
+		return domain.ErrInvalidRole
+	}
+	actor, err := s.userRepo.GetByID(ctx, actorID)
+	if err != nil {
+		return err
+	}
+	if !domain.HasPermission(actor.Role, domain.PermUserManage) || actorID == targetID {
+		return domain.ErrForbidden
+	}
+	if err := s.userRepo.UpdateRole(ctx, targetID, role); err != nil {
+		if errors.Is(err, repo.ErrNotFound) {
+			return domain.ErrUserNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+// preferenceUpgrades[v] แปลง JSON ของ version v เป็น v+1 (เปลี่ยนชื่อ/ความหมาย key)
+// ยังไม่มีเพราะมีแค่ version 1
+var preferenceUpgrades = map[int]func(raw map[string]any){}
+
+func (s *userService) GetPreferences(ctx context.Context, id int64) (*domain.Preferences, error) {
+	u, err := s.userRepo.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, repo.ErrNotFound) {
+			return nil, domain.ErrUserNotFound
+		}
+		return nil, err
+	}
+
+	p := domain.DefaultPreferences()
+	version, data, err := s.prefsRepo.Get(ctx, id)
+	switch {
+	case err == nil:
+		if err := decodePreferences(&p, version, data); err != nil {
+			return nil, err
+		}
+	case !errors.Is(err, repo.ErrNotFound):
+		return nil, err
+	}
+	p.Language = u.Locale.String
+	return &p, nil
+}
+
+// decodePreferences วางค่าที่บันทึกไว้ทับค่า default: key ที่ไม่มี (setting ใหม่) ได้ default, key ที่เลิกใช้ถูกข้าม
+func decodePreferences(p *domain.Preferences, version int, data []byte) error {
+	raw := map[string]any{}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return fmt.Errorf("decode preferences v%d: %w", version, err)
+	}
+	for v := version; v < domain.PreferencesVersion; v++ {
+		if up, ok := preferenceUpgrades[v]; ok {
+			up(raw)
+		}
+	}
+	delete(raw, "version")
+	delete(raw, "language")
+	b, err := json.Marshal(raw)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(b, p); err != nil {
+		return fmt.Errorf("decode preferences v%d: %w", version, err)
+	}
+	p.Version = domain.PreferencesVersion
+	return nil
+}
+
+func (s *userService) UpdatePreferences(ctx context.Context, id int64, p *domain.Preferences) error {
+	if !domain.ValidTheme(p.Theme) || !domain.ValidTimezone(p.Timezone) {
+		return domain.ErrInvalidInput
+	}
+	if err := s.UpdateLocale(ctx, id, p.Language); err != nil {
+		return err
+	}
+
+	stored := *p
+	stored.Version = domain.PreferencesVersion
+	stored.Language = ""
+	data, err := json.Marshal(stored)
+	if err != nil {
+		return err
+	}
+	return s.prefsRepo.Upsert(ctx, id, domain.PreferencesVersion, data)
+}
+
+func (s *userService) ResetPreferences(ctx context.Context, id int64) error {
+	if err := s.UpdateLocale(ctx, id, ""); err != nil {
+		return err
+	}
+	return s.prefsRepo.Delete(ctx, id)
+}
@@ -0,0 +1,154 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"unicode/utf8"
+
+	"task-manager/internal/domain"
+	"task-manager/internal/repo"
+)
+
+const maxWorkspaceName = 100
+
+type WorkspaceService interface {
+	// List คืน workspace ที่ผู้ใช้เป็นสมาชิก (มี workspace ส่วนตัวเสมอ)
+	List(ctx context.Context, userID int64) ([]*domain.Workspace, error)
+	Get(ctx context.Context, userID, id int64) (*domain.Workspace, error)
+	Create(ctx context.Context, userID int64, name string) (*domain.Workspace, error)
+
+	// ต้องเป็น admin ของ workspace
+	Rename(ctx context.Context, userID, id int64, name string) (*domain.Workspace, error)
+	Delete(ctx context.Context, userID, id int64) error
+
+	Members(ctx context.Context, userID, id int64) ([]domain.WorkspaceMember, error)
+	SetMemberRole(ctx context.Context, actorID, id, targetID int64, role string) error
+
+	// admin เอาคนอื่นออกได้ สมาชิกทุกคนออกเองได้ (actorID == targetID)
+	RemoveMember(ctx context.Context, actorID, id, targetID int64) error
+}
+
+type workspaceService struct {
+	workspaceRepo repo.WorkspaceRepo
+}
+
+func NewWorkspaceService(workspaceRepo repo.WorkspaceRepo) WorkspaceService {
+	return &workspaceService{workspaceRepo: workspaceRepo}
+}
+
+func (s *workspaceService) List(ctx context.Context, userID int64) ([]*domain.Workspace, error) {
+	if _, err := s.workspaceRepo.EnsurePersonal(ctx, userID); err != nil {
+		return nil, err
+	}
+	return s.workspaceRepo.ListByMember(ctx, userID)
+}
+
+func (s *workspaceService) Get(ctx context.Context, userID, id int64) (*domain.Workspace, error) {
+	w, err := s.workspaceRepo.GetForMember(ctx, id, userID)
+	if errors.Is(err, repo.ErrNotFound) {
+		return nil, domain.ErrWorkspaceNotFound
+	}
+	return w, err
+}
+
+// admin คืน workspace ถ้า userID เป็น admin (สมาชิกธรรมดา = ErrForbidden)
+func (s *workspaceService) admin(ctx context.Context, userID, id int64) (*domain.Workspace, error) {
+	w, err := s.Get(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	if w.Role != domain.WorkspaceRoleAdmin {
+		return nil, domain.ErrForbidden
+	}
+	return w, nil
+}
+
+func workspaceName(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || utf8.RuneCountInString(name) > maxWorkspaceName {
+		return "", domain.ErrInvalidInput
+	}
+	return name, nil
+}
+
+func (s *workspaceService) Create(ctx context.Context, userID int64, name string) (*domain.Workspace, error) {
+	name, err := workspaceName(name)
+	if err != nil {
+		return nil, err
+	}
+	return s.workspaceRepo.Create(ctx, name, userID)
+}
+
+func (s *workspaceService) Rename(ctx context.Context, userID, id int64, name string) (*domain.Workspace, error) {
+	name, err := workspaceName(name)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.admin(ctx, userID, id); err != nil {
+		return nil, err
+	}
+	if err := s.workspaceRepo.Rename(ctx, id, name); err != nil {
+		if errors.Is(err, repo.ErrNotFound) {
+			return nil, domain.ErrWorkspaceNotFound
+		}
+		return nil, err
+	}
+	return s.Get(ctx, userID, id)
+}
+
+func (s *workspaceService) Delete(ctx context.Context, userID, id int64) error {
+	w, err := s.admin(ctx, userID, id)
+	if err != nil {
+		return err
+	}
+	if w.Personal() {
+		return domain.ErrPersonalWorkspace
+	}
+	if err := s.workspaceRepo.Delete(ctx, id); err != nil {
+		if errors.Is(err, repo.ErrNotFound) {
+			return domain.ErrWorkspaceNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+func (s *workspaceService) Members(ctx context.Context, userID, id int64) ([]domain.WorkspaceMember, error) {
+	if _, err := s.Get(ctx, userID, id); err != nil {
+		return nil, err
+	}
+	return s.workspaceRepo.ListMembers(ctx, id)
+}
+
+func (s *workspaceService) SetMemberRole(ctx context.Context, actorID, id, targetID int64, role string) error {
+	if !domain.ValidWorkspaceRole(role) {
+		return domain.ErrInvalidRole
+	}
+	if _, err := s.admin(ctx, actorID, id); err != nil {
+		return err
+	}
+	return memberErr(s.workspaceRepo.UpdateMemberRole(ctx, id, targetID, role))
+}
+
+func (s *workspaceService) RemoveMember(ctx context.Context, actorID, id, targetID int64) error {
+	w, err := s.Get(ctx, actorID, id)
+	if err != nil {
+		return err
+	}
+	if actorID != targetID && w.Role != domain.WorkspaceRoleAdmin {
+		return domain.ErrForbidden
+	}
+	if w.Personal() {
+		// เจ้าของออกจาก workspace ส่วนตัวไม่ได้ (และไม่มีสมาชิกคนอื่นให้เอาออก)
+		return domain.ErrPersonalWorkspace
+	}
+	return memberErr(s.workspaceRepo.RemoveMember(ctx, id, targetID))
+}
+
+func memberErr(err error) error {
+	if errors.Is(err, repo.ErrNotFound) {
+		return domain.ErrMemberNotFound
+	}
+	return err
+}
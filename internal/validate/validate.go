@@ -0,0 +1,241 @@
+// Package validate checks request DTOs declared with `binding` tags and
+// reports every invalid field at once as response.FieldError (ข้อความไทย/อังกฤษ).
+//
+// Custom tags (ใช้ร่วมกับ tag มาตรฐานของ validator เช่น required, email, max):
+//
+//	notblank  ไม่ใช่ค่าว่างหรือมีแต่ช่องว่าง
+//	username  3-30 ตัว a-z A-Z 0-9 _ .
+//	password  ผ่าน policy ที่ตั้งด้วย SetPasswordPolicy (ค่าเริ่มต้น auth.DefaultPasswordPolicy)
+//	date      YYYY-MM-DD อยู่ระหว่าง MinDate ถึง MaxDate ("" ผ่าน = ไม่ระบุวันที่)
+//	locale    ภาษาที่รองรับใน i18n ("" ผ่าน = ไม่ตั้งค่า)
+//	color     สีแบบ #rrggbb ("" ผ่าน = ใช้สี default)
+//	timezone  ชื่อ IANA เช่น Asia/Bangkok ("" ผ่าน = UTC)
+//
+// ข้อความอยู่ใน i18n catalogue ใต้ key "validation.<code>"
+package validate
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"reflect"
+	"regexp"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"task-manager/internal/auth"
+	"task-manager/internal/domain"
+	"task-manager/internal/i18n"
+	"task-manager/pkg/response"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+const DateLayout = "2006-01-02"
+
+// ช่วงวันที่ที่ยอมรับ (กันพิมพ์ปีผิดเช่น 0202 หรือ 20255)
+var (
+	MinDate = time.Date(1970, 1, 1, 0, 0, 0, 0, time.UTC)
+	MaxDate = time.Date(2100, 12, 31, 0, 0, 0, 0, time.UTC)
+)
+
+var (
+	usernameRe = regexp.MustCompile(`^[A-Za-z0-9_.]{3,30}$`)
+	colorRe    = regexp.MustCompile(`^#[0-9A-Fa-f]{6}$`)
+)
+
+// passwordPolicy คือ policy ของ tag password (ตั้งจาก config ตอนเริ่ม server)
+var passwordPolicy atomic.Pointer[auth.PasswordPolicy]
+
+// SetPasswordPolicy sets the policy the password tag checks against
+// (ต้องเป็นชุดเดียวกับที่ service ใช้ ไม่งั้น PASSWORD_MIN_LENGTH ที่ตั้งไว้ไม่มีผลกับ request)
+func SetPasswordPolicy(p auth.PasswordPolicy) { passwordPolicy.Store(&p) }
+
+func currentPasswordPolicy() auth.PasswordPolicy {
+	if p := passwordPolicy.Load(); p != nil {
+		return *p
+	}
+	return auth.DefaultPasswordPolicy
+}
+
+// Checker is implemented by DTOs with rules tags can't express (เช่น field ที่ต้องมีเฉพาะตอนสร้าง)
+type Checker interface {
+	Check() []response.FieldError
+}
+
+func init() {
+	v, ok := binding.Validator.Engine().(*validator.Validate)
+	if !ok {
+		panic("validate: gin validator engine is not go-playground/validator")
+	}
+	// รายงานชื่อ field ตาม json tag ให้ตรงกับที่ client ส่งมา
+	v.RegisterTagNameFunc(func(f reflect.StructField) string {
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "-" {
+			return ""
+		}
+		if name == "" {
+			return f.Name
+		}
+		return name
+	})
+	must(v.RegisterValidation("notblank", func(fl validator.FieldLevel) bool {
+		return strings.TrimSpace(fl.Field().String()) != ""
+	}))
+	must(v.RegisterValidation("username", func(fl validator.FieldLevel) bool {
+		return usernameRe.MatchString(fl.Field().String())
+	}))
+	must(v.RegisterValidation("password", func(fl validator.FieldLevel) bool {
+		return currentPasswordPolicy().Validate(fl.Field().String()) == nil
+	}))
+	// omitempty ไม่ดูค่าใน pointer (*string ที่ชี้ "") จึงให้ "" ผ่านที่นี่ ใช้ required ถ้าต้องมี
+	must(v.RegisterValidation("locale", func(fl validator.FieldLevel) bool {
+		_, ok := i18n.Normalize(fl.Field().String())
+		return ok || fl.Field().String() == ""
+	}))
+	must(v.RegisterValidation("color", func(fl validator.FieldLevel) bool {
+		return fl.Field().String() == "" || colorRe.MatchString(fl.Field().String())
+	}))
+	must(v.RegisterValidation("timezone", func(fl validator.FieldLevel) bool {
+		return domain.ValidTimezone(fl.Field().String())
+	}))
+	must(v.RegisterValidation("date", func(fl validator.FieldLevel) bool {
+		s := fl.Field().String()
+		if s == "" {
+			return true
+		}
+		_, ok := ParseDate(s)
+		return ok
+	}))
+}
+
+func must(err error) {
+	if err != nil {
+		panic(err)
+	}
+}
+
+// ParseDate parses YYYY-MM-DD and checks it is within MinDate..MaxDate
+func ParseDate(s string) (time.Time, bool) {
+	d, err := time.Parse(DateLayout, s)
+	if err != nil || d.Before(MinDate) || d.After(MaxDate) {
+		return time.Time{}, false
+	}
+	return d, true
+}
+
+// Bind decodes the JSON body into dst and validates it. On failure it writes
+// 400 (validation_failed พร้อม details ทุก field หรือ invalid_input ถ้า JSON เสีย) and returns false.
+// body ว่างถือเป็น {} เพื่อให้ required รายงานครบทุก field
+func Bind(c *gin.Context, dst any) bool {
+	err := c.ShouldBindJSON(dst)
+	if errors.Is(err, io.EOF) {
+		err = binding.Validator.ValidateStruct(dst)
+	}
+	return report(c, dst, err)
+}
+
+// BindStrict is Bind that also rejects keys dst does not declare (ใช้กับ schema ที่ต้องตรงเป๊ะ
+// เช่น preferences จะได้ไม่บันทึก key ที่พิมพ์ผิดแล้วเงียบหายไป)
+func BindStrict(c *gin.Context, dst any) bool {
+	dec := json.NewDecoder(c.Request.Body)
+	dec.DisallowUnknownFields()
+	err := dec.Decode(dst)
+	if err == nil || errors.Is(err, io.EOF) {
+		err = binding.Validator.ValidateStruct(dst)
+	}
+	if field, ok := strings.CutPrefix(errString(err), "json: unknown field "); ok {
+		lang := i18n.FromContext(c.Request.Context())
+		field = strings.Trim(field, `"`)
+		response.Invalid(c, i18n.T(lang, response.CodeValidationFailed),
+			response.FieldError{Field: field, Code: "unknown", Message: message(lang, "unknown")})
+		return false
+	}
+	return report(c, dst, err)
+}
+
+func errString(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
+
+// report เขียน 400 จาก error ของการ decode/validate แล้วคืน false (err == nil และไม่มี Checker error = true)
+func report(c *gin.Context, dst any, err error) bool {
+	lang := i18n.FromContext(c.Request.Context())
+	var details []response.FieldError
+	var verrs validator.ValidationErrors
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case err == nil:
+	case errors.As(err, &verrs):
+		for _, fe := range verrs {
+			details = append(details, fieldError(lang, fe))
+		}
+	case errors.As(err, &typeErr):
+		// ชนิดข้อมูลผิด decode หยุดที่ field แรก จึงรายงานได้ field เดียว
+		details = append(details, response.FieldError{
+			Field: typeErr.Field, Code: "type", Message: message(lang, "type"),
+		})
+	default:
+		response.FailTranslated(c, http.StatusBadRequest, response.CodeInvalidInput, i18n.T(lang, "validation.invalid_json"))
+		return false
+	}
+
+	if ch, ok := dst.(Checker); ok && typeErr == nil {
+		for _, fe := range ch.Check() {
+			fe.Message = message(lang, fe.Code, fe.Field)
+			details = append(details, fe)
+		}
+	}
+	if len(details) > 0 {
+		response.Invalid(c, i18n.T(lang, response.CodeValidationFailed), details...)
+		return false
+	}
+	return true
+}
+
+// fieldError แปลง error ของ validator เป็น FieldError โดย code = ชื่อ tag
+func fieldError(lang string, fe validator.FieldError) response.FieldError {
+	field := fe.Field() // DTO เป็น struct ชั้นเดียว ใช้ชื่อ json ของ field ตรงๆ
+	code := fe.Tag()
+	switch code {
+	case "required", "notblank":
+		code = "required"
+		return response.FieldError{Field: field, Code: code, Message: message(lang, code, field)}
+	case "password":
+		// บอกเหตุผลจริงจาก policy (สั้นไป / ยอดนิยม)
+		var pe *auth.PasswordPolicyError
+		if errors.As(currentPasswordPolicy().Validate(fe.Value().(string)), &pe) {
+			key, args := pe.I18nKey()
+			return response.FieldError{Field: field, Code: pe.Code, Message: i18n.T(lang, key, args...)}
+		}
+	case "min", "max", "len":
+		// string/slice = จำนวนตัวอักษร/รายการ, ตัวเลข = ค่า
+		switch fe.Kind() {
+		case reflect.String, reflect.Slice, reflect.Map, reflect.Array:
+			return response.FieldError{Field: field, Code: code, Message: message(lang, code+"_length", fe.Param())}
+		}
+		return response.FieldError{Field: field, Code: code, Message: message(lang, code, fe.Param())}
+	case "oneof":
+		return response.FieldError{Field: field, Code: code, Message: message(lang, code, strings.ReplaceAll(fe.Param(), " ", ", "))}
+	case "date":
+		return response.FieldError{Field: field, Code: code,
+			Message: message(lang, code, MinDate.Format(DateLayout), MaxDate.Format(DateLayout))}
+	}
+	return response.FieldError{Field: field, Code: code, Message: message(lang, code)}
+}
+
+// message หาข้อความ validation ของ code (ไม่รู้จัก code = "invalid")
+func message(lang, code string, args ...any) string {
+	key := "validation." + code
+	if !i18n.Has(key) {
+		return i18n.T(lang, "validation.invalid")
+	}
+	return i18n.T(lang, key, args...)
+}
@@ -0,0 +1,87 @@
+package response
+
+import (
+	"errors"
+	"net/http"
+
+	"task-manager/internal/domain"
+)
+
+// Generic codes สำหรับ error ที่ไม่ได้มาจาก domain
+const (
+	CodeInvalidInput     = "invalid_input"
+	CodeValidationFailed = "validation_failed"
+	CodeUnauthorized     = "unauthorized"
+	CodeForbidden        = "forbidden"
+	CodeNotFound         = "not_found"
+	CodeConflict         = "conflict"
+	CodeTooManyRequests  = "too_many_requests"
+	CodeInternal         = "internal_error"
+)
+
+type mapping struct {
+	err    error
+	status int
+	code   string
+}
+
+// mappings: code เป็นสัญญากับ client ห้ามเปลี่ยนชื่อ (เพิ่มใหม่ได้)
+var mappings = []mapping{
+	{domain.ErrInvalidInput, http.StatusBadRequest, CodeInvalidInput},
+	{domain.ErrInvalidCredentials, http.StatusUnauthorized, "invalid_credentials"},
+	{domain.ErrUserNotFound, http.StatusNotFound, "user_not_found"},
+	{domain.ErrEmailExists, http.StatusConflict, "email_exists"},
+	{domain.ErrEmailAlreadyExists, http.StatusConflict, "email_exists"},
+	{domain.ErrEmailNotFound, http.StatusNotFound, "email_not_found"},
+	{domain.ErrUsernameAlreadyExists, http.StatusConflict, "username_exists"},
+	{domain.ErrUnauthorized, http.StatusUnauthorized, CodeUnauthorized},
+	{domain.ErrTaskNotFound, http.StatusNotFound, "task_not_found"},
+	{domain.ErrInvalidStatus, http.StatusBadRequest, "invalid_status"},
+	{domain.ErrInvalidStatusSet, http.StatusBadRequest, "invalid_status_set"},
+	{domain.ErrInvalidRefreshToken, http.StatusUnauthorized, "invalid_refresh_token"},
+	{domain.ErrRefreshTokenReused, http.StatusUnauthorized, "refresh_token_reused"},
+	{domain.ErrSessionNotFound, http.StatusNotFound, "session_not_found"},
+	{domain.ErrSessionRevoked, http.StatusUnauthorized, "session_revoked"},
+	{domain.ErrInvalidToken, http.StatusBadRequest, "invalid_token"},
+	{domain.ErrEmailNotVerified, http.StatusForbidden, "email_not_verified"},
+	{domain.ErrTooManyRequests, http.StatusTooManyRequests, CodeTooManyRequests},
+	{domain.ErrForbidden, http.StatusForbidden, CodeForbidden},
+	{domain.ErrMFANotEnabled, http.StatusBadRequest, "mfa_not_enabled"},
+	{domain.ErrMFAAlreadyEnabled, http.StatusConflict, "mfa_already_enabled"},
+	{domain.ErrInvalidMFACode, http.StatusUnauthorized, "invalid_mfa_code"},
+	{domain.ErrPasskeyNotFound, http.StatusNotFound, "passkey_not_found"},
+	{domain.ErrInvalidPasskey, http.StatusUnauthorized, "invalid_passkey"},
+	{domain.ErrIdentityNotFound, http.StatusNotFound, "identity_not_found"},
+	{domain.ErrIdentityInUse, http.StatusConflict, "identity_in_use"},
+	{domain.ErrIdentityNotLinked, http.StatusConflict, "identity_not_linked"},
+	{domain.ErrLastLoginMethod, http.StatusConflict, "last_login_method"},
+	{domain.ErrReauthRequired, http.StatusUnauthorized, "reauth_required"},
+	{domain.ErrWeakPassword, http.StatusBadRequest, "weak_password"},
+	{domain.ErrInvalidRole, http.StatusBadRequest, "invalid_role"},
+	{domain.ErrLastAdmin, http.StatusConflict, "last_admin"},
+	{domain.ErrAccessTokenNotFound, http.StatusNotFound, "access_token_not_found"},
+	{domain.ErrAccessTokenLimit, http.StatusConflict, "access_token_limit"},
+	{domain.ErrInvalidScope, http.StatusBadRequest, "invalid_scope"},
+	{domain.ErrWorkspaceNotFound, http.StatusNotFound, "workspace_not_found"},
+	{domain.ErrPersonalWorkspace, http.StatusConflict, "personal_workspace"},
+	{domain.ErrMemberNotFound, http.StatusNotFound, "member_not_found"},
+	{domain.ErrAlreadyMember, http.StatusConflict, "already_member"},
+	{domain.ErrInvitationNotFound, http.StatusNotFound, "invitation_not_found"},
+	{domain.ErrInviteEmailMismatch, http.StatusForbidden, "invitation_email_mismatch"},
+	{domain.ErrProjectNotFound, http.StatusNotFound, "project_not_found"},
+	{domain.ErrProjectArchived, http.StatusConflict, "project_archived"},
+	{domain.ErrInvalidParent, http.StatusBadRequest, "invalid_parent"},
+	{domain.ErrTaskTooDeep, http.StatusBadRequest, "task_too_deep"},
+	{domain.ErrChecklistItemNotFound, http.StatusNotFound, "checklist_item_not_found"},
+	{domain.ErrChecklistFull, http.StatusConflict, "checklist_full"},
+}
+
+// Lookup returns the status and code for err (รองรับ error ที่ wrap ด้วย %w)
+func Lookup(err error) (int, string, bool) {
+	for _, m := range mappings {
+		if errors.Is(err, m.err) {
+			return m.status, m.code, true
+		}
+	}
+	return 0, "", false
+}
@@ -0,0 +1,21 @@
+package response
+
+import (
+	"testing"
+
+	"task-manager/internal/i18n"
+)
+
+// ทุก code ที่ส่งให้ client ต้องมีคำแปลใน i18n catalogue (เพิ่ม error ใหม่แล้วลืมแปล = เทสต์ไม่ผ่าน)
+func TestEveryErrorCodeIsTranslated(t *testing.T) {
+	codes := []string{CodeInvalidInput, CodeValidationFailed, CodeUnauthorized, CodeForbidden,
+		CodeNotFound, CodeConflict, CodeTooManyRequests, CodeInternal}
+	for _, m := range mappings {
+		codes = append(codes, m.code)
+	}
+	for _, code := range codes {
+		if !i18n.Has(code) {
+			t.Errorf("no translation for error code %s", code)
+		}
+	}
+}
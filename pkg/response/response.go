@@ -1,3 +1,125 @@
+// Package response writes every JSON reply in one shape:
+// errors are {"error": message, "code": stable_code, "details": [...], "request_id": "..."}
+// ("error" ยังเป็นข้อความเหมือนเดิม client เก่าไม่พัง ส่วน client ใหม่ให้ดูที่ "code")
+//
+// ข้อความแปลตามภาษาของ request (i18n.FromContext): ภาษาอังกฤษใช้ข้อความที่ handler ส่งมา
+// ภาษาอื่นใช้คำแปลของ code ใน catalogue (ไม่มีคำแปล = ข้อความเดิม)
 package response
 
-// Response utilities
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"task-manager/internal/i18n"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequestIDKey is where middleware.RequestID stores the id in gin.Context
+const RequestIDKey = "request_id"
+
+// FieldError is one invalid input field
+type FieldError struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// Required is the FieldError for a missing field
+func Required(field string) FieldError {
+	return FieldError{Field: field, Code: "required", Message: field + " is required"}
+}
+
+// Page describes one page of a list (limit/offset)
+type Page struct {
+	Limit  int `json:"limit"`
+	Offset int `json:"offset"`
+	Total  int `json:"total"`
+}
+
+func RequestID(c *gin.Context) string { return c.GetString(RequestIDKey) }
+
+// Error maps err (domain.Err*) to its status and code; error ที่ไม่อยู่ในตาราง = 500
+// โดยใช้ fallback เป็นข้อความ และ log error จริงไว้ (ไม่ส่งรายละเอียดภายในให้ client)
+func Error(c *gin.Context, err error, fallback string) {
+	if status, code, ok := Lookup(err); ok {
+		var keyed i18n.Keyed
+		if errors.As(err, &keyed) {
+			key, args := keyed.I18nKey()
+			write(c, status, code, i18n.T(locale(c), key, args...), nil, nil)
+			return
+		}
+		write(c, status, code, translate(c, code, err.Error()), nil, nil)
+		return
+	}
+	log.Printf("request %s: %s: %v", RequestID(c), fallback, err)
+	write(c, http.StatusInternalServerError, CodeInternal, translate(c, CodeInternal, fallback), nil, nil)
+}
+
+// Fail writes an error that does not come from a domain error
+func Fail(c *gin.Context, status int, code, message string) {
+	write(c, status, code, translate(c, code, message), nil, nil)
+}
+
+// FailWith is Fail plus extra top-level fields (เช่น retry_after)
+func FailWith(c *gin.Context, status int, code, message string, extra gin.H) {
+	write(c, status, code, translate(c, code, message), nil, extra)
+}
+
+// FailTranslated is Fail with a message the caller already translated (ไม่แทนด้วยคำแปลของ code)
+func FailTranslated(c *gin.Context, status int, code, message string) {
+	write(c, status, code, message, nil, nil)
+}
+
+// Invalid writes 400 validation_failed with per-field details (message แปลมาแล้วจาก validate)
+func Invalid(c *gin.Context, message string, details ...FieldError) {
+	write(c, http.StatusBadRequest, CodeValidationFailed, message, details, nil)
+}
+
+func write(c *gin.Context, status int, code, message string, details []FieldError, extra gin.H) {
+	body := gin.H{}
+	for k, v := range extra {
+		body[k] = v
+	}
+	body["error"] = message
+	body["code"] = code
+	if len(details) > 0 {
+		body["details"] = details
+	}
+	if id := RequestID(c); id != "" {
+		body["request_id"] = id
+	}
+	c.AbortWithStatusJSON(status, body)
+}
+
+func locale(c *gin.Context) string { return i18n.FromContext(c.Request.Context()) }
+
+// translate: อังกฤษใช้ข้อความของ handler ตามเดิม ภาษาอื่นใช้คำแปลของ code
+func translate(c *gin.Context, code, message string) string {
+	if loc := locale(c); loc != i18n.EN {
+		if msg, ok := i18n.Lookup(loc, code); ok {
+			return msg
+		}
+	}
+	return message
+}
+
+func OK(c *gin.Context, data any) { c.JSON(http.StatusOK, data) }
+
+func Created(c *gin.Context, data any) { c.JSON(http.StatusCreated, data) }
+
+func NoContent(c *gin.Context) { c.Status(http.StatusNoContent) }
+
+// Paginated writes {key: items, "pagination": {limit, offset, total, has_more}}
+func Paginated(c *gin.Context, key string, items any, p Page) {
+	c.JSON(http.StatusOK, gin.H{
+		key: items,
+		"pagination": gin.H{
+			"limit":    p.Limit,
+			"offset":   p.Offset,
+			"total":    p.Total,
+			"has_more": p.Offset+p.Limit < p.Total,
+		},
+	})
+}